@@ -2,36 +2,67 @@ package bot
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 
 	// Import commands so that each package's init() is run.
+	_ "github.com/cpu/gorfbot/botcmd/calc"
 	_ "github.com/cpu/gorfbot/botcmd/echo"
 	_ "github.com/cpu/gorfbot/botcmd/emoji"
+	_ "github.com/cpu/gorfbot/botcmd/emojiannounce"
 	_ "github.com/cpu/gorfbot/botcmd/frogtip"
 	_ "github.com/cpu/gorfbot/botcmd/gis"
+	_ "github.com/cpu/gorfbot/botcmd/gorfsay"
+	_ "github.com/cpu/gorfbot/botcmd/gorfsaylearn"
+	_ "github.com/cpu/gorfbot/botcmd/halloffame"
 	_ "github.com/cpu/gorfbot/botcmd/hello"
+	_ "github.com/cpu/gorfbot/botcmd/karma"
+	_ "github.com/cpu/gorfbot/botcmd/karmaupdate"
+	_ "github.com/cpu/gorfbot/botcmd/leaderboard"
 	_ "github.com/cpu/gorfbot/botcmd/mktheme"
+	_ "github.com/cpu/gorfbot/botcmd/onthisday"
 	_ "github.com/cpu/gorfbot/botcmd/panoptimoji"
+	_ "github.com/cpu/gorfbot/botcmd/poll"
+	_ "github.com/cpu/gorfbot/botcmd/pollvote"
+	_ "github.com/cpu/gorfbot/botcmd/quote"
+	_ "github.com/cpu/gorfbot/botcmd/quotereact"
 	_ "github.com/cpu/gorfbot/botcmd/rarepattern"
+	_ "github.com/cpu/gorfbot/botcmd/reactji"
 	_ "github.com/cpu/gorfbot/botcmd/reactjikeys"
 	_ "github.com/cpu/gorfbot/botcmd/reactjiupdate"
+	_ "github.com/cpu/gorfbot/botcmd/remind"
+	_ "github.com/cpu/gorfbot/botcmd/repost"
+	_ "github.com/cpu/gorfbot/botcmd/roll"
+	_ "github.com/cpu/gorfbot/botcmd/schedule"
+	_ "github.com/cpu/gorfbot/botcmd/starboard"
 	_ "github.com/cpu/gorfbot/botcmd/themes"
 	_ "github.com/cpu/gorfbot/botcmd/topics"
 	_ "github.com/cpu/gorfbot/botcmd/topicupdate"
+	_ "github.com/cpu/gorfbot/botcmd/urls"
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/metrics"
 	"github.com/cpu/gorfbot/slack"
 	"github.com/cpu/gorfbot/storage"
 	"github.com/cpu/gorfbot/storage/mongo"
 	"github.com/sirupsen/logrus"
 )
 
-// Bot's know how to run and not much else.
+// correlationIDLen is the number of random bytes in an event correlation ID.
+const correlationIDLen = 8
+
+// Bot's know how to run and stop and not much else.
 type Bot interface {
-	// Start the bot. Does not return. Call from a goroutine or block forever.
+	// Start the bot. Does not return until Stop is called. Call from a goroutine
+	// or block forever.
 	Run()
+	// Stop the bot. Run returns, the goroutines it started stop and the Slack
+	// client is closed. Stop must only be called once.
+	Stop()
 }
 
 type botImpl struct {
@@ -39,6 +70,13 @@ type botImpl struct {
 	storage  storage.Storage
 	slack    slack.Client
 	registry *botcmd.CommandRegistry
+	httpConf config.HTTPConfig
+	// schedules are the configured scheduled commands.
+	schedules []schedule
+	// storageHealth is updated by pingStorage and used for readiness checks.
+	storageHealth *storageHealth
+	// done is closed by Stop to stop Run and the goroutines it started.
+	done chan struct{}
 }
 
 func New(log *logrus.Logger, c *config.Config) (Bot, error) {
@@ -52,8 +90,11 @@ func New(log *logrus.Logger, c *config.Config) (Bot, error) {
 
 	// Let's build a Gorfbot
 	bot := &botImpl{
-		log:      log,
-		registry: botcmd.DefaultRegistry,
+		log:           log,
+		registry:      botcmd.DefaultRegistry,
+		httpConf:      c.HTTPConf,
+		storageHealth: &storageHealth{},
+		done:          make(chan struct{}),
 	}
 
 	// Connect to mongo storage
@@ -62,7 +103,8 @@ func New(log *logrus.Logger, c *config.Config) (Bot, error) {
 		return nil, fmt.Errorf("bot storage error: %w", err)
 	}
 
-	bot.storage = storage
+	// Record metrics for all storage operations.
+	bot.storage = metrics.InstrumentStorage(storage)
 
 	// Connect to slack
 	slack, err := slack.New(log, c)
@@ -73,107 +115,269 @@ func New(log *logrus.Logger, c *config.Config) (Bot, error) {
 	bot.slack = slack
 
 	// Configure each command/pattern/reaction handler
-	for _, cmd := range bot.registry.GetConfigurables() {
+	if err := bot.configure(c); err != nil {
+		return nil, err
+	}
+
+	// Configure when to run each scheduled command
+	if bot.schedules, err = bot.configureSchedules(c); err != nil {
+		return nil, err
+	}
+
+	// Ready to Run()
+	return bot, nil
+}
+
+// configure calls Configure on each of the registry's configurables with the
+// provided config.
+func (b botImpl) configure(c *config.Config) error {
+	for _, cmd := range b.registry.GetConfigurables() {
 		if cmd == nil {
 			panic("nil configurable registered???\n")
 		}
 
-		if err := cmd.Configure(log, c); err != nil {
-			return nil, fmt.Errorf("bot cmd configure error: %w", err)
+		if err := cmd.Configure(b.log, c); err != nil {
+			return fmt.Errorf("bot cmd configure error: %w", err)
 		}
 	}
 
-	// Ready to Run()
-	return bot, nil
+	return nil
 }
 
-// Run forever.
+// Run until stopped.
 func (b botImpl) Run() {
-	// Start consuming messages and reactions
+	// Start consuming messages, reactions and emoji changes
 	msgChan := make(chan *slack.Message)
 	reactionChan := make(chan *slack.Reaction)
+	emojiChan := make(chan *slack.EmojiChange)
+
+	go b.slack.Listen(msgChan, reactionChan, emojiChan)
+
+	// Run the scheduled commands, each in their own goroutine.
+	b.startSchedules()
 
-	go b.slack.Listen(msgChan, reactionChan)
+	// Deliver reminders as they come due.
+	go b.dispatchReminders()
 
-	// Process messages forever
+	// Close polls as they reach their deadline.
+	go b.closePolls()
+
+	// Serve metrics and health checks if configured. Storage is pinged
+	// periodically for the readiness check.
+	if b.httpConf.ListenAddr != "" {
+		go b.pingStorage()
+		go b.serveHTTP()
+	}
+
+	// Process messages until stopped
 	for {
 		select {
+		case <-b.done:
+			return
 		case msg := <-msgChan:
 			if msg == nil {
 				continue
 			}
 
-			b.log.Tracef("msg: %q", msg.Text)
-			// First try the message through all of the configured pattern commands.
-			b.tryMessageAsPattern(msg)
-			// Then try to treat the message as a bot command.
-			b.tryMessageAsCommand(msg)
+			b.handleMessage(msg)
 		case reaction := <-reactionChan:
 			if reaction == nil {
 				continue
 			}
-			// Feed the reaction through the reaction handlers
-			b.tryReactionHandlers(reaction)
+
+			b.handleReaction(reaction)
+		case change := <-emojiChan:
+			if change == nil {
+				continue
+			}
+
+			b.handleEmojiChange(change)
 		}
 	}
 }
 
-func (b botImpl) runCtx(m *slack.Message) botcmd.RunContext {
+// Stop the bot and close its Slack client.
+func (b botImpl) Stop() {
+	close(b.done)
+	b.slack.Close()
+}
+
+// sleep waits for the duration and returns true, or returns false early if the
+// bot is stopped first.
+func (b botImpl) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-b.done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// eventLog returns a log entry for logging while handling an event. The entry
+// has a new correlation ID and the provided fields.
+func (b botImpl) eventLog(fields logrus.Fields) *logrus.Entry {
+	return b.log.WithField("correlation_id", newCorrelationID()).WithFields(fields)
+}
+
+// newCorrelationID returns a random ID for correlating the log lines emitted
+// while handling an event.
+func newCorrelationID() string {
+	id := make([]byte, correlationIDLen)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// handleMessage dispatches a message to the pattern handlers and commands.
+func (b botImpl) handleMessage(m *slack.Message) {
+	metrics.EventsReceived.WithLabelValues(metrics.EventMessage).Inc()
+
+	log := b.eventLog(logrus.Fields{
+		"channel": m.ChannelID,
+		"user":    m.UserID,
+	})
+	log.Tracef("msg: %q", m.Text)
+
+	// First try the message through all of the configured pattern commands.
+	b.tryMessageAsPattern(log, m)
+	// Then try to treat the message as a bot command.
+	b.tryMessageAsCommand(log, m)
+}
+
+// handleReaction dispatches a reaction to the reaction handlers.
+func (b botImpl) handleReaction(reaction *slack.Reaction) {
+	if reaction.Removed {
+		metrics.EventsReceived.WithLabelValues(metrics.EventReactionRemoved).Inc()
+	} else {
+		metrics.EventsReceived.WithLabelValues(metrics.EventReactionAdded).Inc()
+	}
+
+	log := b.eventLog(logrus.Fields{
+		"channel": reaction.ItemChannel,
+		"user":    reaction.User,
+	})
+
+	// Feed the reaction through the reaction handlers
+	b.tryReactionHandlers(log, reaction)
+}
+
+// handleEmojiChange dispatches a custom emoji change to the emoji change
+// handlers.
+func (b botImpl) handleEmojiChange(change *slack.EmojiChange) {
+	metrics.EventsReceived.WithLabelValues(metrics.EventEmojiChanged).Inc()
+
+	log := b.eventLog(logrus.Fields{
+		"emoji": change.Names,
+	})
+
+	b.tryEmojiChangeHandlers(log, change)
+}
+
+func (b botImpl) runCtx(log *logrus.Entry, m *slack.Message) botcmd.RunContext {
 	return botcmd.RunContext{
 		Message: m,
 		Storage: b.storage,
 		Slack:   b.slack,
+		Log:     log,
 	}
 }
 
 // tryReactionHandlers calls Run on each reaction handler with the provided
 // reaction.
-func (b botImpl) tryReactionHandlers(reaction *slack.Reaction) {
+func (b botImpl) tryReactionHandlers(log *logrus.Entry, reaction *slack.Reaction) {
 	for _, handler := range b.registry.GetReactionHandlers() {
-		if err := handler.Handler.Run(reaction, b.runCtx(nil)); err != nil {
-			b.log.Errorf("Reaction handler %q returned an error: %v", handler.Name, err)
+		handlerLog := log.WithField("handler", handler.Name)
+
+		start := time.Now()
+		err := handler.Handler.Run(reaction, b.runCtx(handlerLog, nil))
+		metrics.ObserveHandler(metrics.KindReaction, handler.Name, start, err)
+
+		if err != nil {
+			handlerLog.Errorf("Reaction handler %q returned an error: %v", handler.Name, err)
 		}
 	}
 }
 
-// handleRunResult processes the optional message and reactions returned by
-// a botcmd.
-func (b botImpl) handleRunResult(m *slack.Message, res botcmd.RunResult) {
+// tryEmojiChangeHandlers calls Run on each emoji change handler with the
+// provided emoji change.
+func (b botImpl) tryEmojiChangeHandlers(log *logrus.Entry, change *slack.EmojiChange) {
+	for _, handler := range b.registry.GetEmojiChangeHandlers() {
+		handlerLog := log.WithField("handler", handler.Name)
+
+		start := time.Now()
+		err := handler.Handler.Run(change, b.runCtx(handlerLog, nil))
+		metrics.ObserveHandler(metrics.KindEmojiChange, handler.Name, start, err)
+
+		if err != nil {
+			handlerLog.Errorf("Emoji change handler %q returned an error: %v", handler.Name, err)
+		}
+	}
+}
+
+// handleRunResult processes the optional message, file and reactions returned
+// by a botcmd.
+func (b botImpl) handleRunResult(log *logrus.Entry, m *slack.Message, res botcmd.RunResult) {
+	if res.Message != "" || res.File != nil {
+		b.postRunResult(log, m.ChannelID, res)
+	}
+
+	b.addReactions(log, res.Reactji, m)
+}
+
+// postRunResult posts the message and uploads the file of a RunResult to the
+// given channel ID.
+func (b botImpl) postRunResult(log *logrus.Entry, channelID string, res botcmd.RunResult) {
 	if res.Message != "" {
-		b.log.Tracef("Posting returned msg %q", res.Message)
-		b.slack.SendMessage(res.Message, m.ChannelID)
+		log.Tracef("Posting returned msg %q", res.Message)
+		b.slack.SendMessage(res.Message, channelID)
 	}
 
-	b.addReactions(res.Reactji, m)
+	if res.File != nil {
+		log.Infof("Uploading returned %s", res.File)
+
+		if err := b.slack.UploadFile(*res.File, channelID); err != nil {
+			log.Errorf("Failed to upload file: %v", err)
+		}
+	}
 }
 
 // addReactions adds a list of reactions to a given message.
-func (b botImpl) addReactions(reactions []string, m *slack.Message) {
+func (b botImpl) addReactions(log *logrus.Entry, reactions []string, m *slack.Message) {
 	if len(reactions) > 0 {
-		b.log.Infof("Adding reactions: %q", reactions)
+		log.Infof("Adding reactions: %q", reactions)
 	}
 
 	for _, reactji := range reactions {
 		if err := b.slack.AddReaction(reactji, m); err != nil {
-			b.log.Errorf("Failed to add reaction: %v", err)
+			log.Errorf("Failed to add reaction: %v", err)
 		}
 	}
 }
 
 // tryMessageAsPattern tries to match a message on any of the configured pattern
 // handlers, calling Run() on handlers that have a pattern match.
-func (b botImpl) tryMessageAsPattern(m *slack.Message) {
+func (b botImpl) tryMessageAsPattern(log *logrus.Entry, m *slack.Message) {
 	// Try every pattern's regex and call Run() for any that match.
 	for _, pattern := range b.registry.GetPatterns() {
 		if matches := pattern.Pattern.FindAllStringSubmatch(m.Text, -1); len(matches) > 0 {
-			b.log.Infof("pattern %q matched with %q", pattern.Name, pattern.Pattern)
+			patternLog := log.WithField("handler", pattern.Name)
+			patternLog.Infof("pattern %q matched with %q", pattern.Name, pattern.Pattern)
 
-			if res, err := pattern.Handler.Run(matches, b.runCtx(m)); err != nil {
-				b.log.Errorf("Pattern %q returned an error: %v", pattern.Name, err)
+			start := time.Now()
+			res, err := pattern.Handler.Run(matches, b.runCtx(patternLog, m))
+			metrics.ObserveHandler(metrics.KindPattern, pattern.Name, start, err)
+
+			if err != nil {
+				patternLog.Errorf("Pattern %q returned an error: %v", pattern.Name, err)
 
 				continue // pattern returned an error
 			} else {
-				b.handleRunResult(m, res)
+				b.handleRunResult(patternLog, m, res)
 			}
 		}
 	}
@@ -181,7 +385,7 @@ func (b botImpl) tryMessageAsPattern(m *slack.Message) {
 
 // tryMessageAsCommand tries to process a received message as if it were a bot cmd,
 // being flexible about how users might try to use commands.
-func (b botImpl) tryMessageAsCommand(m *slack.Message) {
+func (b botImpl) tryMessageAsCommand(log *logrus.Entry, m *slack.Message) {
 	// Split the incoming message text. It should have at least two words in it
 	// for it to be a command to handle.
 	textWords := strings.Split(m.Text, " ")
@@ -193,26 +397,26 @@ func (b botImpl) tryMessageAsCommand(m *slack.Message) {
 	hasMentionPrefix := strings.HasPrefix(firstWord, "<@")
 	// If it isn't a cmd or a mention that could be a command then return.
 	if !hasCmdPrefix && !hasMentionPrefix {
-		b.log.Trace("Received message didn't have cmd prefix or start with a mention")
+		log.Trace("Received message didn't have cmd prefix or start with a mention")
 		return
 	}
 
-	b.log.Infof("Processing potential command message, first word: %q hasCmdPrefix: %v hasMentionPrefix: %v\n",
+	log.Infof("Processing potential command message, first word: %q hasCmdPrefix: %v hasMentionPrefix: %v\n",
 		firstWord, hasCmdPrefix, hasMentionPrefix)
 
 	if hasCmdPrefix {
 		// Process as a bare cmd heard in a channel.
 		cmd := strings.TrimPrefix(firstWord, "!")
 		rest := strings.Join(textWords[1:], " ")
-		b.log.Infof("Processing heard cmd: %q with rest %q\n", cmd, rest)
-		b.handleCommandMessage(cmd, rest, m)
+		log.Infof("Processing heard cmd: %q with rest %q\n", cmd, rest)
+		b.handleCommandMessage(log, cmd, rest, m)
 	} else if hasMentionPrefix {
 		// Process as a @ mention heard in a channel.
 		// The mention must be to the bot.
 		mention := firstWord
 		expected := fmt.Sprintf("<@%s>", b.slack.BotID())
 		if mention != expected {
-			b.log.Infof("Message mention wasn't to bot: Got %q expected %q",
+			log.Infof("Message mention wasn't to bot: Got %q expected %q",
 				mention, expected)
 
 			return
@@ -220,51 +424,60 @@ func (b botImpl) tryMessageAsCommand(m *slack.Message) {
 		// There must be a command word after the mention for it to be worth
 		// processing.
 		if len(textWords) < 2 { // nolint:gomnd
-			b.log.Info("Message mention too short to be a command message")
+			log.Info("Message mention too short to be a command message")
 
 			return
 		}
 		cmd := strings.TrimPrefix(textWords[1], "!")
 		rest := strings.Join(textWords[2:], " ")
-		b.log.Infof("Processing mentioned cmd: %q with rest %q\n", cmd, rest)
-		b.handleCommandMessage(cmd, rest, m)
+		log.Infof("Processing mentioned cmd: %q with rest %q\n", cmd, rest)
+		b.handleCommandMessage(log, cmd, rest, m)
 	}
 }
 
 // handleCommandMessage tries to find a registered command with the given cmdName
 // and runs it with the rest of the message.
-func (b botImpl) handleCommandMessage(cmdName string, rest string, m *slack.Message) {
+func (b botImpl) handleCommandMessage(log *logrus.Entry, cmdName string, rest string, m *slack.Message) {
 	if cmdName == "" {
-		b.log.Warn("Got empty command name in handleCommandMessage")
+		log.Warn("Got empty command name in handleCommandMessage")
 
 		return
 	}
 
 	if cmdName == "help" || cmdName == "-h" || cmdName == "--help" {
-		b.botHelp(m)
+		b.botHelp(log, m)
 
 		return
 	}
 
-	if cmd := b.registry.GetCommand(cmdName); cmd == nil {
-		b.log.Warnf("Command %q not registered with bot", cmdName)
-		b.addReactions([]string{"interrobang"}, m)
+	cmd := b.registry.GetCommand(cmdName)
+	if cmd == nil {
+		log.Warnf("Command %q not registered with bot", cmdName)
+		b.addReactions(log, []string{"interrobang"}, m)
 
 		return // command not known
-	} else if res, err := cmd.Handler.Run(rest, b.runCtx(m)); err != nil {
-		b.log.Errorf("Command %q returned an error: %v", cmdName, err)
-		b.addReactions([]string{"negative_squared_cross_mark"}, m)
+	}
+
+	cmdLog := log.WithField("handler", cmd.Name)
+
+	start := time.Now()
+	res, err := cmd.Handler.Run(rest, b.runCtx(cmdLog, m))
+	metrics.ObserveHandler(metrics.KindCommand, cmd.Name, start, err)
+
+	if err != nil {
+		cmdLog.Errorf("Command %q returned an error: %v", cmdName, err)
+		b.addReactions(cmdLog, []string{"negative_squared_cross_mark"}, m)
 
 		return // command returned an error
-	} else {
-		b.handleRunResult(m, res)
 	}
+
+	b.handleRunResult(cmdLog, m, res)
 }
 
 // botHelp enumerates the configured botcmds and pattern/reaction handlers
 // and posts help information in reply to the given message.
 //nolint:lll
-func (b botImpl) botHelp(m *slack.Message) {
+func (b botImpl) botHelp(log *logrus.Entry, m *slack.Message) {
 	// TODO: template this mess.
 	userName := b.slack.UserName(m.UserID)
 	buf := new(bytes.Buffer)
@@ -285,8 +498,16 @@ func (b botImpl) botHelp(m *slack.Message) {
 		fmt.Fprintf(buf, "\t\t :eyes: _%s_\n", handler.Name)
 	}
 
+	for _, handler := range b.registry.GetEmojiChangeHandlers() {
+		fmt.Fprintf(buf, "\t\t :eyes: _%s_\n", handler.Name)
+	}
+
+	for _, cmd := range b.registry.GetScheduledCommands() {
+		fmt.Fprintf(buf, "\t\t :alarm_clock: _%s_ - %s\n", cmd.Name, cmd.Description)
+	}
+
 	fmt.Fprintf(buf, ":speech_balloon: - To run a command say `!<command> [arguments]` in a channel/conversation that we're both in.\n")
 	fmt.Fprintf(buf, ":speech_balloon: - Most commands offer help, try `!<command> -h`, like `!emoji -h`\n")
 	fmt.Fprintf(buf, ":nose: :kissing_cat: Smell ya later!")
-	b.handleRunResult(m, botcmd.RunResult{Message: buf.String()})
+	b.handleRunResult(log.WithField("handler", "help"), m, botcmd.RunResult{Message: buf.String()})
 }
@@ -6,17 +6,47 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/botcmd/mocks"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/metrics"
 	"github.com/cpu/gorfbot/slack"
 	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
 	"github.com/cpu/gorfbot/test"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sirupsen/logrus"
 	logtest "github.com/sirupsen/logrus/hooks/test"
 )
 
+// runCtxMatcher is a gomock.Matcher for botcmd.RunContext arguments. The Log
+// entry is created by the bot for each event so the matcher only checks that it
+// was set before comparing the other fields.
+type runCtxMatcher struct {
+	expected botcmd.RunContext
+}
+
+func matchRunCtx(expected botcmd.RunContext) gomock.Matcher {
+	return runCtxMatcher{expected}
+}
+
+func (m runCtxMatcher) Matches(x interface{}) bool {
+	ctx, ok := x.(botcmd.RunContext)
+	if !ok || ctx.Log == nil {
+		return false
+	}
+
+	ctx.Log = nil
+
+	return ctx == m.expected
+}
+
+func (m runCtxMatcher) String() string {
+	return fmt.Sprintf("is %v with a Log entry", m.expected)
+}
+
 func TestTryMessageAsPattern(t *testing.T) {
 	log, logHook := logtest.NewNullLogger()
 
@@ -93,15 +123,15 @@ func TestTryMessageAsPattern(t *testing.T) {
 			// If we expected the handler is called, set that up with the mock
 			if tc.expectHandlerCalled {
 				mockHandler.EXPECT().
-					Run(tc.expectedMatches, botcmd.RunContext{
+					Run(tc.expectedMatches, matchRunCtx(botcmd.RunContext{
 						Message: tc.message,
 						Slack:   mockClient,
-					}).
+					})).
 					Return(tc.handlerResponse, tc.handlerErr)
 			}
 
 			// Handle the message
-			bot.tryMessageAsPattern(tc.message)
+			bot.tryMessageAsPattern(logrus.NewEntry(log), tc.message)
 
 			if tc.handlerErr != nil {
 				expectedLog := `Pattern "test" returned an error: danger danger`
@@ -210,14 +240,14 @@ func TestTryMessageAsCommand(t *testing.T) {
 
 			// If we expected the handler is called, set that up with the mock
 			if tc.expectHandlerCalled {
-				mockHandler.EXPECT().Run(tc.expectedRest, botcmd.RunContext{
+				mockHandler.EXPECT().Run(tc.expectedRest, matchRunCtx(botcmd.RunContext{
 					Message: tc.message,
 					Slack:   mockClient,
-				}).Return(botcmd.RunResult{}, nil)
+				})).Return(botcmd.RunResult{}, nil)
 			}
 
 			// Handle the message
-			bot.tryMessageAsCommand(tc.message)
+			bot.tryMessageAsCommand(logrus.NewEntry(log), tc.message)
 		})
 	}
 }
@@ -232,7 +262,7 @@ func TestHandleCommandMessageEmptyCmd(t *testing.T) {
 	}
 
 	// An empty cmd should warn
-	bot.handleCommandMessage("", "", nil)
+	bot.handleCommandMessage(logrus.NewEntry(log), "", "", nil)
 
 	test.ExpectLastLog(
 		t, logHook, logrus.WarnLevel, "Got empty command name in handleCommandMessage")
@@ -256,7 +286,7 @@ func TestHandleCommandMessageUnknownCmd(t *testing.T) {
 
 	// An unknown cmd should warn
 	// An empty cmd should warn
-	bot.handleCommandMessage("blorp", "", nil)
+	bot.handleCommandMessage(logrus.NewEntry(log), "blorp", "", nil)
 
 	expectedLogs := []*logrus.Entry{
 		{
@@ -301,11 +331,19 @@ func TestHandleCommandMessageError(t *testing.T) {
 	}
 
 	// Mock an error being returned from the test command
-	mockHandler.EXPECT().Run("hello", ctx).
+	mockHandler.EXPECT().Run("hello", matchRunCtx(ctx)).
 		Return(botcmd.RunResult{}, errors.New("bogus"))
 
+	errCount := testutil.ToFloat64(metrics.HandlerErrors.WithLabelValues(metrics.KindCommand, "test"))
+
 	// Then handle a command message for the test command
-	bot.handleCommandMessage("test", "hello", nil)
+	bot.handleCommandMessage(logrus.NewEntry(log), "test", "hello", nil)
+
+	// The error should have been counted
+	newErrCount := testutil.ToFloat64(metrics.HandlerErrors.WithLabelValues(metrics.KindCommand, "test"))
+	if newErrCount != errCount+1 {
+		t.Errorf("expected command error count to be %v, was %v", errCount+1, newErrCount)
+	}
 
 	expectedLogs := []*logrus.Entry{
 		{
@@ -347,11 +385,11 @@ func TestHandleCommandMessageReturnEmpty(t *testing.T) {
 	}
 
 	// Mock an empty, non-err response being returned from the test command
-	mockHandler.EXPECT().Run("hello", botcmd.RunContext{Slack: mockClient}).
+	mockHandler.EXPECT().Run("hello", matchRunCtx(botcmd.RunContext{Slack: mockClient})).
 		Return(botcmd.RunResult{}, nil)
 
 	// Then handle a command message for the test command
-	bot.handleCommandMessage("test", "hello", nil)
+	bot.handleCommandMessage(logrus.NewEntry(log), "test", "hello", nil)
 
 	// There should be no log events
 	if le := logHook.LastEntry(); le != nil {
@@ -395,16 +433,16 @@ func TestHandleCommandMessageReturnMsg(t *testing.T) {
 	}
 
 	// Mock a non-empty, non-err response being returned from the test command
-	mockHandler.EXPECT().Run("hello", botcmd.RunContext{
+	mockHandler.EXPECT().Run("hello", matchRunCtx(botcmd.RunContext{
 		Message: mockMsg,
 		Slack:   mockClient,
-	}).Return(respMsg, nil)
+	})).Return(respMsg, nil)
 
 	// We expect the slack client to be told to send the reply to the right channel
 	mockClient.EXPECT().SendMessage(respMsg.Message, mockMsg.ChannelID)
 
 	// Then handle a command message for the test command
-	bot.handleCommandMessage("test", "hello", mockMsg)
+	bot.handleCommandMessage(logrus.NewEntry(log), "test", "hello", mockMsg)
 
 	expectedMsg := fmt.Sprintf(`Posting returned msg %q`, respMsg.Message)
 	test.ExpectLastLog(t, logHook, logrus.TraceLevel, expectedMsg)
@@ -445,17 +483,17 @@ func TestHandleCommandMessageReturnReactji(t *testing.T) {
 	}
 
 	// Mock a non-empty, non-err response being returned from the test command
-	mockHandler.EXPECT().Run("hello", botcmd.RunContext{
+	mockHandler.EXPECT().Run("hello", matchRunCtx(botcmd.RunContext{
 		Message: mockMsg,
 		Slack:   mockClient,
-	}).Return(respMsg, nil)
+	})).Return(respMsg, nil)
 
 	// We expect the slack client to be told to add reactji
 	mockClient.EXPECT().AddReaction("thumbsup", mockMsg).Return(nil)
 	mockClient.EXPECT().AddReaction("thumbsdown", mockMsg).Return(nil)
 
 	// Then handle a command message for the test command
-	bot.handleCommandMessage("test", "hello", mockMsg)
+	bot.handleCommandMessage(logrus.NewEntry(log), "test", "hello", mockMsg)
 
 	expectedMsg := `Adding reactions: ["thumbsup" "thumbsdown"]`
 	test.ExpectLastLog(t, logHook, logrus.InfoLevel, expectedMsg)
@@ -476,6 +514,187 @@ func TestAddReactionsErr(t *testing.T) {
 		slack: mockClient,
 	}
 
-	bot.addReactions([]string{"whatever"}, nil)
+	bot.addReactions(logrus.NewEntry(log), []string{"whatever"}, nil)
 	test.ExpectLastLog(t, logHook, logrus.ErrorLevel, `Failed to add reaction: bogus`)
 }
+
+func TestHandleMessageCorrelation(t *testing.T) {
+	log, logHook := logtest.NewNullLogger()
+	log.SetLevel(logrus.InfoLevel)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHandler := mocks.NewMockPatternHandler(ctrl)
+	cmdRegistry := botcmd.NewRegistry()
+	cmdRegistry.AddPattern(&botcmd.PatternCommand{
+		Name:    "test",
+		Handler: mockHandler,
+		Pattern: regexp.MustCompile("hello"),
+	})
+
+	bot := botImpl{
+		log:      log,
+		registry: cmdRegistry,
+	}
+
+	// The handler logs with its run context logger.
+	mockHandler.EXPECT().Run(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+			runCtx.Logger(nil).Info("handling hello")
+			return botcmd.RunResult{}, nil
+		}).Times(2)
+
+	msg := &slack.Message{ChannelID: "C001", UserID: "U001", Text: "hello"}
+	bot.handleMessage(msg)
+
+	entries := logHook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+
+	correlationID := entries[0].Data["correlation_id"]
+	if correlationID == nil || correlationID == "" {
+		t.Fatalf("expected log entry to have a correlation_id, had %v", entries[0].Data)
+	}
+
+	// Every log line for the event has the same correlation ID, channel, user and
+	// handler.
+	for i, entry := range entries {
+		expected := logrus.Fields{
+			"correlation_id": correlationID,
+			"channel":        "C001",
+			"user":           "U001",
+			"handler":        "test",
+		}
+		for k, v := range expected {
+			if entry.Data[k] != v {
+				t.Errorf("expected log entry %d to have %s %v, had %v", i, k, v, entry.Data[k])
+			}
+		}
+	}
+
+	// A second event gets a new correlation ID.
+	logHook.Reset()
+	bot.handleMessage(msg)
+
+	if newID := logHook.LastEntry().Data["correlation_id"]; newID == correlationID {
+		t.Errorf("expected a new correlation_id for a new event, got %v again", newID)
+	}
+}
+
+func TestStop(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockClient.EXPECT().Listen(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockClient.EXPECT().StatePopulated().Return(false).AnyTimes()
+	mockClient.EXPECT().Close()
+
+	bot := botImpl{
+		log:      log,
+		slack:    mockClient,
+		registry: botcmd.NewRegistry(),
+		done:     make(chan struct{}),
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		bot.Run()
+		close(stopped)
+	}()
+
+	bot.Stop()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Run to return once the bot is stopped")
+	}
+
+	// The goroutines Run started see the bot is stopped too.
+	if bot.sleep(time.Hour) {
+		t.Errorf("expected sleep to return false once the bot is stopped")
+	}
+}
+
+// errReactionHandler is a reaction handler that always returns an error.
+type errReactionHandler struct{}
+
+func (errReactionHandler) Configure(_ *logrus.Logger, _ *config.Config) error {
+	return nil
+}
+
+func (errReactionHandler) Run(_ *slack.Reaction, _ botcmd.RunContext) error {
+	return errors.New("danger danger")
+}
+
+func TestHandleReaction(t *testing.T) {
+	log, logHook := logtest.NewNullLogger()
+
+	cmdRegistry := botcmd.NewRegistry()
+	cmdRegistry.AddReactionHandler(&botcmd.ReactionCommand{
+		Name:    "test",
+		Handler: errReactionHandler{},
+	})
+
+	bot := botImpl{
+		log:      log,
+		registry: cmdRegistry,
+	}
+
+	bot.handleReaction(&slack.Reaction{User: "U001", Reaction: "gorf", ItemChannel: "C001"})
+
+	expectedLog := `Reaction handler "test" returned an error: danger danger`
+	test.ExpectLastLog(t, logHook, logrus.ErrorLevel, expectedLog)
+
+	// The log line has the channel of the item reacted to and who reacted.
+	entry := logHook.LastEntry()
+	for k, v := range map[string]string{"channel": "C001", "user": "U001"} {
+		if entry.Data[k] != v {
+			t.Errorf("expected log entry to have %s %v, had %v", k, v, entry.Data[k])
+		}
+	}
+}
+
+func TestHandleEmojiChange(t *testing.T) {
+	log, logHook := logtest.NewNullLogger()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHandler := mocks.NewMockEmojiChangeHandler(ctrl)
+	cmdRegistry := botcmd.NewRegistry()
+	cmdRegistry.AddEmojiChangeHandler(&botcmd.EmojiChangeCommand{
+		Name:    "test",
+		Handler: mockHandler,
+	})
+
+	mockClient := slack_mocks.NewMockClient(ctrl)
+
+	bot := botImpl{
+		log:      log,
+		registry: cmdRegistry,
+		slack:    mockClient,
+	}
+
+	change := &slack.EmojiChange{Names: []string{"gorf"}, Value: "https://example.com/gorf.png"}
+	before := testutil.ToFloat64(metrics.EventsReceived.WithLabelValues(metrics.EventEmojiChanged))
+
+	mockHandler.EXPECT().
+		Run(change, matchRunCtx(botcmd.RunContext{Slack: mockClient})).
+		Return(errors.New("danger danger"))
+
+	bot.handleEmojiChange(change)
+
+	if after := testutil.ToFloat64(metrics.EventsReceived.WithLabelValues(metrics.EventEmojiChanged)); after != before+1 {
+		t.Errorf("expected emoji_changed events to increase from %v to %v, got %v", before, before+1, after)
+	}
+
+	expectedLog := `Emoji change handler "test" returned an error: danger danger`
+	test.ExpectLastLog(t, logHook, logrus.ErrorLevel, expectedLog)
+}
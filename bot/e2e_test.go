@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/cpu/gorfbot/test/fakeslack"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+const e2eTimeout = 5 * time.Second
+
+// setupE2E starts a fake Slack server and runs a bot using the default registry
+// connected to it. The bot uses the returned mock storage.
+func setupE2E(t *testing.T, ctrl *gomock.Controller) (*fakeslack.Server, *mocks.MockStorage) {
+	t.Helper()
+
+	server := fakeslack.New(fakeslack.DefaultConfig())
+
+	c := &config.Config{
+		SlackConf: config.SlackConfig{
+			APIToken: fakeslack.Token,
+			APIURL:   server.APIURL(),
+		},
+		ReactjiKeysConf: config.ReactjiKeysConfig{
+			Keywords: map[string][]string{
+				"gorf": {"frog"},
+			},
+		},
+	}
+
+	log, _ := logtest.NewNullLogger()
+
+	client, err := slack.New(log, c)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error creating slack client: %v", err)
+	}
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	// Every message is checked against the stored reactji rules.
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, nil).AnyTimes()
+	mockStorage.EXPECT().GetURLRules().Return(nil, nil).AnyTimes()
+
+	bot := botImpl{
+		log:      log,
+		storage:  mockStorage,
+		slack:    client,
+		registry: botcmd.DefaultRegistry,
+		done:     make(chan struct{}),
+	}
+	t.Cleanup(bot.Stop)
+
+	if err := bot.configure(c); err != nil {
+		server.Close()
+		t.Fatalf("unexpected error configuring bot: %v", err)
+	}
+
+	go bot.Run()
+
+	if err := server.WaitForConnection(e2eTimeout); err != nil {
+		server.Close()
+		t.Fatalf("bot never connected: %v", err)
+	}
+
+	return server, mockStorage
+}
+
+func TestE2ECommands(t *testing.T) {
+	testCases := []struct {
+		name              string
+		text              string
+		expectedMessage   string
+		expectedReactions []string
+	}{
+		{
+			name:              "command",
+			text:              "!hello",
+			expectedMessage:   "hello!",
+			expectedReactions: []string{"wave"},
+		},
+		{
+			name:              "mention command",
+			text:              "<@U000> hello",
+			expectedMessage:   "hello!",
+			expectedReactions: []string{"wave"},
+		},
+		{
+			name:              "unknown command",
+			text:              "!whatever",
+			expectedReactions: []string{"interrobang"},
+		},
+		{
+			name:              "reactji keyword",
+			text:              "I love Gorf",
+			expectedReactions: []string{"frog"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server, _ := setupE2E(t, ctrl)
+			defer server.Close()
+
+			ts, err := server.SendMessage("U001", "C001", tc.text)
+			if err != nil {
+				t.Fatalf("unexpected error sending message: %v", err)
+			}
+
+			reactions, err := server.WaitForReactions(len(tc.expectedReactions), e2eTimeout)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			for i, expected := range tc.expectedReactions {
+				if r := reactions[i]; r.Reaction != expected || r.Timestamp != ts || r.ChannelID != "C001" {
+					t.Errorf("expected reaction %d to be %q on %s in C001, got %v", i, expected, ts, r)
+				}
+			}
+
+			if tc.expectedMessage == "" {
+				return
+			}
+
+			msgs, err := server.WaitForMessages(1, e2eTimeout)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if msgs[0].Text != tc.expectedMessage || msgs[0].ChannelID != "C001" {
+				t.Errorf("expected message %q in C001, got %v", tc.expectedMessage, msgs[0])
+			}
+		})
+	}
+}
+
+func TestE2EReaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, mockStorage := setupE2E(t, ctrl)
+	defer server.Close()
+
+	expected := models.Emoji{
+		User:     "U002",
+		Emoji:    "joy",
+		Count:    1,
+		Reaction: true,
+	}
+	upserted := make(chan struct{})
+
+	mockStorage.EXPECT().UpsertEmojiCount(expected, false).Return(expected, nil)
+	// The daily usage bucket records the channel of the message reacted to.
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).
+		DoAndReturn(func(u models.EmojiUsage, _ bool) (models.EmojiUsage, error) {
+			if u.Channel != "C001" {
+				t.Errorf("expected emoji usage in channel C001, got %v", u)
+			}
+			close(upserted)
+			return u, nil
+		})
+
+	if err := server.AddReaction("U002", "joy", "C001", server.NextTimestamp()); err != nil {
+		t.Fatalf("unexpected error sending reaction: %v", err)
+	}
+
+	select {
+	case <-upserted:
+	case <-time.After(e2eTimeout):
+		t.Fatalf("timed out waiting for reaction to be stored")
+	}
+}
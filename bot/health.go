@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// storagePingInterval is how often the storage backend is pinged.
+	storagePingInterval = 30 * time.Second
+	// storagePingMaxAge is how long ago the last successful storage ping can have
+	// been for the bot to still be considered ready.
+	storagePingMaxAge = 3 * storagePingInterval
+)
+
+// storageHealth tracks the result of periodic storage pings.
+type storageHealth struct {
+	sync.Mutex
+
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// update records the result of a storage ping made at the given time.
+func (h *storageHealth) update(now time.Time, err error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.lastErr = err
+	if err == nil {
+		h.lastSuccess = now
+	}
+}
+
+var (
+	errNotConnected      = errors.New("not connected")
+	errStateNotPopulated = errors.New("users and conversations not loaded yet")
+)
+
+// errStorageUnhealthy is returned from storageHealth.check when there hasn't
+// been a recent successful storage ping.
+type errStorageUnhealthy struct {
+	// age is the time since the last successful ping, or 0 if there hasn't been
+	// one.
+	age     time.Duration
+	lastErr error
+}
+
+func (e errStorageUnhealthy) Error() string {
+	if e.age == 0 {
+		return fmt.Sprintf("no successful ping yet (last error: %v)", e.lastErr)
+	}
+
+	return fmt.Sprintf("last successful ping was %s ago (last error: %v)",
+		e.age.Round(time.Second), e.lastErr)
+}
+
+// check returns an error unless the last successful ping was less than
+// storagePingMaxAge before the given time.
+func (h *storageHealth) check(now time.Time) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.lastSuccess.IsZero() {
+		return errStorageUnhealthy{lastErr: h.lastErr}
+	}
+
+	if age := now.Sub(h.lastSuccess); age > storagePingMaxAge {
+		return errStorageUnhealthy{age: age, lastErr: h.lastErr}
+	}
+
+	return nil
+}
+
+// pingStorage pings the storage backend until the bot is stopped, recording
+// the results for readiness checks. It is intended to be called from a
+// dedicated goroutine.
+func (b botImpl) pingStorage() {
+	for {
+		err := b.storage.Ping()
+		if err != nil {
+			b.log.Errorf("Storage ping failed: %v", err)
+		}
+
+		b.storageHealth.update(time.Now(), err)
+
+		if !b.sleep(storagePingInterval) {
+			return
+		}
+	}
+}
+
+// healthz handles liveness checks. If the bot can answer HTTP requests it is
+// alive.
+func (b botImpl) healthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// readyz handles readiness checks. The bot is ready when it's connected to
+// Slack, has populated its Slack state cache and has recently pinged storage.
+// The result of each check is written to the response and the status is 503 if
+// any failed.
+func (b botImpl) readyz(w http.ResponseWriter, r *http.Request) {
+	buf := new(bytes.Buffer)
+	ready := true
+
+	result := func(name string, err error) {
+		if err != nil {
+			ready = false
+
+			fmt.Fprintf(buf, "%s: %v\n", name, err)
+		} else {
+			fmt.Fprintf(buf, "%s: ok\n", name)
+		}
+	}
+
+	if b.slack.Connected() {
+		result("slack connection", nil)
+	} else {
+		result("slack connection", errNotConnected)
+	}
+
+	if b.slack.StatePopulated() {
+		result("slack state", nil)
+	} else {
+		result("slack state", errStateNotPopulated)
+	}
+
+	result("storage", b.storageHealth.check(time.Now()))
+
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,135 @@
+//nolint:goerr113
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestStorageHealthCheck(t *testing.T) {
+	now := time.Now()
+	pingErr := errors.New("connection refused")
+
+	testCases := []struct {
+		name        string
+		pings       map[time.Time]error
+		expectedErr string
+	}{
+		{
+			name:        "never pinged",
+			expectedErr: "no successful ping yet (last error: <nil>)",
+		},
+		{
+			name:        "never pinged successfully",
+			pings:       map[time.Time]error{now: pingErr},
+			expectedErr: "no successful ping yet (last error: connection refused)",
+		},
+		{
+			name:  "recent success",
+			pings: map[time.Time]error{now.Add(-time.Minute): nil},
+		},
+		{
+			name: "old success",
+			pings: map[time.Time]error{
+				now.Add(-storagePingMaxAge - time.Minute): nil,
+			},
+			expectedErr: "last successful ping was 2m30s ago (last error: <nil>)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &storageHealth{}
+			for pingTime, err := range tc.pings {
+				h.update(pingTime, err)
+			}
+
+			err := h.check(now)
+			if tc.expectedErr == "" && err != nil {
+				t.Errorf("expected no err, got %v", err)
+			} else if tc.expectedErr != "" && (err == nil || err.Error() != tc.expectedErr) {
+				t.Errorf("expected err %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	resp := httptest.NewRecorder()
+	botImpl{}.httpHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if resp.Code != http.StatusOK {
+		t.Errorf("expected status %d got %d", http.StatusOK, resp.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	testCases := []struct {
+		name           string
+		connected      bool
+		statePopulated bool
+		pinged         bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "ready",
+			connected:      true,
+			statePopulated: true,
+			pinged:         true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "slack connection: ok\nslack state: ok\nstorage: ok\n",
+		},
+		{
+			name:           "not connected",
+			statePopulated: true,
+			pinged:         true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "slack connection: not connected\nslack state: ok\nstorage: ok\n",
+		},
+		{
+			name:           "nothing ready",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: "slack connection: not connected\n" +
+				"slack state: users and conversations not loaded yet\n" +
+				"storage: no successful ping yet (last error: <nil>)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := slack_mocks.NewMockClient(ctrl)
+			mockClient.EXPECT().Connected().Return(tc.connected)
+			mockClient.EXPECT().StatePopulated().Return(tc.statePopulated)
+
+			bot := botImpl{
+				slack:         mockClient,
+				storageHealth: &storageHealth{},
+			}
+
+			if tc.pinged {
+				bot.storageHealth.update(time.Now(), nil)
+			}
+
+			resp := httptest.NewRecorder()
+			bot.httpHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if resp.Code != tc.expectedStatus {
+				t.Errorf("expected status %d got %d", tc.expectedStatus, resp.Code)
+			}
+
+			if body := resp.Body.String(); body != tc.expectedBody {
+				t.Errorf("expected body %q got %q", tc.expectedBody, body)
+			}
+		})
+	}
+}
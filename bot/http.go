@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	httpReadTimeout  = 10 * time.Second
+	httpWriteTimeout = 30 * time.Second
+)
+
+// httpHandler returns the handler for the bot's HTTP listener.
+func (b botImpl) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", b.healthz)
+	mux.HandleFunc("/readyz", b.readyz)
+
+	return mux
+}
+
+// serveHTTP runs the bot's HTTP listener on the configured listen address. It
+// is intended to be called from a dedicated goroutine and only returns if the
+// listener fails or the bot is stopped.
+func (b botImpl) serveHTTP() {
+	server := &http.Server{
+		Addr:         b.httpConf.ListenAddr,
+		Handler:      b.httpHandler(),
+		ReadTimeout:  httpReadTimeout,
+		WriteTimeout: httpWriteTimeout,
+	}
+
+	b.log.Infof("HTTP listener serving on %q", b.httpConf.ListenAddr)
+
+	go func() {
+		<-b.done
+
+		if err := server.Close(); err != nil {
+			b.log.Errorf("HTTP listener failed to close: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		b.log.Errorf("HTTP listener failed: %v", err)
+	}
+}
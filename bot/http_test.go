@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandlerMetrics(t *testing.T) {
+	bot := botImpl{}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	resp := httptest.NewRecorder()
+
+	bot.httpHandler().ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, resp.Code)
+	}
+
+	if body := resp.Body.String(); !strings.Contains(body, "gorfbot_slack_state_age_seconds") {
+		t.Errorf("expected metrics to include gorfbot_slack_state_age_seconds, got:\n%s", body)
+	}
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/sirupsen/logrus"
+)
+
+// pollDeadlineInterval is how often storage is checked for polls that have
+// reached their deadline.
+const pollDeadlineInterval = 30 * time.Second
+
+// closePolls closes polls that reach their deadline and posts their
+// results until the bot is stopped. Polls are kept in storage so any that reach their
+// deadline while the bot isn't running are closed once it is. It is intended
+// to be called from a dedicated goroutine.
+func (b botImpl) closePolls() {
+	for {
+		if b.slack.StatePopulated() {
+			b.closeDuePolls(time.Now())
+		}
+
+		if !b.sleep(pollDeadlineInterval) {
+			return
+		}
+	}
+}
+
+// closeDuePolls closes each open poll with a deadline at or before now and
+// posts its results in the poll's channel.
+func (b botImpl) closeDuePolls(now time.Time) {
+	due, err := b.storage.GetDuePolls(now)
+	if err != nil {
+		b.log.Errorf("Failed to get due polls: %v", err)
+
+		return
+	}
+
+	for _, poll := range due {
+		log := b.eventLog(logrus.Fields{
+			"handler": "poll",
+			"channel": b.slack.ConversationName(poll.Channel),
+			"user":    b.slack.UserName(poll.Creator),
+		})
+
+		// Only the caller that closes the poll posts its results.
+		closed, err := b.storage.ClosePoll(poll.Channel, poll.Timestamp)
+		if err != nil {
+			log.Errorf("Failed to close due poll %s: %v", poll, err)
+
+			continue
+		}
+
+		if !closed {
+			continue
+		}
+
+		// Get the poll again to include any votes made before it was closed. The
+		// poll is closed either way, so its results are posted even if that fails.
+		if poll, err = botcmd.ReloadPoll(b.storage, poll); err != nil {
+			log.Errorf("Results of due poll may be missing the latest votes: %v", err)
+		}
+
+		log.Infof("Closing %s", poll)
+		b.slack.SendMessage(botcmd.PollResults(poll), poll.Channel)
+	}
+}
@@ -0,0 +1,74 @@
+//nolint:goerr113
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	storage_mocks "github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestCloseDuePolls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, logHook := logtest.NewNullLogger()
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockStorage := storage_mocks.NewMockStorage(ctrl)
+
+	bot := botImpl{
+		log:     log,
+		slack:   mockClient,
+		storage: mockStorage,
+	}
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	lunch := models.Poll{
+		Channel: "C001", Timestamp: "1.0", Question: "Lunch?", Options: []string{"tacos", "pho"},
+	}
+	due := []models.Poll{
+		lunch,
+		// Already closed by the time it's closed here.
+		{Channel: "C001", Timestamp: "2.0"},
+		{Channel: "C001", Timestamp: "3.0"},
+	}
+
+	// The results include votes made after the due polls were found.
+	final := lunch
+	final.Votes = []models.PollVote{{User: "U001", Option: 1}}
+	final.Closed = true
+
+	mockClient.EXPECT().ConversationName(gomock.Any()).Return("general").AnyTimes()
+	mockClient.EXPECT().UserName(gomock.Any()).Return("bob").AnyTimes()
+
+	mockStorage.EXPECT().GetDuePolls(now).Return(due, nil)
+	mockStorage.EXPECT().ClosePoll("C001", "1.0").Return(true, nil)
+	mockStorage.EXPECT().GetPolls(storage.GetPollOptions{
+		FindOptions: storage.FindOptions{SortField: "timestamp", Limit: 1},
+		Channel:     "C001",
+		Timestamp:   "1.0",
+	}).Return([]models.Poll{final}, nil)
+	mockStorage.EXPECT().ClosePoll("C001", "2.0").Return(false, nil)
+	mockStorage.EXPECT().ClosePoll("C001", "3.0").Return(false, errors.New("storage is sleeping"))
+
+	mockClient.EXPECT().SendMessage(botcmd.PollResults(final), "C001")
+
+	bot.closeDuePolls(now)
+
+	if entry := logHook.LastEntry(); entry == nil || entry.Level != logrus.ErrorLevel {
+		t.Errorf("expected the close error to be logged")
+	}
+
+	// Nothing is closed if the due polls can't be found.
+	mockStorage.EXPECT().GetDuePolls(now).Return(nil, errors.New("storage is sleeping"))
+
+	bot.closeDuePolls(now)
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+// reminderPollInterval is how often storage is checked for due reminders.
+const reminderPollInterval = 30 * time.Second
+
+// dispatchReminders delivers due reminders until the bot is stopped. Reminders are kept in
+// storage so any that come due while the bot isn't running are delivered once
+// it is. It is intended to be called from a dedicated goroutine.
+func (b botImpl) dispatchReminders() {
+	for {
+		if b.slack.StatePopulated() {
+			b.deliverReminders(time.Now())
+		}
+
+		if !b.sleep(reminderPollInterval) {
+			return
+		}
+	}
+}
+
+// deliverReminders delivers each reminder due at or before now.
+func (b botImpl) deliverReminders(now time.Time) {
+	due, err := b.storage.GetDueReminders(now)
+	if err != nil {
+		b.log.Errorf("Failed to get due reminders: %v", err)
+
+		return
+	}
+
+	for _, reminder := range due {
+		log := b.eventLog(logrus.Fields{
+			"handler": "reminder",
+			"channel": b.slack.ConversationName(reminder.Channel),
+			"user":    b.slack.UserName(reminder.Creator),
+		})
+
+		// Cancelling the reminder before delivering it ensures it's only
+		// delivered once.
+		delivered, err := b.storage.CancelReminder(reminder.ID)
+		if err != nil {
+			log.Errorf("Failed to remove due reminder %s: %v", reminder, err)
+
+			continue
+		}
+
+		if !delivered {
+			continue
+		}
+
+		log.Infof("Delivering %s", reminder)
+		b.slack.SendMessage(b.reminderMessage(reminder), reminder.Channel)
+	}
+}
+
+// reminderMessage returns the message to post for a due reminder.
+func (b botImpl) reminderMessage(reminder models.Reminder) string {
+	if reminder.Self {
+		return fmt.Sprintf("<@%s> :alarm_clock: Reminder: %s", reminder.Creator, reminder.Text)
+	}
+
+	return fmt.Sprintf(":alarm_clock: Reminder from _%s_: %s",
+		b.slack.UserName(reminder.Creator), reminder.Text)
+}
@@ -0,0 +1,62 @@
+//nolint:goerr113
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	storage_mocks "github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestDeliverReminders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, logHook := logtest.NewNullLogger()
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockStorage := storage_mocks.NewMockStorage(ctrl)
+
+	bot := botImpl{
+		log:     log,
+		slack:   mockClient,
+		storage: mockStorage,
+	}
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	due := []models.Reminder{
+		{ID: "a", Creator: "U001", Channel: "C001", Self: true, Text: "feed the frog"},
+		{ID: "b", Creator: "U002", Channel: "C002", Text: "standup"},
+		// Already cancelled or delivered by the time it's removed.
+		{ID: "c", Creator: "U002", Channel: "C002", Text: "too late"},
+		{ID: "d", Creator: "U002", Channel: "C002", Text: "broken"},
+	}
+
+	mockClient.EXPECT().ConversationName(gomock.Any()).Return("general").AnyTimes()
+	mockClient.EXPECT().UserName(gomock.Any()).Return("bob").AnyTimes()
+
+	mockStorage.EXPECT().GetDueReminders(now).Return(due, nil)
+	mockStorage.EXPECT().CancelReminder("a").Return(true, nil)
+	mockStorage.EXPECT().CancelReminder("b").Return(true, nil)
+	mockStorage.EXPECT().CancelReminder("c").Return(false, nil)
+	mockStorage.EXPECT().CancelReminder("d").Return(false, errors.New("storage is sleeping"))
+
+	mockClient.EXPECT().SendMessage("<@U001> :alarm_clock: Reminder: feed the frog", "C001")
+	mockClient.EXPECT().SendMessage(":alarm_clock: Reminder from _bob_: standup", "C002")
+
+	bot.deliverReminders(now)
+
+	if entry := logHook.LastEntry(); entry == nil || entry.Level != logrus.ErrorLevel {
+		t.Errorf("expected the cancel error to be logged")
+	}
+
+	// Nothing is delivered if the due reminders can't be found.
+	mockStorage.EXPECT().GetDueReminders(now).Return(nil, errors.New("storage is sleeping"))
+
+	bot.deliverReminders(now)
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/metrics"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+// stateWaitInterval is how often the Slack state is checked while waiting for
+// it to be populated before running a missed scheduled command.
+const stateWaitInterval = time.Second
+
+// schedule is a scheduled command configured to run at the times of a cron
+// expression and post its result in a channel.
+type schedule struct {
+	cmd   *botcmd.ScheduledCommand
+	entry config.ScheduleEntry
+	cron  botcmd.Schedule
+}
+
+// name returns a name for the schedule for logs.
+func (s schedule) name() string {
+	return fmt.Sprintf("%s in #%s", s.cmd.Name, s.entry.Channel)
+}
+
+// missedRun returns the latest time the schedule should have run after
+// lastRun and at or before now, or the zero time if no run was missed.
+func (s schedule) missedRun(lastRun, now time.Time) time.Time {
+	var missed time.Time
+
+	for next := s.cron.Next(lastRun); !next.IsZero() && !next.After(now); next = s.cron.Next(next) {
+		missed = next
+	}
+
+	return missed
+}
+
+type errUnknownScheduledCommand struct {
+	name string
+}
+
+func (e errUnknownScheduledCommand) Error() string {
+	return fmt.Sprintf("unknown scheduled command %q", e.name)
+}
+
+type errMissingScheduleChannel struct {
+	name string
+}
+
+func (e errMissingScheduleChannel) Error() string {
+	return fmt.Sprintf("schedule for %q has no channel", e.name)
+}
+
+// configureSchedules returns a schedule for each of the config's schedule
+// entries. It returns an error if an entry is for a scheduled command that
+// isn't registered, has no channel or has an invalid cron expression.
+func (b botImpl) configureSchedules(c *config.Config) ([]schedule, error) {
+	schedules := make([]schedule, 0, len(c.ScheduleConf.Schedules))
+
+	for _, entry := range c.ScheduleConf.Schedules {
+		cmd := b.registry.GetScheduledCommand(entry.Command)
+		if cmd == nil {
+			return nil, fmt.Errorf("bot schedule error: %w", errUnknownScheduledCommand{entry.Command})
+		}
+
+		if entry.Channel == "" {
+			return nil, fmt.Errorf("bot schedule error: %w", errMissingScheduleChannel{entry.Command})
+		}
+
+		cron, err := botcmd.ParseSchedule(entry.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("bot schedule error for %q: %w", entry.Command, err)
+		}
+
+		schedules = append(schedules, schedule{cmd: cmd, entry: entry, cron: cron})
+	}
+
+	return schedules, nil
+}
+
+// startSchedules starts a goroutine running each schedule. Schedules that
+// missed a run while the bot wasn't running (and don't skip missed runs) run
+// once first for the latest missed time.
+func (b botImpl) startSchedules() {
+	if len(b.schedules) == 0 {
+		return
+	}
+
+	lastRuns := make(map[string]time.Time)
+
+	runs, err := b.storage.GetScheduledRuns()
+	if err != nil {
+		b.log.Errorf("Failed to get scheduled runs, missed runs won't be run: %v", err)
+	}
+
+	for _, run := range runs {
+		lastRuns[run.Command+"#"+run.Channel] = run.LastRun
+	}
+
+	now := time.Now()
+
+	for _, s := range b.schedules {
+		var missed time.Time
+
+		lastRun, found := lastRuns[s.cmd.Name+"#"+s.entry.Channel]
+		if found && !s.entry.SkipMissed {
+			missed = s.missedRun(lastRun, now)
+		}
+
+		go b.runSchedule(s, missed)
+	}
+}
+
+// runSchedule runs the schedule's command at the times of its cron expression
+// until the bot is stopped. If missed isn't the zero time the command is first
+// run once for that time. It is intended to be called from a dedicated
+// goroutine.
+func (b botImpl) runSchedule(s schedule, missed time.Time) {
+	if !missed.IsZero() {
+		b.log.Infof("Schedule %q missed a run at %s, running now", s.name(), missed)
+
+		// The channel can't be found until the Slack state is populated.
+		for !b.slack.StatePopulated() {
+			if !b.sleep(stateWaitInterval) {
+				return
+			}
+		}
+
+		b.runScheduled(s, missed)
+	}
+
+	for {
+		next := s.cron.Next(time.Now())
+		b.log.Infof("Schedule %q will next run at %s", s.name(), next)
+
+		if !b.sleep(time.Until(next)) {
+			return
+		}
+
+		b.runScheduled(s, next)
+	}
+}
+
+// runScheduled runs the schedule's command once for the given scheduled time,
+// posts the result in the schedule's channel and records the run.
+func (b botImpl) runScheduled(s schedule, scheduled time.Time) {
+	log := b.eventLog(logrus.Fields{
+		"handler": s.cmd.Name,
+		"channel": s.entry.Channel,
+	})
+
+	channelID := b.slack.ConversationID(s.entry.Channel)
+	if channelID == "" {
+		log.Errorf("Schedule %q has unknown channel %q", s.name(), s.entry.Channel)
+
+		return
+	}
+
+	log.Infof("Running scheduled command %q for %s", s.cmd.Name, scheduled)
+
+	runCtx := b.runCtx(log, nil)
+	runCtx.ScheduledAt = scheduled
+
+	start := time.Now()
+	res, err := s.cmd.Handler.Run(runCtx)
+	metrics.ObserveHandler(metrics.KindScheduled, s.cmd.Name, start, err)
+
+	run := models.ScheduledRun{
+		Command: s.cmd.Name,
+		Channel: s.entry.Channel,
+		LastRun: scheduled,
+	}
+	if err := b.storage.UpsertScheduledRun(run); err != nil {
+		log.Errorf("Failed to record scheduled run: %v", err)
+	}
+
+	if err != nil {
+		log.Errorf("Scheduled command %q returned an error: %v", s.cmd.Name, err)
+
+		return
+	}
+
+	b.postRunResult(log, channelID, res)
+}
@@ -0,0 +1,198 @@
+//nolint:goerr113
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/botcmd/mocks"
+	"github.com/cpu/gorfbot/config"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	storage_mocks "github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestConfigureSchedules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	registry := botcmd.NewRegistry()
+	registry.AddScheduledCommand(&botcmd.ScheduledCommand{
+		Name:    "test",
+		Handler: mocks.NewMockScheduledHandler(ctrl),
+	})
+
+	bot := botImpl{registry: registry}
+
+	testCases := []struct {
+		name        string
+		entry       config.ScheduleEntry
+		expectedErr bool
+	}{
+		{
+			name:  "valid",
+			entry: config.ScheduleEntry{Command: "test", Cron: "0 14 * * *", Channel: "general"},
+		},
+		{
+			name:  "descriptor",
+			entry: config.ScheduleEntry{Command: "test", Cron: "@daily", Channel: "general"},
+		},
+		{
+			name:        "unknown command",
+			entry:       config.ScheduleEntry{Command: "nope", Cron: "@daily", Channel: "general"},
+			expectedErr: true,
+		},
+		{
+			name:        "no channel",
+			entry:       config.ScheduleEntry{Command: "test", Cron: "@daily"},
+			expectedErr: true,
+		},
+		{
+			name:        "bad cron",
+			entry:       config.ScheduleEntry{Command: "test", Cron: "every day", Channel: "general"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedules, err := bot.configureSchedules(&config.Config{
+				ScheduleConf: config.ScheduleConfig{
+					Schedules: []config.ScheduleEntry{tc.entry},
+				},
+			})
+
+			switch {
+			case tc.expectedErr && err == nil:
+				t.Errorf("expected err, got none")
+			case !tc.expectedErr && err != nil:
+				t.Errorf("unexpected err: %v", err)
+			case !tc.expectedErr && len(schedules) != 1:
+				t.Errorf("expected 1 schedule, got %d", len(schedules))
+			}
+		})
+	}
+}
+
+func TestMissedRun(t *testing.T) {
+	cron, err := botcmd.ParseSchedule("0 14 * * *")
+	if err != nil {
+		t.Fatalf("unexpected ParseSchedule err: %v", err)
+	}
+
+	s := schedule{cron: cron}
+	lastRun := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			name: "nothing missed",
+			now:  time.Date(2021, 1, 3, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "one missed",
+			now:      time.Date(2021, 1, 3, 15, 0, 0, 0, time.UTC),
+			expected: time.Date(2021, 1, 3, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "several missed",
+			now:      time.Date(2021, 1, 6, 9, 0, 0, 0, time.UTC),
+			expected: time.Date(2021, 1, 5, 14, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if missed := s.missedRun(lastRun, tc.now); !missed.Equal(tc.expected) {
+				t.Errorf("expected missed run %s, got %s", tc.expected, missed)
+			}
+		})
+	}
+}
+
+func TestRunScheduled(t *testing.T) {
+	log, logHook := logtest.NewNullLogger()
+	scheduled := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		channelID  string
+		handlerErr error
+		result     botcmd.RunResult
+	}{
+		{
+			name:      "posts result",
+			channelID: "C001",
+			result:    botcmd.RunResult{Message: "it's 2pm"},
+		},
+		{
+			name:      "empty result",
+			channelID: "C001",
+		},
+		{
+			name:       "handler error",
+			channelID:  "C001",
+			handlerErr: errors.New("bad day"),
+		},
+		{
+			name: "unknown channel",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := slack_mocks.NewMockClient(ctrl)
+			mockStorage := storage_mocks.NewMockStorage(ctrl)
+			mockHandler := mocks.NewMockScheduledHandler(ctrl)
+
+			bot := botImpl{
+				log:     log,
+				slack:   mockClient,
+				storage: mockStorage,
+			}
+			s := schedule{
+				cmd:   &botcmd.ScheduledCommand{Name: "test", Handler: mockHandler},
+				entry: config.ScheduleEntry{Command: "test", Channel: "general"},
+			}
+
+			mockClient.EXPECT().ConversationID("general").Return(tc.channelID)
+
+			if tc.channelID != "" {
+				mockHandler.EXPECT().Run(matchRunCtx(botcmd.RunContext{
+					Slack:       mockClient,
+					Storage:     mockStorage,
+					ScheduledAt: scheduled,
+				})).Return(tc.result, tc.handlerErr)
+
+				mockStorage.EXPECT().UpsertScheduledRun(models.ScheduledRun{
+					Command: "test",
+					Channel: "general",
+					LastRun: scheduled,
+				})
+			}
+
+			if tc.handlerErr == nil && tc.result.Message != "" {
+				mockClient.EXPECT().SendMessage(tc.result.Message, tc.channelID)
+			}
+
+			logHook.Reset()
+			bot.runScheduled(s, scheduled)
+
+			if tc.handlerErr != nil || tc.channelID == "" {
+				if len(logHook.Entries) == 0 || logHook.LastEntry().Message == "" {
+					t.Errorf("expected an error to be logged")
+				}
+			}
+		})
+	}
+}
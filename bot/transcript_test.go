@@ -0,0 +1,515 @@
+package bot
+
+// Transcript tests run golden transcript files from testdata/transcripts through
+// the real bot dispatch, backed by a fake Slack server and in-memory storage.
+//
+// A transcript is a list of input events and the output the bot is expected
+// to produce in response to each of them:
+//
+//   # Lines starting with "#" are comments. Blank lines are ignored.
+//   > alice #general: !hello          alice says "!hello" in #general.
+//   < say #general: hello!            the bot says "hello!" in #general.
+//   < react 1 :wave:                  the bot reacts to message 1 with :wave:.
+//   < upload #general: a.png "Title"  the bot uploads a.png titled "Title".
+//   > bob +joy 1                      bob reacts to message 1 with :joy:.
+//   > bob -joy 1                      bob removes their :joy: reaction.
+//   > bob +one <1                     bob reacts to the bot's message 1 with :one:.
+//
+// Input messages are numbered from 1 in the order they appear, and so are the
+// bot's messages (outside of the sync channel) with a "<" prefix. Lines starting
+// with "> |" or "< |" continue the previous input or output message on a new
+// line. Users and channels are created for every name used in the transcript
+// and "<@name>" in message text is translated to and from a user mention. The
+// bot itself is named "gorfbot".
+//
+// Output lines are regenerated on each run. To update the golden transcripts
+// after an intentional change in behaviour run:
+//
+//   go test ./bot -run TestTranscripts -update
+//
+// If a transcript "foo.txt" has a "foo.yml" file beside it that file is used as
+// the bot config (Slack and storage settings are always overridden).
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/memory"
+	"github.com/cpu/gorfbot/test/fakeslack"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+var update = flag.Bool("update", false, "update golden transcript files")
+
+const (
+	transcriptDir     = "testdata/transcripts"
+	transcriptTimeout = 5 * time.Second
+
+	// The sync user and channel are used to flush events through the bot. Their
+	// activity is never included in transcripts.
+	syncUserName    = "transcript-sync"
+	syncChannelName = "transcript-sync"
+
+	inputPrefix        = "> "
+	outputPrefix       = "< "
+	continuationPrefix = "| "
+)
+
+var (
+	inputMessageRegexp  = regexp.MustCompile(`^(\S+) #(\S+): ?(.*)$`)
+	inputReactionRegexp = regexp.MustCompile(`^(\S+) ([+-])(\S+) (<?)(\d+)$`)
+	mentionRegexp       = regexp.MustCompile(`<@([^>|]+)>`)
+)
+
+// transcriptLine is a line of a transcript that is kept when the transcript is
+// regenerated: a comment, a blank line or an input event (including any
+// continuation lines).
+type transcriptLine struct {
+	text string
+	// event is the input event the line starts, if any.
+	event *transcriptEvent
+}
+
+// transcriptEvent is an input message or reaction.
+type transcriptEvent struct {
+	user    string
+	channel string
+	text    string
+
+	// For reactions the reaction, whether it was removed and the number of the
+	// message reacted to, which is one of the bot's messages if botMessage is
+	// true.
+	reaction   string
+	removed    bool
+	message    int
+	botMessage bool
+}
+
+// parseTranscript parses the lines of a transcript that aren't bot output.
+func parseTranscript(data []byte) ([]*transcriptLine, error) {
+	var lines []*transcriptLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(text, outputPrefix):
+			// Output is regenerated.
+			continue
+		case strings.HasPrefix(text, inputPrefix+continuationPrefix):
+			// Continuations must directly follow the message they continue.
+			if len(lines) == 0 || lines[len(lines)-1].event == nil || lines[len(lines)-1].event.reaction != "" {
+				return nil, fmt.Errorf("line %d: continuation without a message", lineNum)
+			}
+
+			last := lines[len(lines)-1]
+			last.text += "\n" + text
+			last.event.text += "\n" + strings.TrimPrefix(text, inputPrefix+continuationPrefix)
+		case strings.HasPrefix(text, inputPrefix):
+			event, err := parseEvent(strings.TrimPrefix(text, inputPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+
+			lines = append(lines, &transcriptLine{text: text, event: event})
+		case text == "" || strings.HasPrefix(text, "#"):
+			lines = append(lines, &transcriptLine{text: text})
+		default:
+			return nil, fmt.Errorf("line %d: unexpected line %q", lineNum, text)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseEvent parses an input event line without its prefix.
+func parseEvent(text string) (*transcriptEvent, error) {
+	if m := inputReactionRegexp.FindStringSubmatch(text); m != nil {
+		message, _ := strconv.Atoi(m[5])
+
+		return &transcriptEvent{
+			user:       m[1],
+			reaction:   m[3],
+			removed:    m[2] == "-",
+			message:    message,
+			botMessage: m[4] == "<",
+		}, nil
+	}
+
+	if m := inputMessageRegexp.FindStringSubmatch(text); m != nil {
+		return &transcriptEvent{
+			user:    m[1],
+			channel: m[2],
+			text:    m[3],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid input event %q", text)
+}
+
+// transcriptRunner runs transcript events through a bot.
+type transcriptRunner struct {
+	t      *testing.T
+	server *fakeslack.Server
+
+	userIDs      map[string]string
+	userNames    map[string]string
+	channelIDs   map[string]string
+	channelNames map[string]string
+
+	// messages holds the channel and timestamp of each input message, in order.
+	messages []fakeslack.Reaction
+	// botMessages holds the channel and timestamp of each message the bot sent
+	// outside of the sync channel, in order.
+	botMessages []fakeslack.Reaction
+
+	// seenMessages and seenReactions count output already included in the
+	// transcript.
+	seenMessages  int
+	seenReactions int
+	seenFiles     int
+	flushes       int
+}
+
+// newTranscriptRunner creates a fake Slack server with users and channels for
+// the given transcript lines and runs a bot connected to it.
+func newTranscriptRunner(t *testing.T, lines []*transcriptLine, c *config.Config) *transcriptRunner {
+	t.Helper()
+
+	fakeConfig := fakeslack.DefaultConfig()
+	fakeConfig.Users = nil
+	fakeConfig.Conversations = nil
+
+	r := &transcriptRunner{
+		t:            t,
+		userIDs:      map[string]string{fakeConfig.Bot.Name: fakeConfig.Bot.ID},
+		userNames:    map[string]string{fakeConfig.Bot.ID: fakeConfig.Bot.Name},
+		channelIDs:   make(map[string]string),
+		channelNames: make(map[string]string),
+	}
+
+	addUser := func(name string) {
+		if _, found := r.userIDs[name]; !found {
+			id := fmt.Sprintf("U%03d", len(fakeConfig.Users)+1)
+			r.userIDs[name] = id
+			r.userNames[id] = name
+			fakeConfig.Users = append(fakeConfig.Users, fakeslack.User{ID: id, Name: name})
+		}
+	}
+	addChannel := func(name string) {
+		if _, found := r.channelIDs[name]; !found {
+			id := fmt.Sprintf("C%03d", len(fakeConfig.Conversations)+1)
+			r.channelIDs[name] = id
+			r.channelNames[id] = name
+			fakeConfig.Conversations = append(fakeConfig.Conversations, fakeslack.Conversation{ID: id, Name: name})
+		}
+	}
+
+	addUser(syncUserName)
+	addChannel(syncChannelName)
+
+	for _, line := range lines {
+		if event := line.event; event != nil {
+			addUser(event.user)
+
+			if event.channel != "" {
+				addChannel(event.channel)
+			}
+		}
+	}
+
+	r.server = fakeslack.New(fakeConfig)
+
+	c.SlackConf = config.SlackConfig{
+		APIToken: fakeslack.Token,
+		APIURL:   r.server.APIURL(),
+	}
+
+	log, _ := logtest.NewNullLogger()
+
+	client, err := slack.New(log, c)
+	if err != nil {
+		r.server.Close()
+		t.Fatalf("unexpected error creating slack client: %v", err)
+	}
+
+	bot := botImpl{
+		log:      log,
+		storage:  memory.NewMemoryStorage(),
+		slack:    client,
+		registry: botcmd.DefaultRegistry,
+		done:     make(chan struct{}),
+	}
+	t.Cleanup(bot.Stop)
+
+	if err := bot.configure(c); err != nil {
+		r.server.Close()
+		t.Fatalf("unexpected error configuring bot: %v", err)
+	}
+
+	go bot.Run()
+
+	if err := r.server.WaitForConnection(transcriptTimeout); err != nil {
+		r.server.Close()
+		t.Fatalf("bot never connected: %v", err)
+	}
+
+	// Wait for the client to have loaded the users and channels so that
+	// commands can look up names.
+	deadline := time.Now().Add(transcriptTimeout)
+	for client.UserName(r.userIDs[syncUserName]) == "" {
+		if time.Now().After(deadline) {
+			r.server.Close()
+			t.Fatalf("slack client state never loaded")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	r.flush()
+
+	return r
+}
+
+// flush sends an echo command in the sync channel and waits for the bot to
+// reply. The bot handles events in order so once the reply arrives all output
+// for earlier events has been recorded by the fake server.
+func (r *transcriptRunner) flush() {
+	r.flushes++
+	marker := fmt.Sprintf("flush %d", r.flushes)
+
+	if _, err := r.server.SendMessage(
+		r.userIDs[syncUserName], r.channelIDs[syncChannelName], "!echo "+marker); err != nil {
+		r.t.Fatalf("unexpected error sending flush: %v", err)
+	}
+
+	deadline := time.Now().Add(transcriptTimeout)
+
+	for {
+		msgs := r.server.Messages()
+		for _, msg := range msgs[r.seenMessages:] {
+			if msg.ChannelID == r.channelIDs[syncChannelName] && msg.Text == marker {
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			r.t.Fatalf("timed out waiting for %q", marker)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// run sends an input event to the bot and returns the output lines it
+// produced in response.
+func (r *transcriptRunner) run(event *transcriptEvent) []string {
+	if event.reaction != "" {
+		messages, prefix := r.messages, ""
+		if event.botMessage {
+			messages, prefix = r.botMessages, "<"
+		}
+
+		if event.message < 1 || event.message > len(messages) {
+			r.t.Fatalf("reaction to unknown message %s%d", prefix, event.message)
+		}
+
+		target := messages[event.message-1]
+		send := r.server.AddReaction
+
+		if event.removed {
+			send = r.server.RemoveReaction
+		}
+
+		if err := send(r.userIDs[event.user], event.reaction, target.ChannelID, target.Timestamp); err != nil {
+			r.t.Fatalf("unexpected error sending reaction: %v", err)
+		}
+	} else {
+		text := mentionRegexp.ReplaceAllStringFunc(event.text, func(mention string) string {
+			name := mentionRegexp.FindStringSubmatch(mention)[1]
+			if id, found := r.userIDs[name]; found {
+				return "<@" + id + ">"
+			}
+
+			return mention
+		})
+
+		channelID := r.channelIDs[event.channel]
+
+		ts, err := r.server.SendMessage(r.userIDs[event.user], channelID, text)
+		if err != nil {
+			r.t.Fatalf("unexpected error sending message: %v", err)
+		}
+
+		r.messages = append(r.messages, fakeslack.Reaction{ChannelID: channelID, Timestamp: ts})
+	}
+
+	r.flush()
+
+	return r.output()
+}
+
+// output returns transcript lines for the messages and reactions the bot has
+// produced since output was last called, ignoring the sync channel. Messages
+// come before uploaded files and reactions: the order between them isn't
+// deterministic. Only the names and titles of uploaded files are included.
+func (r *transcriptRunner) output() []string {
+	var lines []string
+
+	syncChannel := r.channelIDs[syncChannelName]
+
+	msgs := r.server.Messages()
+	for _, msg := range msgs[r.seenMessages:] {
+		if msg.ChannelID == syncChannel {
+			continue
+		}
+
+		r.botMessages = append(r.botMessages, fakeslack.Reaction{ChannelID: msg.ChannelID, Timestamp: msg.Timestamp})
+
+		text := mentionRegexp.ReplaceAllStringFunc(msg.Text, func(mention string) string {
+			id := mentionRegexp.FindStringSubmatch(mention)[1]
+			if name, found := r.userNames[id]; found {
+				return "<@" + name + ">"
+			}
+
+			return mention
+		})
+
+		for i, line := range strings.Split(text, "\n") {
+			if i == 0 {
+				lines = append(lines, fmt.Sprintf("%ssay #%s: %s", outputPrefix, r.channelNames[msg.ChannelID], line))
+			} else {
+				// Avoid trailing whitespace in the transcript for blank lines.
+				lines = append(lines, strings.TrimRight(outputPrefix+continuationPrefix+line, " "))
+			}
+		}
+	}
+
+	r.seenMessages = len(msgs)
+
+	files := r.server.Files()
+	for _, file := range files[r.seenFiles:] {
+		lines = append(lines, fmt.Sprintf("%supload #%s: %s %q",
+			outputPrefix, r.channelNames[file.ChannelID], file.Name, file.Title))
+	}
+
+	r.seenFiles = len(files)
+
+	reactions := r.server.Reactions()
+	for _, reaction := range reactions[r.seenReactions:] {
+		if reaction.ChannelID == syncChannel {
+			continue
+		}
+
+		target := "?"
+		item := fakeslack.Reaction{ChannelID: reaction.ChannelID, Timestamp: reaction.Timestamp}
+
+		for i, msg := range r.messages {
+			if msg == item {
+				target = strconv.Itoa(i + 1)
+			}
+		}
+
+		for i, msg := range r.botMessages {
+			if msg == item {
+				target = "<" + strconv.Itoa(i+1)
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("%sreact %s :%s:", outputPrefix, target, reaction.Reaction))
+	}
+
+	r.seenReactions = len(reactions)
+
+	return lines
+}
+
+// runTranscript runs the transcript at the given path and returns the
+// regenerated transcript.
+func runTranscript(t *testing.T, path string, data []byte) []byte {
+	t.Helper()
+
+	lines, err := parseTranscript(data)
+	if err != nil {
+		t.Fatalf("failed to parse transcript: %v", err)
+	}
+
+	c := &config.Config{}
+
+	configPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".yml"
+	if _, err := os.Stat(configPath); err == nil {
+		if c, err = config.FromYAMLFile(configPath); err != nil {
+			t.Fatalf("failed to load transcript config: %v", err)
+		}
+	}
+
+	runner := newTranscriptRunner(t, lines, c)
+	defer runner.server.Close()
+
+	out := new(bytes.Buffer)
+
+	for _, line := range lines {
+		fmt.Fprintln(out, line.text)
+
+		if line.event == nil {
+			continue
+		}
+
+		for _, output := range runner.run(line.event) {
+			fmt.Fprintln(out, output)
+		}
+	}
+
+	return out.Bytes()
+}
+
+func TestTranscripts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(transcriptDir, "*.txt"))
+	if err != nil {
+		t.Fatalf("failed to find transcripts: %v", err)
+	}
+
+	if len(paths) == 0 {
+		t.Fatalf("no transcripts found in %s", transcriptDir)
+	}
+
+	for _, path := range paths {
+		path := path
+
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			expected, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read transcript: %v", err)
+			}
+
+			actual := runTranscript(t, path, expected)
+
+			if *update {
+				if err := ioutil.WriteFile(path, actual, 0600); err != nil {
+					t.Fatalf("failed to update transcript: %v", err)
+				}
+
+				return
+			}
+
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("transcript %s doesn't match (run with -update to regenerate):\n"+
+					"--- expected\n%s\n--- actual\n%s", path, expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package botcmd
+
+import "strings"
+
+// Arg is an argument of a command split by SplitArgs.
+type Arg struct {
+	Text string
+	// Quoted is true if the argument was in quotes.
+	Quoted bool
+}
+
+// isQuote returns true for straight quotes and the curly quotes some Slack
+// clients replace them with.
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
+
+// SplitArgs splits text into whitespace separated words and quoted strings. It
+// returns false if a quoted string is missing its closing quote.
+func SplitArgs(text string) ([]Arg, bool) {
+	var args []Arg
+
+	var current strings.Builder
+
+	var inQuote, inWord bool
+
+	for _, r := range text {
+		switch {
+		case inQuote && isQuote(r):
+			args = append(args, Arg{Text: strings.TrimSpace(current.String()), Quoted: true})
+			current.Reset()
+
+			inQuote = false
+		case inQuote:
+			current.WriteRune(r)
+		case isQuote(r) || r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, Arg{Text: current.String()})
+				current.Reset()
+
+				inWord = false
+			}
+
+			inQuote = isQuote(r)
+		default:
+			current.WriteRune(r)
+
+			inWord = true
+		}
+	}
+
+	if inWord {
+		args = append(args, Arg{Text: current.String()})
+	}
+
+	return args, !inQuote
+}
@@ -0,0 +1,38 @@
+package botcmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []Arg
+		ok       bool
+	}{
+		{
+			text: `in 2h "Lunch?" “tacos or  burritos” "pho"`,
+			expected: []Arg{
+				{Text: "in"}, {Text: "2h"},
+				{Text: "Lunch?", Quoted: true},
+				{Text: "tacos or  burritos", Quoted: true},
+				{Text: "pho", Quoted: true},
+			},
+			ok: true,
+		},
+		{
+			text:     `"Lunch?" "tacos`,
+			expected: []Arg{{Text: "Lunch?", Quoted: true}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			args, ok := SplitArgs(tc.text)
+			if !reflect.DeepEqual(args, tc.expected) || ok != tc.ok {
+				t.Errorf("expected %v %v, got %v %v", tc.expected, tc.ok, args, ok)
+			}
+		})
+	}
+}
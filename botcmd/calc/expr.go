@@ -0,0 +1,468 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxLength is the maximum length of an expression.
+	maxLength = 500
+	// maxDepth is the maximum nesting of parentheses, function calls and unary
+	// operators in an expression.
+	maxDepth = 100
+)
+
+var (
+	errTooLong        = errors.New("expression is too long")
+	errTooDeep        = errors.New("expression is nested too deeply")
+	errUnexpected     = errors.New("unexpected")
+	errBadNumber      = errors.New("bad number")
+	errUnknownName    = errors.New("unknown function or constant")
+	errNotFunction    = errors.New("is a constant, not a function")
+	errNotConstant    = errors.New("is a function, call it like")
+	errArgs           = errors.New("wrong number of arguments for")
+	errDivideByZero   = errors.New("division by zero")
+	errNotANumber     = errors.New("result is not a number")
+	errInfiniteResult = errors.New("result is too big")
+)
+
+// constants are the named constants that can be used in expressions.
+var constants = map[string]float64{
+	"pi":  math.Pi,
+	"tau": 2 * math.Pi,
+	"e":   math.E,
+	"phi": math.Phi,
+}
+
+// function is a function that can be called in expressions. A function with
+// max < 0 takes any number of arguments from min.
+type function struct {
+	min, max int
+	fn       func(args []float64) float64
+}
+
+func unary(fn func(float64) float64) function {
+	return function{min: 1, max: 1, fn: func(args []float64) float64 { return fn(args[0]) }}
+}
+
+func binary(fn func(float64, float64) float64) function {
+	return function{min: 2, max: 2, fn: func(args []float64) float64 { return fn(args[0], args[1]) }}
+}
+
+// functions are the functions that can be called in expressions.
+var functions = map[string]function{
+	"abs":   unary(math.Abs),
+	"ceil":  unary(math.Ceil),
+	"floor": unary(math.Floor),
+	"round": unary(math.Round),
+	"trunc": unary(math.Trunc),
+	"sqrt":  unary(math.Sqrt),
+	"cbrt":  unary(math.Cbrt),
+	"exp":   unary(math.Exp),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"pow":   binary(math.Pow),
+	"hypot": binary(math.Hypot),
+	"min":   {min: 1, max: -1, fn: minimum},
+	"max":   {min: 1, max: -1, fn: maximum},
+}
+
+func minimum(args []float64) float64 {
+	result := args[0]
+	for _, arg := range args[1:] {
+		result = math.Min(result, arg)
+	}
+
+	return result
+}
+
+func maximum(args []float64) float64 {
+	result := args[0]
+	for _, arg := range args[1:] {
+		result = math.Max(result, arg)
+	}
+
+	return result
+}
+
+// token is a number, a name or an operator in an expression.
+type token struct {
+	// text of the token. It is "" at the end of the expression.
+	text string
+	// pos is the position of the token in the expression, from 1.
+	pos int
+	// number is the value of a number token.
+	number   float64
+	isNumber bool
+	isName   bool
+}
+
+// String describes the token for error messages.
+func (t token) String() string {
+	if t.text == "" {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("`%s` at %d", t.text, t.pos)
+}
+
+// tokenize returns the tokens of an expression, ending with an empty token.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+			continue
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+
+			// An exponent like "1e6" or "2.5E-3".
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					i = j
+					for i < len(runes) && unicode.IsDigit(runes[i]) {
+						i++
+					}
+				}
+			}
+
+			text := string(runes[start:i])
+
+			number, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w `%s` at %d", errBadNumber, text, start+1)
+			}
+
+			tokens = append(tokens, token{text: text, pos: start + 1, number: number, isNumber: true})
+		case unicode.IsLetter(r):
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+
+			tokens = append(tokens, token{text: strings.ToLower(string(runes[start:i])), pos: start + 1, isName: true})
+		case r == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+
+			tokens = append(tokens, token{text: "^", pos: start + 1})
+		case strings.ContainsRune("+-*/%^(),", r):
+			i++
+
+			tokens = append(tokens, token{text: string(r), pos: start + 1})
+		case r == '×':
+			i++
+
+			tokens = append(tokens, token{text: "*", pos: start + 1})
+		case r == '÷':
+			i++
+
+			tokens = append(tokens, token{text: "/", pos: start + 1})
+		default:
+			return nil, fmt.Errorf("%w `%c` at %d", errUnexpected, r, start+1)
+		}
+	}
+
+	return append(tokens, token{pos: len(runes) + 1}), nil
+}
+
+// parser evaluates an expression while parsing it with recursive descent.
+// From lowest to highest precedence an expression has:
+//
+//	sums:       a + b, a - b
+//	products:   a * b, a / b, a % b
+//	signs:      -a, +a
+//	powers:     a ^ b, a ** b (right associative, so 2^3^2 is 2^9)
+//	primaries:  numbers, constants, f(a, b), (a)
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// evaluate returns the value of an arithmetic expression like
+// "2 * (3 + sqrt(16))".
+func evaluate(expr string) (float64, error) {
+	if len(expr) > maxLength {
+		return 0, errTooLong
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	result, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+
+	if next := p.peek(); next.text != "" {
+		return 0, fmt.Errorf("%w %s", errUnexpected, next)
+	}
+
+	switch {
+	case math.IsNaN(result):
+		return 0, errNotANumber
+	case math.IsInf(result, 0):
+		return 0, errInfiniteResult
+	}
+
+	return result, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.text != "" {
+		p.pos++
+	}
+
+	return t
+}
+
+// expect consumes the next token, returning an error if it isn't the given
+// operator.
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.isNumber || t.isName || t.text != op {
+		return fmt.Errorf("%w %s, expected `%s`", errUnexpected, t, op)
+	}
+
+	return nil
+}
+
+// isOp returns true if the next token is one of the given operators.
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.isNumber || t.isName {
+		return false
+	}
+
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) sum() (float64, error) {
+	result, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.isOp("+", "-") {
+		op := p.next()
+
+		right, err := p.product()
+		if err != nil {
+			return 0, err
+		}
+
+		if op.text == "+" {
+			result += right
+		} else {
+			result -= right
+		}
+	}
+
+	return result, nil
+}
+
+func (p *parser) product() (float64, error) {
+	result, err := p.sign()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.isOp("*", "/", "%") {
+		op := p.next()
+
+		right, err := p.sign()
+		if err != nil {
+			return 0, err
+		}
+
+		switch op.text {
+		case "*":
+			result *= right
+		case "/", "%":
+			if right == 0 {
+				return 0, errDivideByZero
+			}
+
+			if op.text == "/" {
+				result /= right
+			} else {
+				result = math.Mod(result, right)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (p *parser) sign() (float64, error) {
+	if !p.isOp("-", "+") {
+		return p.power()
+	}
+
+	if p.depth++; p.depth > maxDepth {
+		return 0, errTooDeep
+	}
+	defer func() { p.depth-- }()
+
+	op := p.next()
+
+	result, err := p.sign()
+	if err != nil {
+		return 0, err
+	}
+
+	if op.text == "-" {
+		return -result, nil
+	}
+
+	return result, nil
+}
+
+func (p *parser) power() (float64, error) {
+	base, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+
+	if !p.isOp("^") {
+		return base, nil
+	}
+
+	p.next()
+
+	// The exponent can have a sign, like 2^-1.
+	exponent, err := p.sign()
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Pow(base, exponent), nil
+}
+
+func (p *parser) primary() (float64, error) {
+	if p.depth++; p.depth > maxDepth {
+		return 0, errTooDeep
+	}
+	defer func() { p.depth-- }()
+
+	t := p.next()
+
+	switch {
+	case t.isNumber:
+		return t.number, nil
+	case t.isName:
+		return p.name(t)
+	case t.text == "(":
+		result, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+
+		return result, p.expect(")")
+	default:
+		return 0, fmt.Errorf("%w %s", errUnexpected, t)
+	}
+}
+
+// name returns the value of a constant, or the result of calling a function
+// with the arguments that follow its name.
+func (p *parser) name(t token) (float64, error) {
+	if value, ok := constants[t.text]; ok {
+		if p.isOp("(") {
+			return 0, fmt.Errorf("`%s` %w", t.text, errNotFunction)
+		}
+
+		return value, nil
+	}
+
+	f, ok := functions[t.text]
+	if !ok {
+		return 0, fmt.Errorf("%w `%s`", errUnknownName, t.text)
+	}
+
+	if !p.isOp("(") {
+		return 0, fmt.Errorf("`%s` %w `%s(x)`", t.text, errNotConstant, t.text)
+	}
+
+	p.next()
+
+	var args []float64
+
+	if !p.isOp(")") {
+		for {
+			arg, err := p.sum()
+			if err != nil {
+				return 0, err
+			}
+
+			args = append(args, arg)
+
+			if !p.isOp(",") {
+				break
+			}
+
+			p.next()
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return 0, err
+	}
+
+	if len(args) < f.min || (f.max >= 0 && len(args) > f.max) {
+		return 0, fmt.Errorf("%w `%s`", errArgs, t.text)
+	}
+
+	return f.fn(args), nil
+}
+
+// format returns a result rounded to 12 significant digits, without trailing
+// zeros.
+func format(result float64) string {
+	if result == 0 {
+		// Avoid "-0".
+		return "0"
+	}
+
+	return strconv.FormatFloat(result, 'g', 12, 64)
+}
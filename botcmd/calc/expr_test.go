@@ -0,0 +1,87 @@
+package calc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: "1 + 2 * 3", expected: "7"},
+		{expr: "(1 + 2) * 3", expected: "9"},
+		{expr: "10 - 4 - 3", expected: "3"},
+		{expr: "2 ^ 3 ^ 2", expected: "512"},
+		{expr: "2 ** 10", expected: "1024"},
+		{expr: "-2 ^ 2", expected: "-4"},
+		{expr: "2 ^ -1", expected: "0.5"},
+		{expr: "--3", expected: "3"},
+		{expr: "7 % 3 + 7 / 2", expected: "4.5"},
+		{expr: "6 × 7 ÷ 2", expected: "21"},
+		{expr: "0.1 + 0.2", expected: "0.3"},
+		{expr: "1_000_000 * 1.5e3", expected: "1500000000"},
+		{expr: "2.5E-3", expected: "0.0025"},
+		{expr: "sqrt(16) + abs(-2)", expected: "6"},
+		{expr: "floor(2.7) + ceil(2.1) + round(2.5) + trunc(-2.5)", expected: "6"},
+		{expr: "max(1, 5, 3) - min(4, 2)", expected: "3"},
+		{expr: "pow(2, 8) + hypot(3, 4)", expected: "261"},
+		{expr: "log(1000) + ln(e) + log2(8)", expected: "7"},
+		{expr: "cos(PI)", expected: "-1"},
+		{expr: "sin(0) * -1", expected: "0"},
+		{expr: "tau / pi", expected: "2"},
+		{expr: "1 / 3", expected: "0.333333333333"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			result, err := evaluate(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if formatted := format(result); formatted != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	testCases := []struct {
+		expr        string
+		expectedErr error
+	}{
+		{expr: "", expectedErr: errUnexpected},
+		{expr: "1 +", expectedErr: errUnexpected},
+		{expr: "(1 + 2", expectedErr: errUnexpected},
+		{expr: "1 + 2)", expectedErr: errUnexpected},
+		{expr: "2 3", expectedErr: errUnexpected},
+		{expr: "1 & 2", expectedErr: errUnexpected},
+		{expr: "1.2.3", expectedErr: errBadNumber},
+		{expr: "frogs(1)", expectedErr: errUnknownName},
+		{expr: "pi(1)", expectedErr: errNotFunction},
+		{expr: "sqrt + 1", expectedErr: errNotConstant},
+		{expr: "sqrt(1, 2)", expectedErr: errArgs},
+		{expr: "max()", expectedErr: errArgs},
+		{expr: "1 / 0", expectedErr: errDivideByZero},
+		{expr: "1 % (2 - 2)", expectedErr: errDivideByZero},
+		{expr: "sqrt(-1)", expectedErr: errNotANumber},
+		{expr: "10 ^ 1000", expectedErr: errInfiniteResult},
+		{expr: strings.Repeat("(", 200) + "1" + strings.Repeat(")", 200), expectedErr: errTooDeep},
+		{expr: strings.Repeat("-", 200) + "1", expectedErr: errTooDeep},
+		{expr: strings.Repeat("1+", 300) + "1", expectedErr: errTooLong},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			if _, err := evaluate(tc.expr); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected err %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
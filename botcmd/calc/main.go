@@ -0,0 +1,65 @@
+// Package calc provides a command for evaluating arithmetic expressions like
+// "2 * (3 + sqrt(16))".
+package calc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "calc"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*calc*:\n" +
+		"\t`!calc <expression>` - e.g. `!calc (2 + 3) * 4 ^ 2 / 3`\n" +
+		"\tOperators: `+ - * / % ^` (or `**`) and parentheses\n" +
+		"\tFunctions: `abs ceil floor round trunc sqrt cbrt exp ln log log2 " +
+		"sin cos tan asin acos atan pow hypot min max`\n" +
+		"\tConstants: `pi tau e phi`"
+)
+
+type calcCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":abacus:",
+		Description: "Calculate the result of an arithmetic expression",
+		Handler:     &calcCmd{},
+	})
+}
+
+func (cmd *calcCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+
+	return nil
+}
+
+func (cmd calcCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	expr := strings.TrimSpace(text)
+
+	switch strings.ToLower(expr) {
+	case "", "-h", "help":
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	// Slack may send an expression wrapped in backticks.
+	expr = strings.TrimSpace(strings.Trim(expr, "`"))
+
+	result, err := evaluate(expr)
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	runCtx.Logger(cmd.log).Debugf("%s evaluated %q: %v", cmdName, expr, result)
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":abacus: `%s` = *%s*", expr, format(result)),
+	}, nil
+}
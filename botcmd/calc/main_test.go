@@ -0,0 +1,44 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestRun(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &calcCmd{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "", expected: usage},
+		{text: " help ", expected: usage},
+		{text: "2 * (3 + sqrt(16))", expected: ":abacus: `2 * (3 + sqrt(16))` = *14*"},
+		{text: "`1/4`", expected: ":abacus: `1/4` = *0.25*"},
+		{text: "1 / 0", expected: "calc: division by zero"},
+		{text: "2 +* 3", expected: "calc: unexpected `*` at 4"},
+		{text: "(2", expected: "calc: unexpected end of expression, expected `)`"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.text, func(t *testing.T) {
+			res, err := cmd.Run(tc.text, botcmd.RunContext{})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/cpu/gorfbot/config"
 	"github.com/cpu/gorfbot/slack"
@@ -23,14 +24,40 @@ type RunContext struct {
 	Message *slack.Message
 	Storage storage.Storage
 	Slack   slack.Client
+	// Log is a log entry for the event being handled. It carries fields like the
+	// event's correlation ID, channel, user and handler name. It may be nil, see
+	// Logger.
+	Log *logrus.Entry
+	// ScheduledAt is the time a ScheduledCommand's run was scheduled for. It is
+	// zero when not running a ScheduledCommand.
+	ScheduledAt time.Time
+}
+
+// Logger returns the RunContext's Log entry, or an entry for the provided
+// fallback logger if the RunContext has no Log entry. Handlers should log with
+// the returned entry while running so their log lines can be correlated with the
+// event being handled.
+func (ctx RunContext) Logger(fallback *logrus.Logger) *logrus.Entry {
+	if ctx.Log != nil {
+		return ctx.Log
+	}
+
+	if fallback == nil {
+		fallback = logrus.StandardLogger()
+	}
+
+	return logrus.NewEntry(fallback)
 }
 
 // RunResult is returned by a botcmd's Run function and can be used as a simple way
-// to post a reply message and/or add reactions to the message that caused the
-// botcmd to be run.
+// to post a reply message, upload a file and/or add reactions to the message
+// that caused the botcmd to be run.
 type RunResult struct {
 	Message string
 	Reactji []string
+	// File is an optional file to upload to the channel the botcmd was run in
+	// (e.g. a rendered chart).
+	File *slack.File
 }
 
 // Configurable is a common interface for anything (cmd, pattern cmd, reaction
@@ -107,6 +134,47 @@ type ReactionCommand struct {
 	Handler ReactionHandler
 }
 
+// EmojiChangeHandler describes a configurable that has its Run function called
+// when workspace custom emoji are added/removed.
+//go:generate mockgen -destination=mocks/mock_emoji_change_handler.go -package=mocks . EmojiChangeHandler
+type EmojiChangeHandler interface {
+	Configurable
+	// Run is called when custom emoji are added or removed.
+	Run(change *slack.EmojiChange, runCtx RunContext) error
+}
+
+// EmojiChangeCommand describes a named emoji change handler that is run when
+// workspace custom emoji are added/removed.
+type EmojiChangeCommand struct {
+	// Name of the emoji change handler. Used in help, should describe purpose of
+	// handler.
+	Name string
+	// Handler is invoked when custom emoji are added/removed.
+	Handler EmojiChangeHandler
+}
+
+// ScheduledHandler describes a configurable that has its Run function called
+// on a schedule.
+//go:generate mockgen -destination=mocks/mock_scheduled_handler.go -package=mocks . ScheduledHandler
+type ScheduledHandler interface {
+	Configurable
+	// Run is called at each scheduled time with a run context that has no
+	// Message. The returned RunResult's Message and File are posted to the
+	// schedule's channel, Reactji are ignored.
+	Run(runCtx RunContext) (RunResult, error)
+}
+
+// ScheduledCommand describes a named scheduled handler. When and where it runs
+// is configured with a config.ScheduleEntry for the Name.
+type ScheduledCommand struct {
+	// Name of the scheduled command. Used in help and schedule config.
+	Name string
+	// Description is a short description of the command for help output.
+	Description string
+	// Handler is invoked at each scheduled time.
+	Handler ScheduledHandler
+}
+
 // bufferedHelp returns a function that when invoked will write a help string
 // for each of the flagset's flags to a returned byte buffer. This is an easy
 // way to dynamically build up a help string for a flag set that can be sent to
@@ -3,6 +3,9 @@ package botcmd
 import (
 	"flag"
 	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 )
 
 func TestParseFlags(t *testing.T) {
@@ -42,3 +45,23 @@ func TestParseFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestRunContextLogger(t *testing.T) {
+	fallback, _ := logtest.NewNullLogger()
+
+	// Without a Log entry the fallback logger is used.
+	if entry := (RunContext{}).Logger(fallback); entry.Logger != fallback {
+		t.Errorf("expected entry for fallback logger, got entry for %v", entry.Logger)
+	}
+
+	// With a Log entry it's used as-is.
+	log := logrus.NewEntry(fallback).WithField("correlation_id", "1234")
+	if entry := (RunContext{Log: log}).Logger(fallback); entry != log {
+		t.Errorf("expected RunContext Log entry, got %v", entry)
+	}
+
+	// Without either the standard logger is used.
+	if entry := (RunContext{}).Logger(nil); entry.Logger != logrus.StandardLogger() {
+		t.Errorf("expected entry for standard logger, got entry for %v", entry.Logger)
+	}
+}
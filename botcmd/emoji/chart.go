@@ -0,0 +1,105 @@
+package emoji
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/chart"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+const (
+	// chartFileName is the name of uploaded chart images.
+	chartFileName = "emoji.png"
+	// chartHistoryDays is how many days of usage a line chart shows when there
+	// is no -since flag.
+	chartHistoryDays = 30
+	// chartDayLayout is the layout of line chart day labels.
+	chartDayLayout = "01-02"
+)
+
+// chartResult returns a RunResult uploading the PNG chart with the given title.
+func chartResult(title string, png []byte) botcmd.RunResult {
+	return botcmd.RunResult{
+		File: &slack.File{
+			Name:    chartFileName,
+			Title:   title,
+			Content: png,
+		},
+	}
+}
+
+// barChart renders the emoji counts as a bar chart, one bar per emoji in order.
+func barChart(title string, emoji []models.Emoji) (botcmd.RunResult, error) {
+	data := make([]chart.Datum, 0, len(emoji))
+	for _, e := range emoji {
+		data = append(data, chart.Datum{Label: e.Emoji, Value: e.Count})
+	}
+
+	png, err := chart.Bar(title, data)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to render bar chart: %w", cmdName, err)
+	}
+
+	return chartResult(title, png), nil
+}
+
+// historyWindow returns the window a usage history chart covers. Without a
+// since bound the chart covers the chartHistoryDays before the until bound, or
+// now.
+func historyWindow(window botcmd.Window, now time.Time) botcmd.Window {
+	if window.Until.IsZero() {
+		window.Until = now
+	}
+
+	if window.Since.IsZero() {
+		window.Since = window.Until.AddDate(0, 0, -chartHistoryDays)
+	}
+
+	return window
+}
+
+// historyData returns a chart datum for every day in the window, counting the
+// usage from the history on that day (or zero).
+func historyData(history []models.EmojiUsage, window botcmd.Window) []chart.Datum {
+	counts := make(map[time.Time]int, len(history))
+	for _, usage := range history {
+		counts[usage.Day] = usage.Count
+	}
+
+	var data []chart.Datum
+
+	for day := models.UsageDay(window.Since); day.Before(window.Until); day = day.AddDate(0, 0, 1) {
+		data = append(data, chart.Datum{Label: day.Format(chartDayLayout), Value: counts[day]})
+	}
+
+	return data
+}
+
+// historyChart renders the daily usage matching the options as a line chart.
+// The options Since and Until are ignored in favour of the window.
+func (cmd emojiCmd) historyChart(
+	runCtx botcmd.RunContext,
+	opts storage.GetEmojiHistoryOptions,
+	window botcmd.Window,
+	title string) (botcmd.RunResult, error) {
+	opts.Since, opts.Until = window.Since, window.Until
+	runCtx.Logger(cmd.log).Infof("Getting emoji history with options: %#v", opts)
+
+	history, err := runCtx.Storage.GetEmojiHistory(opts)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s: failed to get emoji history from storage opts: %v err: %w",
+				cmdName, opts, err)
+	}
+
+	png, err := chart.Line(title, historyData(history, window))
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to render line chart: %w", cmdName, err)
+	}
+
+	return chartResult(title, png), nil
+}
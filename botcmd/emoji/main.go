@@ -5,8 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/chart"
 	"github.com/cpu/gorfbot/config"
 	"github.com/cpu/gorfbot/storage"
 	"github.com/sirupsen/logrus"
@@ -38,11 +40,57 @@ func (cmd emojiCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult
 	emojiFlag := flagSet.String("emoji", "", "display count only for matching emoji")
 	usernameFlag := flagSet.String("user", "", "display emoji stats for a user other than yourself")
 	reactions := flagSet.Bool("reactions", false, "only include reactions stats")
+	sinceFlag := flagSet.String("since", "", "only count usage since an age (e.g. 7d, 2w) or date (e.g. 2021-03-01)")
+	untilFlag := flagSet.String("until", "", "only count usage before an age (e.g. 7d, 2w) or date (e.g. 2021-04-01)")
+	channelFlag := flagSet.String("channel", "", "only count usage in a channel (e.g. general)")
+	received := flagSet.Bool("received", false, "show reactji received from other people instead of reactji given")
+	chartFlag := flagSet.Bool("chart", false,
+		"upload a chart of the top emoji, or with -emoji a chart of its daily usage")
+	unused := flagSet.Bool("unused", false, "list custom emoji nobody has used in messages or reactji")
 
 	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
 		return botcmd.RunResult{Message: respText}, nil
 	}
 
+	if *unused {
+		return cmd.unusedReport(runCtx)
+	}
+
+	if *received && (*sinceFlag != "" || *untilFlag != "" || *channelFlag != "") {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: -received can't be combined with -since, -until or -channel", cmdName),
+		}, nil
+	}
+
+	// Emoji are matched by their canonical name so that aliases and skin tones
+	// count as the emoji they refer to. Reactji are stored without ":"
+	// delimiters.
+	emojiName := *emojiFlag
+	if emojiName != "" {
+		emojiName, _ = runCtx.Slack.CanonicalEmoji(emojiName)
+		if !*reactions && !*received {
+			emojiName = delimit(emojiName)
+		}
+	}
+
+	now := time.Now()
+
+	window, err := botcmd.ParseWindow(*sinceFlag, *untilFlag, now)
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	var channelID string
+
+	channelName := strings.TrimPrefix(*channelFlag, "#")
+	if channelName != "" {
+		if channelID = runCtx.Slack.ConversationID(channelName); channelID == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown channel %q", cmdName, channelName),
+			}, nil
+		}
+	}
+
 	var userID string
 
 	var username string
@@ -55,6 +103,38 @@ func (cmd emojiCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult
 		userID = runCtx.Slack.UserID(username)
 	}
 
+	desc := describeChannel(channelName) + window.String()
+
+	if *chartFlag && *emojiFlag != "" && !*received {
+		chartWindow := historyWindow(window, now)
+		if days := chartWindow.Until.Sub(chartWindow.Since).Hours() / 24; days > chart.MaxPoints {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: -chart can show at most %d days, try a later -since", cmdName, chart.MaxPoints),
+			}, nil
+		}
+
+		objects := "emoji"
+		if *reactions {
+			objects = "reactji"
+		}
+		title := fmt.Sprintf("%s's daily use of the %s %s%s%s",
+			username, delimit(*emojiFlag), objects, describeChannel(channelName), chartWindow)
+
+		return cmd.historyChart(runCtx, storage.GetEmojiHistoryOptions{
+			User:     userID,
+			Emoji:    emojiName,
+			Reaction: *reactions,
+			Channel:  channelID,
+		}, chartWindow, title)
+	}
+
+	if *chartFlag && (*limit <= 0 || *limit > chart.MaxBars) {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: -chart can show at most %d emoji, try a -limit from 1 to %d",
+				cmdName, chart.MaxBars, chart.MaxBars),
+		}, nil
+	}
+
 	opts := storage.GetEmojiOptions{
 		FindOptions: storage.FindOptions{
 			SortField: "count",
@@ -62,10 +142,14 @@ func (cmd emojiCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult
 			Asc:       *asc,
 		},
 		User:     userID,
-		Emoji:    *emojiFlag,
-		Reaction: *reactions,
+		Emoji:    emojiName,
+		Reaction: *reactions || *received,
+		Received: *received,
+		Since:    window.Since,
+		Until:    window.Until,
+		Channel:  channelID,
 	}
-	cmd.log.Infof("Getting emoji with options: %#v", opts)
+	runCtx.Logger(cmd.log).Infof("Getting emoji with options: %#v", opts)
 
 	emoji, err := runCtx.Storage.GetEmoji(opts)
 	if err != nil {
@@ -76,39 +160,76 @@ func (cmd emojiCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult
 
 	buf := new(bytes.Buffer)
 
-	if *emojiFlag != "" {
+	switch {
+	case *emojiFlag != "" && *received:
 		if len(emoji) == 0 {
-			fmt.Fprintf(buf, "%s has not been observed using emoji %q\n",
+			fmt.Fprintf(buf, "%s has not been observed receiving reactji %q\n",
 				username, *emojiFlag)
+		} else {
+			fmt.Fprintf(buf, "people have reacted :%s: to %s %d times\n",
+				emojiName, username, emoji[0].Count)
+		}
+	case *emojiFlag != "":
+		if len(emoji) == 0 {
+			fmt.Fprintf(buf, "%s has not been observed using emoji %q%s\n",
+				username, *emojiFlag, desc)
 		} else {
 			emojiMatch := emoji[0]
-			fmt.Fprintf(buf, "%s has used the %s emoji %d times\n",
-				username, *emojiFlag, emojiMatch.Count)
+			fmt.Fprintf(buf, "%s has used the %s emoji %d times%s\n",
+				username, *emojiFlag, emojiMatch.Count, desc)
 		}
-	} else {
+	default:
 		header := "Top"
 		if *asc {
 			header = "Rarest"
 		}
-		objects := "emoji"
+		objects, verb := "emoji", "used"
 		if *reactions {
 			objects = "reactji"
 		}
-		fmt.Fprintf(buf, ":upside_down_face: %s %d observed %s for *%s*:\n", header, len(emoji), objects, username)
+		if *received {
+			objects, verb = "received reactji", "received"
+		}
+		for i := range emoji {
+			emoji[i].Emoji = delimit(emoji[i].Emoji)
+		}
+		if *chartFlag && len(emoji) > 0 {
+			return barChart(fmt.Sprintf("%s %d observed %s for %s%s",
+				header, len(emoji), objects, username, desc), emoji)
+		}
+		fmt.Fprintf(buf, ":upside_down_face: %s %d observed %s for *%s*%s:\n",
+			header, len(emoji), objects, username, desc)
 		for _, e := range emoji {
-			if !strings.HasPrefix(e.Emoji, ":") {
-				e.Emoji = ":" + e.Emoji
-			}
-			if !strings.HasSuffix(e.Emoji, ":") {
-				e.Emoji += ":"
-			}
-			fmt.Fprintf(buf, "\t%s - used _%d times_.\n", e.Emoji, e.Count)
+			fmt.Fprintf(buf, "\t%s - %s _%d times_.\n", e.Emoji, verb, e.Count)
 		}
 	}
 
 	return botcmd.RunResult{Message: buf.String()}, nil
 }
 
+// delimit returns the emoji name with ":" delimiters, adding them if needed.
+func delimit(emoji string) string {
+	if !strings.HasPrefix(emoji, ":") {
+		emoji = ":" + emoji
+	}
+
+	if !strings.HasSuffix(emoji, ":") {
+		emoji += ":"
+	}
+
+	return emoji
+}
+
+// describeChannel returns a description of the channel filter for output, or
+// an empty string if there isn't one.
+func describeChannel(channelName string) string {
+	if channelName == "" {
+		return ""
+	}
+
+	return " in #" + channelName
+}
+
 func (cmd *emojiCmd) Configure(log *logrus.Logger, c *config.Config) error {
 	cmd.log = log
 	return nil
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/slack"
@@ -286,6 +287,7 @@ func TestRunEmoji(t *testing.T) {
 	}
 	mockStorage.EXPECT().GetEmoji(expectOpts).Return(emojis, nil)
 	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockClient.EXPECT().CanonicalEmoji(":test:").Return("test", true)
 
 	expectedMessage := "Gorfbot has used the :test: emoji 99 times\n"
 
@@ -320,6 +322,7 @@ func TestRunEmojiNoResult(t *testing.T) {
 
 	mockStorage.EXPECT().GetEmoji(expectOpts).Return(nil, nil)
 	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockClient.EXPECT().CanonicalEmoji(":test:").Return("test", true)
 
 	expectedMessage := "Gorfbot has not been observed using emoji \":test:\"\n"
 
@@ -330,6 +333,357 @@ func TestRunEmojiNoResult(t *testing.T) {
 	}
 }
 
+func TestRunWindow(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = fakeUserIDA
+
+	expectOpts := storage.GetEmojiOptions{
+		FindOptions: storage.FindOptions{
+			SortField: "count",
+			Limit:     5,
+		},
+		User:    ctx.Message.UserID,
+		Since:   time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Channel: "C001",
+	}
+
+	emojis := makeEmoji(ctx.Message.UserID, 1)
+	mockClient.EXPECT().ConversationID("general").Return("C001")
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockStorage.EXPECT().GetEmoji(expectOpts).Return(emojis, nil)
+
+	expectedMessage := `:upside_down_face: Top 1 observed emoji for *Gorfbot* in #general since 2021-03-01 until 2021-04-01:
+	:fake: - used _10 times_.
+`
+
+	if res, err := cmd.Run("-since 2021-03-01 -until 2021-04-01 -channel #general", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+}
+
+func TestRunWindowErrs(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Slack = mockClient
+
+	mockClient.EXPECT().ConversationID("nowhere").Return("")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input: "-since whenever",
+			expected: `emoji: -since can't parse "whenever" as a time: ` +
+				`use an age like "7d", "2w" or "36h", or a date like "2006-01-02"`,
+		},
+		{
+			input: "-until whenever",
+			expected: `emoji: -until can't parse "whenever" as a time: ` +
+				`use an age like "7d", "2w" or "36h", or a date like "2006-01-02"`,
+		},
+		{
+			input:    "-channel nowhere",
+			expected: `emoji: unknown channel "nowhere"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if res, err := cmd.Run(tc.input, ctx); err != nil {
+				t.Errorf("unexpected err from Run: %v", err)
+			} else if res.Message != tc.expected {
+				t.Errorf("expected result Message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunReceived(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = fakeUserIDA
+
+	expectOpts := storage.GetEmojiOptions{
+		FindOptions: storage.FindOptions{
+			SortField: "count",
+			Limit:     5,
+		},
+		User:     ctx.Message.UserID,
+		Reaction: true,
+		Received: true,
+	}
+
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot").Times(2)
+	mockStorage.EXPECT().GetEmoji(expectOpts).Return([]models.Emoji{
+		{User: ctx.Message.UserID, Emoji: "joy", Count: 412, Reaction: true, Received: true},
+	}, nil)
+
+	expectedMessage := `:upside_down_face: Top 1 observed received reactji for *Gorfbot*:
+	:joy: - received _412 times_.
+`
+
+	if res, err := cmd.Run("-received", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+
+	// The emoji is looked up without delimiters.
+	expectOpts.Emoji = "joy"
+	mockClient.EXPECT().CanonicalEmoji(":joy:").Return("joy", true)
+	mockStorage.EXPECT().GetEmoji(expectOpts).Return([]models.Emoji{
+		{User: ctx.Message.UserID, Emoji: "joy", Count: 412, Reaction: true, Received: true},
+	}, nil)
+
+	expectedMessage = "people have reacted :joy: to Gorfbot 412 times\n"
+
+	if res, err := cmd.Run("-received -emoji :joy:", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+
+	expectedMessage = "emoji: -received can't be combined with -since, -until or -channel"
+
+	if res, err := cmd.Run("-received -since 7d", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+}
+
+func TestRunChart(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = fakeUserIDA
+
+	expectOpts := storage.GetEmojiOptions{
+		FindOptions: storage.FindOptions{
+			SortField: "count",
+			Limit:     5,
+		},
+		User:     ctx.Message.UserID,
+		Reaction: true,
+	}
+
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockStorage.EXPECT().GetEmoji(expectOpts).Return([]models.Emoji{
+		{User: ctx.Message.UserID, Emoji: "joy", Count: 2, Reaction: true},
+	}, nil)
+
+	res, err := cmd.Run("-reactions -chart", ctx)
+	if err != nil {
+		t.Fatalf("unexpected err from Run: %v", err)
+	}
+
+	if res.Message != "" {
+		t.Errorf("expected no result Message, got %q", res.Message)
+	}
+
+	expectedTitle := "Top 1 observed reactji for Gorfbot"
+	if res.File == nil {
+		t.Fatalf("expected result File, got nil")
+	} else if res.File.Name != chartFileName || res.File.Title != expectedTitle || len(res.File.Content) == 0 {
+		t.Errorf("expected %q file titled %q with content, got %v", chartFileName, expectedTitle, res.File)
+	}
+}
+
+func TestRunChartTooBig(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The mock storage fails the test if a chart too big to render is queried.
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mocks.NewMockStorage(ctrl)
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = fakeUserIDA
+
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot").AnyTimes()
+	mockClient.EXPECT().CanonicalEmoji(":wave:").Return("wave", true).AnyTimes()
+
+	testCases := map[string]string{
+		"-chart -limit 0":                        "emoji: -chart can show at most 25 emoji, try a -limit from 1 to 25",
+		"-chart -limit 26":                       "emoji: -chart can show at most 25 emoji, try a -limit from 1 to 25",
+		"-chart -emoji :wave: -since 2000-01-01": "emoji: -chart can show at most 366 days, try a later -since",
+	}
+
+	for text, expected := range testCases {
+		res, err := cmd.Run(text, ctx)
+		if err != nil {
+			t.Fatalf("unexpected err from Run(%q): %v", text, err)
+		}
+
+		if res.Message != expected || res.File != nil {
+			t.Errorf("expected message %q from Run(%q) and no file, got %q %v", expected, text, res.Message, res.File)
+		}
+	}
+}
+
+func TestRunChartHistory(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = fakeUserIDA
+
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	expectOpts := storage.GetEmojiHistoryOptions{
+		User:  ctx.Message.UserID,
+		Emoji: ":wave:",
+		Since: march1,
+		Until: march1.AddDate(0, 0, 7),
+	}
+
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockClient.EXPECT().CanonicalEmoji(":wave:").Return("wave", true).Times(2)
+	mockStorage.EXPECT().GetEmojiHistory(expectOpts).Return([]models.EmojiUsage{
+		{Day: march1.AddDate(0, 0, 2), Count: 3},
+	}, nil)
+
+	res, err := cmd.Run("-emoji :wave: -since 2021-03-01 -until 2021-03-08 -chart", ctx)
+	if err != nil {
+		t.Fatalf("unexpected err from Run: %v", err)
+	}
+
+	expectedTitle := "Gorfbot's daily use of the :wave: emoji since 2021-03-01 until 2021-03-08"
+	if res.File == nil {
+		t.Fatalf("expected result File, got nil")
+	} else if res.File.Title != expectedTitle || len(res.File.Content) == 0 {
+		t.Errorf("expected file titled %q with content, got %v", expectedTitle, res.File)
+	}
+
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+	mockStorage.EXPECT().GetEmojiHistory(gomock.Any()).Return(nil, errors.New("data is dead"))
+
+	if _, err := cmd.Run("-emoji :wave: -chart", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	}
+}
+
+func TestRunUnused(t *testing.T) {
+	cmd, ctx := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	customEmoji := map[string]string{
+		"gorf":     "https://example.com/gorf.png",
+		"gorfing":  "alias:gorf",
+		"frog":     "https://example.com/frog.png",
+		"bleh":     "https://example.com/bleh.png",
+		"party-ok": "https://example.com/party-ok.png",
+	}
+
+	mockClient.EXPECT().CustomEmoji().Return(customEmoji)
+	mockStorage.EXPECT().GetLeaderboard(storage.GetLeaderboardOptions{}).Return([]models.LeaderboardEntry{
+		{Key: ":gorf:", Count: 3},
+		{Key: ":wave:", Count: 2},
+		{Key: ":bleh:", Count: 0},
+	}, nil)
+	mockStorage.EXPECT().GetLeaderboard(storage.GetLeaderboardOptions{Reaction: true}).Return([]models.LeaderboardEntry{
+		{Key: "frog", Count: 1},
+	}, nil)
+
+	// The alias and the emoji used in messages or reactji aren't unused.
+	expectedMessage := ":upside_down_face: 2 custom emoji nobody has used yet:\n:bleh: :party-ok:\n"
+
+	if res, err := cmd.Run("-unused", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+
+	mockClient.EXPECT().CustomEmoji().Return(map[string]string{"gorf": "https://example.com/gorf.png"})
+	mockStorage.EXPECT().GetLeaderboard(storage.GetLeaderboardOptions{}).Return([]models.LeaderboardEntry{
+		{Key: ":gorf:", Count: 3},
+	}, nil)
+	mockStorage.EXPECT().GetLeaderboard(storage.GetLeaderboardOptions{Reaction: true}).Return(nil, nil)
+
+	expectedMessage = ":tada: Every custom emoji has been used at least once\n"
+
+	if res, err := cmd.Run("-unused", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expectedMessage {
+		t.Errorf("expected result Message %q, got %q", expectedMessage, res.Message)
+	}
+
+	mockStorage.EXPECT().GetLeaderboard(storage.GetLeaderboardOptions{}).Return(nil, errors.New("data is dead"))
+
+	if _, err := cmd.Run("-unused", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	}
+}
+
+func TestHistoryData(t *testing.T) {
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := march1.AddDate(0, 0, chartHistoryDays).Add(12 * time.Hour)
+
+	// Without bounds the window covers the chartHistoryDays before now and
+	// today.
+	window := historyWindow(botcmd.Window{}, now)
+	history := []models.EmojiUsage{{Day: march1.AddDate(0, 0, 1), Count: 4}}
+
+	data := historyData(history, window)
+	if len(data) != chartHistoryDays+1 {
+		t.Fatalf("expected %d days of data, got %d", chartHistoryDays+1, len(data))
+	}
+
+	if data[0].Label != "03-01" || data[0].Value != 0 {
+		t.Errorf("expected first day 03-01 with no usage, got %v", data[0])
+	}
+
+	if data[1].Label != "03-02" || data[1].Value != 4 {
+		t.Errorf("expected second day 03-02 with usage 4, got %v", data[1])
+	}
+}
+
 func TestConfigure(t *testing.T) {
 	log, _ := logtest.NewNullLogger()
 	cmd := &emojiCmd{}
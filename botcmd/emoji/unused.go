@@ -0,0 +1,71 @@
+package emoji
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/storage"
+)
+
+// aliasPrefix prefixes the value of custom emoji that are aliases of other
+// emoji.
+const aliasPrefix = "alias:"
+
+// usedEmoji returns the set of emoji names (no ":" delimiters) that have been
+// used at least once in messages or as reactji.
+func usedEmoji(runCtx botcmd.RunContext) (map[string]bool, error) {
+	used := make(map[string]bool)
+
+	for _, reaction := range []bool{false, true} {
+		opts := storage.GetLeaderboardOptions{Reaction: reaction}
+
+		entries, err := runCtx.Storage.GetLeaderboard(opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to get leaderboard from storage opts: %v err: %w",
+				cmdName, opts, err)
+		}
+
+		for _, e := range entries {
+			if e.Count > 0 {
+				used[strings.Trim(e.Key, ":")] = true
+			}
+		}
+	}
+
+	return used, nil
+}
+
+// unusedReport lists the workspace custom emoji that nobody has ever used in a
+// message or as a reactji. Aliases are skipped since usage is counted for the
+// emoji they refer to.
+func (cmd emojiCmd) unusedReport(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	used, err := usedEmoji(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	var unused []string
+
+	for name, value := range runCtx.Slack.CustomEmoji() {
+		if !strings.HasPrefix(value, aliasPrefix) && !used[name] {
+			unused = append(unused, ":"+name+":")
+		}
+	}
+
+	sort.Strings(unused)
+
+	runCtx.Logger(cmd.log).Infof("Found %d unused custom emoji", len(unused))
+
+	if len(unused) == 0 {
+		return botcmd.RunResult{
+			Message: ":tada: Every custom emoji has been used at least once\n",
+		}, nil
+	}
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":upside_down_face: %d custom emoji nobody has used yet:\n%s\n",
+			len(unused), strings.Join(unused, " ")),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package emojiannounce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	handlerName = "new emoji"
+)
+
+type emojiAnnounceHandler struct {
+	log *logrus.Logger
+	// channel is the name of the channel emoji changes are announced in. The
+	// handler does nothing if it is empty.
+	channel string
+}
+
+func init() {
+	botcmd.MustAddEmojiChangeHandler(&botcmd.EmojiChangeCommand{
+		Name:    handlerName,
+		Handler: &emojiAnnounceHandler{},
+	})
+}
+
+type errUnknownChannel struct {
+	name string
+}
+
+func (e errUnknownChannel) Error() string {
+	return fmt.Sprintf("%s handler error: unknown channel %q", handlerName, e.name)
+}
+
+func (h emojiAnnounceHandler) Run(change *slack.EmojiChange, runCtx botcmd.RunContext) error {
+	if h.channel == "" || len(change.Names) == 0 {
+		return nil
+	}
+
+	channelID := runCtx.Slack.ConversationID(h.channel)
+	if channelID == "" {
+		return errUnknownChannel{h.channel}
+	}
+
+	runCtx.Logger(h.log).Infof("%s - announcing emoji change %#v", handlerName, *change)
+
+	runCtx.Slack.SendMessage(formatAnnouncement(change), channelID)
+
+	return nil
+}
+
+// formatAnnouncement returns the announcement message for an emoji change.
+// Removed emoji can't be displayed so their names are quoted instead.
+func formatAnnouncement(change *slack.EmojiChange) string {
+	if change.Removed {
+		names := make([]string, 0, len(change.Names))
+		for _, name := range change.Names {
+			names = append(names, fmt.Sprintf("`:%s:`", name))
+		}
+
+		return fmt.Sprintf(":wave: Farewell custom emoji %s", strings.Join(names, ", "))
+	}
+
+	name := change.Names[0]
+
+	if alias := change.Alias(); alias != "" {
+		return fmt.Sprintf(":new: New alias `:%s:` for :%s:", name, alias)
+	}
+
+	return fmt.Sprintf(":new: New custom emoji :%s: (`:%s:`)", name, name)
+}
+
+func (h *emojiAnnounceHandler) Configure(log *logrus.Logger, c *config.Config) error {
+	h.log = log
+
+	if c == nil {
+		return nil
+	}
+
+	h.channel = strings.TrimPrefix(c.EmojiAnnounceConf.Channel, "#")
+
+	return nil
+}
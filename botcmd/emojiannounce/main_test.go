@@ -0,0 +1,117 @@
+package emojiannounce
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T, channel string) (*emojiAnnounceHandler, botcmd.RunContext, *slack_mocks.MockClient) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &emojiAnnounceHandler{}
+
+	err := cmd.Configure(log, &config.Config{
+		EmojiAnnounceConf: config.EmojiAnnounceConfig{
+			Channel: channel,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Slack: mockClient,
+	}
+
+	return cmd, ctx, mockClient
+}
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &emojiAnnounceHandler{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Errorf("unexpected err from Configure with nil config: %v", err)
+	}
+
+	if cmd.channel != "" {
+		t.Errorf("expected no channel with nil config, got %q", cmd.channel)
+	}
+
+	cmd, _, _ = setup(t, "#emoji")
+
+	if cmd.channel != "emoji" {
+		t.Errorf("expected channel %q, got %q", "emoji", cmd.channel)
+	}
+}
+
+func TestRunDisabled(t *testing.T) {
+	// No calls are expected to the mock client.
+	cmd, ctx, _ := setup(t, "")
+
+	if err := cmd.Run(&slack.EmojiChange{Names: []string{"gorf"}}, ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		change   slack.EmojiChange
+		expected string
+	}{
+		{
+			name:     "added emoji",
+			change:   slack.EmojiChange{Names: []string{"gorf"}, Value: "https://example.com/gorf.png"},
+			expected: ":new: New custom emoji :gorf: (`:gorf:`)",
+		},
+		{
+			name:     "added alias",
+			change:   slack.EmojiChange{Names: []string{"gorfing"}, Value: "alias:gorf"},
+			expected: ":new: New alias `:gorfing:` for :gorf:",
+		},
+		{
+			name:     "removed emoji",
+			change:   slack.EmojiChange{Names: []string{"gorf", "gorfing"}, Removed: true},
+			expected: ":wave: Farewell custom emoji `:gorf:`, `:gorfing:`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockClient := setup(t, "emoji")
+
+			mockClient.EXPECT().ConversationID("emoji").Return("C001")
+			mockClient.EXPECT().SendMessage(tc.expected, "C001")
+
+			if err := cmd.Run(&tc.change, ctx); err != nil {
+				t.Errorf("unexpected err from Run: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunUnknownChannel(t *testing.T) {
+	cmd, ctx, mockClient := setup(t, "emoji")
+
+	mockClient.EXPECT().ConversationID("emoji").Return("")
+
+	err := cmd.Run(&slack.EmojiChange{Names: []string{"gorf"}}, ctx)
+
+	var unknownErr errUnknownChannel
+	if !errors.As(err, &unknownErr) {
+		t.Errorf("expected errUnknownChannel from Run, got %v", err)
+	}
+}
@@ -120,7 +120,7 @@ func (cmd frogtipCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResu
 	}
 
 	elapsed := time.Since(start)
-	cmd.log.Infof("%s fetched %d tips in %s", cmdName, len(tipResults.Tips), elapsed)
+	runCtx.Logger(cmd.log).Infof("%s fetched %d tips in %s", cmdName, len(tipResults.Tips), elapsed)
 
 	if len(tipResults.Tips) == 0 {
 		return botcmd.RunResult{}, errNoTips
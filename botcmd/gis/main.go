@@ -291,7 +291,8 @@ func (cmd gisCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult,
 		Site:       site,
 	}
 
-	cmd.log.Infof("% searching with options %#v", cmdName, opts)
+	log := runCtx.Logger(cmd.log)
+	log.Infof("%s searching with options %#v", cmdName, opts)
 
 	results, err := cmd.api.ImageSearch(cmd.config, opts)
 	if err != nil {
@@ -300,7 +301,7 @@ func (cmd gisCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult,
 
 	numResults := int64(len(results))
 
-	cmd.log.Infof("%s got %d search results", cmdName, numResults)
+	log.Infof("%s got %d search results", cmdName, numResults)
 
 	if numResults < 1 {
 		return botcmd.RunResult{
@@ -314,9 +315,9 @@ func (cmd gisCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult,
 	}
 
 	if *randomFlag {
-		cmd.log.Tracef("%s results pre-random: %#v\n", cmdName, results)
+		log.Tracef("%s results pre-random: %#v\n", cmdName, results)
 		rand.Shuffle(len(results), func(i, j int) { results[i], results[j] = results[j], results[i] })
-		cmd.log.Tracef("%s post random: %#v\n", cmdName, results)
+		log.Tracef("%s post random: %#v\n", cmdName, results)
 	}
 
 	buf := new(bytes.Buffer)
@@ -0,0 +1,220 @@
+// Package gorfsay provides a command that generates text that sounds like a
+// user or a channel, from Markov models learned by the gorfsaylearn package.
+package gorfsay
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "gorfsay"
+
+	// maxWords is the maximum number of words generated.
+	maxWords = 50
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*gorfsay*:\n" +
+		"\t`!gorfsay` - say something that sounds like this channel\n" +
+		"\t`!gorfsay @user` - say something that sounds like a user\n" +
+		"\t`!gorfsay optout` - forget how you talk and stop learning from your messages\n" +
+		"\t`!gorfsay optin` - start learning from your messages again"
+)
+
+// mentionRegexp matches a Slack user mention like "<@U1234>" or
+// "<@U1234|daniel>", capturing the user ID.
+var mentionRegexp = regexp.MustCompile(`^<@(\w+)(?:\|[^>]*)?>$`)
+
+type gorfsayCmd struct {
+	log   *logrus.Logger
+	order int
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":frog:",
+		Description: "Say something that sounds like a user or this channel",
+		Handler:     &gorfsayCmd{},
+	})
+}
+
+func (cmd *gorfsayCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	cmd.order = models.DefaultMarkovOrder
+
+	if c != nil {
+		if c.GorfsayConf.Order > 0 {
+			cmd.order = c.GorfsayConf.Order
+		}
+
+		if c.GorfsayConf.RandomSeed > 0 {
+			rand.Seed(c.GorfsayConf.RandomSeed)
+		} else {
+			rand.Seed(time.Now().UnixNano())
+		}
+	}
+
+	return nil
+}
+
+func (cmd gorfsayCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	msg := runCtx.Message
+	if msg == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+
+	switch {
+	case len(words) == 0:
+		return cmd.say(models.MarkovChannelModel(msg.ChannelID),
+			"*#"+runCtx.Slack.ConversationName(msg.ChannelID)+"*", runCtx)
+	case len(words) > 1:
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "optout":
+		return cmd.setOptOut(true, runCtx)
+	case "optin":
+		return cmd.setOptOut(false, runCtx)
+	case "-h", "help":
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	userID := userID(words[0], runCtx.Slack)
+	if userID == "" {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: unknown user %q", cmdName, words[0]),
+		}, nil
+	}
+
+	name := runCtx.Slack.UserName(userID)
+
+	optOut, err := runCtx.Storage.GetMarkovOptOut(userID)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get opt out: %w", cmdName, err)
+	} else if optOut {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":no_entry: _%s_ opted out of `!gorfsay`", name),
+		}, nil
+	}
+
+	return cmd.say(models.MarkovUserModel(userID), "_"+name+"_", runCtx)
+}
+
+// userID returns the ID of the user given as a mention, a name or a name with
+// an "@" prefix. It returns "" for unknown users.
+func userID(user string, client slack.Client) string {
+	if m := mentionRegexp.FindStringSubmatch(user); m != nil {
+		return m[1]
+	}
+
+	return client.UserID(strings.TrimPrefix(user, "@"))
+}
+
+// say generates text from the named model and returns it as the message. The
+// who argument describes the model in messages.
+func (cmd gorfsayCmd) say(model, who string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	text, err := cmd.generate(model, runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	if text == "" {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":shrug: I don't know how %s talks yet", who),
+		}, nil
+	}
+
+	return botcmd.RunResult{Message: fmt.Sprintf(":frog: %s: %s", who, text)}, nil
+}
+
+// generate returns text generated by walking the transitions of the named
+// model from the start of a message until the end of a message or maxWords.
+func (cmd gorfsayCmd) generate(model string, runCtx botcmd.RunContext) (string, error) {
+	var words []string
+
+	prefix := make([]string, cmd.order)
+
+	for len(words) < maxWords {
+		transitions, err := runCtx.Storage.GetMarkovTransitions(model, prefix)
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to get transitions for %q: %w", cmdName, model, err)
+		}
+
+		next := choose(transitions)
+		if next == "" {
+			break
+		}
+
+		words = append(words, next)
+		prefix = append(append([]string(nil), prefix[1:]...), next)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// choose returns the next word of a random transition, weighted by the
+// transition counts. It returns "" if there are no transitions.
+func choose(transitions []models.MarkovTransition) string {
+	var total int
+
+	for _, t := range transitions {
+		total += t.Count
+	}
+
+	if total <= 0 {
+		return ""
+	}
+
+	n := rand.Intn(total) //nolint:gosec
+
+	for _, t := range transitions {
+		if n < t.Count {
+			return t.Next
+		}
+
+		n -= t.Count
+	}
+
+	return ""
+}
+
+// setOptOut opts the user that sent the message out of (or back in to) having
+// their messages learned. Opting out also forgets how they talk.
+func (cmd gorfsayCmd) setOptOut(optOut bool, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	userID := runCtx.Message.UserID
+	name := runCtx.Slack.UserName(userID)
+
+	if err := runCtx.Storage.SetMarkovOptOut(userID, optOut); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to set opt out: %w", cmdName, err)
+	}
+
+	runCtx.Logger(cmd.log).Infof("%s: set opt out %v for %q", cmdName, optOut, userID)
+
+	if !optOut {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":ear: Okay _%s_, I'll learn from your messages again", name),
+		}, nil
+	}
+
+	if err := runCtx.Storage.DeleteMarkovModel(models.MarkovUserModel(userID)); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to delete user model: %w", cmdName, err)
+	}
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":see_no_evil: Okay _%s_, I've forgotten how you talk and won't learn from "+
+			"your messages. Channels still sound a bit like you until they move on", name),
+	}, nil
+}
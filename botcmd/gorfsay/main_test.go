@@ -0,0 +1,205 @@
+//nolint:goerr113
+package gorfsay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*gorfsayCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &gorfsayCmd{}
+
+	if err := cmd.Configure(log, &config.Config{
+		GorfsayConf: config.GorfsayConfig{Order: 1, RandomSeed: 1},
+	}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001", Text: "!gorfsay"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+	mockClient.EXPECT().UserName("U002").Return("gorf").AnyTimes()
+	mockClient.EXPECT().UserID("gorf").Return("U002").AnyTimes()
+	mockClient.EXPECT().UserID("nobody").Return("").AnyTimes()
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+// expectChain expects the transitions of the named model to be looked up for
+// a single chain of words.
+func expectChain(mockStorage *mocks.MockStorage, model string, words ...string) {
+	prefix := ""
+
+	for _, next := range append(words, "") {
+		mockStorage.EXPECT().GetMarkovTransitions(model, []string{prefix}).
+			Return([]models.MarkovTransition{
+				{Model: model, Prefix: []string{prefix}, Next: next, Count: 1},
+			}, nil)
+
+		prefix = next
+	}
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd, ctx, _, _ := setup(t)
+	ctx.Message = nil
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	mockStorage.EXPECT().GetMarkovTransitions("channel:C001", []string{""}).
+		Return(nil, errors.New("data is dead"))
+
+	expectedErr := `gorfsay: failed to get transitions for "channel:C001": data is dead`
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expect   func(*mocks.MockStorage)
+		expected string
+	}{
+		{
+			name:     "usage",
+			text:     "help",
+			expected: usage,
+		},
+		{
+			name:     "too many args",
+			text:     "gorf gorf",
+			expected: usage,
+		},
+		{
+			name: "channel",
+			expect: func(mockStorage *mocks.MockStorage) {
+				expectChain(mockStorage, "channel:C001", "frogs", "are", "great")
+			},
+			expected: ":frog: *#general*: frogs are great",
+		},
+		{
+			name: "channel unknown",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().GetMarkovTransitions("channel:C001", []string{""}).Return(nil, nil)
+			},
+			expected: ":shrug: I don't know how *#general* talks yet",
+		},
+		{
+			name: "user by name",
+			text: "@gorf",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().GetMarkovOptOut("U002").Return(false, nil)
+				expectChain(mockStorage, "user:U002", "ribbit")
+			},
+			expected: ":frog: _gorf_: ribbit",
+		},
+		{
+			name: "user by mention",
+			text: "<@U002|gorf>",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().GetMarkovOptOut("U002").Return(false, nil)
+				expectChain(mockStorage, "user:U002", "ribbit")
+			},
+			expected: ":frog: _gorf_: ribbit",
+		},
+		{
+			name:     "unknown user",
+			text:     "nobody",
+			expected: `gorfsay: unknown user "nobody"`,
+		},
+		{
+			name: "opted out user",
+			text: "gorf",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().GetMarkovOptOut("U002").Return(true, nil)
+			},
+			expected: ":no_entry: _gorf_ opted out of `!gorfsay`",
+		},
+		{
+			name: "opt out",
+			text: "optout",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().SetMarkovOptOut("U001", true).Return(nil)
+				mockStorage.EXPECT().DeleteMarkovModel("user:U001").Return(nil)
+			},
+			expected: ":see_no_evil: Okay _daniel_, I've forgotten how you talk and won't learn from " +
+				"your messages. Channels still sound a bit like you until they move on",
+		},
+		{
+			name: "opt in",
+			text: "OptIn",
+			expect: func(mockStorage *mocks.MockStorage) {
+				mockStorage.EXPECT().SetMarkovOptOut("U001", false).Return(nil)
+			},
+			expected: ":ear: Okay _daniel_, I'll learn from your messages again",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, _ := setup(t)
+
+			if tc.expect != nil {
+				tc.expect(mockStorage)
+			}
+
+			res, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	if next := choose(nil); next != "" {
+		t.Errorf("expected no next word without transitions, got %q", next)
+	}
+
+	transitions := []models.MarkovTransition{
+		{Next: "never", Count: 0},
+		{Next: "always", Count: 3},
+	}
+
+	for i := 0; i < 10; i++ {
+		if next := choose(transitions); next != "always" {
+			t.Errorf("expected next word %q, got %q", "always", next)
+		}
+	}
+}
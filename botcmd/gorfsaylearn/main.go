@@ -0,0 +1,110 @@
+// Package gorfsaylearn provides a pattern handler that learns how people talk
+// from their messages in opted in channels, for the gorfsay command.
+package gorfsaylearn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	patternName = "gorfsay learning"
+	// learnRegexp matches any message that doesn't start with the "!" command
+	// prefix.
+	learnRegexp = `^\s*[^!\s]`
+)
+
+// markupRegexp matches Slack markup like user mentions, channel links and
+// URLs. They are never learned.
+var markupRegexp = regexp.MustCompile(`<[^>]*>`)
+
+type learnPattern struct {
+	log *logrus.Logger
+	// channels is the set of channel names messages are learned from.
+	channels map[string]bool
+	order    int
+}
+
+func init() {
+	botcmd.MustAddPattern(&botcmd.PatternCommand{
+		Name:    patternName,
+		Handler: &learnPattern{},
+		Pattern: regexp.MustCompile(learnRegexp),
+	})
+}
+
+// words returns the words of a message to learn, without Slack markup.
+func words(text string) []string {
+	return strings.Fields(markupRegexp.ReplaceAllString(text, " "))
+}
+
+func (p learnPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	msg := runCtx.Message
+	if msg == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error: %w", patternName, botcmd.ErrNilMessage)
+	}
+
+	if len(p.channels) == 0 || !p.channels[runCtx.Slack.ConversationName(msg.ChannelID)] {
+		return botcmd.RunResult{}, nil
+	}
+
+	// Don't learn from the bot or commands addressed to it.
+	botID := runCtx.Slack.BotID()
+	if msg.UserID == "" || msg.UserID == botID ||
+		strings.HasPrefix(strings.TrimSpace(msg.Text), fmt.Sprintf("<@%s>", botID)) {
+		return botcmd.RunResult{}, nil
+	}
+
+	optOut, err := runCtx.Storage.GetMarkovOptOut(msg.UserID)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error getting opt out: %w", patternName, err)
+	} else if optOut {
+		return botcmd.RunResult{}, nil
+	}
+
+	words := words(msg.Text)
+	if len(words) == 0 {
+		return botcmd.RunResult{}, nil
+	}
+
+	transitions := append(
+		models.MarkovTransitions(models.MarkovUserModel(msg.UserID), words, p.order),
+		models.MarkovTransitions(models.MarkovChannelModel(msg.ChannelID), words, p.order)...)
+
+	if err := runCtx.Storage.AddMarkovTransitions(transitions); err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error storing transitions: %w", patternName, err)
+	}
+
+	runCtx.Logger(p.log).Debugf("%s learned %d words", patternName, len(words))
+
+	return botcmd.RunResult{}, nil
+}
+
+func (p *learnPattern) Configure(log *logrus.Logger, c *config.Config) error {
+	p.log = log
+	p.channels = make(map[string]bool)
+	p.order = models.DefaultMarkovOrder
+
+	if c == nil {
+		return nil
+	}
+
+	for _, channel := range c.GorfsayConf.Channels {
+		p.channels[strings.TrimPrefix(channel, "#")] = true
+	}
+
+	if c.GorfsayConf.Order > 0 {
+		p.order = c.GorfsayConf.Order
+	}
+
+	return nil
+}
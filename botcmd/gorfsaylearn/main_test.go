@@ -0,0 +1,153 @@
+//nolint:goerr113
+package gorfsaylearn
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*learnPattern, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	p := &learnPattern{}
+
+	if err := p.Configure(log, &config.Config{
+		GorfsayConf: config.GorfsayConfig{Channels: []string{"#general"}, Order: 1},
+	}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001", Text: "gorf <@U002> gorf"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+	mockClient.EXPECT().ConversationName("C002").Return("random").AnyTimes()
+	mockClient.EXPECT().BotID().Return("U999").AnyTimes()
+
+	return p, ctx, mockStorage, mockClient
+}
+
+func TestPattern(t *testing.T) {
+	pattern := regexp.MustCompile(learnRegexp)
+
+	testCases := map[string]bool{
+		"hello frogs":    true,
+		"  hello frogs":  true,
+		"!gorfsay":       false,
+		"  !gorfsay":     false,
+		"":               false,
+		"wow! so frogs ": true,
+	}
+
+	for text, expected := range testCases {
+		if matched := pattern.MatchString(text); matched != expected {
+			t.Errorf("expected match %v for %q, got %v", expected, text, matched)
+		}
+	}
+}
+
+func TestRunNilMessage(t *testing.T) {
+	p, ctx, _, _ := setup(t)
+	ctx.Message = nil
+
+	if _, err := p.Run(nil, ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	testCases := []struct {
+		name string
+		msg  slack.Message
+	}{
+		{name: "other channel", msg: slack.Message{ChannelID: "C002", UserID: "U001", Text: "gorf"}},
+		{name: "bot message", msg: slack.Message{ChannelID: "C001", UserID: "U999", Text: "gorf"}},
+		{name: "bot command", msg: slack.Message{ChannelID: "C001", UserID: "U001", Text: "<@U999> gorf"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p, ctx, _, _ := setup(t)
+			ctx.Message = &tc.msg
+
+			if _, err := p.Run(nil, ctx); err != nil {
+				t.Errorf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunOptedOut(t *testing.T) {
+	p, ctx, mockStorage, _ := setup(t)
+
+	mockStorage.EXPECT().GetMarkovOptOut("U001").Return(true, nil)
+
+	if _, err := p.Run(nil, ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	p, ctx, mockStorage, _ := setup(t)
+
+	mockStorage.EXPECT().GetMarkovOptOut("U001").Return(false, nil)
+	mockStorage.EXPECT().AddMarkovTransitions(gomock.Any()).Return(errors.New("data is dead"))
+
+	expectedErr := "gorfsay learning pattern error storing transitions: data is dead"
+
+	if _, err := p.Run(nil, ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	p, ctx, mockStorage, _ := setup(t)
+
+	var stored []models.MarkovTransition
+
+	mockStorage.EXPECT().GetMarkovOptOut("U001").Return(false, nil)
+	mockStorage.EXPECT().AddMarkovTransitions(gomock.Any()).
+		DoAndReturn(func(transitions []models.MarkovTransition) error {
+			stored = transitions
+
+			return nil
+		})
+
+	if _, err := p.Run(nil, ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var expected []models.MarkovTransition
+
+	for _, model := range []string{"user:U001", "channel:C001"} {
+		expected = append(expected,
+			models.MarkovTransition{Model: model, Prefix: []string{""}, Next: "gorf", Count: 1},
+			models.MarkovTransition{Model: model, Prefix: []string{"gorf"}, Next: "gorf", Count: 1},
+			models.MarkovTransition{Model: model, Prefix: []string{"gorf"}, Next: "", Count: 1})
+	}
+
+	if !reflect.DeepEqual(stored, expected) {
+		t.Errorf("expected transitions %v, got %v", expected, stored)
+	}
+}
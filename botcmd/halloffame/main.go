@@ -0,0 +1,111 @@
+package halloffame
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "halloffame"
+)
+
+type hallOfFameCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":star2:",
+		Description: "List the most reacted messages",
+		Handler:     &hallOfFameCmd{},
+	})
+}
+
+func (cmd hallOfFameCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	flagSet := flag.NewFlagSet(cmdName, flag.ContinueOnError)
+	limit := flagSet.Int64("limit", 5, "limit for number of messages to display")
+	channelFlag := flagSet.String("channel", "", "only list messages in a channel (e.g. general)")
+	usernameFlag := flagSet.String("user", "", "only list messages by a user")
+
+	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
+		return botcmd.RunResult{Message: respText}, nil
+	}
+
+	opts := storage.GetReactedMessageOptions{
+		FindOptions: storage.FindOptions{
+			SortField: "count",
+			Limit:     *limit,
+		},
+	}
+
+	var desc string
+
+	if channelName := strings.TrimPrefix(*channelFlag, "#"); channelName != "" {
+		if opts.Channel = runCtx.Slack.ConversationID(channelName); opts.Channel == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown channel %q", cmdName, channelName),
+			}, nil
+		}
+
+		desc += " in #" + channelName
+	}
+
+	if username := strings.TrimPrefix(*usernameFlag, "@"); username != "" {
+		if opts.User = runCtx.Slack.UserID(username); opts.User == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown user %q", cmdName, username),
+			}, nil
+		}
+
+		desc += " by *" + username + "*"
+	}
+
+	runCtx.Logger(cmd.log).Infof("Getting reacted messages with options: %#v", opts)
+
+	msgs, err := runCtx.Storage.GetReactedMessages(opts)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s: failed to get reacted messages from storage opts: %v err: %w",
+				cmdName, opts, err)
+	}
+
+	// Messages that had all of their reactions removed aren't famous.
+	famous := msgs[:0]
+
+	for _, msg := range msgs {
+		if msg.Count > 0 {
+			famous = append(famous, msg)
+		}
+	}
+
+	msgs = famous
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, ":star2: Hall of fame: top %d most reacted messages%s:\n", len(msgs), desc)
+
+	for i, msg := range msgs {
+		fmt.Fprintf(buf, "\t%d. *%s* in #%s", i+1,
+			runCtx.Slack.UserName(msg.User), runCtx.Slack.ConversationName(msg.Channel))
+
+		if date, err := runCtx.Slack.ParseTimestamp(msg.Timestamp); err == nil {
+			fmt.Fprintf(buf, " on %s", date.UTC().Format("2006-01-02"))
+		}
+
+		fmt.Fprintf(buf, " - _%d reactions_.\n", msg.Count)
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
+
+func (cmd *hallOfFameCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	return nil
+}
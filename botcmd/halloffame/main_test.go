@@ -0,0 +1,138 @@
+//nolint:goerr113
+package halloffame
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*hallOfFameCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &hallOfFameCmd{
+		log: log,
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestRunParseErr(t *testing.T) {
+	cmd := &hallOfFameCmd{}
+	expected := `halloffame: failed to parse "-hello bye": flag provided but not defined: -hello`
+
+	if res, err := cmd.Run("-hello bye", botcmd.RunContext{}); err != nil {
+		t.Errorf("unexpected run err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("exected run result %q got %q", expected, res)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	expectOpts := storage.GetReactedMessageOptions{
+		FindOptions: storage.FindOptions{SortField: "count", Limit: 5},
+	}
+
+	mockStorage.EXPECT().GetReactedMessages(expectOpts).Return(nil, errors.New("data is dead"))
+
+	expectedErr := fmt.Sprintf(
+		`halloffame: failed to get reacted messages from storage opts: %v err: data is dead`,
+		expectOpts)
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	expectOpts := storage.GetReactedMessageOptions{
+		FindOptions: storage.FindOptions{SortField: "count", Limit: 3},
+		Channel:     "C001",
+		User:        "U002",
+	}
+
+	mockClient.EXPECT().ConversationID("general").Return("C001")
+	mockClient.EXPECT().UserID("bob").Return("U002")
+	mockStorage.EXPECT().GetReactedMessages(expectOpts).Return([]models.ReactedMessage{
+		{Channel: "C001", Timestamp: "1614556800.000100", User: "U002", Count: 12},
+		{Channel: "C001", Timestamp: "bogus", User: "U002", Count: 3},
+		{Channel: "C001", Timestamp: "1614556900.000100", User: "U002", Count: 0},
+	}, nil)
+	mockClient.EXPECT().UserName("U002").Return("bob").Times(2)
+	mockClient.EXPECT().ConversationName("C001").Return("general").Times(2)
+	mockClient.EXPECT().ParseTimestamp("1614556800.000100").Return(time.Unix(1614556800, 0), nil)
+	mockClient.EXPECT().ParseTimestamp("bogus").Return(time.Time{}, errors.New("bad ts"))
+
+	// Messages without reactions are omitted and bad timestamps are skipped.
+	expected := ":star2: Hall of fame: top 2 most reacted messages in #general by *bob*:\n" +
+		"\t1. *bob* in #general on 2021-03-01 - _12 reactions_.\n" +
+		"\t2. *bob* in #general - _3 reactions_.\n"
+
+	if res, err := cmd.Run("-limit 3 -channel #general -user @bob", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected result Message %q, got %q", expected, res.Message)
+	}
+}
+
+func TestRunUnknown(t *testing.T) {
+	cmd, ctx, _, mockClient := setup(t)
+
+	mockClient.EXPECT().ConversationID("nowhere").Return("")
+	mockClient.EXPECT().UserID("nobody").Return("")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "-channel nowhere", expected: `halloffame: unknown channel "nowhere"`},
+		{input: "-user nobody", expected: `halloffame: unknown user "nobody"`},
+	}
+
+	for _, tc := range testCases {
+		if res, err := cmd.Run(tc.input, ctx); err != nil {
+			t.Errorf("unexpected err from Run: %v", err)
+		} else if res.Message != tc.expected {
+			t.Errorf("expected result Message %q, got %q", tc.expected, res.Message)
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &hallOfFameCmd{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Errorf("expected no err from configure")
+	}
+
+	if cmd.log != log {
+		t.Errorf("expected log to be %p was %p", log, cmd.log)
+	}
+}
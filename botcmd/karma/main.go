@@ -0,0 +1,140 @@
+package karma
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "karma"
+)
+
+// mentionRegexp matches a Slack user mention like "<@U1234>" or
+// "<@U1234|daniel>", capturing the user ID.
+var mentionRegexp = regexp.MustCompile(`^<@(\w+)(?:\|[^>]*)?>$`)
+
+type karmaCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":arrow_up_down:",
+		Description: "Show the things (and users) with the most or least karma, or the karma of one thing",
+		Handler:     &karmaCmd{},
+	})
+}
+
+// lookup returns the karma model to look up for the text given to the
+// command. Mentions and names with an "@" prefix of known users are looked up
+// as a user, everything else is looked up as a lowercased thing.
+func lookup(text string, runCtx botcmd.RunContext) models.Karma {
+	if m := mentionRegexp.FindStringSubmatch(text); m != nil {
+		return models.Karma{Thing: m[1], User: true}
+	}
+
+	if strings.HasPrefix(text, "@") {
+		if id := runCtx.Slack.UserID(text[1:]); id != "" {
+			return models.Karma{Thing: id, User: true}
+		}
+
+		text = text[1:]
+	}
+
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "("), ")")
+
+	return models.Karma{Thing: strings.ToLower(text)}
+}
+
+// displayName returns the name to show for a karma model. Users are shown by
+// their friendly name (falling back to their ID if it isn't known).
+func displayName(karma models.Karma, runCtx botcmd.RunContext) string {
+	if !karma.User {
+		return karma.Thing
+	}
+
+	if name := runCtx.Slack.UserName(karma.Thing); name != "" {
+		return "@" + name
+	}
+
+	return karma.Thing
+}
+
+func (cmd karmaCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	flagSet := flag.NewFlagSet(cmdName, flag.ContinueOnError)
+	limit := flagSet.Int64("limit", 10, "limit for number of karma entries to display")
+	bottom := flagSet.Bool("bottom", false, "list the things with the least karma")
+
+	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
+		return botcmd.RunResult{Message: respText}, nil
+	}
+
+	opts := storage.GetKarmaOptions{
+		FindOptions: storage.FindOptions{
+			Limit:     *limit,
+			SortField: "count",
+			Asc:       *bottom,
+		},
+	}
+
+	if flagSet.NArg() > 0 {
+		karma := lookup(strings.Join(flagSet.Args(), " "), runCtx)
+		opts.Thing = karma.Thing
+		opts.User = karma.User
+	}
+
+	runCtx.Logger(cmd.log).Infof("Getting karma with options: %#v", opts)
+
+	results, err := runCtx.Storage.GetKarma(opts)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s: failed to get karma from storage opts: %v err: %w",
+				cmdName, opts, err)
+	}
+
+	if opts.Thing != "" {
+		karma := models.Karma{Thing: opts.Thing, User: opts.User}
+		if len(results) > 0 {
+			karma = results[0]
+		}
+
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":arrow_up_down: *%s* has %d karma", displayName(karma, runCtx), karma.Count),
+		}, nil
+	}
+
+	if len(results) == 0 {
+		return botcmd.RunResult{
+			Message: ":shrug: Nothing has any karma yet. Try `thing++`",
+		}, nil
+	}
+
+	header := "Top"
+	if *bottom {
+		header = "Bottom"
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, ":arrow_up_down: %s %d karma:\n", header, len(results))
+
+	for i, karma := range results {
+		fmt.Fprintf(buf, "\t%d. *%s* - %d karma\n", i+1, displayName(karma, runCtx), karma.Count)
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
+
+func (cmd *karmaCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	return nil
+}
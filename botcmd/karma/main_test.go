@@ -0,0 +1,179 @@
+//nolint:goerr113
+package karma
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*karmaCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &karmaCmd{
+		log: log,
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestRunParseErr(t *testing.T) {
+	cmd := &karmaCmd{}
+	expected := `karma: failed to parse "-hello bye": flag provided but not defined: -hello`
+
+	if res, err := cmd.Run("-hello bye", botcmd.RunContext{}); err != nil {
+		t.Errorf("unexpected run err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("exected run result %q got %q", expected, res)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	expectOpts := storage.GetKarmaOptions{
+		FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+	}
+
+	mockStorage.EXPECT().GetKarma(expectOpts).Return(nil, errors.New("data is dead"))
+
+	expectedErr := fmt.Sprintf(
+		`karma: failed to get karma from storage opts: %v err: data is dead`, expectOpts)
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           string
+		expectOpts      storage.GetKarmaOptions
+		results         []models.Karma
+		expectedMessage string
+	}{
+		{
+			name:  "no karma",
+			input: "",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+			},
+			expectedMessage: ":shrug: Nothing has any karma yet. Try `thing++`",
+		},
+		{
+			name:  "top",
+			input: "",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+			},
+			results: []models.Karma{
+				{Thing: "frogs", Count: 10},
+				{Thing: "U002", User: true, Count: 3},
+				{Thing: "U999", User: true, Count: 1},
+			},
+			expectedMessage: ":arrow_up_down: Top 3 karma:\n" +
+				"\t1. *frogs* - 10 karma\n" +
+				"\t2. *@gorf* - 3 karma\n" +
+				"\t3. *U999* - 1 karma\n",
+		},
+		{
+			name:  "bottom with limit",
+			input: "-bottom -limit 1",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 1, SortField: "count", Asc: true},
+			},
+			results: []models.Karma{
+				{Thing: "mondays", Count: -4},
+			},
+			expectedMessage: ":arrow_up_down: Bottom 1 karma:\n" +
+				"\t1. *mondays* - -4 karma\n",
+		},
+		{
+			name:  "thing",
+			input: "(The Swamp)",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+				Thing:       "the swamp",
+			},
+			results: []models.Karma{
+				{Thing: "the swamp", Count: 2},
+			},
+			expectedMessage: ":arrow_up_down: *the swamp* has 2 karma",
+		},
+		{
+			name:  "unknown thing",
+			input: "@nobody",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+				Thing:       "nobody",
+			},
+			expectedMessage: ":arrow_up_down: *nobody* has 0 karma",
+		},
+		{
+			name:  "user by name",
+			input: "@gorf",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+				Thing:       "U002",
+				User:        true,
+			},
+			results: []models.Karma{
+				{Thing: "U002", User: true, Count: 3},
+			},
+			expectedMessage: ":arrow_up_down: *@gorf* has 3 karma",
+		},
+		{
+			name:  "user by mention",
+			input: "<@U002>",
+			expectOpts: storage.GetKarmaOptions{
+				FindOptions: storage.FindOptions{Limit: 10, SortField: "count"},
+				Thing:       "U002",
+				User:        true,
+			},
+			expectedMessage: ":arrow_up_down: *@gorf* has 0 karma",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, mockClient := setup(t)
+
+			mockClient.EXPECT().UserID("gorf").Return("U002").AnyTimes()
+			mockClient.EXPECT().UserID("nobody").Return("").AnyTimes()
+			mockClient.EXPECT().UserName("U002").Return("gorf").AnyTimes()
+			mockClient.EXPECT().UserName("U999").Return("").AnyTimes()
+			mockStorage.EXPECT().GetKarma(tc.expectOpts).Return(tc.results, nil)
+
+			res, err := cmd.Run(tc.input, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expectedMessage {
+				t.Errorf("expected message %q, got %q", tc.expectedMessage, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package karmaupdate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	patternName = "karma"
+	// karmaRegexp matches a user mention (e.g. "<@U1234>++"), a parenthesized
+	// phrase (e.g. "(the swamp)++") or a word optionally prefixed with "@" (e.g.
+	// "frogs--") followed by "++" or "--" and the end of the word.
+	karmaRegexp = `(?:<@(U[A-Z0-9]+)(?:\|[^>]*)?>\s?|\(([^()\n]+)\)|(@?\w[\w.\-]*))` +
+		`(\+\+|--)(?:[\s.,!?:]|$)`
+	karmaExpectedSubmatchCount = 5
+)
+
+var whitespaceRegexp = regexp.MustCompile(`\s+`)
+
+type submatchErr struct {
+	msg string
+	got interface{}
+}
+
+func (e submatchErr) Error() string {
+	return fmt.Sprintf("%s pattern error: %s, got %v", patternName, e.msg, e.got)
+}
+
+type karmaPattern struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddPattern(&botcmd.PatternCommand{
+		Name:    patternName,
+		Handler: &karmaPattern{},
+		Pattern: regexp.MustCompile(karmaRegexp),
+	})
+}
+
+// karmaUpdate is a karma change from a single match.
+type karmaUpdate struct {
+	karma     models.Karma
+	decrement bool
+}
+
+// updates returns the karma updates for all of the submatches. A thing that
+// appears more than once in a message is only updated for its first match.
+// Words prefixed with "@" are treated as a user if a user with the name
+// exists.
+func (p karmaPattern) updates(allSubmatches [][]string, runCtx botcmd.RunContext) ([]karmaUpdate, error) {
+	var updates []karmaUpdate
+
+	seen := make(map[models.Karma]bool)
+
+	for _, submatches := range allSubmatches {
+		if len(submatches) < karmaExpectedSubmatchCount {
+			return nil, submatchErr{msg: "too few submatches", got: submatches}
+		}
+
+		var karma models.Karma
+
+		switch userID, phrase, word := submatches[1], submatches[2], submatches[3]; {
+		case userID != "":
+			karma = models.Karma{Thing: userID, User: true}
+		case phrase != "":
+			karma = models.Karma{Thing: whitespaceRegexp.ReplaceAllString(strings.TrimSpace(phrase), " ")}
+		case strings.HasPrefix(word, "@"):
+			if id := runCtx.Slack.UserID(word[1:]); id != "" {
+				karma = models.Karma{Thing: id, User: true}
+			} else {
+				karma = models.Karma{Thing: word[1:]}
+			}
+		default:
+			karma = models.Karma{Thing: word}
+		}
+
+		if !karma.User {
+			karma.Thing = strings.ToLower(karma.Thing)
+		}
+
+		if karma.Thing == "" || seen[karma] {
+			continue
+		}
+
+		seen[karma] = true
+
+		updates = append(updates, karmaUpdate{
+			karma:     karma,
+			decrement: submatches[4] == "--",
+		})
+	}
+
+	return updates, nil
+}
+
+// displayName returns the name to show for a karma model. Users are shown by
+// their friendly name (falling back to their ID if it isn't known).
+func displayName(karma models.Karma, runCtx botcmd.RunContext) string {
+	if !karma.User {
+		return karma.Thing
+	}
+
+	if name := runCtx.Slack.UserName(karma.Thing); name != "" {
+		return "@" + name
+	}
+
+	return karma.Thing
+}
+
+func (p karmaPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error: %w", patternName, botcmd.ErrNilMessage)
+	}
+
+	// Don't give or take karma for the bot's own messages (e.g. a "!quote" of
+	// "frogs++").
+	if runCtx.Message.UserID == runCtx.Slack.BotID() {
+		return botcmd.RunResult{}, nil
+	}
+
+	if len(allSubmatches) == 0 {
+		return botcmd.RunResult{}, submatchErr{
+			msg: "expected at least one submatch", got: allSubmatches}
+	}
+
+	updates, err := p.updates(allSubmatches, runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	senderID := runCtx.Message.UserID
+	senderName := runCtx.Slack.UserName(senderID)
+
+	var lines []string
+
+	var triedSelf bool
+
+	for _, update := range updates {
+		karma := update.karma
+
+		if (karma.User && karma.Thing == senderID) ||
+			(!karma.User && senderName != "" && karma.Thing == strings.ToLower(senderName)) {
+			if !triedSelf {
+				lines = append(lines,
+					fmt.Sprintf(":no_good: Nice try _%s_, no karma for yourself", senderName))
+			}
+
+			triedSelf = true
+
+			continue
+		}
+
+		prev, err := runCtx.Storage.UpsertKarma(karma, update.decrement)
+		if err != nil {
+			return botcmd.RunResult{},
+				fmt.Errorf("%s pattern error updating karma: %w", patternName, err)
+		}
+
+		icon, count := ":arrow_up:", prev.Count+1
+		if update.decrement {
+			icon, count = ":arrow_down:", prev.Count-1
+		}
+
+		runCtx.Logger(p.log).Infof("%s karma for %q now %d", patternName, karma.Thing, count)
+
+		lines = append(lines,
+			fmt.Sprintf("%s *%s* now has %d karma", icon, displayName(karma, runCtx), count))
+	}
+
+	return botcmd.RunResult{Message: strings.Join(lines, "\n")}, nil
+}
+
+func (p *karmaPattern) Configure(log *logrus.Logger, c *config.Config) error {
+	p.log = log
+	return nil
+}
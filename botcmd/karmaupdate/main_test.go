@@ -0,0 +1,174 @@
+//nolint:goerr113
+package karmaupdate
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*karmaPattern, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &karmaPattern{
+		log: log,
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001", Text: "hello"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().BotID().Return("UBOT").AnyTimes()
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestPattern(t *testing.T) {
+	pattern := regexp.MustCompile(karmaRegexp)
+
+	testCases := []struct {
+		text     string
+		expected [][]string
+	}{
+		{text: "i++;"},
+		{text: "c++ is great", expected: [][]string{{"c", "++"}}},
+		{text: "no --flags please"},
+		{text: "x++y"},
+		{text: "frogs++", expected: [][]string{{"frogs", "++"}}},
+		{text: "Frogs--, toads++!", expected: [][]string{{"Frogs", "--"}, {"toads", "++"}}},
+		{text: "gorf-bot.go++", expected: [][]string{{"gorf-bot.go", "++"}}},
+		{text: "(the big swamp)++", expected: [][]string{{"the big swamp", "++"}}},
+		{text: "thanks <@U123>++", expected: [][]string{{"U123", "++"}}},
+		{text: "thanks <@U123> --", expected: [][]string{{"U123", "--"}}},
+		{text: "thanks @daniel++", expected: [][]string{{"@daniel", "++"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			var matches [][]string
+
+			for _, submatches := range pattern.FindAllStringSubmatch(tc.text, -1) {
+				var thing string
+
+				for _, s := range submatches[1:4] {
+					thing += s
+				}
+
+				matches = append(matches, []string{thing, submatches[4]})
+			}
+
+			if !reflect.DeepEqual(matches, tc.expected) {
+				t.Errorf("expected matches %q, got %q", tc.expected, matches)
+			}
+		})
+	}
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd, ctx, _, _ := setup(t)
+	ctx.Message = nil
+
+	if _, err := cmd.Run([][]string{}, ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunBotMessage(t *testing.T) {
+	// The mock storage fails the test if karma is updated.
+	cmd, ctx, _, _ := setup(t)
+	ctx.Message.UserID = "UBOT"
+
+	if res, err := cmd.Run([][]string{{"frogs++", "", "", "frogs", "++"}}, ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != "" {
+		t.Errorf("expected no message for the bot's own message, got %q", res.Message)
+	}
+}
+
+func TestRunTooFewMatches(t *testing.T) {
+	cmd, ctx, _, mockClient := setup(t)
+
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+
+	if _, err := cmd.Run([][]string{{"a", "b"}}, ctx); err == nil {
+		t.Errorf("expected err from Run with too few submatches, got nil")
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().UserName("U001").Return("daniel")
+	mockStorage.EXPECT().UpsertKarma(models.Karma{Thing: "frogs"}, false).
+		Return(models.Karma{}, errors.New("data is dead"))
+
+	expectedErr := "karma pattern error updating karma: data is dead"
+
+	if _, err := cmd.Run([][]string{{"frogs++", "", "", "frogs", "++"}}, ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+	mockClient.EXPECT().UserName("U002").Return("gorf").AnyTimes()
+	mockClient.EXPECT().UserID("gorf").Return("U002").AnyTimes()
+	mockClient.EXPECT().UserID("nobody").Return("").AnyTimes()
+
+	gomock.InOrder(
+		mockStorage.EXPECT().UpsertKarma(models.Karma{Thing: "frogs"}, false).
+			Return(models.Karma{Thing: "frogs", Count: 2}, nil),
+		mockStorage.EXPECT().UpsertKarma(models.Karma{Thing: "the swamp"}, true).
+			Return(models.Karma{Thing: "the swamp"}, nil),
+		mockStorage.EXPECT().UpsertKarma(models.Karma{Thing: "U002", User: true}, false).
+			Return(models.Karma{Thing: "U002", User: true, Count: 9}, nil),
+		mockStorage.EXPECT().UpsertKarma(models.Karma{Thing: "nobody"}, false).
+			Return(models.Karma{Thing: "nobody"}, nil),
+	)
+
+	allSubmatches := [][]string{
+		{"Frogs++", "", "", "Frogs", "++"},
+		{"(the   Swamp)--", "", "the   Swamp", "", "--"},
+		// Repeated things are only counted once.
+		{"frogs++", "", "", "frogs", "++"},
+		{"@gorf++", "", "", "@gorf", "++"},
+		// Users can't give themselves karma.
+		{"<@U001>++", "U001", "", "", "++"},
+		{"daniel++", "", "", "daniel", "++"},
+		{"@nobody++", "", "", "@nobody", "++"},
+	}
+
+	expected := ":arrow_up: *frogs* now has 3 karma\n" +
+		":arrow_down: *the swamp* now has -1 karma\n" +
+		":arrow_up: *@gorf* now has 10 karma\n" +
+		":no_good: Nice try _daniel_, no karma for yourself\n" +
+		":arrow_up: *nobody* now has 1 karma"
+
+	res, err := cmd.Run(allSubmatches, ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+}
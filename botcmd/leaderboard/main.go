@@ -0,0 +1,155 @@
+package leaderboard
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "leaderboard"
+)
+
+type leaderboardCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":trophy:",
+		Description: "Rank the most used emoji/reactji, or their most prolific users",
+		Handler:     &leaderboardCmd{},
+	})
+}
+
+// emojiName returns the emoji with ":" delimiters for emoji used in messages,
+// or without for reactji, matching how each is stored.
+func emojiName(emoji string, reactions bool) string {
+	emoji = strings.Trim(emoji, ":")
+	if reactions || emoji == "" {
+		return emoji
+	}
+
+	return ":" + emoji + ":"
+}
+
+// describe returns the description of what is being ranked for the leaderboard
+// header.
+func describe(opts storage.GetLeaderboardOptions) string {
+	objects := "emoji"
+	if opts.Reaction {
+		objects = "reactji"
+	}
+
+	switch {
+	case opts.GroupBy == storage.GroupByUser && opts.Emoji != "":
+		return fmt.Sprintf("users of %s :%s:", objects, strings.Trim(opts.Emoji, ":"))
+	case opts.GroupBy == storage.GroupByUser && opts.Reaction:
+		return "reactors"
+	case opts.GroupBy == storage.GroupByUser:
+		return "emoji users"
+	default:
+		return objects
+	}
+}
+
+//nolint:funlen
+func (cmd leaderboardCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	flagSet := flag.NewFlagSet(cmdName, flag.ContinueOnError)
+	limit := flagSet.Int64("limit", 10, "limit for number of leaderboard entries to display")
+	asc := flagSet.Bool("asc", false, "list entries in order of ascending usage count")
+	reactions := flagSet.Bool("reactions", false, "rank reactji instead of emoji used in messages")
+	users := flagSet.Bool("users", false, "rank users instead of emoji")
+	emojiFlag := flagSet.String("emoji", "", "rank the users of a single emoji")
+	sinceFlag := flagSet.String("since", "", "only count usage since an age (e.g. 7d, 2w) or date (e.g. 2021-03-01)")
+	untilFlag := flagSet.String("until", "", "only count usage before an age (e.g. 7d, 2w) or date (e.g. 2021-04-01)")
+	channelFlag := flagSet.String("channel", "", "only count usage in a channel (e.g. general)")
+
+	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
+		return botcmd.RunResult{Message: respText}, nil
+	}
+
+	window, err := botcmd.ParseWindow(*sinceFlag, *untilFlag, time.Now())
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	var channelID string
+
+	channelName := strings.TrimPrefix(*channelFlag, "#")
+	if channelName != "" {
+		if channelID = runCtx.Slack.ConversationID(channelName); channelID == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown channel %q", cmdName, channelName),
+			}, nil
+		}
+	}
+
+	// Rank the canonical emoji so that aliases and skin tones count as the
+	// emoji they refer to.
+	canonical := *emojiFlag
+	if canonical != "" {
+		canonical, _ = runCtx.Slack.CanonicalEmoji(canonical)
+	}
+
+	opts := storage.GetLeaderboardOptions{
+		FindOptions: storage.FindOptions{
+			Limit: *limit,
+			Asc:   *asc,
+		},
+		Emoji:    emojiName(canonical, *reactions),
+		Reaction: *reactions,
+		Since:    window.Since,
+		Until:    window.Until,
+		Channel:  channelID,
+	}
+	if *users || opts.Emoji != "" {
+		opts.GroupBy = storage.GroupByUser
+	}
+
+	runCtx.Logger(cmd.log).Infof("Getting leaderboard with options: %#v", opts)
+
+	entries, err := runCtx.Storage.GetLeaderboard(opts)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s: failed to get leaderboard from storage opts: %v err: %w",
+				cmdName, opts, err)
+	}
+
+	header := "Top"
+	if *asc {
+		header = "Bottom"
+	}
+
+	desc := window.String()
+	if channelName != "" {
+		desc = " in #" + channelName + desc
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, ":trophy: %s %d %s%s:\n", header, len(entries), describe(opts), desc)
+
+	for i, e := range entries {
+		name := ":" + strings.Trim(e.Key, ":") + ":"
+		if opts.GroupBy == storage.GroupByUser {
+			name = "*" + runCtx.Slack.UserName(e.Key) + "*"
+		}
+
+		fmt.Fprintf(buf, "\t%d. %s - used _%d times_.\n", i+1, name, e.Count)
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
+
+func (cmd *leaderboardCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	return nil
+}
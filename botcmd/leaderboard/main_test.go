@@ -0,0 +1,214 @@
+//nolint:goerr113
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*leaderboardCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &leaderboardCmd{
+		log: log,
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestRunParseErr(t *testing.T) {
+	cmd := &leaderboardCmd{}
+	expected := `leaderboard: failed to parse "-hello bye": flag provided but not defined: -hello`
+
+	if res, err := cmd.Run("-hello bye", botcmd.RunContext{}); err != nil {
+		t.Errorf("unexpected run err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("exected run result %q got %q", expected, res)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	expectOpts := storage.GetLeaderboardOptions{
+		FindOptions: storage.FindOptions{Limit: 10},
+	}
+
+	mockStorage.EXPECT().GetLeaderboard(expectOpts).Return(nil, errors.New("data is dead"))
+
+	expectedErr := fmt.Sprintf(
+		`leaderboard: failed to get leaderboard from storage opts: %v err: data is dead`,
+		expectOpts)
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           string
+		expectOpts      storage.GetLeaderboardOptions
+		canonical       map[string]string
+		entries         []models.LeaderboardEntry
+		expectedMessage string
+	}{
+		{
+			name:       "top emoji",
+			input:      "",
+			expectOpts: storage.GetLeaderboardOptions{FindOptions: storage.FindOptions{Limit: 10}},
+			entries: []models.LeaderboardEntry{
+				{Key: ":wave:", Count: 3},
+				{Key: ":frog:", Count: 2},
+			},
+			expectedMessage: ":trophy: Top 2 emoji:\n" +
+				"\t1. :wave: - used _3 times_.\n" +
+				"\t2. :frog: - used _2 times_.\n",
+		},
+		{
+			name:  "rarest reactji",
+			input: "-reactions -asc -limit 1",
+			expectOpts: storage.GetLeaderboardOptions{
+				FindOptions: storage.FindOptions{Limit: 1, Asc: true},
+				Reaction:    true,
+			},
+			entries: []models.LeaderboardEntry{
+				{Key: "joy", Count: 1},
+			},
+			expectedMessage: ":trophy: Bottom 1 reactji:\n" +
+				"\t1. :joy: - used _1 times_.\n",
+		},
+		{
+			name:  "top users of an emoji",
+			input: "-emoji hello -since 2021-03-01",
+			expectOpts: storage.GetLeaderboardOptions{
+				FindOptions: storage.FindOptions{Limit: 10},
+				GroupBy:     storage.GroupByUser,
+				Emoji:       ":wave:",
+				Since:       time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+			},
+			canonical: map[string]string{"hello": "wave"},
+			entries: []models.LeaderboardEntry{
+				{Key: "U001", Count: 3},
+			},
+			expectedMessage: ":trophy: Top 1 users of emoji :wave: since 2021-03-01:\n" +
+				"\t1. *alice* - used _3 times_.\n",
+		},
+		{
+			name:  "top reactors of a reactji",
+			input: "-reactions -emoji :joy:",
+			expectOpts: storage.GetLeaderboardOptions{
+				FindOptions: storage.FindOptions{Limit: 10},
+				GroupBy:     storage.GroupByUser,
+				Emoji:       "joy",
+				Reaction:    true,
+			},
+			canonical: map[string]string{":joy:": "joy"},
+			entries: []models.LeaderboardEntry{
+				{Key: "U001", Count: 3},
+			},
+			expectedMessage: ":trophy: Top 1 users of reactji :joy::\n" +
+				"\t1. *alice* - used _3 times_.\n",
+		},
+		{
+			name:  "most prolific reactors",
+			input: "-reactions -users",
+			expectOpts: storage.GetLeaderboardOptions{
+				FindOptions: storage.FindOptions{Limit: 10},
+				GroupBy:     storage.GroupByUser,
+				Reaction:    true,
+			},
+			entries: []models.LeaderboardEntry{
+				{Key: "U001", Count: 3},
+			},
+			expectedMessage: ":trophy: Top 1 reactors:\n" +
+				"\t1. *alice* - used _3 times_.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, mockClient := setup(t)
+
+			mockStorage.EXPECT().GetLeaderboard(tc.expectOpts).Return(tc.entries, nil)
+			mockClient.EXPECT().UserName("U001").Return("alice").AnyTimes()
+
+			for name, canonical := range tc.canonical {
+				mockClient.EXPECT().CanonicalEmoji(name).Return(canonical, true)
+			}
+
+			if res, err := cmd.Run(tc.input, ctx); err != nil {
+				t.Errorf("unexpected err from Run: %v", err)
+			} else if res.Message != tc.expectedMessage {
+				t.Errorf("expected result Message %q, got %q", tc.expectedMessage, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunChannel(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	expectOpts := storage.GetLeaderboardOptions{
+		FindOptions: storage.FindOptions{Limit: 10},
+		Channel:     "C001",
+	}
+
+	mockClient.EXPECT().ConversationID("general").Return("C001")
+	mockStorage.EXPECT().GetLeaderboard(expectOpts).Return(nil, nil)
+
+	expected := ":trophy: Top 0 emoji in #general:\n"
+
+	if res, err := cmd.Run("-channel general", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected result Message %q, got %q", expected, res.Message)
+	}
+
+	mockClient.EXPECT().ConversationID("nowhere").Return("")
+
+	expected = `leaderboard: unknown channel "nowhere"`
+
+	if res, err := cmd.Run("-channel nowhere", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected result Message %q, got %q", expected, res.Message)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &leaderboardCmd{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Errorf("expected no err from configure")
+	}
+
+	if cmd.log != log {
+		t.Errorf("expected log to be %p was %p", log, cmd.log)
+	}
+}
@@ -109,7 +109,7 @@ func (cmd mkthemeCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResu
 		paletteType: strings.ToLower(*paletteFlag),
 	}
 
-	cmd.log.Infof("%s making a theme with palette type %q", cmdName, theme.paletteType)
+	runCtx.Logger(cmd.log).Infof("%s making a theme with palette type %q", cmdName, theme.paletteType)
 
 	if err := theme.Generate(); err != nil {
 		return botcmd.RunResult{Message: err.Error()}, nil
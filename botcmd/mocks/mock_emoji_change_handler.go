@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/cpu/gorfbot/botcmd (interfaces: EmojiChangeHandler)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	botcmd "github.com/cpu/gorfbot/botcmd"
+	config "github.com/cpu/gorfbot/config"
+	slack "github.com/cpu/gorfbot/slack"
+	gomock "github.com/golang/mock/gomock"
+	logrus "github.com/sirupsen/logrus"
+	reflect "reflect"
+)
+
+// MockEmojiChangeHandler is a mock of EmojiChangeHandler interface
+type MockEmojiChangeHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmojiChangeHandlerMockRecorder
+}
+
+// MockEmojiChangeHandlerMockRecorder is the mock recorder for MockEmojiChangeHandler
+type MockEmojiChangeHandlerMockRecorder struct {
+	mock *MockEmojiChangeHandler
+}
+
+// NewMockEmojiChangeHandler creates a new mock instance
+func NewMockEmojiChangeHandler(ctrl *gomock.Controller) *MockEmojiChangeHandler {
+	mock := &MockEmojiChangeHandler{ctrl: ctrl}
+	mock.recorder = &MockEmojiChangeHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEmojiChangeHandler) EXPECT() *MockEmojiChangeHandlerMockRecorder {
+	return m.recorder
+}
+
+// Configure mocks base method
+func (m *MockEmojiChangeHandler) Configure(arg0 *logrus.Logger, arg1 *config.Config) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Configure indicates an expected call of Configure
+func (mr *MockEmojiChangeHandlerMockRecorder) Configure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockEmojiChangeHandler)(nil).Configure), arg0, arg1)
+}
+
+// Run mocks base method
+func (m *MockEmojiChangeHandler) Run(arg0 *slack.EmojiChange, arg1 botcmd.RunContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run
+func (mr *MockEmojiChangeHandlerMockRecorder) Run(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockEmojiChangeHandler)(nil).Run), arg0, arg1)
+}
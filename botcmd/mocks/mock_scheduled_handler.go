@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/cpu/gorfbot/botcmd (interfaces: ScheduledHandler)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	botcmd "github.com/cpu/gorfbot/botcmd"
+	config "github.com/cpu/gorfbot/config"
+	gomock "github.com/golang/mock/gomock"
+	logrus "github.com/sirupsen/logrus"
+	reflect "reflect"
+)
+
+// MockScheduledHandler is a mock of ScheduledHandler interface
+type MockScheduledHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledHandlerMockRecorder
+}
+
+// MockScheduledHandlerMockRecorder is the mock recorder for MockScheduledHandler
+type MockScheduledHandlerMockRecorder struct {
+	mock *MockScheduledHandler
+}
+
+// NewMockScheduledHandler creates a new mock instance
+func NewMockScheduledHandler(ctrl *gomock.Controller) *MockScheduledHandler {
+	mock := &MockScheduledHandler{ctrl: ctrl}
+	mock.recorder = &MockScheduledHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockScheduledHandler) EXPECT() *MockScheduledHandlerMockRecorder {
+	return m.recorder
+}
+
+// Configure mocks base method
+func (m *MockScheduledHandler) Configure(arg0 *logrus.Logger, arg1 *config.Config) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Configure indicates an expected call of Configure
+func (mr *MockScheduledHandlerMockRecorder) Configure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockScheduledHandler)(nil).Configure), arg0, arg1)
+}
+
+// Run mocks base method
+func (m *MockScheduledHandler) Run(arg0 botcmd.RunContext) (botcmd.RunResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", arg0)
+	ret0, _ := ret[0].(botcmd.RunResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run
+func (mr *MockScheduledHandlerMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockScheduledHandler)(nil).Run), arg0)
+}
@@ -0,0 +1,141 @@
+// Package onthisday provides a scheduled command that posts the topics and
+// most used emoji from the same day in prior years.
+package onthisday
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "on this day"
+
+	// maxYears is how many prior years are checked for topics and emoji.
+	maxYears = 10
+	// topEmojiLimit is how many of the most used emoji are posted for each
+	// year.
+	topEmojiLimit = 3
+)
+
+type onThisDayCmd struct {
+	log *logrus.Logger
+}
+
+func (cmd *onThisDayCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+
+	return nil
+}
+
+// Run returns a message with the topics and top emoji from the scheduled day in
+// prior years. The message is empty if there were none.
+func (cmd onThisDayCmd) Run(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	now := runCtx.ScheduledAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	today := models.UsageDay(now)
+	buf := new(bytes.Buffer)
+
+	for years := 1; years <= maxYears; years++ {
+		day := today.AddDate(-years, 0, 0)
+		// Skip years where the day doesn't exist (e.g. February 29th).
+		if day.Day() != today.Day() {
+			continue
+		}
+
+		section, err := year(day, years, runCtx)
+		if err != nil {
+			return botcmd.RunResult{}, err
+		}
+
+		buf.WriteString(section)
+	}
+
+	if buf.Len() == 0 {
+		runCtx.Logger(cmd.log).Infof("%s - nothing happened on %s in prior years",
+			cmdName, today.Format("January 2"))
+
+		return botcmd.RunResult{}, nil
+	}
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":calendar: *On this day* (%s)\n%s", today.Format("January 2"), buf.String()),
+	}, nil
+}
+
+// year returns the section of the post for the given day, the given number of
+// years ago, or "" if there were no topics or emoji used that day.
+func year(day time.Time, years int, runCtx botcmd.RunContext) (string, error) {
+	topicOpts := storage.GetTopicOptions{
+		FindOptions: storage.FindOptions{SortField: "date", Asc: true},
+		Since:       day,
+		Until:       day.AddDate(0, 0, 1),
+	}
+
+	topics, err := runCtx.Storage.GetTopics(topicOpts)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get topics from storage opts: %v err: %w",
+			cmdName, topicOpts, err)
+	}
+
+	emojiOpts := storage.GetLeaderboardOptions{
+		FindOptions: storage.FindOptions{Limit: topEmojiLimit},
+		Since:       day,
+		Until:       day.AddDate(0, 0, 1),
+	}
+
+	emoji, err := runCtx.Storage.GetLeaderboard(emojiOpts)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get leaderboard from storage opts: %v err: %w",
+			cmdName, emojiOpts, err)
+	}
+
+	if len(topics) == 0 && len(emoji) == 0 {
+		return "", nil
+	}
+
+	buf := new(bytes.Buffer)
+
+	ago := "years"
+	if years == 1 {
+		ago = "year"
+	}
+
+	fmt.Fprintf(buf, "*%d* (%d %s ago)\n", day.Year(), years, ago)
+
+	for _, topic := range topics {
+		fmt.Fprintf(buf, "\t:scroll: _%s_ set the topic of *#%s* to *%q*\n",
+			runCtx.Slack.UserName(topic.Creator),
+			runCtx.Slack.ConversationName(topic.Channel),
+			topic.Topic)
+	}
+
+	if len(emoji) > 0 {
+		top := make([]string, 0, len(emoji))
+		for _, e := range emoji {
+			top = append(top, fmt.Sprintf("%s x%d", e.Key, e.Count))
+		}
+
+		fmt.Fprintf(buf, "\t:trophy: Most used emoji: %s\n", strings.Join(top, ", "))
+	}
+
+	return buf.String(), nil
+}
+
+func init() {
+	botcmd.MustAddScheduledCommand(&botcmd.ScheduledCommand{
+		Name:        cmdName,
+		Description: "Post the topics and top emoji from this day in prior years",
+		Handler:     &onThisDayCmd{},
+	})
+}
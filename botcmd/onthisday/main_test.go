@@ -0,0 +1,139 @@
+package onthisday
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*onThisDayCmd, *slack_mocks.MockClient, *mocks.MockStorage, botcmd.RunContext) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &onThisDayCmd{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockSlack := slack_mocks.NewMockClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+	ctx := botcmd.RunContext{
+		Slack:   mockSlack,
+		Storage: mockStorage,
+	}
+
+	return cmd, mockSlack, mockStorage, ctx
+}
+
+func TestRun(t *testing.T) {
+	cmd, mockSlack, mockStorage, ctx := setup(t)
+
+	ctx.ScheduledAt = time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	lastYear := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	fiveYears := time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockStorage.EXPECT().GetTopics(gomock.Any()).Times(maxYears).DoAndReturn(
+		func(opts storage.GetTopicOptions) ([]models.Topic, error) {
+			if !opts.Until.Equal(opts.Since.AddDate(0, 0, 1)) {
+				t.Errorf("expected a one day window, got %s to %s", opts.Since, opts.Until)
+			}
+
+			if opts.Since.Equal(lastYear) {
+				return []models.Topic{
+					{Channel: "C001", Creator: "U001", Topic: "happy new year"},
+					{Channel: "C002", Creator: "U002", Topic: "back to work"},
+				}, nil
+			}
+
+			return nil, nil
+		})
+
+	mockStorage.EXPECT().GetLeaderboard(gomock.Any()).Times(maxYears).DoAndReturn(
+		func(opts storage.GetLeaderboardOptions) ([]models.LeaderboardEntry, error) {
+			if opts.Limit != topEmojiLimit {
+				t.Errorf("expected limit %d, got %d", topEmojiLimit, opts.Limit)
+			}
+
+			switch {
+			case opts.Since.Equal(lastYear):
+				return []models.LeaderboardEntry{{Key: ":tada:", Count: 5}}, nil
+			case opts.Since.Equal(fiveYears):
+				return []models.LeaderboardEntry{
+					{Key: ":gorf:", Count: 3},
+					{Key: ":wave:", Count: 1},
+				}, nil
+			default:
+				return nil, nil
+			}
+		})
+
+	mockSlack.EXPECT().UserName("U001").Return("daniel")
+	mockSlack.EXPECT().UserName("U002").Return("gorf")
+	mockSlack.EXPECT().ConversationName("C001").Return("general")
+	mockSlack.EXPECT().ConversationName("C002").Return("random")
+
+	expected := ":calendar: *On this day* (January 2)\n" +
+		"*2020* (1 year ago)\n" +
+		"\t:scroll: _daniel_ set the topic of *#general* to *\"happy new year\"*\n" +
+		"\t:scroll: _gorf_ set the topic of *#random* to *\"back to work\"*\n" +
+		"\t:trophy: Most used emoji: :tada: x5\n" +
+		"*2016* (5 years ago)\n" +
+		"\t:trophy: Most used emoji: :gorf: x3, :wave: x1\n"
+
+	if res, err := cmd.Run(ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q got %q", expected, res.Message)
+	}
+}
+
+func TestRunNothing(t *testing.T) {
+	cmd, _, mockStorage, ctx := setup(t)
+
+	ctx.ScheduledAt = time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	mockStorage.EXPECT().GetTopics(gomock.Any()).Times(maxYears).Return(nil, nil)
+	mockStorage.EXPECT().GetLeaderboard(gomock.Any()).Times(maxYears).Return(nil, nil)
+
+	if res, err := cmd.Run(ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != "" {
+		t.Errorf("expected no message got %q", res.Message)
+	}
+}
+
+func TestRunLeapDay(t *testing.T) {
+	cmd, _, mockStorage, ctx := setup(t)
+
+	// Only 2016 and 2012 have a February 29th in the ten years before 2020.
+	ctx.ScheduledAt = time.Date(2020, 2, 29, 14, 0, 0, 0, time.UTC)
+	mockStorage.EXPECT().GetTopics(gomock.Any()).Times(2).Return(nil, nil)
+	mockStorage.EXPECT().GetLeaderboard(gomock.Any()).Times(2).Return(nil, nil)
+
+	if _, err := cmd.Run(ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	cmd, _, mockStorage, ctx := setup(t)
+	storageErr := errors.New("storage is sleeping")
+
+	ctx.ScheduledAt = time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	mockStorage.EXPECT().GetTopics(gomock.Any()).Return(nil, storageErr)
+
+	if _, err := cmd.Run(ctx); !errors.Is(err, storageErr) {
+		t.Errorf("expected storage err from Run, got %v", err)
+	}
+}
@@ -60,9 +60,17 @@ func (p panoptimojiPattern) Run(allSubmatches [][]string, runCtx botcmd.RunConte
 			return botcmd.RunResult{}, errTooFewSubmatches{submatch}
 		}
 
+		// Only count real emoji, with aliases and skin tone variants counted
+		// together.
+		name, known := runCtx.Slack.CanonicalEmoji(submatch[1])
+		if !known {
+			runCtx.Logger(p.log).Debugf("%s - ignoring unknown emoji %q", patternName, submatch[1])
+			continue
+		}
+
 		e := models.Emoji{
 			User:  runCtx.Message.UserID,
-			Emoji: submatch[1],
+			Emoji: ":" + name + ":",
 			Count: 1,
 		}
 
@@ -72,8 +80,21 @@ func (p panoptimojiPattern) Run(allSubmatches [][]string, runCtx botcmd.RunConte
 				fmt.Errorf("%s storage returned err: %w", patternName, err)
 		}
 
+		usage := models.EmojiUsage{
+			User:    runCtx.Message.UserID,
+			Emoji:   e.Emoji,
+			Channel: runCtx.Message.ChannelID,
+			Day:     models.UsageDay(botcmd.EventTime(runCtx.Slack, runCtx.Message.Timestamp)),
+			Count:   1,
+		}
+
+		if _, err := runCtx.Storage.UpsertEmojiUsage(usage, false); err != nil {
+			return botcmd.RunResult{},
+				fmt.Errorf("%s storage returned usage err: %w", patternName, err)
+		}
+
 		user := runCtx.Slack.UserName(updatedE.User)
-		p.log.Infof("%s update - User %q (%s) has used emoji %q (history: %d times)",
+		runCtx.Logger(p.log).Infof("%s update - User %q (%s) has used emoji %q (history: %d times)",
 			patternName, user, updatedE.User, updatedE.Emoji, updatedE.Count+1)
 	}
 
@@ -4,6 +4,7 @@ package panoptimoji
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/slack"
@@ -22,7 +23,10 @@ func setup() (*panoptimojiPattern, botcmd.RunContext, *logtest.Hook) {
 		log: log,
 	}
 	ctx := botcmd.RunContext{
-		Message: &slack.Message{},
+		Message: &slack.Message{
+			ChannelID: "C001",
+			Timestamp: "1614556800.000100",
+		},
 	}
 
 	return cmd, ctx, logHook
@@ -82,6 +86,8 @@ func TestRunUpsertErr(t *testing.T) {
 
 	ctx.Message.UserID = "U001"
 
+	mockClient.EXPECT().CanonicalEmoji(":fake:").Return("fake", true)
+
 	expectEmoji := models.Emoji{
 		User:  ctx.Message.UserID,
 		Emoji: ":fake:",
@@ -100,6 +106,35 @@ func TestRunUpsertErr(t *testing.T) {
 	}
 }
 
+func TestRunUpsertUsageErr(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = "U001"
+
+	mockClient.EXPECT().CanonicalEmoji(":fake:").Return("fake", true)
+
+	mockClient.EXPECT().ParseTimestamp(ctx.Message.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{}, nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).
+		Return(models.EmojiUsage{}, errors.New("blorp failure"))
+
+	expectedErr := `emoji usage storage returned usage err: blorp failure`
+
+	if _, err := cmd.Run([][]string{{":fake:", ":fake:"}}, ctx); err == nil {
+		t.Errorf("expected err from upsert with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from upsert, got %q", expectedErr, err.Error())
+	}
+}
+
 func TestRunSuccess(t *testing.T) {
 	cmd, ctx, logHook := setup()
 
@@ -113,6 +148,8 @@ func TestRunSuccess(t *testing.T) {
 
 	ctx.Message.UserID = "U001"
 
+	mockClient.EXPECT().CanonicalEmoji(":fake:").Return("fake", true)
+
 	expectEmoji := models.Emoji{
 		User:  ctx.Message.UserID,
 		Emoji: ":fake:",
@@ -124,7 +161,17 @@ func TestRunSuccess(t *testing.T) {
 		Count: 1,
 	}
 
+	expectUsage := models.EmojiUsage{
+		User:    ctx.Message.UserID,
+		Emoji:   ":fake:",
+		Channel: "C001",
+		Day:     time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Count:   1,
+	}
+
+	mockClient.EXPECT().ParseTimestamp(ctx.Message.Timestamp).Return(time.Unix(1614556800, 0), nil)
 	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, false).Return(updatedEmoji, nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(expectUsage, false).Return(models.EmojiUsage{}, nil)
 	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
 
 	if _, err := cmd.Run([][]string{{":fake:", ":fake:"}}, ctx); err != nil {
@@ -134,3 +181,43 @@ func TestRunSuccess(t *testing.T) {
 	expectedLog := `emoji usage update - User "Gorfbot" (U001) has used emoji ":fake:" (history: 2 times)`
 	test.ExpectLastLog(t, logHook, logrus.InfoLevel, expectedLog)
 }
+
+func TestRunCanonicalEmoji(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	ctx.Message.UserID = "U001"
+
+	// Aliases are counted as the canonical emoji and unknown emoji and skin tone
+	// modifiers aren't counted.
+	expectEmoji := models.Emoji{
+		User:  ctx.Message.UserID,
+		Emoji: ":+1:",
+		Count: 1,
+	}
+
+	mockClient.EXPECT().CanonicalEmoji(":thumbsup:").Return("+1", true)
+	mockClient.EXPECT().CanonicalEmoji(":skin-tone-2:").Return("skin-tone-2", false)
+	mockClient.EXPECT().CanonicalEmoji(":notanemoji:").Return("notanemoji", false)
+	mockClient.EXPECT().ParseTimestamp(ctx.Message.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, false).Return(expectEmoji, nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).Return(models.EmojiUsage{}, nil)
+	mockClient.EXPECT().UserName(ctx.Message.UserID).Return("Gorfbot")
+
+	matches := [][]string{
+		{":thumbsup:", ":thumbsup:"},
+		{":skin-tone-2:", ":skin-tone-2:"},
+		{":notanemoji:", ":notanemoji:"},
+	}
+
+	if _, err := cmd.Run(matches, ctx); err != nil {
+		t.Errorf("unexpected err from run: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package botcmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+// ErrPollNotFound is returned by ReloadPoll if the poll isn't in storage.
+var ErrPollNotFound = errors.New("poll not found")
+
+// PollEmoji are the reactions (no ":" delimiters) used to vote for the options
+// of a poll, in order. Polls can't have more options than there are emoji.
+var PollEmoji = []string{
+	"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "keycap_ten",
+}
+
+// PollOption returns the index of the poll option that the reaction (no ":"
+// delimiters) votes for. It returns false if the reaction isn't a vote.
+func PollOption(reaction string) (int, bool) {
+	for i, emoji := range PollEmoji {
+		if reaction == emoji {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// ReloadPoll returns the poll as it is in storage now, e.g. to include votes
+// made since it was loaded. On error it returns the given poll unchanged.
+func ReloadPoll(store storage.Storage, poll models.Poll) (models.Poll, error) {
+	polls, err := store.GetPolls(storage.GetPollOptions{
+		FindOptions: storage.FindOptions{SortField: "timestamp", Limit: 1},
+		Channel:     poll.Channel,
+		Timestamp:   poll.Timestamp,
+	})
+	if err != nil {
+		return poll, fmt.Errorf("failed to reload %s: %w", poll, err)
+	} else if len(polls) == 0 {
+		return poll, fmt.Errorf("failed to reload %s: %w", poll, ErrPollNotFound)
+	}
+
+	return polls[0], nil
+}
+
+// PollResults returns a message announcing the results of a poll. The options
+// with the most votes are marked as the winners.
+func PollResults(poll models.Poll) string {
+	tally := poll.Tally()
+
+	var total, most int
+
+	for _, count := range tally {
+		total += count
+
+		if count > most {
+			most = count
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":bar_chart: Results for *%s* (%d %s):\n", poll.Question, total, plural(total, "vote"))
+
+	for i, option := range poll.Options {
+		fmt.Fprintf(&b, "\t:%s: %s - %d %s", PollEmoji[i], option, tally[i], plural(tally[i], "vote"))
+
+		if most > 0 && tally[i] == most {
+			b.WriteString(" :trophy:")
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// plural returns the noun with an "s" suffix unless count is 1.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return noun
+	}
+
+	return noun + "s"
+}
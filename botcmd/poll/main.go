@@ -0,0 +1,226 @@
+// Package poll provides a command for creating polls that are voted in with
+// reactions, and closing them. Votes are counted by the pollvote package and
+// polls with a deadline are closed by the bot.
+package poll
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "poll"
+
+	// timeLayout is the layout of poll deadlines.
+	timeLayout = "Mon Jan 2 2006 15:04 MST"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*poll*:\n" +
+		"\t`!poll \"<question>\" \"<option>\" \"<option>\"...` - e.g. `!poll \"Lunch?\" \"tacos\" \"pho\" \"pizza\"`\n" +
+		"\t`!poll <when> \"<question>\" \"<option>\"...` - close the poll automatically, " +
+		"e.g. `!poll in 2h \"Lunch?\" \"tacos\" \"pho\"`\n" +
+		"\t`!poll close` - close your latest poll in the channel and post the results\n" +
+		"\tVote by reacting to the poll with the number of an option."
+)
+
+type pollCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":bar_chart:",
+		Description: "Create a poll to vote in with reactji, or close one",
+		Handler:     &pollCmd{},
+	})
+}
+
+func (cmd *pollCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+
+	return nil
+}
+
+func (cmd pollCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "close":
+		if len(words) != 1 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.close(runCtx)
+	case "-h", "help":
+		return botcmd.RunResult{Message: usage}, nil
+	default:
+		return cmd.create(text, runCtx)
+	}
+}
+
+// create posts a new poll, seeds it with a reaction for each option and saves
+// it so that votes can be counted.
+//
+//nolint:funlen
+func (cmd pollCmd) create(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	args, ok := botcmd.SplitArgs(text)
+	if !ok {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: missing a closing quote", cmdName)}, nil
+	}
+
+	// Unquoted words before the question say when the poll closes.
+	var whenWords, quoted []string
+
+	for _, a := range args {
+		switch {
+		case a.Quoted:
+			quoted = append(quoted, a.Text)
+		case len(quoted) == 0:
+			whenWords = append(whenWords, a.Text)
+		default:
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: put the question and each option in \"quotes\"", cmdName),
+			}, nil
+		}
+	}
+
+	if len(quoted) < 3 {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: a poll needs a question and at least 2 options", cmdName),
+		}, nil
+	} else if len(quoted)-1 > len(botcmd.PollEmoji) {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: a poll can't have more than %d options", cmdName, len(botcmd.PollEmoji)),
+		}, nil
+	}
+
+	msg := runCtx.Message
+	poll := models.Poll{
+		Channel:  msg.ChannelID,
+		Creator:  msg.UserID,
+		Question: quoted[0],
+		Options:  quoted[1:],
+	}
+
+	if len(whenWords) > 0 {
+		loc := runCtx.Slack.UserLocation(msg.UserID)
+		now := botcmd.EventTime(runCtx.Slack, msg.Timestamp).In(loc)
+
+		deadline, rest, err := botcmd.ParseWhen(whenWords, now)
+		if err != nil {
+			return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+		} else if len(rest) > 0 {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: put the question and each option in \"quotes\"", cmdName),
+			}, nil
+		}
+
+		poll.Deadline = deadline.UTC()
+	}
+
+	posted, err := runCtx.Slack.PostMessage(cmd.pollMessage(poll, runCtx), msg.ChannelID)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to post poll: %w", cmdName, err)
+	}
+
+	poll.Timestamp = posted.Timestamp
+
+	if err := runCtx.Storage.AddPoll(poll); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to add poll: %w", cmdName, err)
+	}
+
+	log := runCtx.Logger(cmd.log)
+	log.Infof("Added %s", poll)
+
+	for i := range poll.Options {
+		if err := runCtx.Slack.AddReaction(botcmd.PollEmoji[i], posted); err != nil {
+			log.Errorf("%s: failed to add poll reaction: %v", cmdName, err)
+		}
+	}
+
+	return botcmd.RunResult{}, nil
+}
+
+// pollMessage returns the message that is posted for a poll.
+func (cmd pollCmd) pollMessage(poll models.Poll, runCtx botcmd.RunContext) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":bar_chart: *%s* - poll by _%s_", poll.Question, runCtx.Slack.UserName(poll.Creator))
+
+	if !poll.Deadline.IsZero() {
+		loc := runCtx.Slack.UserLocation(poll.Creator)
+		fmt.Fprintf(&b, ", closes %s", poll.Deadline.In(loc).Format(timeLayout))
+	}
+
+	b.WriteString("\n")
+
+	for i, option := range poll.Options {
+		fmt.Fprintf(&b, "\t:%s: %s\n", botcmd.PollEmoji[i], option)
+	}
+
+	b.WriteString("React with the number of an option to vote.")
+
+	return b.String()
+}
+
+// close closes the latest open poll created by the user in the channel and
+// returns its results.
+func (cmd pollCmd) close(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	notFound := botcmd.RunResult{Message: ":shrug: You don't have an open poll in this channel"}
+
+	opts := storage.GetPollOptions{
+		FindOptions: storage.FindOptions{Limit: 1, SortField: "timestamp"},
+		Channel:     runCtx.Message.ChannelID,
+		Creator:     runCtx.Message.UserID,
+		Open:        true,
+	}
+
+	polls, err := runCtx.Storage.GetPolls(opts)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get polls from storage opts: %v err: %w",
+			cmdName, opts, err)
+	}
+
+	if len(polls) == 0 {
+		return notFound, nil
+	}
+
+	poll := polls[0]
+
+	closed, err := runCtx.Storage.ClosePoll(poll.Channel, poll.Timestamp)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to close poll: %w", cmdName, err)
+	}
+
+	// The poll reached its deadline and was closed while we were looking for
+	// it.
+	if !closed {
+		return notFound, nil
+	}
+
+	log := runCtx.Logger(cmd.log)
+
+	// Get the poll again to include any votes made before it was closed. The
+	// poll is closed either way, so its results are posted even if that fails.
+	if poll, err = botcmd.ReloadPoll(runCtx.Storage, poll); err != nil {
+		log.Errorf("%s: results may be missing the latest votes: %v", cmdName, err)
+	}
+
+	log.Infof("Closed %s", poll)
+
+	return botcmd.RunResult{Message: botcmd.PollResults(poll)}, nil
+}
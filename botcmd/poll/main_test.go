@@ -0,0 +1,225 @@
+//nolint:goerr113
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+var now = time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC)
+
+func setup(t *testing.T) (*pollCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &pollCmd{log: log}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001", Timestamp: "1600000000.000000"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().UserName("U001").Return("alice").AnyTimes()
+	mockClient.EXPECT().UserLocation("U001").Return(time.UTC).AnyTimes()
+	mockClient.EXPECT().ParseTimestamp("1600000000.000000").Return(now, nil).AnyTimes()
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd := &pollCmd{}
+
+	if _, err := cmd.Run("", botcmd.RunContext{}); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunBadInput(t *testing.T) {
+	testCases := map[string]string{
+		"":                   usage,
+		"help":               usage,
+		"close now":          usage,
+		`"Lunch?" "tacos`:    `poll: missing a closing quote`,
+		`"Lunch?" "tacos"`:   `poll: a poll needs a question and at least 2 options`,
+		`"Lunch?" tacos pho`: `poll: put the question and each option in "quotes"`,
+		`"?" "1" "2" "3" "4" "5" "6" "7" "8" "9" "10" "11"`: `poll: a poll can't have more than 10 options`,
+		`whenever "Lunch?" "tacos" "pho"`: `poll: can't understand when "whenever" is: try something like ` +
+			`"in 2h", "in 3 days", "tomorrow 9am", "friday at 5pm", "2006-01-02 13:30" or "noon"`,
+		`in 2h soon "Lunch?" "tacos" "pho"`: `poll: put the question and each option in "quotes"`,
+	}
+
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			cmd, ctx, _, _ := setup(t)
+
+			res, err := cmd.Run(input, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != expected {
+				t.Errorf("expected message %q, got %q", expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunCreate(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	expectedText := ":bar_chart: *Lunch?* - poll by _alice_, closes Sun Sep 13 2020 14:26 UTC\n" +
+		"\t:one: tacos\n" +
+		"\t:two: pho\n" +
+		"React with the number of an option to vote."
+	posted := &slack.Message{ChannelID: "C001", UserID: "B001", Text: expectedText, Timestamp: "1600000001.000000"}
+	expectedPoll := models.Poll{
+		Channel:   "C001",
+		Timestamp: "1600000001.000000",
+		Creator:   "U001",
+		Question:  "Lunch?",
+		Options:   []string{"tacos", "pho"},
+		Deadline:  now.Add(2 * time.Hour),
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().PostMessage(expectedText, "C001").Return(posted, nil),
+		mockStorage.EXPECT().AddPoll(expectedPoll).Return(nil),
+		mockClient.EXPECT().AddReaction("one", posted).Return(nil),
+		mockClient.EXPECT().AddReaction("two", posted).Return(errors.New("too many reactions")),
+	)
+
+	res, err := cmd.Run(`in 2h "Lunch?" "tacos" "pho"`, ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if res.Message != "" {
+		t.Errorf("expected no message, got %q", res.Message)
+	}
+}
+
+func TestRunCreateErrs(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().PostMessage(gomock.Any(), "C001").Return(nil, errors.New("slack is sleeping"))
+
+	if _, err := cmd.Run(`"Lunch?" "tacos" "pho"`, ctx); err == nil {
+		t.Errorf("expected err from Run with PostMessage err, got nil")
+	}
+
+	mockClient.EXPECT().PostMessage(gomock.Any(), "C001").Return(&slack.Message{}, nil)
+	mockStorage.EXPECT().AddPoll(gomock.Any()).Return(errors.New("storage is sleeping"))
+
+	if _, err := cmd.Run(`"Lunch?" "tacos" "pho"`, ctx); err == nil {
+		t.Errorf("expected err from Run with AddPoll err, got nil")
+	}
+}
+
+func TestRunClose(t *testing.T) {
+	openOpts := storage.GetPollOptions{
+		FindOptions: storage.FindOptions{Limit: 1, SortField: "timestamp"},
+		Channel:     "C001",
+		Creator:     "U001",
+		Open:        true,
+	}
+	finalOpts := storage.GetPollOptions{
+		FindOptions: storage.FindOptions{SortField: "timestamp", Limit: 1},
+		Channel:     "C001",
+		Timestamp:   "1.0",
+	}
+	poll := models.Poll{
+		Channel: "C001", Timestamp: "1.0", Creator: "U001", Question: "Lunch?", Options: []string{"tacos", "pho"},
+	}
+	final := poll
+	final.Votes = []models.PollVote{{User: "U002", Option: 0}}
+
+	notFound := ":shrug: You don't have an open poll in this channel"
+
+	t.Run("no open poll", func(t *testing.T) {
+		cmd, ctx, mockStorage, _ := setup(t)
+
+		mockStorage.EXPECT().GetPolls(openOpts).Return(nil, nil)
+
+		if res, err := cmd.Run("close", ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if res.Message != notFound {
+			t.Errorf("expected message %q, got %q", notFound, res.Message)
+		}
+	})
+
+	t.Run("already closed", func(t *testing.T) {
+		cmd, ctx, mockStorage, _ := setup(t)
+
+		mockStorage.EXPECT().GetPolls(openOpts).Return([]models.Poll{poll}, nil)
+		mockStorage.EXPECT().ClosePoll("C001", "1.0").Return(false, nil)
+
+		if res, err := cmd.Run("close", ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if res.Message != notFound {
+			t.Errorf("expected message %q, got %q", notFound, res.Message)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		cmd, ctx, mockStorage, _ := setup(t)
+
+		gomock.InOrder(
+			mockStorage.EXPECT().GetPolls(openOpts).Return([]models.Poll{poll}, nil),
+			mockStorage.EXPECT().ClosePoll("C001", "1.0").Return(true, nil),
+			mockStorage.EXPECT().GetPolls(finalOpts).Return([]models.Poll{final}, nil),
+		)
+
+		expected := botcmd.PollResults(final)
+
+		if res, err := cmd.Run("close", ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if res.Message != expected {
+			t.Errorf("expected message %q, got %q", expected, res.Message)
+		}
+	})
+
+	t.Run("reload err", func(t *testing.T) {
+		cmd, ctx, mockStorage, _ := setup(t)
+
+		// The poll is closed, so its results are posted without the latest votes.
+		gomock.InOrder(
+			mockStorage.EXPECT().GetPolls(openOpts).Return([]models.Poll{poll}, nil),
+			mockStorage.EXPECT().ClosePoll("C001", "1.0").Return(true, nil),
+			mockStorage.EXPECT().GetPolls(finalOpts).Return(nil, errors.New("storage is sleeping")),
+		)
+
+		expected := botcmd.PollResults(poll)
+
+		if res, err := cmd.Run("close", ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if res.Message != expected {
+			t.Errorf("expected message %q, got %q", expected, res.Message)
+		}
+	})
+
+	t.Run("storage err", func(t *testing.T) {
+		cmd, ctx, mockStorage, _ := setup(t)
+
+		mockStorage.EXPECT().GetPolls(openOpts).Return(nil, errors.New("storage is sleeping"))
+
+		if _, err := cmd.Run("close", ctx); err == nil {
+			t.Errorf("expected err from Run with storage err, got nil")
+		}
+	})
+}
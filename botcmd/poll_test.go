@@ -0,0 +1,104 @@
+package botcmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cpu/gorfbot/storage/memory"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+func TestPollOption(t *testing.T) {
+	if option, ok := PollOption("three"); !ok || option != 2 {
+		t.Errorf("expected :three: to vote for option 2, got %d %v", option, ok)
+	}
+
+	if option, ok := PollOption("keycap_ten"); !ok || option != 9 {
+		t.Errorf("expected :keycap_ten: to vote for option 9, got %d %v", option, ok)
+	}
+
+	if _, ok := PollOption("frog"); ok {
+		t.Errorf("expected :frog: not to be a vote")
+	}
+}
+
+func TestPollResults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		votes    []models.PollVote
+		expected string
+	}{
+		{
+			name: "no votes",
+			expected: ":bar_chart: Results for *Lunch?* (0 votes):\n" +
+				"\t:one: tacos - 0 votes\n" +
+				"\t:two: pho - 0 votes\n" +
+				"\t:three: pizza - 0 votes\n",
+		},
+		{
+			name: "winner",
+			votes: []models.PollVote{
+				{User: "U001", Option: 1},
+				{User: "U002", Option: 1},
+				{User: "U002", Option: 0},
+			},
+			expected: ":bar_chart: Results for *Lunch?* (3 votes):\n" +
+				"\t:one: tacos - 1 vote\n" +
+				"\t:two: pho - 2 votes :trophy:\n" +
+				"\t:three: pizza - 0 votes\n",
+		},
+		{
+			name: "tie",
+			votes: []models.PollVote{
+				{User: "U001", Option: 0},
+				{User: "U002", Option: 2},
+			},
+			expected: ":bar_chart: Results for *Lunch?* (2 votes):\n" +
+				"\t:one: tacos - 1 vote :trophy:\n" +
+				"\t:two: pho - 0 votes\n" +
+				"\t:three: pizza - 1 vote :trophy:\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			poll := models.Poll{
+				Question: "Lunch?",
+				Options:  []string{"tacos", "pho", "pizza"},
+				Votes:    tc.votes,
+			}
+
+			if results := PollResults(poll); results != tc.expected {
+				t.Errorf("expected results %q, got %q", tc.expected, results)
+			}
+		})
+	}
+}
+
+func TestReloadPoll(t *testing.T) {
+	store := memory.NewMemoryStorage()
+	poll := models.Poll{Channel: "C001", Timestamp: "1.0", Question: "Lunch?", Options: []string{"tacos", "pho"}}
+
+	if _, err := ReloadPoll(store, poll); !errors.Is(err, ErrPollNotFound) {
+		t.Errorf("expected ErrPollNotFound reloading a poll that isn't stored, got %v", err)
+	}
+
+	if err := store.AddPoll(poll); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	vote := models.PollVote{User: "U001", Option: 1}
+	if _, err := store.UpdatePollVote("C001", "1.0", vote, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	reloaded, err := ReloadPoll(store, poll)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := []models.PollVote{vote}; !reflect.DeepEqual(reloaded.Votes, expected) {
+		t.Errorf("expected reloaded poll to have votes %v, got %v", expected, reloaded.Votes)
+	}
+}
@@ -0,0 +1,65 @@
+// Package pollvote provides a reaction handler that counts votes in polls
+// created with the poll command.
+package pollvote
+
+import (
+	"fmt"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	handlerName = "poll votes"
+)
+
+type pollVoteHandler struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddReactionHandler(&botcmd.ReactionCommand{
+		Name:    handlerName,
+		Handler: &pollVoteHandler{},
+	})
+}
+
+func (h pollVoteHandler) Run(reaction *slack.Reaction, runCtx botcmd.RunContext) error {
+	// Only reactions to the bot's own messages can be votes (the item user may
+	// be empty for bot messages), and the bot's own reactions seeding a poll
+	// aren't votes.
+	botID := runCtx.Slack.BotID()
+	if reaction.ItemTimestamp == "" || reaction.User == botID ||
+		(reaction.ItemUser != "" && reaction.ItemUser != botID) {
+		return nil
+	}
+
+	option, ok := botcmd.PollOption(reaction.Reaction)
+	if !ok {
+		return nil
+	}
+
+	vote := models.PollVote{User: reaction.User, Option: option}
+
+	updated, err := runCtx.Storage.UpdatePollVote(
+		reaction.ItemChannel, reaction.ItemTimestamp, vote, reaction.Removed)
+	if err != nil {
+		return fmt.Errorf("%s storage returned err: %w", handlerName, err)
+	}
+
+	if updated {
+		runCtx.Logger(h.log).Infof("%s - %v (removed: %v) in poll %s",
+			handlerName, vote, reaction.Removed, reaction.ItemTimestamp)
+	}
+
+	return nil
+}
+
+func (h *pollVoteHandler) Configure(log *logrus.Logger, c *config.Config) error {
+	h.log = log
+
+	return nil
+}
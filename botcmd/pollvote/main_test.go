@@ -0,0 +1,100 @@
+//nolint:goerr113
+package pollvote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*pollVoteHandler, botcmd.RunContext, *mocks.MockStorage) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &pollVoteHandler{log: log}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockClient.EXPECT().BotID().Return("B001").AnyTimes()
+
+	ctx := botcmd.RunContext{
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage
+}
+
+func voteReaction() *slack.Reaction {
+	return &slack.Reaction{
+		User:          "U002",
+		Reaction:      "two",
+		ItemChannel:   "C001",
+		ItemUser:      "B001",
+		ItemTimestamp: "1600000001.000000",
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	cmd, ctx, _ := setup(t)
+
+	byBot := voteReaction()
+	byBot.User = "B001"
+
+	notBotMessage := voteReaction()
+	notBotMessage.ItemUser = "U001"
+
+	notVote := voteReaction()
+	notVote.Reaction = "frog"
+
+	notMessage := voteReaction()
+	notMessage.ItemTimestamp = ""
+
+	// None of the reactions are votes, so there are no storage calls.
+	for _, reaction := range []*slack.Reaction{byBot, notBotMessage, notVote, notMessage} {
+		if err := cmd.Run(reaction, ctx); err != nil {
+			t.Errorf("unexpected err for reaction %v: %v", reaction, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	cmd, ctx, mockStorage := setup(t)
+
+	removed := voteReaction()
+	removed.Removed = true
+
+	// The item user may be unknown for bot messages.
+	noItemUser := voteReaction()
+	noItemUser.ItemUser = ""
+
+	vote := models.PollVote{User: "U002", Option: 1}
+
+	gomock.InOrder(
+		mockStorage.EXPECT().UpdatePollVote("C001", "1600000001.000000", vote, false).Return(true, nil),
+		mockStorage.EXPECT().UpdatePollVote("C001", "1600000001.000000", vote, true).Return(true, nil),
+		mockStorage.EXPECT().UpdatePollVote("C001", "1600000001.000000", vote, false).Return(false, nil),
+		mockStorage.EXPECT().UpdatePollVote("C001", "1600000001.000000", vote, false).
+			Return(false, errors.New("storage is sleeping")),
+	)
+
+	for _, reaction := range []*slack.Reaction{voteReaction(), removed, noItemUser} {
+		if err := cmd.Run(reaction, ctx); err != nil {
+			t.Errorf("unexpected err for reaction %v: %v", reaction, err)
+		}
+	}
+
+	if err := cmd.Run(voteReaction(), ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	}
+}
@@ -0,0 +1,278 @@
+// Package quote provides a command for saving memorable messages to the quote
+// database and recalling them. Messages can also be saved by reacting to them
+// (see the quotereact package).
+package quote
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "quote"
+
+	// searchLimit is the maximum number of quotes listed by a search.
+	searchLimit = 5
+
+	// dateLayout is the layout of the date a quote was said.
+	dateLayout = "Mon Jan 2 2006"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*quote*:\n" +
+		"\t`!quote` - recall a random quote\n" +
+		"\t`!quote by @user` - recall a random quote said by a user\n" +
+		"\t`!quote search <text>` - list the latest quotes containing any of the words\n" +
+		"\t`!quote add <message link>` - save a message as a quote\n" +
+		"\tMessages can also be saved by reacting to them with :%s:."
+)
+
+var (
+	// mentionRegexp matches a Slack user mention like "<@U1234>" or
+	// "<@U1234|daniel>", capturing the user ID.
+	mentionRegexp = regexp.MustCompile(`^<@(\w+)(?:\|[^>]*)?>$`)
+	// permalinkRegexp matches a Slack message permalink, optionally wrapped in
+	// "<>" the way Slack sends links, capturing the channel ID and the seconds
+	// and microseconds of the message timestamp.
+	permalinkRegexp = regexp.MustCompile(
+		`^<?https://[\w.\-]+/archives/([A-Z0-9]+)/p(\d{10})(\d{6})(?:[?|][^>]*)?>?$`)
+	// latestFirst sorts quotes from the latest to the earliest. Every find needs
+	// a sort field since Mongo rejects sorting by an empty one.
+	latestFirst = storage.FindOptions{SortField: "timestamp"}
+)
+
+type quoteCmd struct {
+	log   *logrus.Logger
+	emoji string
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":speech_balloon:",
+		Description: "Save memorable messages and recall them",
+		Handler:     &quoteCmd{},
+	})
+}
+
+func (cmd *quoteCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	cmd.emoji = "speech_balloon"
+
+	if c != nil {
+		if emoji := strings.Trim(c.QuoteConf.Emoji, ":"); emoji != "" {
+			cmd.emoji = emoji
+		}
+
+		if c.QuoteConf.RandomSeed > 0 {
+			rand.Seed(c.QuoteConf.RandomSeed)
+		} else {
+			rand.Seed(time.Now().UnixNano())
+		}
+	}
+
+	return nil
+}
+
+func (cmd quoteCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return cmd.random(storage.GetQuoteOptions{FindOptions: latestFirst}, runCtx)
+	}
+
+	switch args := words[1:]; strings.ToLower(words[0]) {
+	case "by":
+		if len(args) != 1 {
+			return cmd.usage(), nil
+		}
+
+		author := userID(args[0], runCtx.Slack)
+		if author == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown user %q", cmdName, args[0]),
+			}, nil
+		}
+
+		return cmd.random(storage.GetQuoteOptions{FindOptions: latestFirst, Author: author}, runCtx)
+	case "search":
+		if len(args) == 0 {
+			return cmd.usage(), nil
+		}
+
+		return cmd.search(strings.Join(args, " "), runCtx)
+	case "add":
+		if len(args) != 1 {
+			return cmd.usage(), nil
+		}
+
+		return cmd.add(args[0], runCtx)
+	default:
+		return cmd.usage(), nil
+	}
+}
+
+func (cmd quoteCmd) usage() botcmd.RunResult {
+	return botcmd.RunResult{Message: fmt.Sprintf(usage, cmd.emoji)}
+}
+
+// userID returns the ID of the user given as a mention, a name or a name with
+// an "@" prefix. It returns "" for unknown users.
+func userID(user string, client slack.Client) string {
+	if m := mentionRegexp.FindStringSubmatch(user); m != nil {
+		return m[1]
+	}
+
+	return client.UserID(strings.TrimPrefix(user, "@"))
+}
+
+// quotes returns the quotes matching the options that can be recalled in the
+// channel of the message. Quotes from private channels, group DMs and DMs are
+// only recalled in the conversation they were said in.
+func quotes(opts storage.GetQuoteOptions, runCtx botcmd.RunContext) ([]models.Quote, error) {
+	quotes, err := runCtx.Storage.GetQuotes(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get quotes from storage opts: %v err: %w",
+			cmdName, opts, err)
+	}
+
+	visible := quotes[:0]
+
+	for _, quote := range quotes {
+		if quote.Channel == runCtx.Message.ChannelID || runCtx.Slack.ConversationPublic(quote.Channel) {
+			visible = append(visible, quote)
+		}
+	}
+
+	return visible, nil
+}
+
+// random recalls a random quote matching the options.
+func (cmd quoteCmd) random(opts storage.GetQuoteOptions, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	quotes, err := quotes(opts, runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	if len(quotes) == 0 && opts.Author != "" {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":shrug: No quotes by _%s_ yet", runCtx.Slack.UserName(opts.Author)),
+		}, nil
+	} else if len(quotes) == 0 {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":shrug: No quotes yet. React to a memorable message with :%s: to save it", cmd.emoji),
+		}, nil
+	}
+
+	quote := quotes[rand.Intn(len(quotes))] //nolint:gosec
+
+	return botcmd.RunResult{Message: formatQuote(quote, runCtx)}, nil
+}
+
+// search lists the latest quotes containing any of the words of the search.
+func (cmd quoteCmd) search(search string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	// The limit is applied after leaving out quotes that can't be recalled here.
+	quotes, err := quotes(storage.GetQuoteOptions{
+		FindOptions: latestFirst,
+		Search:      search,
+	}, runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	if len(quotes) > searchLimit {
+		quotes = quotes[:searchLimit]
+	}
+
+	if len(quotes) == 0 {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":shrug: No quotes matching %q", search),
+		}, nil
+	}
+
+	buf := new(bytes.Buffer)
+
+	for i, quote := range quotes {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(formatQuote(quote, runCtx))
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
+
+// add saves the message with the given permalink as a quote.
+func (cmd quoteCmd) add(link string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	m := permalinkRegexp.FindStringSubmatch(link)
+	if m == nil {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: %q isn't a message link. Use \"Copy link\" on the message to get one",
+				cmdName, link),
+		}, nil
+	}
+
+	channelID, timestamp := m[1], m[2]+"."+m[3]
+
+	msg, err := runCtx.Slack.GetMessage(channelID, timestamp)
+	if err != nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s: failed to get message %s in %s: %w", cmdName, timestamp, channelID, err)
+	}
+
+	if msg.Text == "" {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: that message has no text to quote", cmdName),
+		}, nil
+	}
+
+	added, err := runCtx.Storage.AddQuote(models.Quote{
+		Channel:   msg.ChannelID,
+		Timestamp: msg.Timestamp,
+		Author:    msg.UserID,
+		Text:      msg.Text,
+		AddedBy:   runCtx.Message.UserID,
+	})
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to add quote: %w", cmdName, err)
+	}
+
+	if !added {
+		return botcmd.RunResult{Message: ":shrug: That message is already a quote"}, nil
+	}
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":floppy_disk: Saved a quote from _%s_", runCtx.Slack.UserName(msg.UserID)),
+	}, nil
+}
+
+// formatQuote returns the quote's text as a Slack quote followed by who said
+// it, where and when.
+func formatQuote(quote models.Quote, runCtx botcmd.RunContext) string {
+	var b strings.Builder
+
+	for _, line := range strings.Split(quote.Text, "\n") {
+		fmt.Fprintf(&b, "> %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "— _%s_ in *#%s* on %s",
+		runCtx.Slack.UserName(quote.Author),
+		runCtx.Slack.ConversationName(quote.Channel),
+		botcmd.EventTime(runCtx.Slack, quote.Timestamp).UTC().Format(dateLayout))
+
+	return b.String()
+}
@@ -0,0 +1,289 @@
+//nolint:goerr113
+package quote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*quoteCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &quoteCmd{}
+
+	if err := cmd.Configure(log, &config.Config{QuoteConf: config.QuoteConfig{RandomSeed: 1}}); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U002"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().UserName("U001").Return("alice").AnyTimes()
+	mockClient.EXPECT().UserID("alice").Return("U001").AnyTimes()
+	mockClient.EXPECT().UserID("nobody").Return("").AnyTimes()
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+	mockClient.EXPECT().ConversationPublic("C002").Return(true).AnyTimes()
+	mockClient.EXPECT().ConversationName("C002").Return("random").AnyTimes()
+	mockClient.EXPECT().ConversationPublic("D001").Return(false).AnyTimes()
+	mockClient.EXPECT().ParseTimestamp(gomock.Any()).
+		Return(time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC), nil).AnyTimes()
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+var testQuote = models.Quote{
+	Channel:   "C001",
+	Timestamp: "1600000000.000000",
+	Author:    "U001",
+	Text:      "gorf is a frog\nribbit",
+	AddedBy:   "U003",
+}
+
+const testQuoteMessage = "> gorf is a frog\n> ribbit\n— _alice_ in *#general* on Sun Sep 13 2020"
+
+// publicQuote is a quote from a public channel other than the one the command
+// is run in.
+var publicQuote = models.Quote{
+	Channel:   "C002",
+	Timestamp: "1600000000.000000",
+	Author:    "U001",
+	Text:      "ribbit",
+}
+
+const publicQuoteMessage = "> ribbit\n— _alice_ in *#random* on Sun Sep 13 2020"
+
+// privateQuote is a quote from a DM that can't be recalled in other channels.
+var privateQuote = models.Quote{
+	Channel:   "D001",
+	Timestamp: "1600000000.000000",
+	Author:    "U001",
+	Text:      "a secret",
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd := &quoteCmd{}
+
+	if _, err := cmd.Run("", botcmd.RunContext{}); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	mockStorage.EXPECT().GetQuotes(storage.GetQuoteOptions{FindOptions: latestFirst}).Return(nil, errors.New("data is dead"))
+
+	expectedErr := fmt.Sprintf(
+		"quote: failed to get quotes from storage opts: %v err: data is dead", storage.GetQuoteOptions{FindOptions: latestFirst})
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	searchOpts := storage.GetQuoteOptions{
+		FindOptions: latestFirst,
+		Search:      "gorf frog",
+	}
+
+	testCases := []struct {
+		name            string
+		input           string
+		expectOpts      *storage.GetQuoteOptions
+		results         []models.Quote
+		expectedMessage string
+	}{
+		{
+			name:            "usage",
+			input:           "help",
+			expectedMessage: fmt.Sprintf(usage, "speech_balloon"),
+		},
+		{
+			name:            "by without user",
+			input:           "by",
+			expectedMessage: fmt.Sprintf(usage, "speech_balloon"),
+		},
+		{
+			name:            "no quotes",
+			input:           "",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst},
+			expectedMessage: ":shrug: No quotes yet. React to a memorable message with :speech_balloon: to save it",
+		},
+		{
+			name:            "random",
+			input:           "",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst},
+			results:         []models.Quote{testQuote},
+			expectedMessage: testQuoteMessage,
+		},
+		{
+			name:            "by user",
+			input:           "by @alice",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst, Author: "U001"},
+			results:         []models.Quote{testQuote},
+			expectedMessage: testQuoteMessage,
+		},
+		{
+			name:            "by user without quotes",
+			input:           "by alice",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst, Author: "U001"},
+			expectedMessage: ":shrug: No quotes by _alice_ yet",
+		},
+		{
+			name:            "by mention",
+			input:           "by <@U001>",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst, Author: "U001"},
+			results:         []models.Quote{testQuote},
+			expectedMessage: testQuoteMessage,
+		},
+		{
+			name:            "by unknown user",
+			input:           "by nobody",
+			expectedMessage: `quote: unknown user "nobody"`,
+		},
+		{
+			name:            "search without results",
+			input:           "search gorf frog",
+			expectOpts:      &searchOpts,
+			expectedMessage: `:shrug: No quotes matching "gorf frog"`,
+		},
+		{
+			name:            "search",
+			input:           "search gorf frog",
+			expectOpts:      &searchOpts,
+			results:         []models.Quote{testQuote, testQuote},
+			expectedMessage: testQuoteMessage + "\n" + testQuoteMessage,
+		},
+		{
+			name:            "search limit",
+			input:           "search gorf frog",
+			expectOpts:      &searchOpts,
+			results:         []models.Quote{testQuote, testQuote, testQuote, testQuote, testQuote, testQuote},
+			expectedMessage: strings.Repeat(testQuoteMessage+"\n", searchLimit-1) + testQuoteMessage,
+		},
+		{
+			name:            "search leaves out private quotes from elsewhere",
+			input:           "search gorf frog",
+			expectOpts:      &searchOpts,
+			results:         []models.Quote{privateQuote, publicQuote, testQuote},
+			expectedMessage: publicQuoteMessage + "\n" + testQuoteMessage,
+		},
+		{
+			name:            "random only private quotes from elsewhere",
+			input:           "",
+			expectOpts:      &storage.GetQuoteOptions{FindOptions: latestFirst},
+			results:         []models.Quote{privateQuote},
+			expectedMessage: ":shrug: No quotes yet. React to a memorable message with :speech_balloon: to save it",
+		},
+		{
+			name:            "add bad link",
+			input:           "add gorf",
+			expectedMessage: `quote: "gorf" isn't a message link. Use "Copy link" on the message to get one`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, _ := setup(t)
+
+			if tc.expectOpts != nil {
+				mockStorage.EXPECT().GetQuotes(*tc.expectOpts).Return(tc.results, nil)
+			}
+
+			res, err := cmd.Run(tc.input, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expectedMessage {
+				t.Errorf("expected message %q, got %q", tc.expectedMessage, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunAdd(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	msg := &slack.Message{
+		ChannelID: "C001",
+		UserID:    "U001",
+		Text:      "gorf is a frog",
+		Timestamp: "1600000000.000100",
+	}
+	expectedQuote := models.Quote{
+		Channel:   "C001",
+		Timestamp: "1600000000.000100",
+		Author:    "U001",
+		Text:      "gorf is a frog",
+		AddedBy:   "U002",
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().GetMessage("C001", "1600000000.000100").Return(msg, nil),
+		mockStorage.EXPECT().AddQuote(expectedQuote).Return(true, nil),
+		mockClient.EXPECT().GetMessage("C001", "1600000000.000100").Return(msg, nil),
+		mockStorage.EXPECT().AddQuote(expectedQuote).Return(false, nil),
+	)
+
+	link := "<https://gorf.slack.com/archives/C001/p1600000000000100>"
+	expected := []string{
+		":floppy_disk: Saved a quote from _alice_",
+		":shrug: That message is already a quote",
+	}
+
+	for _, expectedMessage := range expected {
+		res, err := cmd.Run("add "+link, ctx)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if res.Message != expectedMessage {
+			t.Errorf("expected message %q, got %q", expectedMessage, res.Message)
+		}
+	}
+}
+
+func TestRunAddNoText(t *testing.T) {
+	cmd, ctx, _, mockClient := setup(t)
+
+	mockClient.EXPECT().GetMessage("C001", "1600000000.000100").Return(&slack.Message{
+		ChannelID: "C001",
+		UserID:    "U001",
+		Timestamp: "1600000000.000100",
+	}, nil)
+
+	res, err := cmd.Run("add https://gorf.slack.com/archives/C001/p1600000000000100", ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := "quote: that message has no text to quote"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+}
@@ -0,0 +1,86 @@
+// Package quotereact provides a reaction handler that saves messages reacted
+// to with the quote emoji to the quote database.
+package quotereact
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	handlerName = "quotes"
+
+	defaultEmoji = "speech_balloon"
+	// savedReactji is added to messages when they are saved as a quote.
+	savedReactji = "floppy_disk"
+)
+
+type quoteReactHandler struct {
+	log   *logrus.Logger
+	emoji string
+}
+
+func init() {
+	botcmd.MustAddReactionHandler(&botcmd.ReactionCommand{
+		Name:    handlerName,
+		Handler: &quoteReactHandler{},
+	})
+}
+
+func (h quoteReactHandler) Run(reaction *slack.Reaction, runCtx botcmd.RunContext) error {
+	// Removing the reaction doesn't remove the quote.
+	if reaction.Removed || reaction.ItemTimestamp == "" || reaction.Reaction != h.emoji {
+		return nil
+	}
+
+	original, err := runCtx.Slack.GetMessage(reaction.ItemChannel, reaction.ItemTimestamp)
+	if err != nil {
+		return fmt.Errorf("%s handler error getting message: %w", handlerName, err)
+	}
+
+	if original.Text == "" {
+		return nil
+	}
+
+	quote := models.Quote{
+		Channel:   original.ChannelID,
+		Timestamp: original.Timestamp,
+		Author:    original.UserID,
+		Text:      original.Text,
+		AddedBy:   reaction.User,
+	}
+
+	added, err := runCtx.Storage.AddQuote(quote)
+	if err != nil {
+		return fmt.Errorf("%s storage returned err: %w", handlerName, err)
+	} else if !added {
+		return nil
+	}
+
+	runCtx.Logger(h.log).Infof("%s - saved quote %s", handlerName, quote)
+
+	if err := runCtx.Slack.AddReaction(savedReactji, original); err != nil {
+		return fmt.Errorf("%s handler error adding reaction: %w", handlerName, err)
+	}
+
+	return nil
+}
+
+func (h *quoteReactHandler) Configure(log *logrus.Logger, c *config.Config) error {
+	h.log = log
+	h.emoji = defaultEmoji
+
+	if c != nil {
+		if emoji := strings.Trim(c.QuoteConf.Emoji, ":"); emoji != "" {
+			h.emoji = emoji
+		}
+	}
+
+	return nil
+}
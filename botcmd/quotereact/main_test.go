@@ -0,0 +1,158 @@
+//nolint:goerr113
+package quotereact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*quoteReactHandler, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &quoteReactHandler{}
+
+	if err := cmd.Configure(log, &config.Config{}); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func quoteReaction() *slack.Reaction {
+	return &slack.Reaction{
+		User:          "U002",
+		Reaction:      "speech_balloon",
+		Timestamp:     "1600000010.000000",
+		ItemChannel:   "C001",
+		ItemUser:      "U001",
+		ItemTimestamp: "1600000001.000000",
+	}
+}
+
+func original() *slack.Message {
+	return &slack.Message{
+		ChannelID: "C001",
+		UserID:    "U001",
+		Text:      "gorf is a frog",
+		Timestamp: "1600000001.000000",
+	}
+}
+
+func TestConfigureEmoji(t *testing.T) {
+	cmd := &quoteReactHandler{}
+
+	err := cmd.Configure(nil, &config.Config{
+		QuoteConf: config.QuoteConfig{Emoji: ":star:"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	if cmd.emoji != "star" {
+		t.Errorf("expected emoji %q, got %q", "star", cmd.emoji)
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	cmd, ctx, _, _ := setup(t)
+
+	removed := quoteReaction()
+	removed.Removed = true
+
+	other := quoteReaction()
+	other.Reaction = "star"
+
+	notMessage := quoteReaction()
+	notMessage.ItemTimestamp = ""
+
+	// None of the reactions are handled, so there are no mock calls.
+	for _, reaction := range []*slack.Reaction{removed, other, notMessage} {
+		if err := cmd.Run(reaction, ctx); err != nil {
+			t.Errorf("unexpected err for reaction %v: %v", reaction, err)
+		}
+	}
+}
+
+func TestRunGetMessageErr(t *testing.T) {
+	cmd, ctx, _, mockClient := setup(t)
+
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(nil, errors.New("no such message"))
+
+	if err := cmd.Run(quoteReaction(), ctx); err == nil {
+		t.Errorf("expected err from Run with GetMessage err, got nil")
+	}
+}
+
+func TestRunNoText(t *testing.T) {
+	cmd, ctx, _, mockClient := setup(t)
+
+	// Messages without text (e.g. only a file) aren't saved.
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").
+		Return(&slack.Message{ChannelID: "C001", UserID: "U001", Timestamp: "1600000001.000000"}, nil)
+
+	if err := cmd.Run(quoteReaction(), ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(original(), nil)
+	mockStorage.EXPECT().AddQuote(gomock.Any()).Return(false, errors.New("data is dead"))
+
+	expectedErr := "quotes storage returned err: data is dead"
+
+	if err := cmd.Run(quoteReaction(), ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRun(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	expectedQuote := models.Quote{
+		Channel:   "C001",
+		Timestamp: "1600000001.000000",
+		Author:    "U001",
+		Text:      "gorf is a frog",
+		AddedBy:   "U002",
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(original(), nil),
+		mockStorage.EXPECT().AddQuote(expectedQuote).Return(true, nil),
+		mockClient.EXPECT().AddReaction(savedReactji, original()).Return(nil),
+		// The second reaction to the same message doesn't save it again.
+		mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(original(), nil),
+		mockStorage.EXPECT().AddQuote(expectedQuote).Return(false, nil),
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := cmd.Run(quoteReaction(), ctx); err != nil {
+			t.Errorf("unexpected err: %v", err)
+		}
+	}
+}
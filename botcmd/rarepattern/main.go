@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/config"
@@ -27,11 +28,7 @@ type urlPattern struct {
 	Reactji    []string
 }
 
-func (u urlPattern) Matches(log *logrus.Logger, url *url.URL) string {
-	if u.PathRegexp == nil {
-		return "" // Shouldn't happen
-	}
-
+func (u urlPattern) Matches(log *logrus.Entry, url *url.URL) string {
 	if !u.HostRegexp.MatchString(url.Host) {
 		log.Tracef("URL Host %q doesn't match HostRegexp %q",
 			url.Host, u.HostRegexp)
@@ -59,6 +56,15 @@ type rareURLPattern struct {
 	log         *logrus.Logger
 	urlPatterns []urlPattern
 	config      config.URLsConfig
+
+	mu sync.Mutex
+	// storedPatterns are the compiled patterns of the URL rules loaded from
+	// storage, if loaded is true.
+	storedPatterns []urlPattern
+	loaded         bool
+	// storedVersion is the botcmd.URLRulesVersion the stored patterns were
+	// loaded at. They're loaded again when it changes.
+	storedVersion uint64
 }
 
 func init() {
@@ -80,13 +86,33 @@ func (e errBadMatches) Error() string {
 }
 
 //nolint:funlen
-func (p rareURLPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+func (p *rareURLPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
 	if len(allSubmatches) == 0 {
 		return botcmd.RunResult{}, errBadMatches{"expected at least one submatch", allSubmatches}
 	}
 
+	msg := runCtx.Message
+	if msg == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error: %w", patternName, botcmd.ErrNilMessage)
+	}
+
+	// Don't count URLs in commands (e.g. "!urls seen") or in the bot's own
+	// replies (e.g. to "!urls top").
+	if strings.HasPrefix(msg.Text, "!") || msg.UserID == runCtx.Slack.BotID() {
+		return botcmd.RunResult{}, nil
+	}
+
 	var messages []string
 
+	log := runCtx.Logger(p.log)
+	now := botcmd.EventTime(runCtx.Slack, msg.Timestamp)
+
+	urlPatterns, err := p.allURLPatterns(log, runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
 	reactionsMap := make(map[string]bool)
 
 	for _, submatches := range allSubmatches {
@@ -101,22 +127,25 @@ func (p rareURLPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext)
 
 		url, err := url.Parse(urlPart)
 		if err != nil || url == nil {
-			p.log.Warnf("%s pattern submatch part %q didn't parse as URL: %v",
+			log.Warnf("%s pattern submatch part %q didn't parse as URL: %v",
 				patternName, urlPart, err)
 			return botcmd.RunResult{}, nil //
 		}
 
-		p.log.Infof("%s pattern saw URL for Host %q Path %q",
+		log.Infof("%s pattern saw URL for Host %q Path %q",
 			patternName, url.Host, url.Path)
 
-		for _, urlPattern := range p.urlPatterns {
-			if collection := urlPattern.Matches(p.log, url); collection != "" {
-				// Clear out the query and fragment before storing the count
-				url.RawQuery = ""
-				url.Fragment = ""
+		for _, urlPattern := range urlPatterns {
+			if collection := urlPattern.Matches(log, url); collection != "" {
 				u := models.URLCount{
-					URL:         url.String(),
-					Occurrences: 1,
+					URL:            botcmd.CountedURL(*url),
+					Occurrences:    1,
+					FirstUser:      msg.UserID,
+					FirstChannel:   msg.ChannelID,
+					FirstPublic:    runCtx.Slack.ConversationPublic(msg.ChannelID),
+					FirstTimestamp: msg.Timestamp,
+					FirstSeen:      now,
+					LastSeen:       now,
 				}
 
 				updatedU, err := runCtx.Storage.UpsertURLCount(collection, u)
@@ -125,7 +154,7 @@ func (p rareURLPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext)
 						fmt.Errorf("%s storage returned err: %w", patternName, err)
 				}
 
-				p.log.Infof("%s update - collection %q matched URL %q (history: %d times)",
+				log.Infof("%s update - collection %q matched URL %q (history: %d times)",
 					patternName, collection, updatedU.URL, updatedU.Occurrences+1)
 
 				for _, r := range urlPattern.Reactji {
@@ -152,15 +181,63 @@ func (p rareURLPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext)
 	}, nil
 }
 
+// allURLPatterns returns the URL patterns from the config followed by the URL
+// rules in storage. Stored rules are only loaded and compiled again after they
+// change. Stored rules that don't compile, or that are for a collection the
+// config already has a pattern for, are skipped.
+func (p *rareURLPattern) allURLPatterns(log *logrus.Entry, runCtx botcmd.RunContext) ([]urlPattern, error) {
+	version := botcmd.URLRulesVersion()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.loaded || p.storedVersion != version {
+		stored, err := runCtx.Storage.GetURLRules()
+		if err != nil {
+			return nil, fmt.Errorf("%s pattern error getting URL rules: %w", patternName, err)
+		}
+
+		configured := make(map[string]bool, len(p.urlPatterns))
+		for _, u := range p.urlPatterns {
+			configured[u.Collection] = true
+		}
+
+		p.storedPatterns = make([]urlPattern, 0, len(stored))
+
+		for _, rule := range stored {
+			if configured[rule.Collection] {
+				log.Warnf("%s pattern skipping %s: collection is in the config", patternName, rule)
+
+				continue
+			}
+
+			urlPattern, err := newURLPattern(rule)
+			if err != nil {
+				log.Warnf("%s pattern skipping %s: %v", patternName, rule, err)
+
+				continue
+			}
+
+			p.storedPatterns = append(p.storedPatterns, urlPattern)
+		}
+
+		p.loaded, p.storedVersion = true, version
+	}
+
+	urlPatterns := make([]urlPattern, 0, len(p.urlPatterns)+len(p.storedPatterns))
+
+	return append(append(urlPatterns, p.urlPatterns...), p.storedPatterns...), nil
+}
+
 type errEmpty struct {
 	what string
 }
 
 func (e errEmpty) Error() string {
-	return fmt.Sprintf("error: URL in urlsconf with empty %s", e.what)
+	return fmt.Sprintf("error: URL rule with empty %s", e.what)
 }
 
-func newURLPattern(urlConf config.URLConfig) (urlPattern, error) {
+func newURLPattern(urlConf models.URLRule) (urlPattern, error) {
 	if urlConf.HostPattern == "" {
 		return urlPattern{}, errEmpty{"hostpattern"}
 	}
@@ -198,12 +275,18 @@ func newURLPattern(urlConf config.URLConfig) (urlPattern, error) {
 
 func (p *rareURLPattern) Configure(log *logrus.Logger, c *config.Config) error {
 	p.log = log
+
+	p.mu.Lock()
+	p.storedPatterns = nil
+	p.loaded = false
+	p.mu.Unlock()
+
 	if c != nil {
 		p.config = c.URLsConf
 
 		var urlPatterns []urlPattern
 
-		for _, urlConf := range c.URLsConf.URLs {
+		for _, urlConf := range botcmd.ConfigURLRules(c.URLsConf) {
 			urlPattern, err := newURLPattern(urlConf)
 			if err != nil {
 				return err
@@ -1 +1,230 @@
+//nolint:goerr113
 package rarepattern
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+
+	testCases := []struct {
+		name        string
+		urls        []config.URLConfig
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			urls: []config.URLConfig{
+				{HostPattern: `github\.com`, PathPattern: "/cpu/.*", Collection: "cpu_links"},
+				{HostPattern: `frog\.tips`, Collection: "frog_links"},
+			},
+		},
+		{
+			name:        "empty host pattern",
+			urls:        []config.URLConfig{{Collection: "frog_links"}},
+			expectedErr: true,
+		},
+		{
+			name:        "empty collection",
+			urls:        []config.URLConfig{{HostPattern: `frog\.tips`}},
+			expectedErr: true,
+		},
+		{
+			name:        "bad path pattern",
+			urls:        []config.URLConfig{{HostPattern: `frog\.tips`, PathPattern: "(", Collection: "frog_links"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := &rareURLPattern{}
+
+			err := p.Configure(log, &config.Config{URLsConf: config.URLsConfig{URLs: tc.urls}})
+			if tc.expectedErr && err == nil {
+				t.Errorf("expected err from Configure, got nil")
+			} else if !tc.expectedErr && err != nil {
+				t.Errorf("unexpected err from Configure: %v", err)
+			} else if !tc.expectedErr && len(p.urlPatterns) != len(tc.urls) {
+				t.Errorf("expected %d URL patterns, got %d", len(tc.urls), len(p.urlPatterns))
+			}
+		})
+	}
+}
+
+var testTime = time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+
+func setup(t *testing.T, urls []config.URLConfig) (*rareURLPattern, botcmd.RunContext, *mocks.MockStorage) {
+	log, _ := logtest.NewNullLogger()
+	p := &rareURLPattern{}
+
+	if err := p.Configure(log, &config.Config{URLsConf: config.URLsConfig{URLs: urls}}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001", ChannelID: "C001", Timestamp: "1609596000.000001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().BotID().Return("UBOT").AnyTimes()
+	mockClient.EXPECT().ConversationPublic("C001").Return(true).AnyTimes()
+	mockClient.EXPECT().ParseTimestamp("1609596000.000001").Return(testTime, nil).AnyTimes()
+
+	return p, ctx, mockStorage
+}
+
+// newCount returns the URLCount the test message upserts for a URL.
+func newCount(url string) models.URLCount {
+	return models.URLCount{
+		URL:            url,
+		Occurrences:    1,
+		FirstUser:      "U001",
+		FirstChannel:   "C001",
+		FirstPublic:    true,
+		FirstTimestamp: "1609596000.000001",
+		FirstSeen:      testTime,
+		LastSeen:       testTime,
+	}
+}
+
+func TestRun(t *testing.T) {
+	urls := []config.URLConfig{
+		{
+			HostPattern: `github\.com`,
+			PathPattern: `/cpu/gorfbot/issues/.*`,
+			Collection:  "gorfbot_issue_links",
+			FirstMsg:    "a new issue!",
+			Reactji:     []string{"bug"},
+		},
+	}
+
+	stored := []models.URLRule{
+		// A host only rule.
+		{HostPattern: `^frog\.tips$`, Collection: "frog_links", Reactji: []string{"frog"}, Creator: "U002"},
+		// A rule for a collection in the config is skipped.
+		{HostPattern: `.*`, Collection: "gorfbot_issue_links", Reactji: []string{"skull"}},
+		// A rule that doesn't compile is skipped.
+		{HostPattern: `(`, Collection: "broken_links"},
+	}
+
+	p, ctx, mockStorage := setup(t, urls)
+	mockStorage.EXPECT().GetURLRules().Return(stored, nil)
+	mockStorage.EXPECT().UpsertURLCount("gorfbot_issue_links", newCount("https://github.com/cpu/gorfbot/issues/1")).Return(models.URLCount{URL: "https://github.com/cpu/gorfbot/issues/1"}, nil)
+	mockStorage.EXPECT().UpsertURLCount("frog_links", newCount("https://frog.tips/api/1/tips")).Return(models.URLCount{URL: "https://frog.tips/api/1/tips", Occurrences: 3}, nil)
+
+	res, err := p.Run([][]string{
+		{"<https://github.com/cpu/gorfbot/issues/1?x=y#frag>", "https://github.com/cpu/gorfbot/issues/1?x=y#frag"},
+		{"<https://frog.tips/api/1/tips>", "https://frog.tips/api/1/tips"},
+		{"<https://example.com>", "https://example.com"},
+	}, ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := botcmd.RunResult{Message: "a new issue!", Reactji: []string{"bug", "frog"}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected result %#v, got %#v", expected, res)
+	}
+}
+
+func TestRunConfigHostOnly(t *testing.T) {
+	// A URL from the config without a path pattern matches every path on the
+	// host.
+	p, ctx, mockStorage := setup(t, []config.URLConfig{{HostPattern: `^frog\.tips$`, Collection: "frog_links"}})
+	mockStorage.EXPECT().GetURLRules().Return(nil, nil)
+	mockStorage.EXPECT().UpsertURLCount("frog_links", newCount("https://frog.tips/api/1/tips")).
+		Return(models.URLCount{URL: "https://frog.tips/api/1/tips"}, nil)
+
+	if _, err := p.Run([][]string{{"<https://frog.tips/api/1/tips>", "https://frog.tips/api/1/tips"}}, ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestRunRulesChanged(t *testing.T) {
+	p, ctx, mockStorage := setup(t, nil)
+	submatches := [][]string{{"<https://frog.tips>", "https://frog.tips"}}
+
+	// The stored rules are loaded once, and again after they change.
+	gomock.InOrder(
+		mockStorage.EXPECT().GetURLRules().Return(nil, nil),
+		mockStorage.EXPECT().GetURLRules().Return([]models.URLRule{
+			{HostPattern: `^frog\.tips$`, Collection: "frog_links", Reactji: []string{"frog"}},
+		}, nil),
+	)
+	mockStorage.EXPECT().UpsertURLCount("frog_links", newCount("https://frog.tips")).
+		Return(models.URLCount{URL: "https://frog.tips"}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		if res, err := p.Run(submatches, ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if len(res.Reactji) != 0 {
+			t.Errorf("expected no reactji before the rules changed, got %v", res.Reactji)
+		}
+	}
+
+	botcmd.URLRulesChanged()
+
+	for i := 0; i < 2; i++ {
+		if res, err := p.Run(submatches, ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if !reflect.DeepEqual(res.Reactji, []string{"frog"}) {
+			t.Errorf("expected reactji [frog] after the rules changed, got %v", res.Reactji)
+		}
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	p, ctx, mockStorage := setup(t, nil)
+	mockStorage.EXPECT().GetURLRules().Return(nil, errors.New("oops"))
+
+	if _, err := p.Run([][]string{{"<https://frog.tips>", "https://frog.tips"}}, ctx); err == nil {
+		t.Errorf("expected err from Run with a storage err, got nil")
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	p, ctx, _ := setup(t, []config.URLConfig{{HostPattern: `frog\.tips`, Collection: "frog_links"}})
+	submatches := [][]string{{"<https://frog.tips>", "https://frog.tips"}}
+
+	// URLs in commands and the bot's own messages aren't counted.
+	for _, msg := range []slack.Message{
+		{UserID: "U001", Text: "!urls seen <https://frog.tips>"},
+		{UserID: "UBOT", Text: "<https://frog.tips>"},
+	} {
+		msg := msg
+		ctx.Message = &msg
+
+		if res, err := p.Run(submatches, ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if !reflect.DeepEqual(res, botcmd.RunResult{}) {
+			t.Errorf("expected empty result for message %v, got %#v", msg, res)
+		}
+	}
+
+	ctx.Message = nil
+
+	if _, err := p.Run(submatches, ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
@@ -0,0 +1,66 @@
+package botcmd
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+// reactjiRulesVersion counts changes to the reactji rules in storage. Commands
+// changing the rules call ReactjiRulesChanged so handlers caching them know to
+// load them again.
+var reactjiRulesVersion uint64
+
+// ReactjiRulesVersion returns the version of the reactji rules in storage.
+func ReactjiRulesVersion() uint64 {
+	return atomic.LoadUint64(&reactjiRulesVersion)
+}
+
+// ReactjiRulesChanged records that the reactji rules in storage changed.
+func ReactjiRulesChanged() {
+	atomic.AddUint64(&reactjiRulesVersion, 1)
+}
+
+// ConfigReactjiRules returns the reactji rules described by the configuration:
+// a rule for each of the Keywords (sorted by keyword), then the Rules. Rules
+// from the configuration have no ID. Keywords match in any case, like they did
+// when messages were lowercased before looking them up.
+func ConfigReactjiRules(c config.ReactjiKeysConfig) []models.ReactjiRule {
+	keywords := make([]string, 0, len(c.Keywords))
+	for keyword := range c.Keywords {
+		keywords = append(keywords, keyword)
+	}
+
+	sort.Strings(keywords)
+
+	rules := make([]models.ReactjiRule, 0, len(keywords)+len(c.Rules))
+
+	for _, keyword := range keywords {
+		rules = append(rules, models.ReactjiRule{
+			Trigger:   keyword,
+			Reactions: c.Keywords[keyword],
+		})
+	}
+
+	for _, r := range c.Rules {
+		rule := models.ReactjiRule{
+			Trigger:       r.Trigger,
+			Regex:         r.Regex,
+			CaseSensitive: r.CaseSensitive,
+			Reactions:     r.Reactions,
+			Reply:         r.Reply,
+			Chance:        r.Chance,
+			Channels:      r.Channels,
+		}
+
+		if r.Cooldown != nil {
+			rule.Cooldown = *r.Cooldown
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -0,0 +1,341 @@
+// Package reactji provides a command for listing, adding and removing the
+// rules the reactjikeys package uses to react to messages.
+package reactji
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "reactji"
+
+	// minCooldown is the shortest cooldown of a rule with a regex trigger or a
+	// reply that a user who isn't an admin can add, so a rule like
+	// `-regex . "hi"` can't make the bot reply to every message.
+	minCooldown = 5 * time.Minute
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*reactji*:\n" +
+		"\t`!reactji list` - list the reactji rules\n" +
+		"\t`!reactji add [flags] <trigger> :emoji: ... [\"reply\"]` - react to (and optionally reply to) " +
+		"messages matching a word or \"a phrase\", e.g. `!reactji add -cooldown 1h turkey :turkey:`\n" +
+		"\t`!reactji remove <id>` - remove a rule you added\n" +
+		"\tFlags for add: `-regex` (the trigger is a regular expression), `-case` (match case), " +
+		"`-chance 0.5` (react half the time), `-cooldown 1h`, `-channels general,random`. " +
+		"Rules with `-regex` or a reply need a cooldown of at least 5m"
+)
+
+var (
+	// emojiRegexp matches an emoji like ":turkey:" or ":+1::skin-tone-2:".
+	emojiRegexp = regexp.MustCompile(`^:([\w+\-']+(?:::skin-tone-\d)?):$`)
+	// channelMention matches a Slack channel mention like "<#C001|general>".
+	channelMention = regexp.MustCompile(`^<#(C[A-Z0-9]+)(?:\|[^>]*)?>$`)
+)
+
+type reactjiCmd struct {
+	log  *logrus.Logger
+	conf *config.Config
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":robot_face:",
+		Description: "List, add and remove rules for reacting to messages",
+		Handler:     &reactjiCmd{},
+	})
+}
+
+func (cmd *reactjiCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	cmd.conf = c
+
+	return nil
+}
+
+func (cmd reactjiCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "list":
+		return cmd.list(runCtx)
+	case "add":
+		return cmd.add(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), words[0])), runCtx)
+	case "remove":
+		if len(words) != 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.remove(words[1], runCtx)
+	default:
+		return botcmd.RunResult{Message: usage}, nil
+	}
+}
+
+// list returns a message describing the rules from the config and the rules
+// in storage.
+func (cmd reactjiCmd) list(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	var rules []models.ReactjiRule
+
+	if cmd.conf != nil {
+		rules = botcmd.ConfigReactjiRules(cmd.conf.ReactjiKeysConf)
+	}
+
+	stored, err := runCtx.Storage.GetReactjiRules()
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get rules: %w", cmdName, err)
+	}
+
+	rules = append(rules, stored...)
+
+	if len(rules) == 0 {
+		return botcmd.RunResult{
+			Message: ":shrug: No reactji rules yet. Try `!reactji add hello :wave:`",
+		}, nil
+	}
+
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, ":robot_face: %d reactji rules:\n", len(rules))
+
+	for _, r := range rules {
+		id := r.ID
+		if id == "" {
+			id = "config"
+		}
+
+		fmt.Fprintf(&msg, "\t`%s` %s\n", id, describe(r))
+	}
+
+	return botcmd.RunResult{Message: msg.String()}, nil
+}
+
+// add adds a rule described by text like `-cooldown 1h turkey :turkey:`.
+//
+//nolint:funlen
+func (cmd reactjiCmd) add(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	flagSet := flag.NewFlagSet(cmdName+" add", flag.ContinueOnError)
+	regex := flagSet.Bool("regex", false, "the trigger is a regular expression")
+	caseSensitive := flagSet.Bool("case", false, "the trigger must match the case of messages")
+	chance := flagSet.Float64("chance", 0, "the probability (between 0 and 1) of reacting to a matching message")
+	cooldown := flagSet.Duration("cooldown", 0, "the minimum time between reacting (e.g. 30m, 1h)")
+	channels := flagSet.String("channels", "", "comma separated channel names to react in (default all)")
+
+	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
+		return botcmd.RunResult{Message: respText}, nil
+	}
+
+	args, ok := botcmd.SplitArgs(strings.Join(flagSet.Args(), " "))
+	if !ok {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: missing a closing quote", cmdName)}, nil
+	} else if len(args) == 0 {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	msg := runCtx.Message
+	rule := models.ReactjiRule{
+		ID:            ruleID(msg),
+		Trigger:       args[0].Text,
+		Regex:         *regex,
+		CaseSensitive: *caseSensitive,
+		Chance:        *chance,
+		Cooldown:      *cooldown,
+		Channels:      channelNames(*channels, runCtx.Slack),
+		Creator:       msg.UserID,
+	}
+
+	for _, a := range args[1:] {
+		if m := emojiRegexp.FindStringSubmatch(a.Text); m != nil && !a.Quoted {
+			rule.Reactions = append(rule.Reactions, m[1])
+		} else if a.Quoted && rule.Reply == "" {
+			rule.Reply = a.Text
+		} else {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: put reactions in :colons: and one reply in \"quotes\", not %q",
+					cmdName, a.Text),
+			}, nil
+		}
+	}
+
+	var problem string
+
+	if _, err := rule.Matcher(); err != nil || strings.TrimSpace(rule.Trigger) == "" {
+		problem = fmt.Sprintf("can't use %q as a trigger", rule.Trigger)
+	} else if len(rule.Reactions) == 0 && rule.Reply == "" {
+		problem = "a rule needs at least one :reaction: or a \"reply\""
+	} else if rule.Chance < 0 || rule.Chance > 1 {
+		problem = "the chance must be between 0 and 1"
+	} else if rule.Cooldown < 0 {
+		problem = "the cooldown can't be negative"
+	} else if (rule.Regex || rule.Reply != "") && rule.Cooldown < minCooldown && !cmd.isAdmin(msg.UserID, runCtx) {
+		problem = fmt.Sprintf("rules with -regex or a reply need a -cooldown of at least %s",
+			formatDuration(minCooldown))
+	}
+
+	if problem != "" {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %s", cmdName, problem)}, nil
+	}
+
+	if err := runCtx.Storage.AddReactjiRule(rule); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to add rule %v: %w", cmdName, rule, err)
+	}
+
+	botcmd.ReactjiRulesChanged()
+	runCtx.Logger(cmd.log).Infof("%s - added %s", cmdName, rule)
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":robot_face: Added reactji rule `%s`: %s", rule.ID, describe(rule)),
+	}, nil
+}
+
+// remove removes the stored rule with the given ID. Only the rule's creator
+// and admins can remove it.
+func (cmd reactjiCmd) remove(id string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	rules, err := runCtx.Storage.GetReactjiRules()
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get rules: %w", cmdName, err)
+	}
+
+	var (
+		rule  models.ReactjiRule
+		found bool
+	)
+
+	for _, r := range rules {
+		if r.ID == id {
+			rule, found = r, true
+
+			break
+		}
+	}
+
+	if !found {
+		return botcmd.RunResult{Message: fmt.Sprintf(":shrug: There's no reactji rule `%s`", id)}, nil
+	}
+
+	if rule.Creator != runCtx.Message.UserID && !cmd.isAdmin(runCtx.Message.UserID, runCtx) {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":no_entry: Sorry _%s_, only the rule's creator or admins can remove it",
+				runCtx.Slack.UserName(runCtx.Message.UserID)),
+		}, nil
+	}
+
+	if removed, err := runCtx.Storage.RemoveReactjiRule(id); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to remove rule %q: %w", cmdName, id, err)
+	} else if !removed {
+		return botcmd.RunResult{Message: fmt.Sprintf(":shrug: There's no reactji rule `%s`", id)}, nil
+	}
+
+	botcmd.ReactjiRulesChanged()
+	runCtx.Logger(cmd.log).Infof("%s - removed %s", cmdName, rule)
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":wastebasket: Removed reactji rule `%s`: %s", id, describe(rule)),
+	}, nil
+}
+
+// isAdmin returns true if the user with the given ID is an admin.
+func (cmd reactjiCmd) isAdmin(userID string, runCtx botcmd.RunContext) bool {
+	return cmd.conf != nil && cmd.conf.IsAdmin(runCtx.Slack.UserName(userID))
+}
+
+// describe returns a description of a rule like
+// "*turkey* :arrow_right: :turkey: (1h cooldown)".
+func describe(r models.ReactjiRule) string {
+	var desc strings.Builder
+
+	if r.Regex {
+		fmt.Fprintf(&desc, "`%s`", r.Trigger)
+	} else {
+		fmt.Fprintf(&desc, "*%s*", r.Trigger)
+	}
+
+	desc.WriteString(" :arrow_right:")
+
+	for _, reaction := range r.Reactions {
+		fmt.Fprintf(&desc, " :%s:", reaction)
+	}
+
+	if r.Reply != "" {
+		fmt.Fprintf(&desc, " %q", r.Reply)
+	}
+
+	var options []string
+
+	if r.CaseSensitive {
+		options = append(options, "match case")
+	}
+
+	if r.Chance > 0 && r.Chance < 1 {
+		options = append(options, fmt.Sprintf("%g%% chance", r.Chance*100))
+	}
+
+	if r.Cooldown > 0 {
+		options = append(options, formatDuration(r.Cooldown)+" cooldown")
+	}
+
+	if len(r.Channels) > 0 {
+		options = append(options, "in #"+strings.Join(r.Channels, ", #"))
+	}
+
+	if len(options) > 0 {
+		fmt.Fprintf(&desc, " (%s)", strings.Join(options, ", "))
+	}
+
+	return desc.String()
+}
+
+// formatDuration returns a duration like "1h30m" without the zero units
+// time.Duration's String adds (e.g. "1h30m0s").
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	s = strings.Replace(s, "m0s", "m", 1)
+
+	return strings.Replace(s, "h0m", "h", 1)
+}
+
+// channelNames returns the channel names from a comma separated list of
+// channel names or mentions.
+func channelNames(list string, client slack.Client) []string {
+	var names []string
+
+	for _, channel := range strings.Split(list, ",") {
+		channel = strings.TrimPrefix(strings.TrimSpace(channel), "#")
+
+		if m := channelMention.FindStringSubmatch(channel); m != nil {
+			channel = client.ConversationName(m[1])
+		}
+
+		if channel != "" {
+			names = append(names, channel)
+		}
+	}
+
+	return names
+}
+
+// ruleID returns a short identifier for a rule added by a message.
+func ruleID(msg *slack.Message) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg.ChannelID + msg.Timestamp))
+
+	return fmt.Sprintf("%06x", h.Sum32()&0xffffff)
+}
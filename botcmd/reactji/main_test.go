@@ -0,0 +1,251 @@
+//nolint:goerr113
+package reactji
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*reactjiCmd, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &reactjiCmd{}
+
+	if err := cmd.Configure(log, &config.Config{Admins: []string{"admin"}}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001", Timestamp: "1600000000.000001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+	mockClient.EXPECT().UserName("U002").Return("admin").AnyTimes()
+	mockClient.EXPECT().ConversationName("C002").Return("random").AnyTimes()
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd, ctx, _, _ := setup(t)
+	ctx.Message = nil
+
+	if _, err := cmd.Run("list", ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunAdd(t *testing.T) {
+	testCases := []struct {
+		name     string
+		user     string
+		text     string
+		expected string
+		rule     *models.ReactjiRule
+	}{
+		{name: "usage", text: "add", expected: usage},
+		{
+			name: "word",
+			text: "add -cooldown 1h turkey :turkey: :+1::skin-tone-2:",
+			rule: &models.ReactjiRule{
+				Trigger:   "turkey",
+				Reactions: []string{"turkey", "+1::skin-tone-2"},
+				Cooldown:  time.Hour,
+			},
+			expected: ":robot_face: Added reactji rule `0bf86f`: *turkey* :arrow_right: :turkey: :+1::skin-tone-2: " +
+				"(1h cooldown)",
+		},
+		{
+			name: "phrase",
+			text: `add -channels general,<#C002|random> -chance 0.25 -cooldown 5m “good morning” :sunny: "morning!"`,
+			rule: &models.ReactjiRule{
+				Trigger:   "good morning",
+				Reactions: []string{"sunny"},
+				Reply:     "morning!",
+				Chance:    0.25,
+				Cooldown:  5 * time.Minute,
+				Channels:  []string{"general", "random"},
+			},
+			expected: ":robot_face: Added reactji rule `0bf86f`: *good morning* :arrow_right: :sunny: " +
+				`"morning!" (25% chance, 5m cooldown, in #general, #random)`,
+		},
+		{
+			name:     "regex without cooldown",
+			text:     `add -regex . :frog:`,
+			expected: `reactji: rules with -regex or a reply need a -cooldown of at least 5m`,
+		},
+		{
+			name:     "reply with short cooldown",
+			text:     `add -cooldown 1m gorf "GORF"`,
+			expected: `reactji: rules with -regex or a reply need a -cooldown of at least 5m`,
+		},
+		{
+			name: "admin regex without cooldown",
+			user: "U002",
+			text: `add -regex -case ^ri+bbit$ "ribbit"`,
+			rule: &models.ReactjiRule{
+				Trigger:       "^ri+bbit$",
+				Regex:         true,
+				CaseSensitive: true,
+				Reply:         "ribbit",
+				Creator:       "U002",
+			},
+			expected: ":robot_face: Added reactji rule `0bf86f`: `^ri+bbit$` :arrow_right: \"ribbit\" (match case)",
+		},
+		{name: "bad regex", text: `add -regex ( :frog:`, expected: `reactji: can't use "(" as a trigger`},
+		{name: "nothing to do", text: `add gorf`, expected: `reactji: a rule needs at least one :reaction: or a "reply"`},
+		{name: "bad chance", text: `add -chance 2 gorf :frog:`, expected: `reactji: the chance must be between 0 and 1`},
+		{
+			name:     "unquoted words",
+			text:     `add good morning :sunny:`,
+			expected: `reactji: put reactions in :colons: and one reply in "quotes", not "morning"`,
+		},
+		{name: "missing quote", text: `add "good morning :sunny:`, expected: `reactji: missing a closing quote`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, _ := setup(t)
+
+			if tc.user != "" {
+				ctx.Message.UserID = tc.user
+			}
+
+			if tc.rule != nil {
+				tc.rule.ID = "0bf86f"
+				if tc.rule.Creator == "" {
+					tc.rule.Creator = "U001"
+				}
+
+				mockStorage.EXPECT().AddReactjiRule(gomock.Eq(*tc.rule)).Return(nil)
+			}
+
+			res, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunList(t *testing.T) {
+	cmd, ctx, mockStorage, _ := setup(t)
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, nil)
+
+	expected := ":shrug: No reactji rules yet. Try `!reactji add hello :wave:`"
+
+	if res, err := cmd.Run("list", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	cmd.conf.ReactjiKeysConf.Keywords = map[string][]string{"hello": {"wave"}}
+
+	mockStorage.EXPECT().GetReactjiRules().Return([]models.ReactjiRule{
+		{ID: "abc123", Trigger: "gorf", Reactions: []string{"frog"}},
+	}, nil)
+
+	expected = ":robot_face: 2 reactji rules:\n" +
+		"\t`config` *hello* :arrow_right: :wave:\n" +
+		"\t`abc123` *gorf* :arrow_right: :frog:\n"
+
+	if res, err := cmd.Run("list", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, errors.New("data is dead"))
+
+	if _, err := cmd.Run("list", ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	}
+}
+
+func TestRunRemove(t *testing.T) {
+	rules := []models.ReactjiRule{
+		{ID: "abc123", Trigger: "gorf", Reactions: []string{"frog"}, Creator: "U001"},
+		{ID: "def456", Trigger: "toad", Reactions: []string{"frog"}, Creator: "U003"},
+	}
+
+	testCases := []struct {
+		name     string
+		user     string
+		id       string
+		removed  bool
+		expected string
+	}{
+		{
+			name:     "creator",
+			user:     "U001",
+			id:       "abc123",
+			removed:  true,
+			expected: ":wastebasket: Removed reactji rule `abc123`: *gorf* :arrow_right: :frog:",
+		},
+		{
+			name:     "admin",
+			user:     "U002",
+			id:       "def456",
+			removed:  true,
+			expected: ":wastebasket: Removed reactji rule `def456`: *toad* :arrow_right: :frog:",
+		},
+		{
+			name:     "other user",
+			user:     "U001",
+			id:       "def456",
+			expected: ":no_entry: Sorry _daniel_, only the rule's creator or admins can remove it",
+		},
+		{
+			name:     "unknown",
+			user:     "U001",
+			id:       "zzz",
+			expected: ":shrug: There's no reactji rule `zzz`",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, _ := setup(t)
+			ctx.Message.UserID = tc.user
+
+			mockStorage.EXPECT().GetReactjiRules().Return(rules, nil)
+
+			if tc.removed {
+				mockStorage.EXPECT().RemoveReactjiRule(tc.id).Return(true, nil)
+			}
+
+			res, err := cmd.Run("remove "+tc.id, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package botcmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+func TestConfigReactjiRules(t *testing.T) {
+	oneHour := time.Hour
+	conf := config.ReactjiKeysConfig{
+		Keywords: map[string][]string{
+			"party": {"tada"},
+			"gorf":  {"frog"},
+		},
+		Rules: []config.ReactjiRule{
+			{Trigger: "turkey", Reactions: []string{"turkey"}, Reply: "gobble", Cooldown: &oneHour},
+			{Trigger: "^ri+bbit$", Regex: true, CaseSensitive: true, Chance: 0.5, Channels: []string{"general"}},
+		},
+	}
+
+	expected := []models.ReactjiRule{
+		{Trigger: "gorf", Reactions: []string{"frog"}},
+		{Trigger: "party", Reactions: []string{"tada"}},
+		{Trigger: "turkey", Reactions: []string{"turkey"}, Reply: "gobble", Cooldown: time.Hour},
+		{Trigger: "^ri+bbit$", Regex: true, CaseSensitive: true, Chance: 0.5, Channels: []string{"general"}},
+	}
+
+	if rules := ConfigReactjiRules(conf); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected rules %v, got %v", expected, rules)
+	}
+}
@@ -2,24 +2,48 @@ package reactjikeys
 
 import (
 	"fmt"
+	"math/rand"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	patternName                  = "reactji keywords"
-	patternRegexp                = `(\w+)`
+	patternRegexp                = `(?s)^(.*\S.*)$`
 	patternExpectedSubmatchCount = 2
 )
 
+// rule is a reactji rule with its compiled trigger.
+type rule struct {
+	models.ReactjiRule
+	// key identifies the rule for cooldowns.
+	key     string
+	matcher *regexp.Regexp
+}
+
 type reactjikeysPattern struct {
 	log    *logrus.Logger
 	config config.ReactjiKeysConfig
+	// rules are the rules from the config.
+	rules []rule
+
+	mu sync.Mutex
+	// stored are the rules loaded from storage, if loaded is true.
+	stored []rule
+	loaded bool
+	// storedVersion is the botcmd.ReactjiRulesVersion the stored rules were
+	// loaded at. They're loaded again when it changes.
+	storedVersion uint64
+	// lastFired is when each rule last fired by rule key.
+	lastFired map[string]time.Time
 }
 
 func init() {
@@ -43,25 +67,53 @@ func (e errUnexpectedSubmatchLen) Error() string {
 		patternName, e.got)
 }
 
-func (p reactjikeysPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+//nolint:funlen
+func (p *reactjikeysPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
 	if len(allSubmatches) < 1 {
 		return botcmd.RunResult{}, errAllSubmatchesEmpty
 	}
 
+	msg := runCtx.Message
+	if msg == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error: %w", patternName, botcmd.ErrNilMessage)
+	}
+
+	// Don't react to the bot's own replies.
+	if msg.UserID == runCtx.Slack.BotID() {
+		return botcmd.RunResult{}, nil
+	}
+
+	rules, err := p.allRules(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	channel := runCtx.Slack.ConversationName(msg.ChannelID)
+	now := botcmd.EventTime(runCtx.Slack, msg.Timestamp)
+
 	reactionsMap := make(map[string]bool)
 
+	var replies []string
+
 	for _, submatches := range allSubmatches {
 		if len(submatches) != patternExpectedSubmatchCount {
 			return botcmd.RunResult{}, errUnexpectedSubmatchLen{submatches}
 		}
 
-		word := strings.ToLower(submatches[1])
+		for _, r := range rules {
+			if !r.AppliesTo(channel) || !r.matcher.MatchString(submatches[1]) || !p.fire(r, now) {
+				continue
+			}
+
+			runCtx.Logger(p.log).Infof("rule %q triggers %#v", r.Trigger, r.Reactions)
 
-		if reactions, found := p.config.Keywords[word]; found {
-			p.log.Infof("word: %q triggers %#v", word, reactions)
+			for _, reaction := range r.Reactions {
+				reactionsMap[reaction] = true
+			}
 
-			for _, r := range reactions {
-				reactionsMap[r] = true
+			if r.Reply != "" {
+				replies = append(replies, r.Reply)
 			}
 		}
 	}
@@ -74,15 +126,101 @@ func (p reactjikeysPattern) Run(allSubmatches [][]string, runCtx botcmd.RunConte
 	sort.Strings(allReactions) // Sort reactions to make unit tests easier.
 
 	return botcmd.RunResult{
+		Message: strings.Join(replies, "\n"),
 		Reactji: allReactions,
 	}, nil
 }
 
+// allRules returns the rules from the config followed by the rules in
+// storage. Stored rules are only loaded again after they change. Stored rules
+// with a bad trigger are skipped.
+func (p *reactjikeysPattern) allRules(runCtx botcmd.RunContext) ([]rule, error) {
+	version := botcmd.ReactjiRulesVersion()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.loaded || p.storedVersion != version {
+		stored, err := runCtx.Storage.GetReactjiRules()
+		if err != nil {
+			return nil, fmt.Errorf("%s pattern error getting rules: %w", patternName, err)
+		}
+
+		p.stored = make([]rule, 0, len(stored))
+
+		for _, r := range stored {
+			matcher, err := r.Matcher()
+			if err != nil {
+				runCtx.Logger(p.log).Warnf("%s skipping %s: %v", patternName, r, err)
+
+				continue
+			}
+
+			p.stored = append(p.stored, rule{ReactjiRule: r, key: r.ID, matcher: matcher})
+		}
+
+		p.loaded, p.storedVersion = true, version
+	}
+
+	rules := make([]rule, 0, len(p.rules)+len(p.stored))
+
+	return append(append(rules, p.rules...), p.stored...), nil
+}
+
+// fire returns true if a rule matching a message at the given time fires,
+// taking its cooldown and chance into account.
+func (p *reactjikeysPattern) fire(r rule, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if last, found := p.lastFired[r.key]; found && r.Cooldown > 0 && now.Sub(last) < r.Cooldown {
+		return false
+	}
+
+	if r.Chance > 0 && r.Chance < 1 && rand.Float64() >= r.Chance { //nolint:gosec
+		return false
+	}
+
+	if p.lastFired == nil {
+		p.lastFired = make(map[string]time.Time)
+	}
+
+	p.lastFired[r.key] = now
+
+	return true
+}
+
 func (p *reactjikeysPattern) Configure(log *logrus.Logger, c *config.Config) error {
 	p.log = log
-	if c != nil {
-		p.config = c.ReactjiKeysConf
-		p.log.Tracef("Loaded reactji config: %v", p.config)
+	p.config = config.ReactjiKeysConfig{}
+	p.rules = nil
+
+	p.mu.Lock()
+	p.stored = nil
+	p.loaded = false
+	p.lastFired = nil
+	p.mu.Unlock()
+
+	if c == nil {
+		return nil
+	}
+
+	p.config = c.ReactjiKeysConf
+	p.log.Tracef("Loaded reactji config: %v", p.config)
+
+	for i, r := range botcmd.ConfigReactjiRules(p.config) {
+		matcher, err := r.Matcher()
+		if err != nil {
+			return fmt.Errorf("%s pattern config error: %w", patternName, err)
+		}
+
+		p.rules = append(p.rules, rule{ReactjiRule: r, key: fmt.Sprintf("config:%d", i), matcher: matcher})
+	}
+
+	if p.config.RandomSeed > 0 {
+		rand.Seed(p.config.RandomSeed)
+	} else {
+		rand.Seed(time.Now().UnixNano())
 	}
 
 	return nil
@@ -1,14 +1,23 @@
-//nolint:funlen
+//nolint:funlen,goerr113
 package reactjikeys
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
 	"github.com/cpu/gorfbot/test"
+	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 	logtest "github.com/sirupsen/logrus/hooks/test"
 )
@@ -42,24 +51,55 @@ func TestConfigure(t *testing.T) {
 	} else if cmd.log != log {
 		t.Errorf("expected log to be set to %p was %p", log, cmd.log)
 	} else if !reflect.DeepEqual(cmd.config, emptyConfig) {
-		t.Errorf("expected config to be set to empty was %q", cmd.config)
+		t.Errorf("expected config to be set to empty was %#v", cmd.config)
+	}
+
+	// A rule with a bad regex should err
+	cmd = &reactjikeysPattern{}
+	badConfig := &config.Config{
+		ReactjiKeysConf: config.ReactjiKeysConfig{
+			Rules: []config.ReactjiRule{{Trigger: "(", Regex: true}},
+		},
+	}
+
+	if err := cmd.Configure(log, badConfig); err == nil {
+		t.Errorf("expected err from configure with bad regex, got nil")
 	}
 }
 
-func setup(conf config.ReactjiKeysConfig) (*logtest.Hook, *reactjikeysPattern, botcmd.RunContext) {
+func setup(t *testing.T, conf config.ReactjiKeysConfig) (*logtest.Hook, *reactjikeysPattern, botcmd.RunContext, *mocks.MockStorage) {
 	log, logHook := logtest.NewNullLogger()
-	cmd := &reactjikeysPattern{
-		log:    log,
-		config: conf,
+	cmd := &reactjikeysPattern{}
+
+	if err := cmd.Configure(log, &config.Config{ReactjiKeysConf: conf}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
 	}
 
-	ctx := botcmd.RunContext{}
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001", Timestamp: "1600000000.000001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
 
-	return logHook, cmd, ctx
+	mockClient.EXPECT().BotID().Return("U999").AnyTimes()
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+	mockClient.EXPECT().ConversationName("C002").Return("random").AnyTimes()
+	mockClient.EXPECT().ParseTimestamp(gomock.Any()).DoAndReturn(func(ts string) (time.Time, error) {
+		seconds, err := strconv.ParseFloat(ts, 64)
+
+		return time.Unix(int64(seconds), 0), err
+	}).AnyTimes()
+
+	return logHook, cmd, ctx, mockStorage
 }
 
 func TestRunTooFewMatches(t *testing.T) {
-	_, cmd, ctx := setup(config.ReactjiKeysConfig{})
+	_, cmd, ctx, _ := setup(t, config.ReactjiKeysConfig{})
 
 	// Too few submatches
 	if _, err := cmd.Run([][]string{}, ctx); err == nil {
@@ -68,7 +108,9 @@ func TestRunTooFewMatches(t *testing.T) {
 }
 
 func TestRunTooSmallMatch(t *testing.T) {
-	_, cmd, ctx := setup(config.ReactjiKeysConfig{})
+	_, cmd, ctx, mockStorage := setup(t, config.ReactjiKeysConfig{})
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, nil)
 
 	// Too small submatch
 	if _, err := cmd.Run([][]string{{"a"}}, ctx); err == nil {
@@ -83,7 +125,9 @@ func TestRun(t *testing.T) {
 			"goodbye": {"wave", "cry"},
 		},
 	}
-	logHook, cmd, ctx := setup(conf)
+	logHook, cmd, ctx, mockStorage := setup(t, conf)
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, nil).AnyTimes()
 
 	repeatWord := func(word string, times int) []string {
 		var results []string
@@ -140,7 +184,7 @@ func TestRun(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if res, err := cmd.Run(tc.allSubmatches, botcmd.RunContext{}); err != nil {
+			if res, err := cmd.Run(tc.allSubmatches, ctx); err != nil {
 				t.Fatalf("unexpected err from Run: %v", err)
 			} else if res.Message != "" {
 				t.Errorf("unexpected result message from Run: %q", res.Message)
@@ -151,7 +195,7 @@ func TestRun(t *testing.T) {
 
 			var expectedLogs []*logrus.Entry
 			for _, expectedHit := range tc.expectedHits {
-				expectedLogMsg := fmt.Sprintf("word: %q triggers %#v",
+				expectedLogMsg := fmt.Sprintf("rule %q triggers %#v",
 					expectedHit, conf.Keywords[expectedHit])
 				expectedLogs = append(expectedLogs, &logrus.Entry{
 					Level:   logrus.InfoLevel,
@@ -167,3 +211,151 @@ func TestRun(t *testing.T) {
 		t.Errorf("expected err from Run with empty matches, got nil")
 	}
 }
+
+// run runs the pattern for a message like the bot would.
+func run(t *testing.T, cmd *reactjikeysPattern, ctx botcmd.RunContext, msg slack.Message) botcmd.RunResult {
+	t.Helper()
+
+	ctx.Message = &msg
+
+	res, err := cmd.Run(regexp.MustCompile(patternRegexp).FindAllStringSubmatch(msg.Text, -1), ctx)
+	if err != nil {
+		t.Fatalf("unexpected err from Run: %v", err)
+	}
+
+	return res
+}
+
+func TestRunRules(t *testing.T) {
+	oneHour := time.Hour
+	conf := config.ReactjiKeysConfig{
+		Rules: []config.ReactjiRule{
+			{Trigger: "good morning", Reactions: []string{"sunny"}, Channels: []string{"general"}},
+			{Trigger: `^ri+bbit$`, Regex: true, CaseSensitive: true, Reply: "ribbit :frog:"},
+			{Trigger: "turkey", Reactions: []string{"turkey"}, Cooldown: &oneHour},
+		},
+	}
+
+	_, cmd, ctx, mockStorage := setup(t, conf)
+
+	// Stored rules are only loaded once.
+	mockStorage.EXPECT().GetReactjiRules().Return([]models.ReactjiRule{
+		{ID: "abc123", Trigger: "gorf", Reactions: []string{"frog", "sunny"}, Reply: "GORF"},
+		{ID: "bad", Trigger: "(", Regex: true, Reply: "never"},
+	}, nil)
+
+	testCases := []struct {
+		name     string
+		msg      slack.Message
+		expected botcmd.RunResult
+	}{
+		{
+			name:     "phrase",
+			msg:      slack.Message{ChannelID: "C001", Text: "Good  Morning gorf", Timestamp: "1600000000.000001"},
+			expected: botcmd.RunResult{Message: "GORF", Reactji: []string{"frog", "sunny"}},
+		},
+		{
+			name: "other channel",
+			msg:  slack.Message{ChannelID: "C002", Text: "good morning", Timestamp: "1600000001.000001"},
+		},
+		{
+			name:     "regex",
+			msg:      slack.Message{ChannelID: "C002", Text: "riiibbit", Timestamp: "1600000002.000001"},
+			expected: botcmd.RunResult{Message: "ribbit :frog:"},
+		},
+		{
+			name: "case sensitive regex",
+			msg:  slack.Message{ChannelID: "C002", Text: "RIBBIT", Timestamp: "1600000003.000001"},
+		},
+		{
+			name:     "cooldown",
+			msg:      slack.Message{ChannelID: "C001", Text: "turkey!", Timestamp: "1600000004.000001"},
+			expected: botcmd.RunResult{Reactji: []string{"turkey"}},
+		},
+		{
+			name: "during cooldown",
+			msg:  slack.Message{ChannelID: "C001", Text: "more turkey", Timestamp: "1600003603.000001"},
+		},
+		{
+			name:     "after cooldown",
+			msg:      slack.Message{ChannelID: "C001", Text: "more turkey", Timestamp: "1600003604.000001"},
+			expected: botcmd.RunResult{Reactji: []string{"turkey"}},
+		},
+		{
+			name: "bot message",
+			msg:  slack.Message{ChannelID: "C001", UserID: "U999", Text: "gorf", Timestamp: "1600003605.000001"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if res := run(t, cmd, ctx, tc.msg); !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("expected result %#v, got %#v", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestRunRulesChanged(t *testing.T) {
+	_, cmd, ctx, mockStorage := setup(t, config.ReactjiKeysConfig{})
+
+	gomock.InOrder(
+		mockStorage.EXPECT().GetReactjiRules().Return(nil, nil),
+		mockStorage.EXPECT().GetReactjiRules().Return([]models.ReactjiRule{
+			{ID: "abc123", Trigger: "gorf", Reactions: []string{"frog"}},
+		}, nil),
+	)
+
+	msg := slack.Message{ChannelID: "C001", Text: "gorf"}
+
+	if res := run(t, cmd, ctx, msg); len(res.Reactji) != 0 {
+		t.Errorf("expected no reactji before the rules changed, got %v", res.Reactji)
+	}
+
+	// The stored rules are loaded again after they change.
+	botcmd.ReactjiRulesChanged()
+
+	for i := 0; i < 2; i++ {
+		if res := run(t, cmd, ctx, msg); !reflect.DeepEqual(res.Reactji, []string{"frog"}) {
+			t.Errorf("expected reactji [frog] after the rules changed, got %v", res.Reactji)
+		}
+	}
+}
+
+func TestRunChance(t *testing.T) {
+	conf := config.ReactjiKeysConfig{
+		Rules:      []config.ReactjiRule{{Trigger: "gorf", Reactions: []string{"frog"}, Chance: 0.5}},
+		RandomSeed: 1,
+	}
+
+	_, cmd, ctx, mockStorage := setup(t, conf)
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, nil).AnyTimes()
+
+	var fired int
+
+	for i := 0; i < 100; i++ {
+		if res := run(t, cmd, ctx, slack.Message{ChannelID: "C001", Text: "gorf"}); len(res.Reactji) > 0 {
+			fired++
+		}
+	}
+
+	if fired < 25 || fired > 75 {
+		t.Errorf("expected rule with 0.5 chance to fire about 50 times out of 100, fired %d", fired)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	_, cmd, ctx, mockStorage := setup(t, config.ReactjiKeysConfig{})
+
+	mockStorage.EXPECT().GetReactjiRules().Return(nil, errors.New("data is dead"))
+
+	expectedErr := "reactji keywords pattern error getting rules: data is dead"
+
+	if _, err := cmd.Run([][]string{{"gorf", "gorf"}}, ctx); err == nil {
+		t.Errorf("expected err from Run with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from Run, got %q", expectedErr, err.Error())
+	}
+}
@@ -32,6 +32,13 @@ func (rh reactjiHandler) Run(reaction *slack.Reaction, runCtx botcmd.RunContext)
 		return errEmptyReaction
 	}
 
+	// Count aliases and skin tone variants of a reactji together. Slack only
+	// allows real emoji as reactions so names that aren't known (e.g. custom
+	// emoji added since the Slack state was refreshed) are counted too.
+	canonical := *reaction
+	canonical.Reaction, _ = runCtx.Slack.CanonicalEmoji(reaction.Reaction)
+	reaction = &canonical
+
 	initialCount := 1
 	if reaction.Removed {
 		initialCount = 0
@@ -49,19 +56,82 @@ func (rh reactjiHandler) Run(reaction *slack.Reaction, runCtx botcmd.RunContext)
 		return fmt.Errorf("%s storage returned err: %w", handlerName, err)
 	}
 
+	usage := models.EmojiUsage{
+		User:     reaction.User,
+		Emoji:    reaction.Reaction,
+		Channel:  reaction.ItemChannel,
+		Day:      models.UsageDay(botcmd.EventTime(runCtx.Slack, reaction.Timestamp)),
+		Count:    initialCount,
+		Reaction: true,
+	}
+
+	if _, err := runCtx.Storage.UpsertEmojiUsage(usage, reaction.Removed); err != nil {
+		return fmt.Errorf("%s storage returned usage err: %w", handlerName, err)
+	}
+
+	if err := rh.updateReceived(reaction, runCtx); err != nil {
+		return err
+	}
+
 	user := runCtx.Slack.UserName(updatedE.User)
+	log := runCtx.Logger(rh.log)
 
 	if reaction.Removed {
-		rh.log.Infof("%s update - User %q (%s) removed reactji %q (new history: %d times)",
+		log.Infof("%s update - User %q (%s) removed reactji %q (new history: %d times)",
 			handlerName, user, updatedE.User, updatedE.Emoji, updatedE.Count-1)
 	} else {
-		rh.log.Infof("%s update - User %q (%s) reacted with reactji %q (history: %d times)",
+		log.Infof("%s update - User %q (%s) reacted with reactji %q (history: %d times)",
 			handlerName, user, updatedE.User, updatedE.Emoji, updatedE.Count+1)
 	}
 
 	return nil
 }
 
+// updateReceived updates the count of the reactji received by the author of
+// the item that was reacted to, and the count of reactions the item has
+// received. Reactions to items without an author and users reacting to their
+// own items are ignored.
+func (rh reactjiHandler) updateReceived(reaction *slack.Reaction, runCtx botcmd.RunContext) error {
+	if reaction.ItemUser == "" || reaction.ItemUser == reaction.User {
+		return nil
+	}
+
+	initialCount := 1
+	if reaction.Removed {
+		initialCount = 0
+	}
+
+	received := models.Emoji{
+		User:     reaction.ItemUser,
+		Emoji:    reaction.Reaction,
+		Count:    initialCount,
+		Reaction: true,
+		Received: true,
+	}
+
+	if _, err := runCtx.Storage.UpsertEmojiCount(received, reaction.Removed); err != nil {
+		return fmt.Errorf("%s storage returned received err: %w", handlerName, err)
+	}
+
+	// Only messages (not files) have an item timestamp.
+	if reaction.ItemTimestamp == "" {
+		return nil
+	}
+
+	msg := models.ReactedMessage{
+		Channel:   reaction.ItemChannel,
+		Timestamp: reaction.ItemTimestamp,
+		User:      reaction.ItemUser,
+		Count:     initialCount,
+	}
+
+	if _, err := runCtx.Storage.UpsertReactedMessage(msg, reaction.Removed); err != nil {
+		return fmt.Errorf("%s storage returned reacted message err: %w", handlerName, err)
+	}
+
+	return nil
+}
+
 func (rh *reactjiHandler) Configure(log *logrus.Logger, c *config.Config) error {
 	rh.log = log
 	return nil
@@ -4,6 +4,7 @@ package reactjiupdate
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/slack"
@@ -26,6 +27,11 @@ func setup() (*reactjiHandler, botcmd.RunContext, *logtest.Hook) {
 	return cmd, ctx, logHook
 }
 
+// expectCanonical expects the reaction name to be canonicalized to itself.
+func expectCanonical(mockClient *slack_mocks.MockClient, name string) {
+	mockClient.EXPECT().CanonicalEmoji(name).Return(name, true)
+}
+
 func TestConfigure(t *testing.T) {
 	log, _ := logtest.NewNullLogger()
 	cmd := &reactjiHandler{}
@@ -57,17 +63,20 @@ func TestRunUpsertErr(t *testing.T) {
 	mockClient := slack_mocks.NewMockClient(ctrl)
 	ctx.Storage = mockStorage
 	ctx.Slack = mockClient
+	expectCanonical(mockClient, "fake")
 
 	expectEmoji := models.Emoji{
 		User:     "U001",
-		Emoji:    ":fake:",
+		Emoji:    "fake",
 		Count:    1,
 		Reaction: true,
 	}
 
 	reaction := &slack.Reaction{
-		User:     expectEmoji.User,
-		Reaction: expectEmoji.Emoji,
+		User:        expectEmoji.User,
+		Reaction:    expectEmoji.Emoji,
+		Timestamp:   "1614556800.000100",
+		ItemChannel: "C001",
 	}
 
 	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, false).
@@ -82,6 +91,38 @@ func TestRunUpsertErr(t *testing.T) {
 	}
 }
 
+func TestRunUpsertUsageErr(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+	expectCanonical(mockClient, "fake")
+
+	reaction := &slack.Reaction{
+		User:      "U001",
+		Reaction:  "fake",
+		Timestamp: "1614556800.000100",
+	}
+
+	mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{}, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).
+		Return(models.EmojiUsage{}, errors.New("blorp failure"))
+
+	expectedErr := `reactji usage storage returned usage err: blorp failure`
+
+	if err := cmd.Run(reaction, ctx); err == nil {
+		t.Errorf("expected err from upsert with storage err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q from upsert, got %q", expectedErr, err.Error())
+	}
+}
+
 func TestRunSuccessIncrement(t *testing.T) {
 	cmd, ctx, logHook := setup()
 
@@ -92,17 +133,20 @@ func TestRunSuccessIncrement(t *testing.T) {
 	mockClient := slack_mocks.NewMockClient(ctrl)
 	ctx.Storage = mockStorage
 	ctx.Slack = mockClient
+	expectCanonical(mockClient, "fake")
 
 	expectEmoji := models.Emoji{
 		User:     "U001",
-		Emoji:    ":fake:",
+		Emoji:    "fake",
 		Count:    1,
 		Reaction: true,
 	}
 
 	reaction := &slack.Reaction{
-		User:     expectEmoji.User,
-		Reaction: expectEmoji.Emoji,
+		User:        expectEmoji.User,
+		Reaction:    expectEmoji.Emoji,
+		Timestamp:   "1614556800.000100",
+		ItemChannel: "C001",
 	}
 	updatedEmoji := models.Emoji{
 		User:  expectEmoji.User,
@@ -110,14 +154,25 @@ func TestRunSuccessIncrement(t *testing.T) {
 		Count: 2,
 	}
 
+	expectUsage := models.EmojiUsage{
+		User:     expectEmoji.User,
+		Emoji:    expectEmoji.Emoji,
+		Channel:  "C001",
+		Day:      time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Count:    1,
+		Reaction: true,
+	}
+
 	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, false).Return(updatedEmoji, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(expectUsage, false).Return(models.EmojiUsage{}, nil)
 	mockClient.EXPECT().UserName(expectEmoji.User).Return("Gorfbot")
 
 	if err := cmd.Run(reaction, ctx); err != nil {
 		t.Errorf("unexpected err from run: %v\n", err)
 	}
 
-	expectedLog := `reactji usage update - User "Gorfbot" (U001) reacted with reactji ":fake:" (history: 3 times)`
+	expectedLog := `reactji usage update - User "Gorfbot" (U001) reacted with reactji "fake" (history: 3 times)`
 	test.ExpectLastLog(t, logHook, logrus.InfoLevel, expectedLog)
 }
 
@@ -131,18 +186,21 @@ func TestRunSuccessDecrement(t *testing.T) {
 	mockClient := slack_mocks.NewMockClient(ctrl)
 	ctx.Storage = mockStorage
 	ctx.Slack = mockClient
+	expectCanonical(mockClient, "fake")
 
 	expectEmoji := models.Emoji{
 		User:     "U001",
-		Emoji:    ":fake:",
+		Emoji:    "fake",
 		Count:    0,
 		Reaction: true,
 	}
 
 	reaction := &slack.Reaction{
-		User:     expectEmoji.User,
-		Reaction: expectEmoji.Emoji,
-		Removed:  true,
+		User:        expectEmoji.User,
+		Reaction:    expectEmoji.Emoji,
+		Timestamp:   "1614556800.000100",
+		ItemChannel: "C001",
+		Removed:     true,
 	}
 	updatedEmoji := models.Emoji{
 		User:  expectEmoji.User,
@@ -150,13 +208,208 @@ func TestRunSuccessDecrement(t *testing.T) {
 		Count: 1,
 	}
 
+	expectUsage := models.EmojiUsage{
+		User:     expectEmoji.User,
+		Emoji:    expectEmoji.Emoji,
+		Channel:  "C001",
+		Day:      time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Count:    0,
+		Reaction: true,
+	}
+
 	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, true).Return(updatedEmoji, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(expectUsage, true).Return(models.EmojiUsage{}, nil)
 	mockClient.EXPECT().UserName(expectEmoji.User).Return("Gorfbot")
 
 	if err := cmd.Run(reaction, ctx); err != nil {
 		t.Errorf("unexpected err from run: %v\n", err)
 	}
 
-	expectedLog := `reactji usage update - User "Gorfbot" (U001) removed reactji ":fake:" (new history: 0 times)`
+	expectedLog := `reactji usage update - User "Gorfbot" (U001) removed reactji "fake" (new history: 0 times)`
 	test.ExpectLastLog(t, logHook, logrus.InfoLevel, expectedLog)
 }
+
+func TestRunReceived(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+	expectCanonical(mockClient, "joy")
+
+	reaction := &slack.Reaction{
+		User:          "U001",
+		Reaction:      "joy",
+		Timestamp:     "1614556800.000100",
+		ItemUser:      "U002",
+		ItemChannel:   "C001",
+		ItemTimestamp: "1614556700.000100",
+	}
+
+	expectReceived := models.Emoji{
+		User:     "U002",
+		Emoji:    "joy",
+		Count:    1,
+		Reaction: true,
+		Received: true,
+	}
+	expectMessage := models.ReactedMessage{
+		Channel:   "C001",
+		Timestamp: "1614556700.000100",
+		User:      "U002",
+		Count:     1,
+	}
+
+	mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{User: "U001"}, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).Return(models.EmojiUsage{}, nil)
+	mockStorage.EXPECT().UpsertEmojiCount(expectReceived, false).Return(models.Emoji{}, nil)
+	mockStorage.EXPECT().UpsertReactedMessage(expectMessage, false).Return(models.ReactedMessage{}, nil)
+	mockClient.EXPECT().UserName("U001").Return("Gorfbot")
+
+	if err := cmd.Run(reaction, ctx); err != nil {
+		t.Errorf("unexpected err from run: %v\n", err)
+	}
+}
+
+func TestRunReceivedSelfReaction(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+	expectCanonical(mockClient, "joy")
+
+	// Users reacting to their own messages don't receive reactji.
+	reaction := &slack.Reaction{
+		User:          "U001",
+		Reaction:      "joy",
+		Timestamp:     "1614556800.000100",
+		ItemUser:      "U001",
+		ItemChannel:   "C001",
+		ItemTimestamp: "1614556700.000100",
+	}
+
+	mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{User: "U001"}, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).Return(models.EmojiUsage{}, nil)
+	mockClient.EXPECT().UserName("U001").Return("Gorfbot")
+
+	if err := cmd.Run(reaction, ctx); err != nil {
+		t.Errorf("unexpected err from run: %v\n", err)
+	}
+}
+
+func TestRunReceivedErrs(t *testing.T) {
+	reaction := &slack.Reaction{
+		User:          "U001",
+		Reaction:      "joy",
+		Timestamp:     "1614556800.000100",
+		ItemUser:      "U002",
+		ItemChannel:   "C001",
+		ItemTimestamp: "1614556700.000100",
+	}
+
+	t.Run("received", func(t *testing.T) {
+		cmd, ctx, _ := setup()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := mocks.NewMockStorage(ctrl)
+		mockClient := slack_mocks.NewMockClient(ctrl)
+		ctx.Storage = mockStorage
+		ctx.Slack = mockClient
+		expectCanonical(mockClient, "joy")
+
+		gomock.InOrder(
+			mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{}, nil),
+			mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).
+				Return(models.Emoji{}, errors.New("blorp failure")),
+		)
+		mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+		mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).Return(models.EmojiUsage{}, nil)
+
+		expectedErr := `reactji usage storage returned received err: blorp failure`
+		if err := cmd.Run(reaction, ctx); err == nil || err.Error() != expectedErr {
+			t.Errorf("expected err %q from run, got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("reacted message", func(t *testing.T) {
+		cmd, ctx, _ := setup()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := mocks.NewMockStorage(ctrl)
+		mockClient := slack_mocks.NewMockClient(ctrl)
+		ctx.Storage = mockStorage
+		ctx.Slack = mockClient
+		expectCanonical(mockClient, "joy")
+
+		mockStorage.EXPECT().UpsertEmojiCount(gomock.Any(), false).Return(models.Emoji{}, nil).Times(2)
+		mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+		mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).Return(models.EmojiUsage{}, nil)
+		mockStorage.EXPECT().UpsertReactedMessage(gomock.Any(), false).
+			Return(models.ReactedMessage{}, errors.New("blorp failure"))
+
+		expectedErr := `reactji usage storage returned reacted message err: blorp failure`
+		if err := cmd.Run(reaction, ctx); err == nil || err.Error() != expectedErr {
+			t.Errorf("expected err %q from run, got %v", expectedErr, err)
+		}
+	})
+}
+
+func TestRunCanonicalReaction(t *testing.T) {
+	cmd, ctx, _ := setup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx.Storage = mockStorage
+	ctx.Slack = mockClient
+
+	reaction := &slack.Reaction{
+		User:        "U001",
+		Reaction:    "thumbsup::skin-tone-2",
+		Timestamp:   "1614556800.000100",
+		ItemChannel: "C001",
+	}
+
+	// Aliases and skin tone variants are counted as the canonical emoji.
+	expectEmoji := models.Emoji{
+		User:     "U001",
+		Emoji:    "+1",
+		Count:    1,
+		Reaction: true,
+	}
+
+	mockClient.EXPECT().CanonicalEmoji(reaction.Reaction).Return("+1", true)
+	mockStorage.EXPECT().UpsertEmojiCount(expectEmoji, false).Return(expectEmoji, nil)
+	mockClient.EXPECT().ParseTimestamp(reaction.Timestamp).Return(time.Unix(1614556800, 0), nil)
+	mockStorage.EXPECT().UpsertEmojiUsage(gomock.Any(), false).
+		DoAndReturn(func(usage models.EmojiUsage, _ bool) (models.EmojiUsage, error) {
+			if usage.Emoji != expectEmoji.Emoji {
+				t.Errorf("expected usage of %q, got %q", expectEmoji.Emoji, usage.Emoji)
+			}
+
+			return models.EmojiUsage{}, nil
+		})
+	mockClient.EXPECT().UserName("U001").Return("Gorfbot")
+
+	if err := cmd.Run(reaction, ctx); err != nil {
+		t.Errorf("unexpected err from run: %v", err)
+	}
+}
@@ -2,8 +2,9 @@ package botcmd
 
 import "fmt"
 
-// CommandRegistry describes a collection of basic commands, pattern commands
-// and reaction handlers. It is not thread safe - do not use concurrently.
+// CommandRegistry describes a collection of basic commands, pattern commands,
+// reaction handlers, emoji change handlers and scheduled commands. It is not thread safe - do not
+// use concurrently.
 type CommandRegistry struct {
 	cmdsList []*BasicCommand
 	cmdsMap  map[string]*BasicCommand
@@ -13,19 +14,28 @@ type CommandRegistry struct {
 
 	reactionHandlerList []*ReactionCommand
 	reactionHandlerMap  map[string]*ReactionCommand
+
+	emojiChangeHandlerList []*EmojiChangeCommand
+	emojiChangeHandlerMap  map[string]*EmojiChangeCommand
+
+	scheduledList []*ScheduledCommand
+	scheduledMap  map[string]*ScheduledCommand
 }
 
 // NewRegistry constructs a new CommandRegistry.
 func NewRegistry() *CommandRegistry {
 	return &CommandRegistry{
-		cmdsMap:            make(map[string]*BasicCommand),
-		patternsMap:        make(map[string]*PatternCommand),
-		reactionHandlerMap: make(map[string]*ReactionCommand),
+		cmdsMap:               make(map[string]*BasicCommand),
+		patternsMap:           make(map[string]*PatternCommand),
+		reactionHandlerMap:    make(map[string]*ReactionCommand),
+		emojiChangeHandlerMap: make(map[string]*EmojiChangeCommand),
+		scheduledMap:          make(map[string]*ScheduledCommand),
 	}
 }
 
 // GetConfigurables returns a list of all of the configurables in the registry.
-// This includes basic commands, patterns and reaction handlers.
+// This includes basic commands, patterns, reaction handlers, emoji change
+// handlers and scheduled commands.
 func (c CommandRegistry) GetConfigurables() []Configurable {
 	var allConfigurables []Configurable //nolint:prealloc
 
@@ -41,6 +51,14 @@ func (c CommandRegistry) GetConfigurables() []Configurable {
 		allConfigurables = append(allConfigurables, h.Handler)
 	}
 
+	for _, h := range c.GetEmojiChangeHandlers() {
+		allConfigurables = append(allConfigurables, h.Handler)
+	}
+
+	for _, s := range c.GetScheduledCommands() {
+		allConfigurables = append(allConfigurables, s.Handler)
+	}
+
 	return allConfigurables
 }
 
@@ -157,6 +175,80 @@ func (c CommandRegistry) GetReactionHandler(cmdName string) *ReactionCommand {
 	return c.reactionHandlerMap[cmdName]
 }
 
+// AddEmojiChangeHandler adds an emoji change handler to the registry. It
+// returns false if the emoji change command is invalid or if the emoji change
+// command name was already registered.
+func (c *CommandRegistry) AddEmojiChangeHandler(cmd *EmojiChangeCommand) bool {
+	if cmd == nil {
+		return false
+	}
+
+	if cmd.Name == "" {
+		return false
+	}
+
+	if _, found := c.emojiChangeHandlerMap[cmd.Name]; found {
+		return false
+	}
+
+	if cmd.Handler == nil {
+		return false
+	}
+
+	c.emojiChangeHandlerList = append(c.emojiChangeHandlerList, cmd)
+	c.emojiChangeHandlerMap[cmd.Name] = cmd
+
+	return true
+}
+
+// GetEmojiChangeHandlers returns a list of the registered EmojiChangeCommands.
+func (c CommandRegistry) GetEmojiChangeHandlers() []*EmojiChangeCommand {
+	return c.emojiChangeHandlerList
+}
+
+// GetEmojiChangeHandler returns the Emoji Change Command registered with the
+// given cmdName (or nil if there was no such handler).
+func (c CommandRegistry) GetEmojiChangeHandler(cmdName string) *EmojiChangeCommand {
+	return c.emojiChangeHandlerMap[cmdName]
+}
+
+// AddScheduledCommand adds a scheduled command to the registry. It returns
+// false if the scheduled command is invalid or if the scheduled command name
+// was already registered.
+func (c *CommandRegistry) AddScheduledCommand(cmd *ScheduledCommand) bool {
+	if cmd == nil {
+		return false
+	}
+
+	if cmd.Name == "" {
+		return false
+	}
+
+	if _, found := c.scheduledMap[cmd.Name]; found {
+		return false
+	}
+
+	if cmd.Handler == nil {
+		return false
+	}
+
+	c.scheduledList = append(c.scheduledList, cmd)
+	c.scheduledMap[cmd.Name] = cmd
+
+	return true
+}
+
+// GetScheduledCommands returns a list of the registered ScheduledCommands.
+func (c CommandRegistry) GetScheduledCommands() []*ScheduledCommand {
+	return c.scheduledList
+}
+
+// GetScheduledCommand returns the Scheduled Command registered with the given
+// cmdName (or nil if there was no such command).
+func (c CommandRegistry) GetScheduledCommand(cmdName string) *ScheduledCommand {
+	return c.scheduledMap[cmdName]
+}
+
 // DefaultRegistry is the global registry instance used by default.
 var DefaultRegistry = NewRegistry()
 
@@ -195,3 +287,29 @@ func MustAddReactionHandler(cmd *ReactionCommand) {
 		panic(fmt.Sprintf("failed to add reaction handler: %v\n", cmd))
 	}
 }
+
+// AddEmojiChangeHandler adds an emoji change command to the default registry.
+func AddEmojiChangeHandler(cmd *EmojiChangeCommand) bool {
+	return DefaultRegistry.AddEmojiChangeHandler(cmd)
+}
+
+// MustAddEmojiChangeHandler adds an emoji change command to the default
+// registry or panics.
+func MustAddEmojiChangeHandler(cmd *EmojiChangeCommand) {
+	if added := DefaultRegistry.AddEmojiChangeHandler(cmd); !added {
+		panic(fmt.Sprintf("failed to add emoji change handler: %v\n", cmd))
+	}
+}
+
+// AddScheduledCommand adds a scheduled command to the default registry.
+func AddScheduledCommand(cmd *ScheduledCommand) bool {
+	return DefaultRegistry.AddScheduledCommand(cmd)
+}
+
+// MustAddScheduledCommand adds a scheduled command to the default registry or
+// panics.
+func MustAddScheduledCommand(cmd *ScheduledCommand) {
+	if added := DefaultRegistry.AddScheduledCommand(cmd); !added {
+		panic(fmt.Sprintf("failed to add scheduled command: %v\n", cmd))
+	}
+}
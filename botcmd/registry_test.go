@@ -40,6 +40,16 @@ func (mrh mockReactionHandler) Run(reacton *slack.Reaction, ctx RunContext) erro
 	return nil
 }
 
+type mockEmojiChangeHandler struct{}
+
+func (meh mockEmojiChangeHandler) Configure(l *logrus.Logger, c *config.Config) error {
+	return nil
+}
+
+func (meh mockEmojiChangeHandler) Run(change *slack.EmojiChange, ctx RunContext) error {
+	return nil
+}
+
 func TestCommandRegistryAddCommand(t *testing.T) {
 	registry := NewRegistry()
 
@@ -191,6 +201,134 @@ func TestCommandRegistryAddReactionHandler(t *testing.T) {
 	}
 }
 
+type mockScheduledHandler struct{}
+
+func (msh mockScheduledHandler) Configure(l *logrus.Logger, c *config.Config) error {
+	return nil
+}
+
+func (msh mockScheduledHandler) Run(ctx RunContext) (RunResult, error) {
+	return RunResult{}, nil
+}
+
+func TestCommandRegistryAddScheduledCommand(t *testing.T) {
+	registry := NewRegistry()
+
+	testCases := []struct {
+		name     string
+		cmd      *ScheduledCommand
+		expected bool
+	}{
+		{
+			name: "nil cmd",
+		},
+		{
+			name: "empty name",
+			cmd:  &ScheduledCommand{},
+		},
+		{
+			name: "nil handler",
+			cmd: &ScheduledCommand{
+				Name: "nohandler",
+			},
+		},
+		{
+			name: "valid cmd",
+			cmd: &ScheduledCommand{
+				Name:    "valid",
+				Handler: mockScheduledHandler{},
+			},
+			expected: true,
+		},
+		{
+			name: "duplicate name",
+			cmd: &ScheduledCommand{
+				Name:    "valid",
+				Handler: mockScheduledHandler{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := registry.AddScheduledCommand(tc.cmd); actual != tc.expected {
+				t.Errorf("expected AddScheduledCommand to return %v got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if cmd := registry.GetScheduledCommand("valid"); cmd == nil {
+		t.Errorf("expected GetScheduledCommand to return the valid command, got nil")
+	}
+
+	if cmds := registry.GetScheduledCommands(); len(cmds) != 1 {
+		t.Errorf("expected 1 scheduled command, got %d", len(cmds))
+	}
+
+	if configurables := registry.GetConfigurables(); len(configurables) != 1 {
+		t.Errorf("expected 1 configurable, got %d", len(configurables))
+	}
+}
+
+func TestCommandRegistryAddEmojiChangeHandler(t *testing.T) {
+	registry := NewRegistry()
+
+	testCases := []struct {
+		name     string
+		handler  *EmojiChangeCommand
+		expected bool
+	}{
+		{
+			name: "nil cmd",
+		},
+		{
+			name:    "empty name",
+			handler: &EmojiChangeCommand{},
+		},
+		{
+			name: "nil handler",
+			handler: &EmojiChangeCommand{
+				Name: "nohandler",
+			},
+		},
+		{
+			name: "valid handler",
+			handler: &EmojiChangeCommand{
+				Name:    "valid",
+				Handler: mockEmojiChangeHandler{},
+			},
+			expected: true,
+		},
+		{
+			name: "duplicate name",
+			handler: &EmojiChangeCommand{
+				Name:    "valid",
+				Handler: mockEmojiChangeHandler{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := registry.AddEmojiChangeHandler(tc.handler); actual != tc.expected {
+				t.Errorf("expected AddEmojiChangeHandler to return %v got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if handler := registry.GetEmojiChangeHandler("valid"); handler == nil {
+		t.Errorf("expected GetEmojiChangeHandler to return the valid handler, got nil")
+	}
+
+	if handlers := registry.GetEmojiChangeHandlers(); len(handlers) != 1 {
+		t.Errorf("expected 1 emoji change handler, got %d", len(handlers))
+	}
+
+	if configurables := registry.GetConfigurables(); len(configurables) != 1 {
+		t.Errorf("expected 1 configurable, got %d", len(configurables))
+	}
+}
+
 //nolint:funlen
 func TestGlobalRegistry(t *testing.T) {
 	egRegexp := regexp.MustCompile(`.*`)
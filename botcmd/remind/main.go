@@ -0,0 +1,251 @@
+// Package remind provides a command for creating, listing and cancelling
+// reminders. Due reminders are delivered by the bot.
+package remind
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "remind"
+
+	// timeLayout is the layout of reminder due times.
+	timeLayout = "Mon Jan 2 2006 15:04 MST"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*remind*:\n" +
+		"\t`!remind me <when> <what>` - e.g. `!remind me in 2h to feed the frog`\n" +
+		"\t`!remind #channel <when> <what>` - e.g. `!remind #general tomorrow 9am standup`\n" +
+		"\t`!remind list` - list your reminders\n" +
+		"\t`!remind cancel <id>` - cancel one of your reminders\n" +
+		"\tTimes are in the time zone from your Slack profile."
+)
+
+// channelMention matches a Slack channel mention like "<#C001|general>".
+var channelMention = regexp.MustCompile(`^<#(C[A-Z0-9]+)(?:\|[^>]*)?>$`)
+
+type remindCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":alarm_clock:",
+		Description: "Set a reminder for yourself or a channel",
+		Handler:     &remindCmd{},
+	})
+}
+
+func (cmd *remindCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+
+	return nil
+}
+
+func (cmd remindCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "list":
+		return cmd.list(runCtx)
+	case "cancel":
+		if len(words) != 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.cancel(words[1], runCtx)
+	case "-h", "help":
+		return botcmd.RunResult{Message: usage}, nil
+	default:
+		return cmd.add(words, runCtx)
+	}
+}
+
+// add adds a reminder described by words like "me in 2h to feed the frog".
+func (cmd remindCmd) add(words []string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	msg := runCtx.Message
+	reminder := models.Reminder{
+		Creator: msg.UserID,
+		Channel: msg.ChannelID,
+	}
+
+	var target string
+
+	if strings.EqualFold(words[0], "me") {
+		reminder.Self = true
+		target = "you"
+	} else {
+		reminder.Channel = channelID(words[0], runCtx.Slack)
+		if reminder.Channel == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: remind `me` or a `#channel`, not %q", cmdName, words[0]),
+			}, nil
+		}
+
+		target = fmt.Sprintf("*#%s*", runCtx.Slack.ConversationName(reminder.Channel))
+	}
+
+	loc := runCtx.Slack.UserLocation(msg.UserID)
+	now := botcmd.EventTime(runCtx.Slack, msg.Timestamp).In(loc)
+
+	due, rest, err := botcmd.ParseWhen(words[1:], now)
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	if len(rest) > 0 && strings.EqualFold(rest[0], "to") {
+		rest = rest[1:]
+	}
+
+	if len(rest) == 0 {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: what should I remind %s about?", cmdName, target),
+		}, nil
+	}
+
+	reminder.ID = reminderID(msg)
+	reminder.Text = strings.Join(rest, " ")
+	reminder.Due = due
+	reminder.Created = now
+
+	if err := runCtx.Storage.AddReminder(reminder); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to add reminder %v: %w", cmdName, reminder, err)
+	}
+
+	runCtx.Logger(cmd.log).Infof("%s - added %s", cmdName, reminder)
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":alarm_clock: Okay _%s_, I'll remind %s on %s: %s (`%s`)",
+			runCtx.Slack.UserName(msg.UserID), target, due.Format(timeLayout), reminder.Text, reminder.ID),
+	}, nil
+}
+
+// list lists the reminders created by the user that sent the message.
+func (cmd remindCmd) list(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	reminders, err := cmd.reminders(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	if len(reminders) == 0 {
+		return botcmd.RunResult{Message: ":alarm_clock: You don't have any reminders"}, nil
+	}
+
+	loc := runCtx.Slack.UserLocation(runCtx.Message.UserID)
+	buf := new(bytes.Buffer)
+
+	noun := "reminders"
+	if len(reminders) == 1 {
+		noun = "reminder"
+	}
+
+	fmt.Fprintf(buf, ":alarm_clock: You have %d %s:\n", len(reminders), noun)
+
+	for _, reminder := range reminders {
+		where := ""
+		if !reminder.Self {
+			where = fmt.Sprintf(" in *#%s*", runCtx.Slack.ConversationName(reminder.Channel))
+		}
+
+		fmt.Fprintf(buf, "\t`%s` %s%s: %s\n",
+			reminder.ID, reminder.Due.In(loc).Format(timeLayout), where, reminder.Text)
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
+
+// cancel cancels the reminder with the given ID if it was created by the user
+// that sent the message.
+func (cmd remindCmd) cancel(id string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	reminders, err := cmd.reminders(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	id = strings.Trim(id, "`")
+
+	for _, reminder := range reminders {
+		if reminder.ID != id {
+			continue
+		}
+
+		cancelled, err := runCtx.Storage.CancelReminder(id)
+		if err != nil {
+			return botcmd.RunResult{}, fmt.Errorf("%s: failed to cancel reminder %q: %w", cmdName, id, err)
+		}
+
+		// The reminder was delivered while we were looking for it.
+		if !cancelled {
+			break
+		}
+
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":wastebasket: Cancelled reminder `%s`: %s", id, reminder.Text),
+		}, nil
+	}
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":shrug: You don't have a reminder `%s`", id),
+	}, nil
+}
+
+// reminders returns the reminders created by the user that sent the message,
+// soonest first.
+func (cmd remindCmd) reminders(runCtx botcmd.RunContext) ([]models.Reminder, error) {
+	opts := storage.GetReminderOptions{
+		FindOptions: storage.FindOptions{SortField: "due", Asc: true},
+		Creator:     runCtx.Message.UserID,
+	}
+
+	reminders, err := runCtx.Storage.GetReminders(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get reminders from storage opts: %v err: %w",
+			cmdName, opts, err)
+	}
+
+	return reminders, nil
+}
+
+// channelID returns the ID of the channel referred to by a channel mention
+// (e.g. "<#C001|general>") or name (e.g. "#general"), or "" if it isn't a known
+// channel.
+func channelID(word string, client slack.Client) string {
+	if match := channelMention.FindStringSubmatch(word); match != nil {
+		return match[1]
+	}
+
+	if !strings.HasPrefix(word, "#") {
+		return ""
+	}
+
+	return client.ConversationID(strings.TrimPrefix(word, "#"))
+}
+
+// reminderID returns a short ID for the reminder created by the given message.
+// Messages are uniquely identified by their channel and timestamp so the ID is
+// a hash of the two.
+func reminderID(msg *slack.Message) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg.ChannelID + msg.Timestamp))
+
+	return fmt.Sprintf("%06x", h.Sum32()&0xffffff)
+}
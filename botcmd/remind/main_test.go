@@ -0,0 +1,119 @@
+package remind
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*remindCmd, *slack_mocks.MockClient, *mocks.MockStorage, botcmd.RunContext) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &remindCmd{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockSlack := slack_mocks.NewMockClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+	ctx := botcmd.RunContext{
+		Slack:   mockSlack,
+		Storage: mockStorage,
+		Message: &slack.Message{
+			ChannelID: "C001",
+			UserID:    "U001",
+			Timestamp: "1610287200.000000",
+		},
+	}
+
+	return cmd, mockSlack, mockStorage, ctx
+}
+
+func TestRunAddTimeZone(t *testing.T) {
+	cmd, mockSlack, mockStorage, ctx := setup(t)
+
+	// Sunday January 10th 2021, 14:00 UTC is 09:00 in Toronto.
+	now := time.Date(2021, 1, 10, 14, 0, 0, 0, time.UTC)
+	toronto := time.FixedZone("EST", -5*60*60)
+
+	mockSlack.EXPECT().ParseTimestamp("1610287200.000000").Return(now, nil)
+	mockSlack.EXPECT().UserLocation("U001").Return(toronto)
+	mockSlack.EXPECT().UserName("U001").Return("bob")
+	mockSlack.EXPECT().ConversationName("C002").Return("random")
+
+	expected := models.Reminder{
+		ID:      reminderID(ctx.Message),
+		Creator: "U001",
+		Channel: "C002",
+		Text:    "standup",
+		// Tomorrow is still Monday in Toronto.
+		Due:     time.Date(2021, 1, 11, 9, 0, 0, 0, toronto),
+		Created: now.In(toronto),
+	}
+	mockStorage.EXPECT().AddReminder(expected)
+
+	res, err := cmd.Run("<#C002|random> tomorrow 9am standup", ctx)
+	if err != nil {
+		t.Fatalf("unexpected err from Run: %v", err)
+	}
+
+	expectedMsg := ":alarm_clock: Okay _bob_, I'll remind *#random* on Mon Jan 11 2021 09:00 EST: standup (`" +
+		expected.ID + "`)"
+	if res.Message != expectedMsg {
+		t.Errorf("expected message %q got %q", expectedMsg, res.Message)
+	}
+}
+
+func TestRunStorageErrors(t *testing.T) {
+	cmd, mockSlack, mockStorage, ctx := setup(t)
+	storageErr := errors.New("storage is sleeping")
+
+	mockSlack.EXPECT().ParseTimestamp(gomock.Any()).Return(time.Date(2021, 1, 10, 14, 0, 0, 0, time.UTC), nil)
+	mockSlack.EXPECT().UserLocation("U001").Return(time.UTC)
+	mockStorage.EXPECT().AddReminder(gomock.Any()).Return(storageErr)
+
+	if _, err := cmd.Run("me in 2h feed the frog", ctx); !errors.Is(err, storageErr) {
+		t.Errorf("expected storage err from Run add, got %v", err)
+	}
+
+	mockStorage.EXPECT().GetReminders(gomock.Any()).Return(nil, storageErr).Times(2)
+
+	if _, err := cmd.Run("list", ctx); !errors.Is(err, storageErr) {
+		t.Errorf("expected storage err from Run list, got %v", err)
+	}
+
+	if _, err := cmd.Run("cancel abc123", ctx); !errors.Is(err, storageErr) {
+		t.Errorf("expected storage err from Run cancel, got %v", err)
+	}
+}
+
+func TestRunCancelDelivered(t *testing.T) {
+	cmd, _, mockStorage, ctx := setup(t)
+
+	mockStorage.EXPECT().GetReminders(gomock.Any()).Return([]models.Reminder{
+		{ID: "abc123", Creator: "U001", Text: "feed the frog"},
+	}, nil)
+	// The reminder was delivered between being listed and cancelled.
+	mockStorage.EXPECT().CancelReminder("abc123").Return(false, nil)
+
+	expected := ":shrug: You don't have a reminder `abc123`"
+
+	if res, err := cmd.Run("cancel abc123", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q got %q", expected, res.Message)
+	}
+}
@@ -0,0 +1,74 @@
+package repost
+
+import (
+	"html"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that are always removed from links.
+// Parameters starting with "utm_" are removed too.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"igshid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"ref_src": true,
+	"si":      true,
+}
+
+// canonicalHost returns a host name in lowercase without a trailing dot or a
+// "www." or "m." (mobile) prefix.
+func canonicalHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	for _, prefix := range []string{"www.", "m."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	return host
+}
+
+// canonicalURL returns the canonical form of a link so that the same link
+// shared in slightly different ways compares equal. HTML escaping (e.g. "&amp;"
+// in Slack messages) is undone, the scheme is always "https", the host is
+// canonicalized by canonicalHost, and user info, default ports, fragments and
+// trailing slashes are removed. Tracking parameters and the given params are
+// removed from the query and the remaining parameters are sorted. It returns
+// false if the link isn't an HTTP(S) URL.
+func canonicalURL(link string, params map[string]bool) (*url.URL, bool) {
+	u, err := url.Parse(html.UnescapeString(link))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return nil, false
+	}
+
+	host := canonicalHost(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	query := u.Query()
+	for param := range query {
+		if trackingParams[param] || params[param] || strings.HasPrefix(param, "utm_") {
+			query.Del(param)
+		}
+	}
+
+	return &url.URL{
+		Scheme:   "https",
+		Host:     host,
+		Path:     strings.TrimRight(u.Path, "/"),
+		RawQuery: query.Encode(),
+	}, true
+}
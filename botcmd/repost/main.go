@@ -0,0 +1,238 @@
+// Package repost provides a pattern handler that remembers every link shared
+// and calls out links that were already shared recently.
+package repost
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	patternName = "reposts"
+	// linkRegexp matches an HTTP(S) link in a Slack message like
+	// "<https://frog.tips>" or "<https://frog.tips|frog.tips>".
+	linkRegexp          = `(?i)<(https?://[^|>]+)(?:\|[^>]*)?>`
+	expectedSubmatchLen = 2
+
+	// defaultLookback is how recently a link must have been shared for sharing
+	// it again to be called out, if the config doesn't say.
+	defaultLookback = 30 * 24 * time.Hour
+)
+
+type repostPattern struct {
+	log      *logrus.Logger
+	enabled  bool
+	lookback time.Duration
+	// ignoreHosts is the set of canonical hosts whose links are ignored.
+	ignoreHosts map[string]bool
+	// ignoreChannels is the set of channel names whose links are ignored.
+	ignoreChannels map[string]bool
+	// stripParams is the set of extra query parameters removed from links.
+	stripParams map[string]bool
+}
+
+func init() {
+	botcmd.MustAddPattern(&botcmd.PatternCommand{
+		Name:    patternName,
+		Handler: &repostPattern{},
+		Pattern: regexp.MustCompile(linkRegexp),
+	})
+}
+
+var errBadLookback = errors.New("lookback must be positive")
+
+type errUnexpectedSubmatchLen struct {
+	got interface{}
+}
+
+func (e errUnexpectedSubmatchLen) Error() string {
+	return fmt.Sprintf("%s pattern found submatch with unexpected len: %#v",
+		patternName, e.got)
+}
+
+//nolint:funlen
+func (p repostPattern) Run(allSubmatches [][]string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	msg := runCtx.Message
+	if msg == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s pattern error: %w", patternName, botcmd.ErrNilMessage)
+	}
+
+	// Don't remember links in commands (e.g. "!urls seen") or in the bot's own
+	// replies.
+	if !p.enabled || strings.HasPrefix(msg.Text, "!") || msg.UserID == runCtx.Slack.BotID() {
+		return botcmd.RunResult{}, nil
+	}
+
+	channel := runCtx.Slack.ConversationName(msg.ChannelID)
+	if p.ignoreChannels[channel] {
+		return botcmd.RunResult{}, nil
+	}
+
+	log := runCtx.Logger(p.log)
+	now := botcmd.EventTime(runCtx.Slack, msg.Timestamp)
+	shared := make(map[string]bool)
+
+	var callouts []string
+
+	for _, submatches := range allSubmatches {
+		if len(submatches) != expectedSubmatchLen {
+			return botcmd.RunResult{}, errUnexpectedSubmatchLen{submatches}
+		}
+
+		u, ok := canonicalURL(submatches[1], p.stripParams)
+		if !ok || p.ignored(u.Hostname()) || shared[u.String()] {
+			continue
+		}
+
+		link := u.String()
+		shared[link] = true
+
+		previous, err := runCtx.Storage.GetLinkShares(storage.GetLinkShareOptions{
+			FindOptions: storage.FindOptions{SortField: "time", Asc: true, Limit: 1},
+			URL:         link,
+			Since:       now.Add(-p.lookback),
+		})
+		if err != nil {
+			return botcmd.RunResult{},
+				fmt.Errorf("%s pattern error getting shares of %q: %w", patternName, link, err)
+		}
+
+		share := models.LinkShare{
+			URL:       link,
+			User:      msg.UserID,
+			Channel:   msg.ChannelID,
+			Public:    runCtx.Slack.ConversationPublic(msg.ChannelID),
+			Timestamp: msg.Timestamp,
+			Time:      now,
+		}
+
+		if err := runCtx.Storage.AddLinkShare(share); err != nil {
+			return botcmd.RunResult{},
+				fmt.Errorf("%s pattern error adding %s: %w", patternName, share, err)
+		}
+
+		if len(previous) == 0 {
+			continue
+		}
+
+		log.Infof("%s pattern saw repost of %s", patternName, previous[0])
+
+		what := "That link"
+		if len(allSubmatches) > 1 {
+			what = link
+		}
+
+		callouts = append(callouts, callout(what, previous[0], msg.ChannelID, now, runCtx))
+	}
+
+	return botcmd.RunResult{Message: strings.Join(callouts, "\n")}, nil
+}
+
+// callout returns the message calling out a repost of the earlier share. Who
+// shared the link and where is left out if the earlier share was in a
+// conversation that isn't public, unless that's the conversation the link was
+// shared in again.
+func callout(what string, previous models.LinkShare, channelID string, now time.Time, runCtx botcmd.RunContext) string {
+	when := ago(now.Sub(previous.Time))
+
+	if !previous.Public && previous.Channel != channelID {
+		return fmt.Sprintf(":older_man: Old! %s was already shared %s", what, when)
+	}
+
+	return fmt.Sprintf(":older_man: Old! %s was already shared by _%s_ %s in #%s",
+		what,
+		runCtx.Slack.UserName(previous.User),
+		when,
+		runCtx.Slack.ConversationName(previous.Channel))
+}
+
+// ignored returns true if links to the given canonical host, or a subdomain of
+// one, are ignored.
+func (p repostPattern) ignored(host string) bool {
+	for {
+		if p.ignoreHosts[host] {
+			return true
+		}
+
+		i := strings.Index(host, ".")
+		if i < 0 {
+			return false
+		}
+
+		host = host[i+1:]
+	}
+}
+
+// ago describes how long ago something happened like "3 days ago".
+func ago(d time.Duration) string {
+	var (
+		n    int
+		unit string
+	)
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	default:
+		n, unit = int(d/(24*time.Hour)), "day"
+	}
+
+	if n != 1 {
+		unit += "s"
+	}
+
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+func (p *repostPattern) Configure(log *logrus.Logger, c *config.Config) error {
+	p.log = log
+	p.enabled = false
+	p.lookback = defaultLookback
+	p.ignoreHosts = make(map[string]bool)
+	p.ignoreChannels = make(map[string]bool)
+	p.stripParams = make(map[string]bool)
+
+	if c == nil {
+		return nil
+	}
+
+	conf := c.RepostConf
+	p.enabled = conf.Enabled
+
+	if conf.Lookback != nil {
+		if *conf.Lookback <= 0 {
+			return fmt.Errorf("%s pattern config error: %w, got %s",
+				patternName, errBadLookback, *conf.Lookback)
+		}
+
+		p.lookback = *conf.Lookback
+	}
+
+	for _, host := range conf.IgnoreHosts {
+		p.ignoreHosts[canonicalHost(host)] = true
+	}
+
+	for _, channel := range conf.IgnoreChannels {
+		p.ignoreChannels[strings.TrimPrefix(channel, "#")] = true
+	}
+
+	for _, param := range conf.StripParams {
+		p.stripParams[param] = true
+	}
+
+	return nil
+}
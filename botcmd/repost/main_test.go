@@ -0,0 +1,239 @@
+//nolint:funlen,goerr113
+package repost
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+var testTime = time.Date(2021, 1, 5, 14, 0, 0, 0, time.UTC)
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	p := &repostPattern{}
+
+	if err := p.Configure(log, nil); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	} else if p.enabled || p.lookback != defaultLookback {
+		t.Errorf("expected disabled pattern with default lookback, got %v %s", p.enabled, p.lookback)
+	}
+
+	oneHour := time.Hour
+
+	err := p.Configure(log, &config.Config{RepostConf: config.RepostConfig{
+		Enabled:        true,
+		Lookback:       &oneHour,
+		IgnoreHosts:    []string{"WWW.Giphy.com"},
+		IgnoreChannels: []string{"#memes"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	} else if !p.enabled || p.lookback != time.Hour || !p.ignoreHosts["giphy.com"] || !p.ignoreChannels["memes"] {
+		t.Errorf("expected config to be loaded, got %#v", p)
+	}
+
+	zero := time.Duration(0)
+
+	err = p.Configure(log, &config.Config{RepostConf: config.RepostConfig{Enabled: true, Lookback: &zero}})
+	if !errors.Is(err, errBadLookback) {
+		t.Errorf("expected errBadLookback from Configure with a zero lookback, got %v", err)
+	}
+}
+
+func setup(t *testing.T, conf config.RepostConfig) (*repostPattern, botcmd.RunContext, *mocks.MockStorage) {
+	log, _ := logtest.NewNullLogger()
+	p := &repostPattern{}
+
+	if err := p.Configure(log, &config.Config{RepostConf: conf}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U002", ChannelID: "C001", Timestamp: "1609855200.000001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().BotID().Return("UBOT").AnyTimes()
+	mockClient.EXPECT().ParseTimestamp("1609855200.000001").Return(testTime, nil).AnyTimes()
+	mockClient.EXPECT().UserName("U001").Return("alice").AnyTimes()
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+	mockClient.EXPECT().ConversationName("C002").Return("random").AnyTimes()
+	mockClient.EXPECT().ConversationName("C003").Return("memes").AnyTimes()
+	mockClient.EXPECT().ConversationPublic("C001").Return(true).AnyTimes()
+
+	return p, ctx, mockStorage
+}
+
+// links returns the submatches of the pattern for the given links.
+func links(links ...string) [][]string {
+	submatches := make([][]string, 0, len(links))
+	for _, link := range links {
+		submatches = append(submatches, []string{"<" + link + ">", link})
+	}
+
+	return submatches
+}
+
+// expectShare expects a lookup of the previous shares of a link returning the
+// given share (if any), and the new share being added.
+func expectShare(mockStorage *mocks.MockStorage, link string, previous *models.LinkShare) {
+	var shares []models.LinkShare
+	if previous != nil {
+		shares = append(shares, *previous)
+	}
+
+	mockStorage.EXPECT().GetLinkShares(storage.GetLinkShareOptions{
+		FindOptions: storage.FindOptions{SortField: "time", Asc: true, Limit: 1},
+		URL:         link,
+		Since:       testTime.Add(-defaultLookback),
+	}).Return(shares, nil)
+	mockStorage.EXPECT().AddLinkShare(models.LinkShare{
+		URL:       link,
+		User:      "U002",
+		Channel:   "C001",
+		Public:    true,
+		Timestamp: "1609855200.000001",
+		Time:      testTime,
+	}).Return(nil)
+}
+
+func TestRun(t *testing.T) {
+	p, ctx, mockStorage := setup(t, config.RepostConfig{Enabled: true, IgnoreHosts: []string{"giphy.com"}})
+
+	old := &models.LinkShare{URL: "https://frog.tips", User: "U001", Channel: "C002", Public: true, Time: testTime.Add(-72 * time.Hour)}
+	expectShare(mockStorage, "https://frog.tips", old)
+
+	res, err := p.Run(links("https://frog.tips/?utm_source=gorf"), ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := ":older_man: Old! That link was already shared by _alice_ 3 days ago in #random"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	// Each link in a message is checked once, ignored hosts (and their
+	// subdomains) aren't checked at all, and links that were shared before are
+	// named. Earlier shares in the same conversation are described in full even
+	// if it isn't public.
+	recent := &models.LinkShare{URL: "https://example.com/a", User: "U001", Channel: "C001", Time: testTime.Add(-time.Hour)}
+	expectShare(mockStorage, "https://example.com/a", recent)
+	expectShare(mockStorage, "https://example.com/b", nil)
+
+	res, err = p.Run(links(
+		"https://example.com/a",
+		"https://media.giphy.com/frog.gif",
+		"http://www.example.com/a/",
+		"https://example.com/b",
+	), ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := ":older_man: Old! https://example.com/a was already shared by _alice_ 1 hour ago in #general"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	// Who shared a link and where isn't given away if it was first shared
+	// somewhere that isn't public.
+	private := &models.LinkShare{URL: "https://frog.tips", User: "U001", Channel: "D001", Time: testTime.Add(-time.Hour)}
+	expectShare(mockStorage, "https://frog.tips", private)
+
+	res, err = p.Run(links("https://frog.tips"), ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := ":older_man: Old! That link was already shared 1 hour ago"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetLinkShares(gomock.Any()).Return(nil, errors.New("oops"))
+
+	if _, err := p.Run(links("https://frog.tips"), ctx); err == nil {
+		t.Errorf("expected err from Run with a storage err, got nil")
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	testCases := []struct {
+		name string
+		conf config.RepostConfig
+		msg  slack.Message
+	}{
+		{
+			name: "disabled",
+			msg:  slack.Message{UserID: "U002", ChannelID: "C001"},
+		},
+		{
+			name: "command",
+			conf: config.RepostConfig{Enabled: true},
+			msg:  slack.Message{UserID: "U002", ChannelID: "C001", Text: "!urls seen <https://frog.tips>"},
+		},
+		{
+			name: "bot",
+			conf: config.RepostConfig{Enabled: true},
+			msg:  slack.Message{UserID: "UBOT", ChannelID: "C001"},
+		},
+		{
+			name: "channel",
+			conf: config.RepostConfig{Enabled: true, IgnoreChannels: []string{"memes"}},
+			msg:  slack.Message{UserID: "U002", ChannelID: "C003"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// The mock storage fails the test if it's used.
+			p, ctx, _ := setup(t, tc.conf)
+			ctx.Message = &tc.msg
+
+			if res, err := p.Run(links("https://frog.tips"), ctx); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			} else if res.Message != "" {
+				t.Errorf("expected no message, got %q", res.Message)
+			}
+		})
+	}
+
+	p, ctx, _ := setup(t, config.RepostConfig{Enabled: true})
+	ctx.Message = nil
+
+	if _, err := p.Run(links("https://frog.tips"), ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestAgo(t *testing.T) {
+	testCases := map[time.Duration]string{
+		30 * time.Second:     "just now",
+		time.Minute:          "1 minute ago",
+		59 * time.Minute:     "59 minutes ago",
+		25 * time.Hour:       "1 day ago",
+		100 * 24 * time.Hour: "100 days ago",
+	}
+
+	for d, expected := range testCases {
+		if got := ago(d); got != expected {
+			t.Errorf("expected %s to be %q, got %q", d, expected, got)
+		}
+	}
+}
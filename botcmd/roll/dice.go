@@ -0,0 +1,269 @@
+package roll
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxTerms is the maximum number of dice and numbers in an expression.
+	maxTerms = 20
+	// maxDice is the maximum number of dice rolled for one term, including
+	// extra dice from exploding dice.
+	maxDice = 100
+	// maxSides is the maximum number of sides of a die.
+	maxSides = 1000
+	// maxNumber is the maximum number added to or subtracted from a roll.
+	maxNumber = 1000000
+)
+
+var (
+	errEmptyTerm      = errors.New("expected dice like `2d6` or a number")
+	errBadTerm        = errors.New("can't understand")
+	errTooManyTerms   = errors.New("too many dice and numbers, the limit is")
+	errTooManyDice    = errors.New("too many dice, the limit is")
+	errBadSides       = errors.New("dice need between 1 and 1000 sides, not")
+	errBadNumber      = errors.New("numbers must be at most 1000000, not")
+	errBadKeep        = errors.New("can't keep or drop")
+	errExplodeOneSide = errors.New("can't explode dice with one side")
+)
+
+// diceRegexp matches dice notation like "d20", "4d6kh3", "2d10!" or "d%".
+// The submatches are the count, the sides, the explode marker and the keep or
+// drop modifier and its number.
+var diceRegexp = regexp.MustCompile(`^(\d*)d(\d+|%)(!?)(?:(kh|kl|dh|dl|k)(\d+))?$`)
+
+// term is one set of dice or a number in a dice expression.
+type term struct {
+	// negative is true if the term is subtracted.
+	negative bool
+	// number is the value of a term without dice.
+	number int
+	// count is the number of dice rolled. It is zero for a number.
+	count int
+	sides int
+	// explode rolls another die for each die that rolls its maximum.
+	explode bool
+	// keep is the keep or drop modifier ("kh", "kl", "dh" or "dl"), or "".
+	keep  string
+	keepN int
+}
+
+// die is the result of rolling one die.
+type die struct {
+	value int
+	// exploded is true if the die rolled its maximum and exploded.
+	exploded bool
+	// dropped is true if the die was dropped by a keep or drop modifier.
+	dropped bool
+}
+
+// roll is the result of rolling a term.
+type roll struct {
+	term
+	dice []die
+}
+
+// total returns the sum of the kept dice, or the number of a term without
+// dice. It is negative for subtracted terms.
+func (r roll) total() int {
+	total := r.number
+
+	for _, d := range r.dice {
+		if !d.dropped {
+			total += d.value
+		}
+	}
+
+	if r.negative {
+		return -total
+	}
+
+	return total
+}
+
+// String returns the dice rolled like "[6, 5, ~2~, 3]", or the number of a
+// term without dice. Exploded dice are marked with a "!" and dropped dice are
+// struck through.
+func (r roll) String() string {
+	if r.count == 0 {
+		return strconv.Itoa(r.number)
+	}
+
+	values := make([]string, len(r.dice))
+
+	for i, d := range r.dice {
+		values[i] = strconv.Itoa(d.value)
+		if d.exploded {
+			values[i] += "!"
+		}
+
+		if d.dropped {
+			values[i] = "~" + values[i] + "~"
+		}
+	}
+
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// parseDice returns the terms of a dice expression like "4d6kh3+2". Spaces
+// are ignored.
+func parseDice(expr string) ([]term, error) {
+	expr = strings.ToLower(strings.Join(strings.Fields(expr), ""))
+
+	var terms []term
+
+	for start := 0; start < len(expr); {
+		negative := false
+
+		switch expr[start] {
+		case '-':
+			negative = true
+
+			fallthrough
+		case '+':
+			start++
+		}
+
+		end := start + strings.IndexAny(expr[start:], "+-")
+		if end < start {
+			end = len(expr)
+		}
+
+		t, err := parseTerm(expr[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		t.negative = negative
+		terms = append(terms, t)
+
+		if len(terms) > maxTerms {
+			return nil, fmt.Errorf("%w %d", errTooManyTerms, maxTerms)
+		}
+
+		start = end
+	}
+
+	if len(terms) == 0 {
+		return nil, errEmptyTerm
+	}
+
+	return terms, nil
+}
+
+// parseTerm returns the term for dice like "4d6kh3" or a number like "2".
+func parseTerm(text string) (term, error) {
+	if text == "" {
+		return term{}, errEmptyTerm
+	}
+
+	if n, err := strconv.Atoi(text); err == nil {
+		if n > maxNumber {
+			return term{}, fmt.Errorf("%w %d", errBadNumber, n)
+		}
+
+		return term{number: n}, nil
+	}
+
+	m := diceRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return term{}, fmt.Errorf("%w `%s`", errBadTerm, text)
+	}
+
+	t := term{count: 1, sides: 100, explode: m[3] != "", keep: m[4]}
+
+	if m[1] != "" {
+		t.count, _ = strconv.Atoi(m[1])
+	}
+
+	if m[2] != "%" {
+		t.sides, _ = strconv.Atoi(m[2])
+	}
+
+	if t.keep == "k" {
+		t.keep = "kh"
+	}
+
+	if t.keep != "" {
+		t.keepN, _ = strconv.Atoi(m[5])
+	}
+
+	switch {
+	case t.count < 1 || t.count > maxDice:
+		return term{}, fmt.Errorf("%w %d", errTooManyDice, maxDice)
+	case t.sides < 1 || t.sides > maxSides:
+		return term{}, fmt.Errorf("%w %d", errBadSides, t.sides)
+	case t.explode && t.sides == 1:
+		return term{}, errExplodeOneSide
+	case t.keep != "" && t.keepN > t.count:
+		return term{}, fmt.Errorf("%w %d of %d dice", errBadKeep, t.keepN, t.count)
+	}
+
+	return t, nil
+}
+
+// rollTerm rolls the dice of a term and applies its modifiers.
+func rollTerm(t term) (roll, error) {
+	r := roll{term: t}
+
+	for i := 0; i < t.count; i++ {
+		for {
+			if len(r.dice) >= maxDice {
+				return roll{}, fmt.Errorf("%w %d", errTooManyDice, maxDice)
+			}
+
+			d := die{value: rand.Intn(t.sides) + 1} //nolint:gosec
+			d.exploded = t.explode && d.value == t.sides
+			r.dice = append(r.dice, d)
+
+			if !d.exploded {
+				break
+			}
+		}
+	}
+
+	if t.keep == "" {
+		return r, nil
+	}
+
+	// Sort the indexes of the dice from lowest to highest value, keeping the
+	// order the dice were rolled in for display.
+	order := make([]int, len(r.dice))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.dice[order[i]].value < r.dice[order[j]].value
+	})
+
+	// Work out which dice to drop from the low end or the high end.
+	var low, high int
+
+	switch t.keep {
+	case "kh":
+		low = len(r.dice) - t.keepN
+	case "kl":
+		high = len(r.dice) - t.keepN
+	case "dh":
+		high = t.keepN
+	case "dl":
+		low = t.keepN
+	}
+
+	for i := 0; i < low && i < len(order); i++ {
+		r.dice[order[i]].dropped = true
+	}
+
+	for i := 0; i < high && i < len(order); i++ {
+		r.dice[order[len(order)-1-i]].dropped = true
+	}
+
+	return r, nil
+}
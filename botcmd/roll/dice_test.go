@@ -0,0 +1,161 @@
+package roll
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParseDice(t *testing.T) {
+	testCases := []struct {
+		expr        string
+		expected    []term
+		expectedErr error
+	}{
+		{expr: "d20", expected: []term{{count: 1, sides: 20}}},
+		{expr: "D%", expected: []term{{count: 1, sides: 100}}},
+		{
+			expr: "4d6kh3 + 2",
+			expected: []term{
+				{count: 4, sides: 6, keep: "kh", keepN: 3},
+				{number: 2},
+			},
+		},
+		{
+			expr: "-1+2d10!-d4k1",
+			expected: []term{
+				{negative: true, number: 1},
+				{count: 2, sides: 10, explode: true},
+				{negative: true, count: 1, sides: 4, keep: "kh", keepN: 1},
+			},
+		},
+		{expr: "3d8dl1", expected: []term{{count: 3, sides: 8, keep: "dl", keepN: 1}}},
+		{expr: "", expectedErr: errEmptyTerm},
+		{expr: "d20+", expectedErr: errEmptyTerm},
+		{expr: "d20++1", expectedErr: errEmptyTerm},
+		{expr: "frogs", expectedErr: errBadTerm},
+		{expr: "2d", expectedErr: errBadTerm},
+		{expr: "0d6", expectedErr: errTooManyDice},
+		{expr: "101d6", expectedErr: errTooManyDice},
+		{expr: "d0", expectedErr: errBadSides},
+		{expr: "d1001", expectedErr: errBadSides},
+		{expr: "d1!", expectedErr: errExplodeOneSide},
+		{expr: "2d6kh3", expectedErr: errBadKeep},
+		{expr: "d6+1000001", expectedErr: errBadNumber},
+		{expr: "1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1+1", expectedErr: errTooManyTerms},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			terms, err := parseDice(tc.expr)
+
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected err %v, got %v", tc.expectedErr, err)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(terms, tc.expected) {
+				t.Errorf("expected terms %+v, got %+v", tc.expected, terms)
+			}
+		})
+	}
+}
+
+func TestRollTerm(t *testing.T) {
+	rand.Seed(1)
+
+	for i := 0; i < 100; i++ {
+		r, err := rollTerm(term{count: 4, sides: 6, keep: "kh", keepN: 3})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var (
+			kept    int
+			minKept = 7
+			maxDrop int
+		)
+
+		for _, d := range r.dice {
+			if d.value < 1 || d.value > 6 {
+				t.Fatalf("expected d6 value, got %d", d.value)
+			}
+
+			if d.dropped {
+				maxDrop = d.value
+			} else {
+				kept++
+
+				if d.value < minKept {
+					minKept = d.value
+				}
+			}
+		}
+
+		if kept != 3 || len(r.dice) != 4 {
+			t.Fatalf("expected 3 of 4 dice kept, got %s", r)
+		}
+
+		if maxDrop > minKept {
+			t.Fatalf("expected the lowest die dropped, got %s", r)
+		}
+	}
+}
+
+func TestRollTermExplode(t *testing.T) {
+	rand.Seed(1)
+
+	var exploded bool
+
+	for i := 0; i < 100; i++ {
+		r, err := rollTerm(term{count: 1, sides: 2, explode: true})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		for j, d := range r.dice {
+			last := j == len(r.dice)-1
+			if d.exploded == last || d.exploded != (d.value == 2) {
+				t.Fatalf("expected every die but the last to explode, got %s", r)
+			}
+		}
+
+		exploded = exploded || len(r.dice) > 1
+	}
+
+	if !exploded {
+		t.Errorf("expected some dice to explode")
+	}
+}
+
+func TestRollString(t *testing.T) {
+	r := roll{
+		term: term{negative: true, count: 3, sides: 6, explode: true, keep: "dl", keepN: 1},
+		dice: []die{{value: 6, exploded: true}, {value: 2}, {value: 1, dropped: true}},
+	}
+
+	if s := r.String(); s != "[6!, 2, ~1~]" {
+		t.Errorf("expected %q, got %q", "[6!, 2, ~1~]", s)
+	}
+
+	if total := r.total(); total != -8 {
+		t.Errorf("expected total -8, got %d", total)
+	}
+
+	r = roll{term: term{number: 7}}
+
+	if s := r.String(); s != "7" {
+		t.Errorf("expected %q, got %q", "7", s)
+	}
+
+	if total := r.total(); total != 7 {
+		t.Errorf("expected total 7, got %d", total)
+	}
+}
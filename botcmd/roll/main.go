@@ -0,0 +1,122 @@
+// Package roll provides a command for rolling dice written in dice notation
+// like "4d6kh3+2", for tabletop games.
+package roll
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "roll"
+
+	// defaultDice are rolled when no dice are given.
+	defaultDice = "d20"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*roll*:\n" +
+		"\t`!roll` - roll a d20\n" +
+		"\t`!roll 2d6+3` - roll dice and add (or subtract) numbers and other dice\n" +
+		"\t`!roll 4d6kh3` - keep the highest 3 (or `kl` lowest, `dh` drop highest, `dl` drop lowest)\n" +
+		"\t`!roll 3d6!` - explode dice, rolling again whenever a die rolls its maximum\n" +
+		"\t`!roll adv +5` - roll a d20 with advantage (or `dis` disadvantage), e.g. `2d20kh1+5`"
+)
+
+type rollCmd struct {
+	log *logrus.Logger
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":game_die:",
+		Description: "Roll dice, e.g. `!roll 4d6kh3+2`",
+		Handler:     &rollCmd{},
+	})
+}
+
+func (cmd *rollCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+
+	if c != nil && c.RollConf.RandomSeed > 0 {
+		rand.Seed(c.RollConf.RandomSeed)
+	} else {
+		rand.Seed(time.Now().UnixNano())
+	}
+
+	return nil
+}
+
+func (cmd rollCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	expr := diceExpression(text)
+	if expr == "" {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	terms, err := parseDice(expr)
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	var (
+		total int
+		parts []string
+	)
+
+	for i, t := range terms {
+		r, err := rollTerm(t)
+		if err != nil {
+			return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+		}
+
+		total += r.total()
+
+		switch {
+		case r.negative:
+			parts = append(parts, "-", r.String())
+		case i > 0:
+			parts = append(parts, "+", r.String())
+		default:
+			parts = append(parts, r.String())
+		}
+	}
+
+	runCtx.Logger(cmd.log).Debugf("%s rolled %q: %d", cmdName, expr, total)
+
+	return botcmd.RunResult{
+		Message: fmt.Sprintf(":game_die: _%s_ rolled `%s`: %s = *%d*",
+			runCtx.Slack.UserName(runCtx.Message.UserID), expr,
+			strings.Join(parts, " "), total),
+	}, nil
+}
+
+// diceExpression returns the dice expression for the text of a roll command,
+// without spaces. Advantage ("adv") and disadvantage ("dis") are rewritten to
+// "2d20kh1" and "2d20kl1". It returns "" if usage was asked for.
+func diceExpression(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return defaultDice
+	}
+
+	switch words[0] {
+	case "-h", "help":
+		return ""
+	case "adv", "advantage":
+		words[0] = "2d20kh1"
+	case "dis", "disadvantage":
+		words[0] = "2d20kl1"
+	}
+
+	return strings.Join(words, "")
+}
@@ -0,0 +1,139 @@
+package roll
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*rollCmd, botcmd.RunContext) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &rollCmd{}
+
+	if err := cmd.Configure(log, &config.Config{RollConf: config.RollConfig{RandomSeed: 1}}); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{UserID: "U001", Text: "!roll"},
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd, ctx := setup(t)
+	ctx.Message = nil
+
+	if _, err := cmd.Run("", ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "usage",
+			text:     "help",
+			expected: usage,
+		},
+		{
+			name:     "numbers",
+			text:     "3d1 + 2 - 1d1",
+			expected: ":game_die: _daniel_ rolled `3d1+2-1d1`: [1, 1, 1] + 2 - [1] = *4*",
+		},
+		{
+			name:     "keep",
+			text:     "3D1kl1",
+			expected: ":game_die: _daniel_ rolled `3d1kl1`: [1, ~1~, ~1~] = *1*",
+		},
+		{
+			name:     "bad dice",
+			text:     "2d6kh3",
+			expected: "roll: can't keep or drop 3 of 2 dice",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx := setup(t)
+
+			res, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunRandom(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected *regexp.Regexp
+	}{
+		{
+			text:     "",
+			expected: regexp.MustCompile("^:game_die: _daniel_ rolled `d20`: \\[\\d+\\] = \\*\\d+\\*$"),
+		},
+		{
+			text: "adv +5",
+			expected: regexp.MustCompile(
+				"^:game_die: _daniel_ rolled `2d20kh1\\+5`: \\[(\\d+, ~\\d+~|~\\d+~, \\d+)\\] \\+ 5 = \\*\\d+\\*$"),
+		},
+		{
+			text: "disadvantage",
+			expected: regexp.MustCompile(
+				"^:game_die: _daniel_ rolled `2d20kl1`: \\[(\\d+, ~\\d+~|~\\d+~, \\d+)\\] = \\*\\d+\\*$"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.text, func(t *testing.T) {
+			cmd, ctx := setup(t)
+
+			first, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !tc.expected.MatchString(first.Message) {
+				t.Errorf("expected message matching %q, got %q", tc.expected, first.Message)
+			}
+
+			// The same seed rolls the same dice.
+			cmd, ctx = setup(t)
+
+			second, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if second.Message != first.Message {
+				t.Errorf("expected the same message %q with the same seed, got %q", first.Message, second.Message)
+			}
+		})
+	}
+}
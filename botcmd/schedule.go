@@ -0,0 +1,35 @@
+package botcmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule interface {
+	// Next returns the first scheduled time after the given time.
+	Next(after time.Time) time.Time
+}
+
+// ParseSchedule parses a standard five field cron expression (e.g.
+// "0 14 * * *") or descriptor (e.g. "@daily") into a Schedule. Times are
+// always scheduled in UTC.
+func ParseSchedule(expr string) (Schedule, error) {
+	s, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+
+	return utcSchedule{s}, nil
+}
+
+// utcSchedule is a cron.Schedule evaluated in UTC.
+type utcSchedule struct {
+	cron.Schedule
+}
+
+func (s utcSchedule) Next(after time.Time) time.Time {
+	return s.Schedule.Next(after.UTC())
+}
@@ -0,0 +1,114 @@
+// Package schedule provides an admin command for viewing the scheduled
+// commands.
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "schedule"
+
+	// timeLayout is the layout of the last and next run times.
+	timeLayout = "2006-01-02 15:04 MST"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage: `!schedule list`"
+)
+
+type scheduleCmd struct {
+	log       *logrus.Logger
+	conf      *config.Config
+	schedules []config.ScheduleEntry
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":alarm_clock:",
+		Description: "List the scheduled commands (admins only)",
+		Handler:     &scheduleCmd{},
+	})
+}
+
+func (cmd *scheduleCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	cmd.conf = c
+
+	if c != nil {
+		cmd.schedules = c.ScheduleConf.Schedules
+	}
+
+	return nil
+}
+
+func (cmd scheduleCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	userName := runCtx.Slack.UserName(runCtx.Message.UserID)
+	if cmd.conf == nil || !cmd.conf.IsAdmin(userName) {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":no_entry: Sorry _%s_, only admins can use `!%s`", userName, cmdName),
+		}, nil
+	}
+
+	switch strings.TrimSpace(text) {
+	case "list":
+		return cmd.list(runCtx)
+	default:
+		return botcmd.RunResult{Message: usage}, nil
+	}
+}
+
+// list returns a message describing each schedule's command, channel, cron
+// expression and last and next run.
+func (cmd scheduleCmd) list(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if len(cmd.schedules) == 0 {
+		return botcmd.RunResult{Message: ":alarm_clock: No commands are scheduled"}, nil
+	}
+
+	runs, err := runCtx.Storage.GetScheduledRuns()
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get scheduled runs: %w", cmdName, err)
+	}
+
+	lastRuns := make(map[string]string)
+	for _, run := range runs {
+		lastRuns[run.Command+"#"+run.Channel] = run.LastRun.UTC().Format(timeLayout)
+	}
+
+	now := botcmd.EventTime(runCtx.Slack, runCtx.Message.Timestamp)
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, ":alarm_clock: %d scheduled commands:\n", len(cmd.schedules))
+
+	for _, entry := range cmd.schedules {
+		lastRun, found := lastRuns[entry.Command+"#"+entry.Channel]
+		if !found {
+			lastRun = "never"
+		}
+
+		nextRun := "invalid cron expression"
+		if s, err := botcmd.ParseSchedule(entry.Cron); err == nil {
+			nextRun = s.Next(now).Format(timeLayout)
+		}
+
+		missed := ""
+		if entry.SkipMissed {
+			missed = " (missed runs skipped)"
+		}
+
+		fmt.Fprintf(buf, "\t:small_blue_diamond: _%s_ in *#%s* `%s`%s - last run: %s, next run: %s\n",
+			entry.Command, entry.Channel, entry.Cron, missed, lastRun, nextRun)
+	}
+
+	return botcmd.RunResult{Message: buf.String()}, nil
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T, c *config.Config) (*scheduleCmd, *slack_mocks.MockClient, *mocks.MockStorage, botcmd.RunContext) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &scheduleCmd{}
+
+	if err := cmd.Configure(log, c); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockSlack := slack_mocks.NewMockClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+	ctx := botcmd.RunContext{
+		Slack:   mockSlack,
+		Storage: mockStorage,
+		Message: &slack.Message{
+			UserID:    "U001",
+			Timestamp: "1610287200.000000",
+		},
+	}
+
+	mockSlack.EXPECT().UserName("U001").Return("alice")
+
+	return cmd, mockSlack, mockStorage, ctx
+}
+
+func TestRunNotAdmin(t *testing.T) {
+	cmd, _, _, ctx := setup(t, &config.Config{Admins: []string{"bob"}})
+
+	expected := ":no_entry: Sorry _alice_, only admins can use `!schedule`"
+
+	if res, err := cmd.Run("list", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q got %q", expected, res.Message)
+	}
+}
+
+func TestRunList(t *testing.T) {
+	cmd, mockSlack, mockStorage, ctx := setup(t, &config.Config{
+		Admins: []string{"alice"},
+		ScheduleConf: config.ScheduleConfig{
+			Schedules: []config.ScheduleEntry{
+				{Command: "on this day", Cron: "0 14 * * *", Channel: "general"},
+				{Command: "on this day", Cron: "nonsense", Channel: "random", SkipMissed: true},
+			},
+		},
+	})
+
+	now := time.Date(2021, 1, 10, 14, 0, 0, 0, time.UTC)
+	mockSlack.EXPECT().ParseTimestamp("1610287200.000000").Return(now, nil)
+	mockStorage.EXPECT().GetScheduledRuns().Return([]models.ScheduledRun{
+		{Command: "on this day", Channel: "general", LastRun: now},
+	}, nil)
+
+	expected := ":alarm_clock: 2 scheduled commands:\n" +
+		"\t:small_blue_diamond: _on this day_ in *#general* `0 14 * * *` - " +
+		"last run: 2021-01-10 14:00 UTC, next run: 2021-01-11 14:00 UTC\n" +
+		"\t:small_blue_diamond: _on this day_ in *#random* `nonsense` (missed runs skipped) - " +
+		"last run: never, next run: invalid cron expression\n"
+
+	if res, err := cmd.Run("list", ctx); err != nil {
+		t.Errorf("unexpected err from Run: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected message %q got %q", expected, res.Message)
+	}
+}
+
+func TestRunListError(t *testing.T) {
+	cmd, _, mockStorage, ctx := setup(t, &config.Config{
+		Admins: []string{"alice"},
+		ScheduleConf: config.ScheduleConfig{
+			Schedules: []config.ScheduleEntry{
+				{Command: "on this day", Cron: "@daily", Channel: "general"},
+			},
+		},
+	})
+
+	storageErr := errors.New("storage is sleeping")
+	mockStorage.EXPECT().GetScheduledRuns().Return(nil, storageErr)
+
+	if _, err := cmd.Run("list", ctx); !errors.Is(err, storageErr) {
+		t.Errorf("expected storage err from Run, got %v", err)
+	}
+}
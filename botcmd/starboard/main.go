@@ -0,0 +1,198 @@
+package starboard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	handlerName = "starboard"
+
+	defaultEmoji     = "star"
+	defaultThreshold = 5
+)
+
+type starboardHandler struct {
+	log *logrus.Logger
+	// channel is the name of the channel messages are reposted to. The handler
+	// does nothing if it is empty.
+	channel string
+	// rules maps channel names to the rule for the channel. The rule for the ""
+	// key applies to channels without their own rule.
+	rules map[string]config.StarboardRule
+}
+
+func init() {
+	botcmd.MustAddReactionHandler(&botcmd.ReactionCommand{
+		Name:    handlerName,
+		Handler: &starboardHandler{},
+	})
+}
+
+// rule returns the rule for the named channel, falling back to the default rule
+// for public channels. Messages in private channels, group DMs and DMs are only
+// reposted if a rule names their channel so they aren't shown to a wider
+// audience by default.
+func (h starboardHandler) rule(channelName string, public bool) (config.StarboardRule, bool) {
+	if channelName != "" {
+		if rule, found := h.rules[channelName]; found {
+			return rule, true
+		}
+	}
+
+	if !public {
+		return config.StarboardRule{}, false
+	}
+
+	rule, found := h.rules[""]
+
+	return rule, found
+}
+
+type errUnknownChannel struct {
+	name string
+}
+
+func (e errUnknownChannel) Error() string {
+	return fmt.Sprintf("%s handler error: unknown channel %q", handlerName, e.name)
+}
+
+func (h starboardHandler) Run(reaction *slack.Reaction, runCtx botcmd.RunContext) error {
+	// Only reactions to messages by someone other than the reacting user count.
+	if h.channel == "" || reaction.ItemTimestamp == "" || reaction.ItemUser == reaction.User {
+		return nil
+	}
+
+	channelName := runCtx.Slack.ConversationName(reaction.ItemChannel)
+	if channelName == h.channel {
+		return nil
+	}
+
+	rule, found := h.rule(channelName, runCtx.Slack.ConversationPublic(reaction.ItemChannel))
+	if !found {
+		return nil
+	}
+
+	// Compare the canonical name so e.g. a "+1::skin-tone-2" reaction counts
+	// for a "thumbsup" rule.
+	if emoji, _ := runCtx.Slack.CanonicalEmoji(reaction.Reaction); emoji != rule.Emoji {
+		return nil
+	}
+
+	msg := models.StarboardMessage{
+		Channel:   reaction.ItemChannel,
+		Timestamp: reaction.ItemTimestamp,
+		Emoji:     rule.Emoji,
+	}
+
+	prev, err := runCtx.Storage.UpsertStarboardCount(msg, reaction.Removed)
+	if err != nil {
+		return fmt.Errorf("%s storage returned err: %w", handlerName, err)
+	}
+
+	count := prev.Count + 1
+	if reaction.Removed || prev.Posted || count < rule.Threshold {
+		return nil
+	}
+
+	return h.repost(msg, count, runCtx)
+}
+
+// repost sends a quote of the starred message and its permalink to the
+// starboard channel. The message is only marked posted once everything needed
+// for the repost was fetched so a failure doesn't stop a later reaction from
+// reposting it.
+func (h starboardHandler) repost(msg models.StarboardMessage, count int, runCtx botcmd.RunContext) error {
+	channelID := runCtx.Slack.ConversationID(h.channel)
+	if channelID == "" {
+		return errUnknownChannel{h.channel}
+	}
+
+	original, err := runCtx.Slack.GetMessage(msg.Channel, msg.Timestamp)
+	if err != nil {
+		return fmt.Errorf("%s handler error getting message: %w", handlerName, err)
+	}
+
+	permalink, err := runCtx.Slack.GetPermalink(msg.Channel, msg.Timestamp)
+	if err != nil {
+		return fmt.Errorf("%s handler error getting permalink: %w", handlerName, err)
+	}
+
+	// Only the first handler run to mark the message posted reposts it.
+	if marked, err := runCtx.Storage.MarkStarboardPosted(msg); err != nil {
+		return fmt.Errorf("%s storage returned mark posted err: %w", handlerName, err)
+	} else if !marked {
+		return nil
+	}
+
+	runCtx.Logger(h.log).Infof("%s - reposting message %s in %s with %d %q reactions",
+		handlerName, msg.Timestamp, msg.Channel, count, msg.Emoji)
+
+	runCtx.Slack.SendMessage(formatRepost(original, msg.Emoji, count, permalink), channelID)
+
+	return nil
+}
+
+// formatRepost returns the starboard message for the original message.
+func formatRepost(original *slack.Message, emoji string, count int, permalink string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":%s: *%d* | <@%s> in <#%s>\n", emoji, count, original.UserID, original.ChannelID)
+
+	for _, line := range strings.Split(original.Text, "\n") {
+		fmt.Fprintf(&b, "> %s\n", line)
+	}
+
+	b.WriteString(permalink)
+
+	return b.String()
+}
+
+type errInvalidRule struct {
+	rule config.StarboardRule
+	why  string
+}
+
+func (e errInvalidRule) Error() string {
+	return fmt.Sprintf("%s config error: rule %#v %s", handlerName, e.rule, e.why)
+}
+
+func (h *starboardHandler) Configure(log *logrus.Logger, c *config.Config) error {
+	h.log = log
+	h.rules = make(map[string]config.StarboardRule)
+
+	if c == nil {
+		return nil
+	}
+
+	h.channel = strings.TrimPrefix(c.StarboardConf.Channel, "#")
+
+	for _, rule := range c.StarboardConf.Rules {
+		rule.Channel = strings.TrimPrefix(rule.Channel, "#")
+		rule.Emoji = strings.Trim(rule.Emoji, ":")
+
+		if rule.Emoji == "" {
+			rule.Emoji = defaultEmoji
+		}
+
+		if rule.Threshold == 0 {
+			rule.Threshold = defaultThreshold
+		} else if rule.Threshold < 0 {
+			return errInvalidRule{rule, "has a negative threshold"}
+		}
+
+		if _, found := h.rules[rule.Channel]; found {
+			return errInvalidRule{rule, "is a duplicate of an earlier rule for the same channel"}
+		}
+
+		h.rules[rule.Channel] = rule
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+//nolint:goerr113,funlen
+package starboard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func setup(t *testing.T) (*starboardHandler, botcmd.RunContext, *mocks.MockStorage, *slack_mocks.MockClient) {
+	t.Helper()
+
+	log, _ := logtest.NewNullLogger()
+	cmd := &starboardHandler{}
+
+	err := cmd.Configure(log, &config.Config{
+		StarboardConf: config.StarboardConfig{
+			Channel: "#hall-of-fame",
+			Rules: []config.StarboardRule{
+				{Threshold: 2},
+				{Channel: "random", Emoji: ":fire:", Threshold: 3},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	mockClient.EXPECT().ConversationPublic("C001").Return(true).AnyTimes()
+	mockClient.EXPECT().CanonicalEmoji(gomock.Any()).DoAndReturn(func(name string) (string, bool) {
+		return strings.Split(name, "::")[0], true
+	}).AnyTimes()
+
+	ctx := botcmd.RunContext{
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	return cmd, ctx, mockStorage, mockClient
+}
+
+func starReaction() *slack.Reaction {
+	return &slack.Reaction{
+		User:          "U002",
+		Reaction:      "star",
+		Timestamp:     "1600000010.000000",
+		ItemChannel:   "C001",
+		ItemUser:      "U001",
+		ItemTimestamp: "1600000001.000000",
+	}
+}
+
+var starMsg = models.StarboardMessage{
+	Channel:   "C001",
+	Timestamp: "1600000001.000000",
+	Emoji:     "star",
+}
+
+func TestConfigure(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &starboardHandler{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Errorf("expected no err from configure, got %v", err)
+	}
+
+	if cmd.log != log {
+		t.Errorf("expected log to be %p was %p", log, cmd.log)
+	}
+
+	badConfigs := map[string]config.StarboardConfig{
+		"negative threshold": {
+			Channel: "hall-of-fame",
+			Rules:   []config.StarboardRule{{Threshold: -1}},
+		},
+		"duplicate rule": {
+			Channel: "hall-of-fame",
+			Rules:   []config.StarboardRule{{Channel: "general"}, {Channel: "#general"}},
+		},
+	}
+
+	for name, conf := range badConfigs {
+		if err := cmd.Configure(log, &config.Config{StarboardConf: conf}); err == nil {
+			t.Errorf("expected err from configure with %s, got nil", name)
+		}
+	}
+
+	if err := cmd.Configure(log, &config.Config{
+		StarboardConf: config.StarboardConfig{
+			Channel: "hall-of-fame",
+			Rules:   []config.StarboardRule{{Channel: "#general"}},
+		},
+	}); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	expectedRule := config.StarboardRule{Channel: "general", Emoji: defaultEmoji, Threshold: defaultThreshold}
+	if rule, found := cmd.rule("general", true); !found || rule != expectedRule {
+		t.Errorf("expected rule %#v for general, got %#v", expectedRule, rule)
+	}
+
+	if _, found := cmd.rule("random", true); found {
+		t.Errorf("expected no rule for random")
+	}
+}
+
+func TestRunIgnored(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reaction func(*slack.Reaction)
+	}{
+		{
+			name:     "self reaction",
+			reaction: func(r *slack.Reaction) { r.User = r.ItemUser },
+		},
+		{
+			name:     "no item timestamp",
+			reaction: func(r *slack.Reaction) { r.ItemTimestamp = "" },
+		},
+		{
+			name:     "other emoji",
+			reaction: func(r *slack.Reaction) { r.Reaction = "fire" },
+		},
+		{
+			name:     "hall of fame channel",
+			reaction: func(r *slack.Reaction) { r.ItemChannel = "C003" },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, _, mockClient := setup(t)
+
+			mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+			mockClient.EXPECT().ConversationName("C003").Return("hall-of-fame").AnyTimes()
+
+			reaction := starReaction()
+			tc.reaction(reaction)
+
+			if err := cmd.Run(reaction, ctx); err != nil {
+				t.Errorf("expected no err, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunDisabled(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &starboardHandler{}
+
+	if err := cmd.Configure(log, nil); err != nil {
+		t.Fatalf("unexpected configure err: %v", err)
+	}
+
+	if err := cmd.Run(starReaction(), botcmd.RunContext{}); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunBelowThreshold(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(models.StarboardMessage{}, nil)
+
+	if err := cmd.Run(starReaction(), ctx); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunRemoved(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	reaction := starReaction()
+	reaction.Removed = true
+
+	prev := starMsg
+	prev.Count = 5
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, true).Return(prev, nil)
+
+	if err := cmd.Run(reaction, ctx); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunAlreadyPosted(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	prev := starMsg
+	prev.Count = 1
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(prev, nil)
+	mockClient.EXPECT().ConversationID("hall-of-fame").Return("C003")
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(&slack.Message{}, nil)
+	mockClient.EXPECT().GetPermalink("C001", "1600000001.000000").Return("", nil)
+	mockStorage.EXPECT().MarkStarboardPosted(starMsg).Return(false, nil)
+
+	if err := cmd.Run(starReaction(), ctx); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunRepost(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	prev := starMsg
+	prev.Count = 1
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(prev, nil)
+	mockClient.EXPECT().ConversationID("hall-of-fame").Return("C003")
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(&slack.Message{
+		ChannelID: "C001",
+		UserID:    "U001",
+		Text:      "gorf\nis great",
+	}, nil)
+	mockClient.EXPECT().GetPermalink("C001", "1600000001.000000").
+		Return("https://test.slack.com/archives/C001/p1600000001000000", nil)
+	mockStorage.EXPECT().MarkStarboardPosted(starMsg).Return(true, nil)
+	mockClient.EXPECT().SendMessage(
+		":star: *2* | <@U001> in <#C001>\n> gorf\n> is great\nhttps://test.slack.com/archives/C001/p1600000001000000",
+		"C003")
+
+	if err := cmd.Run(starReaction(), ctx); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunStorageErr(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).
+		Return(models.StarboardMessage{}, errors.New("blorp failure"))
+
+	expectedErr := "starboard storage returned err: blorp failure"
+	if err := cmd.Run(starReaction(), ctx); err == nil {
+		t.Errorf("expected err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRunUnknownChannel(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	prev := starMsg
+	prev.Count = 1
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(prev, nil)
+	mockClient.EXPECT().ConversationID("hall-of-fame").Return("")
+
+	expectedErr := `starboard handler error: unknown channel "hall-of-fame"`
+	if err := cmd.Run(starReaction(), ctx); err == nil {
+		t.Errorf("expected err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
+
+func TestRunSkinTone(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	reaction := starReaction()
+	reaction.Reaction = "star::skin-tone-3"
+
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(models.StarboardMessage{}, nil)
+
+	if err := cmd.Run(reaction, ctx); err != nil {
+		t.Errorf("expected no err, got %v", err)
+	}
+}
+
+func TestRunPrivate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		channelName string
+		expectCount bool
+	}{
+		{
+			name: "unknown conversation",
+		},
+		{
+			name:        "private channel without a rule",
+			channelName: "secret",
+		},
+		{
+			name:        "private channel with a rule",
+			channelName: "random",
+			expectCount: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage, mockClient := setup(t)
+
+			reaction := starReaction()
+			reaction.ItemChannel = "C002"
+			reaction.Reaction = "fire"
+
+			mockClient.EXPECT().ConversationName("C002").Return(tc.channelName)
+			mockClient.EXPECT().ConversationPublic("C002").Return(false)
+
+			if tc.expectCount {
+				msg := models.StarboardMessage{Channel: "C002", Timestamp: reaction.ItemTimestamp, Emoji: "fire"}
+				mockStorage.EXPECT().UpsertStarboardCount(msg, false).Return(models.StarboardMessage{}, nil)
+			}
+
+			if err := cmd.Run(reaction, ctx); err != nil {
+				t.Errorf("expected no err, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunRepostErr(t *testing.T) {
+	cmd, ctx, mockStorage, mockClient := setup(t)
+
+	prev := starMsg
+	prev.Count = 1
+
+	// The message isn't marked posted when the repost fails so a later reaction
+	// can try again.
+	mockClient.EXPECT().ConversationName("C001").Return("general")
+	mockStorage.EXPECT().UpsertStarboardCount(starMsg, false).Return(prev, nil)
+	mockClient.EXPECT().ConversationID("hall-of-fame").Return("C003")
+	mockClient.EXPECT().GetMessage("C001", "1600000001.000000").Return(&slack.Message{}, nil)
+	mockClient.EXPECT().GetPermalink("C001", "1600000001.000000").Return("", errors.New("blorp failure"))
+
+	expectedErr := "starboard handler error getting permalink: blorp failure"
+	if err := cmd.Run(starReaction(), ctx); err == nil {
+		t.Errorf("expected err, got nil")
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
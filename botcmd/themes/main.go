@@ -78,7 +78,7 @@ func (cmd themesCmd) add(text string, runCtx botcmd.RunContext) (botcmd.RunResul
 		Creator: runCtx.Message.UserID,
 	}
 	creatorName := runCtx.Slack.UserName(theme.Creator)
-	cmd.log.Infof("%s adding theme %q with name %q and creator %q (%s)\n",
+	runCtx.Logger(cmd.log).Infof("%s adding theme %q with name %q and creator %q (%s)\n",
 		cmdName, theme.Theme, theme.Name, creatorName, theme.Creator)
 
 	if err := runCtx.Storage.AddTheme(theme); err != nil {
@@ -0,0 +1,126 @@
+package botcmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/slack"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+
+	// dateLayout is the layout of absolute dates accepted by ParseTime.
+	dateLayout = "2006-01-02"
+)
+
+// EventTime returns the time of the Slack event with the given timestamp
+// according to the client, or the current time if the timestamp can't be
+// parsed.
+func EventTime(client slack.Client, ts string) time.Time {
+	if t, err := client.ParseTimestamp(ts); err == nil {
+		return t
+	}
+
+	return time.Now()
+}
+
+type errBadTime struct {
+	input string
+}
+
+func (e errBadTime) Error() string {
+	return fmt.Sprintf(
+		"can't parse %q as a time: use an age like \"7d\", \"2w\" or \"36h\", or a date like %q",
+		e.input, dateLayout)
+}
+
+// ParseTime parses a time given to a command flag. The input may either be an
+// age relative to now (e.g. "7d" for seven days ago, "2w" for two weeks ago, or
+// any time.ParseDuration input like "36h") or a UTC date in YYYY-MM-DD form.
+func ParseTime(input string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, input); err == nil {
+		return t, nil
+	}
+
+	age, err := parseAge(input)
+	if err != nil || age < 0 {
+		return time.Time{}, errBadTime{input}
+	}
+
+	return now.Add(-age), nil
+}
+
+// parseAge parses a duration, extending time.ParseDuration with whole numbers
+// of days ("d") and weeks ("w").
+func parseAge(input string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": day,
+		"w": week,
+	}
+
+	for suffix, unit := range units {
+		if !strings.HasSuffix(input, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(input, suffix))
+		if err != nil {
+			return 0, fmt.Errorf("parsing age %q: %w", input, err)
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	return time.ParseDuration(input)
+}
+
+// Window is a time window for filtering results, typically parsed from
+// "-since" and "-until" command flags. Either bound may be zero.
+type Window struct {
+	// Since is the start of the window, or zero for no start.
+	Since time.Time
+	// Until is the end of the window, or zero for no end.
+	Until time.Time
+}
+
+// ParseWindow parses "-since" and "-until" flag values (which may be empty)
+// with ParseTime.
+func ParseWindow(since, until string, now time.Time) (Window, error) {
+	var window Window
+
+	var err error
+
+	if since != "" {
+		if window.Since, err = ParseTime(since, now); err != nil {
+			return Window{}, fmt.Errorf("-since %w", err)
+		}
+	}
+
+	if until != "" {
+		if window.Until, err = ParseTime(until, now); err != nil {
+			return Window{}, fmt.Errorf("-until %w", err)
+		}
+	}
+
+	return window, nil
+}
+
+// String describes the window for output, e.g. " since 2021-03-01". It is
+// empty if neither bound is set.
+func (w Window) String() string {
+	var desc string
+
+	if !w.Since.IsZero() {
+		desc += " since " + w.Since.Format(dateLayout)
+	}
+
+	if !w.Until.IsZero() {
+		desc += " until " + w.Until.Format(dateLayout)
+	}
+
+	return desc
+}
@@ -0,0 +1,123 @@
+//nolint:goerr113
+package botcmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/slack/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestEventTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockClient(ctrl)
+
+	expected := time.Unix(1487690385, 0)
+	mockClient.EXPECT().ParseTimestamp("1487690385.010607").Return(expected, nil)
+
+	if actual := EventTime(mockClient, "1487690385.010607"); !actual.Equal(expected) {
+		t.Errorf("expected event time %v, got %v", expected, actual)
+	}
+
+	// Unparseable timestamps fall back to the current time.
+	mockClient.EXPECT().ParseTimestamp("bogus").Return(time.Time{}, errors.New("bad ts"))
+
+	before := time.Now()
+	if actual := EventTime(mockClient, "bogus"); actual.Before(before) {
+		t.Errorf("expected event time for bad timestamp to be now, got %v", actual)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2021, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		input       string
+		expected    time.Time
+		expectedErr string
+	}{
+		{
+			input:    "7d",
+			expected: now.AddDate(0, 0, -7),
+		},
+		{
+			input:    "2w",
+			expected: now.AddDate(0, 0, -14),
+		},
+		{
+			input:    "36h",
+			expected: now.Add(-36 * time.Hour),
+		},
+		{
+			input:    "2021-03-01",
+			expected: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			input: "soon",
+			expectedErr: `can't parse "soon" as a time: use an age like "7d", "2w" or "36h", ` +
+				`or a date like "2006-01-02"`,
+		},
+		{
+			input: "xd",
+			expectedErr: `can't parse "xd" as a time: use an age like "7d", "2w" or "36h", ` +
+				`or a date like "2006-01-02"`,
+		},
+		{
+			input: "-1d",
+			expectedErr: `can't parse "-1d" as a time: use an age like "7d", "2w" or "36h", ` +
+				`or a date like "2006-01-02"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseTime(tc.input, now)
+			if err != nil && tc.expectedErr == "" {
+				t.Errorf("unexpected err: %v", err)
+			} else if err == nil && tc.expectedErr != "" {
+				t.Errorf("expected err %q, got nil", tc.expectedErr)
+			} else if err != nil && err.Error() != tc.expectedErr {
+				t.Errorf("expected err %q, got %q", tc.expectedErr, err.Error())
+			} else if !actual.Equal(tc.expected) {
+				t.Errorf("expected %q to parse to %v, got %v", tc.input, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	now := time.Date(2021, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	window, err := ParseWindow("7d", "2021-03-14", now)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := Window{
+		Since: now.AddDate(0, 0, -7),
+		Until: time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if window != expected {
+		t.Errorf("expected window %v, got %v", expected, window)
+	}
+
+	if desc := window.String(); desc != " since 2021-03-08 until 2021-03-14" {
+		t.Errorf("unexpected window description %q", desc)
+	}
+
+	if desc := (Window{}).String(); desc != "" {
+		t.Errorf("expected empty description for empty window, got %q", desc)
+	}
+
+	expectedErr := `-until can't parse "later" as a time: use an age like "7d", "2w" or "36h", ` +
+		`or a date like "2006-01-02"`
+	if _, err := ParseWindow("", "later", now); err == nil {
+		t.Errorf("expected err %q, got nil", expectedErr)
+	} else if err.Error() != expectedErr {
+		t.Errorf("expected err %q, got %q", expectedErr, err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+package topics
+
+import (
+	"strings"
+)
+
+// diffOp is the kind of change made to a run of words.
+type diffOp int
+
+const (
+	opSame diffOp = iota
+	opRemoved
+	opAdded
+)
+
+// diffRun is a run of consecutive words with the same diffOp.
+type diffRun struct {
+	op    diffOp
+	words []string
+}
+
+// wordDiff returns the runs of words that are the same, removed or added when
+// changing the before text to the after text. It finds the longest common
+// subsequence of the words of each, preferring removals before additions.
+func wordDiff(before, after string) []diffRun {
+	a, b := strings.Fields(before), strings.Fields(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var runs []diffRun
+
+	add := func(op diffOp, word string) {
+		if len(runs) > 0 && runs[len(runs)-1].op == op {
+			runs[len(runs)-1].words = append(runs[len(runs)-1].words, word)
+			return
+		}
+
+		runs = append(runs, diffRun{op: op, words: []string{word}})
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			add(opSame, a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			add(opRemoved, a[i])
+			i++
+		default:
+			add(opAdded, b[j])
+			j++
+		}
+	}
+
+	return runs
+}
+
+// formatDiff formats the word diff from the before to the after text with Slack
+// markup: removed words are struck through and added words are bold.
+func formatDiff(before, after string) string {
+	runs := wordDiff(before, after)
+	parts := make([]string, 0, len(runs))
+
+	for _, run := range runs {
+		text := strings.Join(run.words, " ")
+
+		switch run.op {
+		case opRemoved:
+			text = "~" + text + "~"
+		case opAdded:
+			text = "*" + text + "*"
+		case opSame:
+		}
+
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,59 @@
+package topics
+
+import (
+	"testing"
+)
+
+func TestFormatDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "same",
+			before:   "happy new year",
+			after:    "happy new year",
+			expected: "happy new year",
+		},
+		{
+			name:     "replaced word",
+			before:   "happy new year",
+			after:    "happy new month",
+			expected: "happy new ~year~ *month*",
+		},
+		{
+			name:     "added words",
+			before:   "speak freely",
+			after:    "please speak very freely",
+			expected: "*please* speak *very* freely",
+		},
+		{
+			name:     "removed words",
+			before:   "speak very very freely",
+			after:    "speak freely",
+			expected: "speak ~very very~ freely",
+		},
+		{
+			name:     "from empty",
+			before:   "",
+			after:    "hello world",
+			expected: "*hello world*",
+		},
+		{
+			name:     "everything changed",
+			before:   "gorf",
+			after:    "frog",
+			expected: "~gorf~ *frog*",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := formatDiff(tc.before, tc.after); actual != tc.expected {
+				t.Errorf("expected diff %q got %q", tc.expected, actual)
+			}
+		})
+	}
+}
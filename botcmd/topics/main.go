@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/cpu/gorfbot/botcmd"
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
 	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,6 +20,10 @@ const (
 	cmdName = "topics"
 )
 
+// mentionRegexp matches a Slack user mention like "<@U1234>" or
+// "<@U1234|daniel>", capturing the user ID.
+var mentionRegexp = regexp.MustCompile(`^<@(\w+)(?:\|[^>]*)?>$`)
+
 type topicsCmd struct {
 	log *logrus.Logger
 }
@@ -23,37 +32,90 @@ func init() {
 	botcmd.MustAddCommand(&botcmd.BasicCommand{
 		Name:        cmdName,
 		Icon:        ":newspaper:",
-		Description: "List previous channel topics",
+		Description: "List, search and diff previous channel topics",
 		Handler:     &topicsCmd{},
 	})
 }
 
+// userID returns the ID of the user given to the -by flag as a mention, a
+// name or a name with an "@" prefix. It returns "" for unknown users.
+func userID(by string, client slack.Client) string {
+	if m := mentionRegexp.FindStringSubmatch(by); m != nil {
+		return m[1]
+	}
+
+	return client.UserID(strings.TrimPrefix(by, "@"))
+}
+
+//nolint:funlen
 func (cmd topicsCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
 	flagSet := flag.NewFlagSet(cmdName, flag.ContinueOnError)
 	limit := flagSet.Int64("limit", 5, "optional limit for number of topics to display")
 	channelFlag := flagSet.String("channel", "", "optional channel name to display topics for")
 	asc := flagSet.Bool("asc", false, "list topics in ascending age")
+	search := flagSet.String("search", "",
+		"only list topics containing a word (put -search last to search for any of several words)")
+	by := flagSet.String("by", "", "only list topics set by a user (e.g. @daniel)")
+	sinceFlag := flagSet.String("since", "", "only list topics set since an age (e.g. 7d, 2w) or date (e.g. 2021-03-01)")
+	untilFlag := flagSet.String("until", "", "only list topics set before an age (e.g. 7d, 2w) or date (e.g. 2021-04-01)")
+	allChannels := flagSet.Bool("all-channels", false, "list topics from every channel")
+	diff := flagSet.Bool("diff", false, "show what changed from each channel's previous topic")
 
 	if respText := botcmd.ParseFlags(text, flagSet); respText != "" {
 		return botcmd.RunResult{Message: respText}, nil
 	}
 
+	// Words after the -search value are searched for too.
+	if *search != "" && flagSet.NArg() > 0 {
+		*search = strings.Join(append([]string{*search}, flagSet.Args()...), " ")
+	}
+
+	if *allChannels && *channelFlag != "" {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: -all-channels can't be combined with -channel", cmdName),
+		}, nil
+	}
+
+	// A topic's previous topic must be listed too to diff it.
+	if *diff && (*search != "" || *by != "") {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: -diff can't be combined with -search or -by", cmdName),
+		}, nil
+	}
+
 	var channelID string
 
 	var channelName string
 
-	if *channelFlag != "" {
+	switch {
+	case *allChannels:
+	case *channelFlag != "":
 		channelID = runCtx.Slack.ConversationID(*channelFlag)
 		if channelID == "" {
 			return botcmd.RunResult{Message: "no such channel"}, nil
 		}
 
 		channelName = *channelFlag
-	} else {
+	default:
 		channelID = runCtx.Message.ChannelID
 		channelName = runCtx.Slack.ConversationName(channelID)
 	}
 
+	window, err := botcmd.ParseWindow(*sinceFlag, *untilFlag, time.Now())
+	if err != nil {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %v", cmdName, err)}, nil
+	}
+
+	var creator string
+
+	if *by != "" {
+		if creator = userID(*by, runCtx.Slack); creator == "" {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: unknown user %q", cmdName, *by),
+			}, nil
+		}
+	}
+
 	opts := storage.GetTopicOptions{
 		Channel: channelID,
 		FindOptions: storage.FindOptions{
@@ -61,8 +123,22 @@ func (cmd topicsCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResul
 			Asc:       *asc,
 			SortField: "date",
 		},
+		Search:  *search,
+		Creator: creator,
+		Since:   window.Since,
+		Until:   window.Until,
 	}
-	cmd.log.Infof("Getting topics for opts %#v", opts)
+
+	// Diffs are found from newest to oldest, with one extra topic so that the
+	// oldest listed topic has its previous topic.
+	if *diff {
+		opts.Asc = false
+		if opts.Limit > 0 {
+			opts.Limit++
+		}
+	}
+
+	runCtx.Logger(cmd.log).Infof("Getting topics for opts %#v", opts)
 
 	topics, err := runCtx.Storage.GetTopics(opts)
 	if err != nil {
@@ -71,22 +147,93 @@ func (cmd topicsCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResul
 				cmdName, opts, err)
 	}
 
+	var changes []string
+	if *diff {
+		topics, changes = diffTopics(topics, *limit, *asc)
+	}
+
 	buf := new(bytes.Buffer)
-	fmt.Fprintf(buf, ":newspaper: :mega: %d topics from channel *#%s* :mega: :newspaper:\n", len(topics), channelName)
 
-	for _, topic := range topics {
+	from := fmt.Sprintf("channel *#%s*", channelName)
+	if *allChannels {
+		from = "all channels"
+	}
+
+	fmt.Fprintf(buf, ":newspaper: :mega: %d topics from %s%s :mega: :newspaper:\n",
+		len(topics), from, describe(*search, *by, window))
+
+	for i, topic := range topics {
 		userName := runCtx.Slack.UserName(topic.Creator)
 		// ignore date errs - if there's an err just use the empty time.Time
 		date, _ := runCtx.Slack.ParseTimestamp(topic.Date)
 		dateStr := botcmd.FormatTime(date)
 
-		fmt.Fprintf(buf, "\t:rolled_up_newspaper: %s - Topic changed by _%s_ to :scroll: *%q*\n",
-			dateStr, userName, topic.Topic)
+		var where string
+		if *allChannels {
+			where = " in *#" + runCtx.Slack.ConversationName(topic.Channel) + "*"
+		}
+
+		if *diff && changes[i] != "" {
+			fmt.Fprintf(buf, "\t:rolled_up_newspaper: %s - Topic changed by _%s_%s: :pencil2: %s\n",
+				dateStr, userName, where, changes[i])
+
+			continue
+		}
+
+		fmt.Fprintf(buf, "\t:rolled_up_newspaper: %s - Topic changed by _%s_%s to :scroll: *%q*\n",
+			dateStr, userName, where, topic.Topic)
 	}
 
 	return botcmd.RunResult{Message: buf.String()}, nil
 }
 
+// describe returns a description of the search, user and window filters for
+// the output header, or "" if there are none.
+func describe(search, by string, window botcmd.Window) string {
+	var desc string
+
+	if search != "" {
+		desc += fmt.Sprintf(" matching %q", search)
+	}
+
+	if by != "" {
+		desc += " by _" + strings.TrimPrefix(by, "@") + "_"
+	}
+
+	return desc + window.String()
+}
+
+// diffTopics takes topics ordered newest first and returns up to limit of them
+// (or all of them if limit isn't positive), in ascending order if asc is true,
+// along with the formatted diff of each from the previous topic in the same
+// channel. The diff is "" for topics without a previous topic in the list.
+func diffTopics(topics []models.Topic, limit int64, asc bool) ([]models.Topic, []string) {
+	changes := make([]string, len(topics))
+
+	for i, topic := range topics {
+		for _, prev := range topics[i+1:] {
+			if prev.Channel == topic.Channel {
+				changes[i] = formatDiff(prev.Topic, topic.Topic)
+
+				break
+			}
+		}
+	}
+
+	if limit > 0 && int64(len(topics)) > limit {
+		topics, changes = topics[:limit], changes[:limit]
+	}
+
+	if asc {
+		for i, j := 0, len(topics)-1; i < j; i, j = i+1, j-1 {
+			topics[i], topics[j] = topics[j], topics[i]
+			changes[i], changes[j] = changes[j], changes[i]
+		}
+	}
+
+	return topics, changes
+}
+
 func (cmd *topicsCmd) Configure(log *logrus.Logger, c *config.Config) error {
 	cmd.log = log
 	return nil
@@ -4,6 +4,7 @@ package topics
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -278,6 +279,170 @@ func TestUnknownChannel(t *testing.T) {
 	}
 }
 
+func TestSearch(t *testing.T) {
+	ctrl, mockSlack, mockStorage, ctx := setup(t)
+	defer ctrl.Finish()
+
+	since := time.Date(2020, time.December, 1, 0, 0, 0, 0, time.UTC)
+	opts := expectedOptions("", 5, false)
+	opts.Search = "new year"
+	opts.Creator = "U0000"
+	opts.Since = since
+
+	mockSlack.EXPECT().UserID("gorf").Return("U0000")
+	mockStorage.EXPECT().GetTopics(opts).Return([]models.Topic{
+		{Channel: "C0001", Topic: "happy new year", Date: "1607050812.008800", Creator: "U0000"},
+	}, nil)
+	mockSlack.EXPECT().UserName("U0000").Return("gorf")
+	mockSlack.EXPECT().ParseTimestamp("1607050812.008800").Return(mockTimeA, nil)
+	mockSlack.EXPECT().ConversationName("C0001").Return("random")
+
+	expectedResult := fmt.Sprintf(`:newspaper: :mega: 1 topics from all channels matching "new year" by _gorf_ since 2020-12-01 :mega: :newspaper:
+	:rolled_up_newspaper: %s - Topic changed by _gorf_ in *#random* to :scroll: *"happy new year"*
+`, formattedTimestampA)
+	log, _ := test.NewNullLogger()
+	cmd := &topicsCmd{log: log}
+
+	input := "-all-channels -by @gorf -since 2020-12-01 -search new year"
+	if result, err := cmd.Run(input, ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	} else if result.Message != expectedResult {
+		t.Errorf("expected %q got %q", expectedResult, result.Message)
+	}
+}
+
+func TestSearchMention(t *testing.T) {
+	ctrl, mockSlack, mockStorage, ctx := setup(t)
+	defer ctrl.Finish()
+
+	opts := expectedOptions(ctx.Message.ChannelID, 5, false)
+	opts.Creator = "U0001"
+
+	mockSlack.EXPECT().ConversationName(ctx.Message.ChannelID).Return("general")
+	mockStorage.EXPECT().GetTopics(opts).Return(nil, nil)
+
+	log, _ := test.NewNullLogger()
+	cmd := &topicsCmd{log: log}
+	expected := ":newspaper: :mega: 0 topics from channel *#general* by _<@U0001>_ :mega: :newspaper:\n"
+
+	if result, err := cmd.Run("-by <@U0001>", ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	} else if result.Message != expected {
+		t.Errorf("expected %q got %q", expected, result.Message)
+	}
+}
+
+func TestInvalidFlags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "all channels and channel",
+			input:    "-all-channels -channel random",
+			expected: "topics: -all-channels can't be combined with -channel",
+		},
+		{
+			name:     "diff and search",
+			input:    "-diff -search gorf",
+			expected: "topics: -diff can't be combined with -search or -by",
+		},
+		{
+			name:     "bad since",
+			input:    "-all-channels -since yesterday",
+			expected: `topics: -since can't parse "yesterday" as a time: use an age like "7d", "2w" or "36h", or a date like "2006-01-02"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl, _, _, ctx := setup(t)
+			defer ctrl.Finish()
+
+			cmd := &topicsCmd{}
+
+			if res, err := cmd.Run(tc.input, ctx); err != nil {
+				t.Errorf("unexpected err: %v", err)
+			} else if res.Message != tc.expected {
+				t.Errorf("expected res msg %q got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestUnknownUser(t *testing.T) {
+	ctrl, mockSlack, _, ctx := setup(t)
+	defer ctrl.Finish()
+
+	mockSlack.EXPECT().ConversationName(ctx.Message.ChannelID).Return("general")
+	mockSlack.EXPECT().UserID("nobody").Return("")
+
+	cmd := &topicsCmd{}
+	expected := `topics: unknown user "@nobody"`
+
+	if res, err := cmd.Run("-by @nobody", ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	} else if res.Message != expected {
+		t.Errorf("expected res msg %q got %q", expected, res.Message)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	ctrl, mockSlack, mockStorage, ctx := setup(t)
+	defer ctrl.Finish()
+
+	// One extra topic is fetched, newest first, to diff the oldest listed
+	// topic.
+	opts := expectedOptions(ctx.Message.ChannelID, 2, false)
+	opts.Limit = 3
+
+	mockSlack.EXPECT().ConversationName(ctx.Message.ChannelID).Return("general")
+	mockStorage.EXPECT().GetTopics(opts).Return([]models.Topic{
+		{Channel: "C0000", Topic: "happy new month", Date: "3", Creator: "U0000"},
+		{Channel: "C0000", Topic: "happy new year", Date: "2", Creator: "U0001"},
+		{Channel: "C0000", Topic: "speak freely", Date: "1", Creator: "U0001"},
+	}, nil)
+	mockSlack.EXPECT().UserName("U0000").Return("Gorf")
+	mockSlack.EXPECT().UserName("U0001").Return("Garf")
+	mockSlack.EXPECT().ParseTimestamp("3").Return(mockTimeA, nil)
+	mockSlack.EXPECT().ParseTimestamp("2").Return(mockTimeB, nil)
+
+	expectedResult := fmt.Sprintf(`:newspaper: :mega: 2 topics from channel *#general* :mega: :newspaper:
+	:rolled_up_newspaper: %s - Topic changed by _Garf_: :pencil2: ~speak freely~ *happy new year*
+	:rolled_up_newspaper: %s - Topic changed by _Gorf_: :pencil2: happy new ~year~ *month*
+`, formattedTimestampB, formattedTimestampA)
+	log, _ := test.NewNullLogger()
+	cmd := &topicsCmd{log: log}
+
+	if result, err := cmd.Run("-diff -limit 2 -asc", ctx); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	} else if result.Message != expectedResult {
+		t.Errorf("expected %q got %q", expectedResult, result.Message)
+	}
+}
+
+func TestDiffTopicsChannels(t *testing.T) {
+	topics := []models.Topic{
+		{Channel: "C0001", Topic: "gorf"},
+		{Channel: "C0002", Topic: "frog"},
+		{Channel: "C0001", Topic: "garf"},
+	}
+
+	// Topics are diffed with the previous topic of the same channel and topics
+	// without a previous topic have no diff.
+	listed, changes := diffTopics(topics, 0, false)
+	expectedChanges := []string{"~garf~ *gorf*", "", ""}
+
+	if len(listed) != 3 {
+		t.Errorf("expected 3 topics, got %v", listed)
+	}
+
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("expected changes %q got %q", expectedChanges, changes)
+	}
+}
+
 func TestConfigure(t *testing.T) {
 	log, _ := test.NewNullLogger()
 	cmd := &topicsCmd{}
@@ -66,7 +66,7 @@ func (p topicUpdatePattern) Run(allSubmatches [][]string, runCtx botcmd.RunConte
 			fmt.Errorf("%s pattern error storing new topic: %w", patternName, err)
 	}
 
-	p.log.Info(model)
+	runCtx.Logger(p.log).Info(model)
 
 	return botcmd.RunResult{
 		Reactji: []string{"mag", "newspaper"},
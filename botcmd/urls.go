@@ -0,0 +1,51 @@
+package botcmd
+
+import (
+	"net/url"
+	"sync/atomic"
+
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+// urlRulesVersion counts changes to the URL rules in storage. Commands changing
+// the rules call URLRulesChanged so handlers caching them know to load them
+// again.
+var urlRulesVersion uint64
+
+// URLRulesVersion returns the version of the URL rules in storage.
+func URLRulesVersion() uint64 {
+	return atomic.LoadUint64(&urlRulesVersion)
+}
+
+// URLRulesChanged records that the URL rules in storage changed.
+func URLRulesChanged() {
+	atomic.AddUint64(&urlRulesVersion, 1)
+}
+
+// ConfigURLRules returns the URL rules described by the configuration, in
+// order. Rules from the configuration have no Creator.
+func ConfigURLRules(c config.URLsConfig) []models.URLRule {
+	rules := make([]models.URLRule, 0, len(c.URLs))
+
+	for _, u := range c.URLs {
+		rules = append(rules, models.URLRule{
+			HostPattern: u.HostPattern,
+			PathPattern: u.PathPattern,
+			Collection:  u.Collection,
+			FirstMsg:    u.FirstMsg,
+			Reactji:     u.Reactji,
+		})
+	}
+
+	return rules
+}
+
+// CountedURL returns the form of a URL its occurrences are counted by: the URL
+// without its query or fragment.
+func CountedURL(u url.URL) string {
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
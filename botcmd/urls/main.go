@@ -0,0 +1,573 @@
+// Package urls provides a command for listing, tracking and untracking the URL
+// patterns the rarepattern package counts occurrences of, and for querying the
+// URLs it counted.
+package urls
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdName = "urls"
+
+	usage = ":speech_balloon: :bookmark_tabs: Usage of !*urls*:\n" +
+		"\t`!urls list` - list the tracked URL patterns\n" +
+		"\t`!urls track <host-regex> [path-regex] <collection> [:emoji: ...] [\"first seen message\"]` - " +
+		"count URLs matching the patterns in a collection, e.g. " +
+		"`!urls track github\\.com /cpu/gorfbot/.* gorfbot_links :frog: \"a new gorfbot link!\"`\n" +
+		"\t`!urls untrack <collection>` - stop tracking URLs for a collection you added\n" +
+		"\t`!urls top <collection>` - list the most shared URLs in a collection\n" +
+		"\t`!urls seen <url>` - say who shared a URL first and how many times it was shared\n" +
+		"\t`!urls recent [collection]` - list the most recently shared URLs"
+
+	// maxResults is the maximum number of URLs listed by top and recent.
+	maxResults = 10
+
+	dateLayout = "2006-01-02"
+)
+
+var (
+	// emojiRegexp matches an emoji like ":frog:" or ":+1::skin-tone-2:".
+	emojiRegexp = regexp.MustCompile(`^:([\w+\-']+(?:::skin-tone-\d)?):$`)
+	// slackLink matches text Slack turned into a link like
+	// "<http://github.com|github.com>".
+	slackLink = regexp.MustCompile(`^<([^|>]+)(?:\|([^>]+))?>$`)
+	// collectionRegexp matches the names of collections that can be tracked.
+	// The "_links" suffix keeps them apart from the bot's other collections.
+	collectionRegexp = regexp.MustCompile(`^[a-z0-9_]+_links$`)
+)
+
+type urlsCmd struct {
+	log  *logrus.Logger
+	conf *config.Config
+}
+
+func init() {
+	botcmd.MustAddCommand(&botcmd.BasicCommand{
+		Name:        cmdName,
+		Icon:        ":link:",
+		Description: "List, track and untrack URL patterns",
+		Handler:     &urlsCmd{},
+	})
+}
+
+func (cmd *urlsCmd) Configure(log *logrus.Logger, c *config.Config) error {
+	cmd.log = log
+	cmd.conf = c
+
+	return nil
+}
+
+func (cmd urlsCmd) Run(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if runCtx.Message == nil {
+		return botcmd.RunResult{},
+			fmt.Errorf("%s cmd error: %w", cmdName, botcmd.ErrNilMessage)
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "list":
+		return cmd.list(runCtx)
+	case "track":
+		return cmd.track(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), words[0])), runCtx)
+	case "untrack":
+		if len(words) != 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.untrack(words[1], runCtx)
+	case "top":
+		if len(words) != 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.top(words[1], runCtx)
+	case "seen":
+		if len(words) != 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.seen(words[1], runCtx)
+	case "recent":
+		if len(words) > 2 {
+			return botcmd.RunResult{Message: usage}, nil
+		}
+
+		return cmd.recent(words[1:], runCtx)
+	default:
+		return botcmd.RunResult{Message: usage}, nil
+	}
+}
+
+// configRules returns the URL rules from the config.
+func (cmd urlsCmd) configRules() []models.URLRule {
+	if cmd.conf == nil {
+		return nil
+	}
+
+	return botcmd.ConfigURLRules(cmd.conf.URLsConf)
+}
+
+// allRules returns the URL rules from the config followed by the URL rules in
+// storage.
+func (cmd urlsCmd) allRules(runCtx botcmd.RunContext) ([]models.URLRule, error) {
+	stored, err := runCtx.Storage.GetURLRules()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get URL rules: %w", cmdName, err)
+	}
+
+	return append(cmd.configRules(), stored...), nil
+}
+
+// list returns a message describing the URL rules from the config and the URL
+// rules in storage.
+func (cmd urlsCmd) list(runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	rules, err := cmd.allRules(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	if len(rules) == 0 {
+		return botcmd.RunResult{
+			Message: ":shrug: No URLs are tracked yet. Try `!urls track github\\.com gorf_links`",
+		}, nil
+	}
+
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, ":link: %d tracked URL patterns:\n", len(rules))
+
+	for _, r := range rules {
+		fmt.Fprintf(&msg, "\t%s", describe(r))
+
+		if r.Creator == "" {
+			msg.WriteString(" (config)")
+		} else {
+			fmt.Fprintf(&msg, " (by _%s_)", runCtx.Slack.UserName(r.Creator))
+		}
+
+		msg.WriteString("\n")
+	}
+
+	return botcmd.RunResult{Message: msg.String()}, nil
+}
+
+// track adds a URL rule described by text like
+// `github\.com /cpu/.* cpu_links :frog: "a new link!"`.
+//
+//nolint:funlen
+func (cmd urlsCmd) track(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	args, ok := botcmd.SplitArgs(text)
+	if !ok {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: missing a closing quote", cmdName)}, nil
+	}
+
+	rule := models.URLRule{Creator: runCtx.Message.UserID}
+
+	var patterns []string
+
+	for _, a := range args {
+		if m := emojiRegexp.FindStringSubmatch(a.Text); m != nil && !a.Quoted {
+			rule.Reactji = append(rule.Reactji, m[1])
+		} else if a.Quoted && rule.FirstMsg == "" {
+			rule.FirstMsg = a.Text
+		} else if !a.Quoted && len(rule.Reactji) == 0 && rule.FirstMsg == "" {
+			patterns = append(patterns, unlink(a.Text))
+		} else {
+			return botcmd.RunResult{
+				Message: fmt.Sprintf("%s: put reactions in :colons: and one first seen message in \"quotes\", not %q",
+					cmdName, a.Text),
+			}, nil
+		}
+	}
+
+	switch len(patterns) {
+	case 2:
+		rule.HostPattern, rule.Collection = patterns[0], patterns[1]
+	case 3:
+		rule.HostPattern, rule.PathPattern, rule.Collection = patterns[0], patterns[1], patterns[2]
+	default:
+		return botcmd.RunResult{Message: usage}, nil
+	}
+
+	if problem := cmd.check(rule); problem != "" {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %s", cmdName, problem)}, nil
+	}
+
+	added, err := runCtx.Storage.AddURLRule(rule)
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to add URL rule %v: %w", cmdName, rule, err)
+	} else if !added {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf("%s: `%s` is already tracked", cmdName, rule.Collection),
+		}, nil
+	}
+
+	botcmd.URLRulesChanged()
+	runCtx.Logger(cmd.log).Infof("%s - added %s", cmdName, rule)
+
+	return botcmd.RunResult{Message: ":link: Tracking " + describe(rule)}, nil
+}
+
+// check returns a description of the problem with a rule to be added, or ""
+// if there isn't one.
+func (cmd urlsCmd) check(rule models.URLRule) string {
+	if _, err := regexp.Compile(rule.HostPattern); err != nil {
+		return fmt.Sprintf("can't use %q as a host regex: %v", rule.HostPattern, err)
+	}
+
+	if _, err := regexp.Compile(rule.PathPattern); err != nil {
+		return fmt.Sprintf("can't use %q as a path regex: %v", rule.PathPattern, err)
+	}
+
+	if !collectionRegexp.MatchString(rule.Collection) {
+		return fmt.Sprintf("can't use %q as a collection, use lowercase letters, numbers and "+
+			"underscores ending in `_links` like `gorf_links`", rule.Collection)
+	}
+
+	for _, r := range cmd.configRules() {
+		if r.Collection == rule.Collection {
+			return fmt.Sprintf("`%s` is already tracked in the config", rule.Collection)
+		}
+	}
+
+	return ""
+}
+
+// untrack removes the stored rule for the given collection. Only the rule's
+// creator and admins can remove it. URLs already counted are kept.
+func (cmd urlsCmd) untrack(collection string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	rules, err := runCtx.Storage.GetURLRules()
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get URL rules: %w", cmdName, err)
+	}
+
+	var (
+		rule  models.URLRule
+		found bool
+	)
+
+	for _, r := range rules {
+		if r.Collection == collection {
+			rule, found = r, true
+
+			break
+		}
+	}
+
+	if !found {
+		return botcmd.RunResult{Message: fmt.Sprintf(":shrug: `%s` isn't tracked with `!urls track`", collection)}, nil
+	}
+
+	userName := runCtx.Slack.UserName(runCtx.Message.UserID)
+	if rule.Creator != runCtx.Message.UserID && (cmd.conf == nil || !cmd.conf.IsAdmin(userName)) {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":no_entry: Sorry _%s_, only the rule's creator or admins can untrack it", userName),
+		}, nil
+	}
+
+	if removed, err := runCtx.Storage.RemoveURLRule(collection); err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to remove URL rule %q: %w", cmdName, collection, err)
+	} else if !removed {
+		return botcmd.RunResult{Message: fmt.Sprintf(":shrug: `%s` isn't tracked with `!urls track`", collection)}, nil
+	}
+
+	botcmd.URLRulesChanged()
+	runCtx.Logger(cmd.log).Infof("%s - removed %s", cmdName, rule)
+
+	return botcmd.RunResult{Message: ":wastebasket: Stopped tracking " + describe(rule)}, nil
+}
+
+// collections returns the names of the collections of the URL rules from the
+// config and in storage, in order.
+func (cmd urlsCmd) collections(runCtx botcmd.RunContext) ([]string, error) {
+	rules, err := cmd.allRules(runCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]string, 0, len(rules))
+	for _, r := range rules {
+		collections = append(collections, r.Collection)
+	}
+
+	return collections, nil
+}
+
+// checkCollection returns a description of the problem with querying the named
+// collection, or "" if there isn't one. Collections that were untracked can
+// still be queried but other collections (e.g. the bot's quotes) can't be.
+func (cmd urlsCmd) checkCollection(collection string, runCtx botcmd.RunContext) (string, error) {
+	if collectionRegexp.MatchString(collection) {
+		return "", nil
+	}
+
+	collections, err := cmd.collections(runCtx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range collections {
+		if c == collection {
+			return "", nil
+		}
+	}
+
+	return fmt.Sprintf(":shrug: `%s` isn't a collection of URLs", collection), nil
+}
+
+// top returns a message listing the most shared URLs in a collection.
+func (cmd urlsCmd) top(collection string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if problem, err := cmd.checkCollection(collection, runCtx); err != nil {
+		return botcmd.RunResult{}, err
+	} else if problem != "" {
+		return botcmd.RunResult{Message: problem}, nil
+	}
+
+	counts, err := runCtx.Storage.GetURLCounts(collection, storage.GetURLCountOptions{
+		FindOptions: storage.FindOptions{SortField: "occurrences", Limit: maxResults},
+	})
+	if err != nil {
+		return botcmd.RunResult{}, fmt.Errorf("%s: failed to get URL counts: %w", cmdName, err)
+	}
+
+	if len(counts) == 0 {
+		return botcmd.RunResult{Message: fmt.Sprintf(":shrug: No URLs in `%s` yet", collection)}, nil
+	}
+
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, ":link: Top URLs in `%s`:\n", collection)
+
+	for i, u := range counts {
+		fmt.Fprintf(&msg, "\t%d. %s\n", i+1, describeCount(u, runCtx))
+	}
+
+	return botcmd.RunResult{Message: msg.String()}, nil
+}
+
+// seen returns a message saying who first shared a URL, and how many times it
+// was shared, according to the collection it was shared the most in.
+func (cmd urlsCmd) seen(text string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	if m := slackLink.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	}
+
+	parsed, err := url.Parse(html.UnescapeString(text))
+	if err != nil || parsed.Host == "" {
+		return botcmd.RunResult{Message: fmt.Sprintf("%s: %q isn't a URL", cmdName, text)}, nil
+	}
+
+	counted := botcmd.CountedURL(*parsed)
+
+	collections, err := cmd.collections(runCtx)
+	if err != nil {
+		return botcmd.RunResult{}, err
+	}
+
+	var (
+		best  models.URLCount
+		found bool
+	)
+
+	for _, collection := range collections {
+		counts, err := runCtx.Storage.GetURLCounts(collection, storage.GetURLCountOptions{
+			FindOptions: storage.FindOptions{SortField: "occurrences"},
+			URL:         counted,
+		})
+		if err != nil {
+			return botcmd.RunResult{}, fmt.Errorf("%s: failed to get URL counts: %w", cmdName, err)
+		}
+
+		for _, u := range counts {
+			if !found || u.Occurrences > best.Occurrences {
+				best, found = u, true
+			}
+		}
+	}
+
+	if !found {
+		return botcmd.RunResult{
+			Message: fmt.Sprintf(":shrug: I haven't seen %s shared (I only keep track of `!urls list` URLs)", counted),
+		}, nil
+	}
+
+	return botcmd.RunResult{Message: ":link: " + describeCount(best, runCtx)}, nil
+}
+
+// recent returns a message listing the most recently shared URLs in the named
+// collection, or in every collection if none is named.
+func (cmd urlsCmd) recent(args []string, runCtx botcmd.RunContext) (botcmd.RunResult, error) {
+	collections := args
+
+	if len(args) == 0 {
+		var err error
+		if collections, err = cmd.collections(runCtx); err != nil {
+			return botcmd.RunResult{}, err
+		}
+	} else if problem, err := cmd.checkCollection(args[0], runCtx); err != nil {
+		return botcmd.RunResult{}, err
+	} else if problem != "" {
+		return botcmd.RunResult{Message: problem}, nil
+	}
+
+	// The same URL can be counted in more than one collection. The count it was
+	// seen in last is listed.
+	latest := make(map[string]models.URLCount)
+
+	for _, collection := range collections {
+		counts, err := runCtx.Storage.GetURLCounts(collection, storage.GetURLCountOptions{
+			FindOptions: storage.FindOptions{SortField: "lastseen", Limit: maxResults},
+		})
+		if err != nil {
+			return botcmd.RunResult{}, fmt.Errorf("%s: failed to get URL counts: %w", cmdName, err)
+		}
+
+		for _, u := range counts {
+			if existing, found := latest[u.URL]; !found || u.LastSeen.After(existing.LastSeen) {
+				latest[u.URL] = u
+			}
+		}
+	}
+
+	if len(latest) == 0 {
+		return botcmd.RunResult{Message: ":shrug: No URLs have been shared yet"}, nil
+	}
+
+	recent := make([]models.URLCount, 0, len(latest))
+	for _, u := range latest {
+		recent = append(recent, u)
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		if !recent[i].LastSeen.Equal(recent[j].LastSeen) {
+			return recent[i].LastSeen.After(recent[j].LastSeen)
+		}
+
+		return recent[i].URL < recent[j].URL
+	})
+
+	if len(recent) > maxResults {
+		recent = recent[:maxResults]
+	}
+
+	var msg strings.Builder
+
+	msg.WriteString(":link: Recently shared URLs")
+
+	if len(args) > 0 {
+		fmt.Fprintf(&msg, " in `%s`", args[0])
+	}
+
+	msg.WriteString(":\n")
+
+	for _, u := range recent {
+		fmt.Fprintf(&msg, "\t%s\n", describeCount(u, runCtx))
+	}
+
+	return botcmd.RunResult{Message: msg.String()}, nil
+}
+
+// describeCount returns a description of a URL's history like "https://x.com
+// was first shared by _alice_ in #general on 2021-01-02, shared 7 times (last
+// on 2021-01-05)". URLs counted before their first share was recorded only
+// have a count. Who first shared a URL and where is left out if it was in a
+// conversation that isn't public, unless that's where the description is for.
+func describeCount(u models.URLCount, runCtx botcmd.RunContext) string {
+	var desc strings.Builder
+
+	desc.WriteString(u.URL)
+
+	switch {
+	case u.FirstUser == "":
+		desc.WriteString(" was")
+	case !u.FirstPublic && u.FirstChannel != runCtx.Message.ChannelID:
+		fmt.Fprintf(&desc, " was first shared on %s,", formatDate(u.FirstSeen))
+	default:
+		fmt.Fprintf(&desc, " was first shared by _%s_ in #%s on %s,",
+			runCtx.Slack.UserName(u.FirstUser),
+			runCtx.Slack.ConversationName(u.FirstChannel),
+			formatDate(u.FirstSeen))
+	}
+
+	fmt.Fprintf(&desc, " shared %d %s", u.Occurrences, plural(u.Occurrences, "time"))
+
+	if u.Occurrences > 1 && !u.LastSeen.IsZero() {
+		fmt.Fprintf(&desc, " (last on %s)", formatDate(u.LastSeen))
+	}
+
+	return desc.String()
+}
+
+// formatDate returns the UTC date of a time like "2021-01-02".
+func formatDate(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// plural returns the noun with an "s" suffix unless count is 1.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return noun
+	}
+
+	return noun + "s"
+}
+
+// describe returns a description of a rule like
+// "`github\.com` `/cpu/.*` in `cpu_links` :arrow_right: :frog: "a new link!"".
+func describe(r models.URLRule) string {
+	var desc strings.Builder
+
+	fmt.Fprintf(&desc, "`%s`", r.HostPattern)
+
+	if r.PathPattern != "" {
+		fmt.Fprintf(&desc, " `%s`", r.PathPattern)
+	}
+
+	fmt.Fprintf(&desc, " in `%s`", r.Collection)
+
+	if len(r.Reactji) > 0 || r.FirstMsg != "" {
+		desc.WriteString(" :arrow_right:")
+	}
+
+	for _, reaction := range r.Reactji {
+		fmt.Fprintf(&desc, " :%s:", reaction)
+	}
+
+	if r.FirstMsg != "" {
+		fmt.Fprintf(&desc, " %q", r.FirstMsg)
+	}
+
+	return desc.String()
+}
+
+// unlink returns the text a user typed for an argument Slack may have turned
+// into a link, like "github.com" for "<http://github.com|github.com>", with
+// any HTML escaping (e.g. "&amp;") undone.
+func unlink(text string) string {
+	if m := slackLink.FindStringSubmatch(text); m != nil {
+		if m[2] != "" {
+			text = m[2]
+		} else {
+			text = strings.TrimPrefix(strings.TrimPrefix(m[1], "http://"), "https://")
+		}
+	}
+
+	return html.UnescapeString(text)
+}
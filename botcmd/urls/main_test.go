@@ -0,0 +1,435 @@
+//nolint:goerr113
+package urls
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/botcmd"
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/slack"
+	slack_mocks "github.com/cpu/gorfbot/slack/mocks"
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+var testConfig = &config.Config{
+	Admins: []string{"admin"},
+	URLsConf: config.URLsConfig{
+		URLs: []config.URLConfig{
+			{HostPattern: `github\.com`, PathPattern: `/cpu/gorfbot/issues/.*`, Collection: "gorfbot_issue_links"},
+		},
+	},
+}
+
+func setup(t *testing.T) (*urlsCmd, botcmd.RunContext, *mocks.MockStorage) {
+	log, _ := logtest.NewNullLogger()
+	cmd := &urlsCmd{}
+
+	if err := cmd.Configure(log, testConfig); err != nil {
+		t.Fatalf("unexpected err from Configure: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockClient := slack_mocks.NewMockClient(ctrl)
+	ctx := botcmd.RunContext{
+		Message: &slack.Message{ChannelID: "C001", UserID: "U001"},
+		Storage: mockStorage,
+		Slack:   mockClient,
+	}
+
+	mockClient.EXPECT().UserName("U001").Return("daniel").AnyTimes()
+	mockClient.EXPECT().UserName("U002").Return("admin").AnyTimes()
+	mockClient.EXPECT().ConversationName("C001").Return("general").AnyTimes()
+
+	return cmd, ctx, mockStorage
+}
+
+func TestRunNilMessage(t *testing.T) {
+	cmd, ctx, _ := setup(t)
+	ctx.Message = nil
+
+	if _, err := cmd.Run("list", ctx); err == nil {
+		t.Errorf("expected err from Run w/ nil message, got nil")
+	}
+}
+
+func TestRunTrack(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		rule     *models.URLRule
+		added    bool
+	}{
+		{name: "usage", text: "track", expected: usage},
+		{name: "one pattern", text: "track frog_links", expected: usage},
+		{name: "too many patterns", text: "track a b c frog_links", expected: usage},
+		{
+			name: "host only",
+			text: `track <http://frog.tips|frog.tips> frog_links :frog: :+1::skin-tone-2: “a new tip!”`,
+			rule: &models.URLRule{
+				HostPattern: "frog.tips",
+				Collection:  "frog_links",
+				FirstMsg:    "a new tip!",
+				Reactji:     []string{"frog", "+1::skin-tone-2"},
+				Creator:     "U001",
+			},
+			added:    true,
+			expected: ":link: Tracking `frog.tips` in `frog_links` :arrow_right: :frog: :+1::skin-tone-2: \"a new tip!\"",
+		},
+		{
+			name: "host and path",
+			text: `track <https://github.com> /cpu/gorfbot/pull/\d+ pr_links`,
+			rule: &models.URLRule{
+				HostPattern: "github.com",
+				PathPattern: `/cpu/gorfbot/pull/\d+`,
+				Collection:  "pr_links",
+				Creator:     "U001",
+			},
+			added:    true,
+			expected: ":link: Tracking `github.com` `/cpu/gorfbot/pull/\\d+` in `pr_links`",
+		},
+		{
+			name:     "escaped",
+			text:     `track frog\.tips ^/a&amp;b$ frog_links`,
+			rule:     &models.URLRule{HostPattern: `frog\.tips`, PathPattern: "^/a&b$", Collection: "frog_links", Creator: "U001"},
+			expected: "urls: `frog_links` is already tracked",
+		},
+		{
+			name:     "bad host regex",
+			text:     "track ( frog_links",
+			expected: "urls: can't use \"(\" as a host regex: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:     "bad path regex",
+			text:     "track frog.tips [ frog_links",
+			expected: "urls: can't use \"[\" as a path regex: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name: "bad collection",
+			text: "track frog.tips topics",
+			expected: "urls: can't use \"topics\" as a collection, use lowercase letters, numbers and " +
+				"underscores ending in `_links` like `gorf_links`",
+		},
+		{
+			name:     "config collection",
+			text:     "track github.com gorfbot_issue_links",
+			expected: "urls: `gorfbot_issue_links` is already tracked in the config",
+		},
+		{
+			name:     "pattern after reactji",
+			text:     "track frog.tips :frog: frog_links",
+			expected: "urls: put reactions in :colons: and one first seen message in \"quotes\", not \"frog_links\"",
+		},
+		{
+			name:     "missing quote",
+			text:     `track frog.tips frog_links "oops`,
+			expected: "urls: missing a closing quote",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage := setup(t)
+
+			if tc.rule != nil {
+				mockStorage.EXPECT().AddURLRule(*tc.rule).Return(tc.added, nil)
+			}
+
+			res, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunList(t *testing.T) {
+	cmd, ctx, mockStorage := setup(t)
+	mockStorage.EXPECT().GetURLRules().Return([]models.URLRule{
+		{HostPattern: `frog\.tips`, Collection: "frog_links", Reactji: []string{"frog"}, Creator: "U002"},
+	}, nil)
+
+	res, err := cmd.Run("list", ctx)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := ":link: 2 tracked URL patterns:\n" +
+		"\t`github\\.com` `/cpu/gorfbot/issues/.*` in `gorfbot_issue_links` (config)\n" +
+		"\t`frog\\.tips` in `frog_links` :arrow_right: :frog: (by _admin_)\n"
+	if res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	cmd.conf = nil
+
+	mockStorage.EXPECT().GetURLRules().Return(nil, nil)
+
+	if res, err := cmd.Run("list", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":shrug: No URLs are tracked yet. Try `!urls track github\\.com gorf_links`"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetURLRules().Return(nil, errors.New("oops"))
+
+	if _, err := cmd.Run("list", ctx); err == nil {
+		t.Errorf("expected err from list with a storage err, got nil")
+	}
+}
+
+func TestRunUntrack(t *testing.T) {
+	rules := []models.URLRule{
+		{HostPattern: `frog\.tips`, Collection: "frog_links", Creator: "U003"},
+		{HostPattern: `github\.com`, Collection: "cpu_links", Creator: "U001"},
+	}
+
+	testCases := []struct {
+		name       string
+		userID     string
+		collection string
+		remove     bool
+		expected   string
+	}{
+		{
+			name:       "creator",
+			userID:     "U001",
+			collection: "cpu_links",
+			remove:     true,
+			expected:   ":wastebasket: Stopped tracking `github\\.com` in `cpu_links`",
+		},
+		{
+			name:       "admin",
+			userID:     "U002",
+			collection: "frog_links",
+			remove:     true,
+			expected:   ":wastebasket: Stopped tracking `frog\\.tips` in `frog_links`",
+		},
+		{
+			name:       "not allowed",
+			userID:     "U001",
+			collection: "frog_links",
+			expected:   ":no_entry: Sorry _daniel_, only the rule's creator or admins can untrack it",
+		},
+		{
+			name:       "config",
+			userID:     "U002",
+			collection: "gorfbot_issue_links",
+			expected:   ":shrug: `gorfbot_issue_links` isn't tracked with `!urls track`",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage := setup(t)
+			ctx.Message.UserID = tc.userID
+
+			mockStorage.EXPECT().GetURLRules().Return(rules, nil)
+
+			if tc.remove {
+				mockStorage.EXPECT().RemoveURLRule(tc.collection).Return(true, nil)
+			}
+
+			res, err := cmd.Run("untrack "+tc.collection, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+var (
+	firstSeen = time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	frogCount = models.URLCount{
+		URL:            "https://frog.tips/api/1/tips",
+		Occurrences:    3,
+		FirstUser:      "U001",
+		FirstChannel:   "C001",
+		FirstPublic:    true,
+		FirstTimestamp: "1609596000.000001",
+		FirstSeen:      firstSeen,
+		LastSeen:       firstSeen.Add(72 * time.Hour),
+	}
+	frogDesc = "https://frog.tips/api/1/tips was first shared by _daniel_ in #general on 2021-01-02, " +
+		"shared 3 times (last on 2021-01-05)"
+
+	// oldCount was counted before the first share was recorded.
+	oldCount = models.URLCount{URL: "https://frog.tips/old", Occurrences: 1}
+	oldDesc  = "https://frog.tips/old was shared 1 time"
+)
+
+func TestRunTop(t *testing.T) {
+	cmd, ctx, mockStorage := setup(t)
+
+	topOpts := storage.GetURLCountOptions{
+		FindOptions: storage.FindOptions{SortField: "occurrences", Limit: maxResults},
+	}
+
+	mockStorage.EXPECT().GetURLCounts("frog_links", topOpts).Return([]models.URLCount{frogCount, oldCount}, nil)
+
+	if res, err := cmd.Run("top frog_links", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":link: Top URLs in `frog_links`:\n\t1. " + frogDesc + "\n\t2. " + oldDesc + "\n"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetURLCounts("gorfbot_issue_links", topOpts).Return(nil, nil)
+
+	if res, err := cmd.Run("top gorfbot_issue_links", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":shrug: No URLs in `gorfbot_issue_links` yet"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	// Collections that aren't for URLs can't be read.
+	mockStorage.EXPECT().GetURLRules().Return(nil, nil)
+
+	if res, err := cmd.Run("top quotes", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":shrug: `quotes` isn't a collection of URLs"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetURLCounts("frog_links", topOpts).Return(nil, errors.New("oops"))
+
+	if _, err := cmd.Run("top frog_links", ctx); err == nil {
+		t.Errorf("expected err from top with a storage err, got nil")
+	}
+}
+
+func TestRunSeen(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		url      string
+		counts   map[string][]models.URLCount
+		expected string
+	}{
+		{
+			name: "most shared",
+			text: "seen <https://frog.tips/api/1/tips?utm_source=x#top>",
+			url:  "https://frog.tips/api/1/tips",
+			counts: map[string][]models.URLCount{
+				"gorfbot_issue_links": {{URL: "https://frog.tips/api/1/tips", Occurrences: 1}},
+				"frog_links":          {frogCount},
+			},
+			expected: ":link: " + frogDesc,
+		},
+		{
+			name: "first shared privately",
+			text: "seen https://frog.tips/secret",
+			url:  "https://frog.tips/secret",
+			counts: map[string][]models.URLCount{
+				"frog_links": {{
+					URL:          "https://frog.tips/secret",
+					Occurrences:  2,
+					FirstUser:    "U002",
+					FirstChannel: "D001",
+					FirstSeen:    firstSeen,
+					LastSeen:     firstSeen.Add(24 * time.Hour),
+				}},
+			},
+			expected: ":link: https://frog.tips/secret was first shared on 2021-01-02, shared 2 times (last on 2021-01-03)",
+		},
+		{
+			name:     "not seen",
+			text:     "seen https://frog.tips/nope",
+			url:      "https://frog.tips/nope",
+			expected: ":shrug: I haven't seen https://frog.tips/nope shared (I only keep track of `!urls list` URLs)",
+		},
+		{
+			name:     "not a URL",
+			text:     "seen frogs",
+			expected: "urls: \"frogs\" isn't a URL",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, ctx, mockStorage := setup(t)
+
+			if tc.url != "" {
+				mockStorage.EXPECT().GetURLRules().Return([]models.URLRule{{HostPattern: ".*", Collection: "frog_links"}}, nil)
+
+				for _, collection := range []string{"gorfbot_issue_links", "frog_links"} {
+					mockStorage.EXPECT().GetURLCounts(collection, storage.GetURLCountOptions{
+						FindOptions: storage.FindOptions{SortField: "occurrences"},
+						URL:         tc.url,
+					}).Return(tc.counts[collection], nil)
+				}
+			}
+
+			res, err := cmd.Run(tc.text, ctx)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if res.Message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, res.Message)
+			}
+		})
+	}
+}
+
+func TestRunRecent(t *testing.T) {
+	recentOpts := storage.GetURLCountOptions{
+		FindOptions: storage.FindOptions{SortField: "lastseen", Limit: maxResults},
+	}
+
+	newCount := models.URLCount{URL: "https://frog.tips/new", Occurrences: 1, LastSeen: firstSeen.Add(96 * time.Hour)}
+	newDesc := "https://frog.tips/new was shared 1 time"
+
+	// A URL counted in more than one collection is listed with the count it was
+	// seen in last, even if it was seen more often in another.
+	staleCount := frogCount
+	staleCount.Occurrences = 5
+	staleCount.LastSeen = firstSeen.Add(time.Hour)
+
+	cmd, ctx, mockStorage := setup(t)
+	mockStorage.EXPECT().GetURLRules().Return([]models.URLRule{{HostPattern: ".*", Collection: "frog_links"}}, nil)
+	mockStorage.EXPECT().GetURLCounts("gorfbot_issue_links", recentOpts).Return([]models.URLCount{staleCount}, nil)
+	mockStorage.EXPECT().GetURLCounts("frog_links", recentOpts).Return([]models.URLCount{newCount, frogCount, oldCount}, nil)
+
+	if res, err := cmd.Run("recent", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":link: Recently shared URLs:\n\t" + newDesc + "\n\t" + frogDesc + "\n\t" + oldDesc + "\n"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetURLCounts("frog_links", recentOpts).Return([]models.URLCount{newCount}, nil)
+
+	if res, err := cmd.Run("recent frog_links", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":link: Recently shared URLs in `frog_links`:\n\t" + newDesc + "\n"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+
+	mockStorage.EXPECT().GetURLCounts("frog_links", recentOpts).Return(nil, nil)
+
+	if res, err := cmd.Run("recent frog_links", ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if expected := ":shrug: No URLs have been shared yet"; res.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, res.Message)
+	}
+}
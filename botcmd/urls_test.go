@@ -0,0 +1,31 @@
+package botcmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+func TestConfigURLRules(t *testing.T) {
+	conf := config.URLsConfig{
+		URLs: []config.URLConfig{
+			{HostPattern: `github\.com`, PathPattern: `/cpu/gorfbot/issues/.*`, Collection: "gorfbot_issue_links"},
+			{HostPattern: `frog\.tips`, Collection: "frog_links", FirstMsg: "new tip!", Reactji: []string{"frog"}},
+		},
+	}
+
+	expected := []models.URLRule{
+		{HostPattern: `github\.com`, PathPattern: `/cpu/gorfbot/issues/.*`, Collection: "gorfbot_issue_links"},
+		{HostPattern: `frog\.tips`, Collection: "frog_links", FirstMsg: "new tip!", Reactji: []string{"frog"}},
+	}
+
+	if rules := ConfigURLRules(conf); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected rules %v, got %v", expected, rules)
+	}
+
+	if rules := ConfigURLRules(config.URLsConfig{}); len(rules) != 0 {
+		t.Errorf("expected no rules for an empty config, got %v", rules)
+	}
+}
@@ -0,0 +1,214 @@
+package botcmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is the hour of the day used by ParseWhen for a day without a
+// time of day (e.g. "tomorrow").
+const defaultHour = 9
+
+var (
+	// clockPattern matches a time of day like "9am", "9:30pm" or "17:00".
+	clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+	// unitWords maps the unit words accepted after a number in a relative time
+	// (e.g. "in 2 hours") to a parseAge suffix.
+	unitWords = map[string]string{
+		"m": "m", "min": "m", "mins": "m", "minute": "m", "minutes": "m",
+		"h": "h", "hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+		"d": "d", "day": "d", "days": "d",
+		"w": "w", "week": "w", "weeks": "w",
+	}
+
+	weekdays = map[string]time.Weekday{
+		"sunday":    time.Sunday,
+		"monday":    time.Monday,
+		"tuesday":   time.Tuesday,
+		"wednesday": time.Wednesday,
+		"thursday":  time.Thursday,
+		"friday":    time.Friday,
+		"saturday":  time.Saturday,
+	}
+)
+
+type errBadWhen struct {
+	input string
+}
+
+func (e errBadWhen) Error() string {
+	return fmt.Sprintf(
+		"can't understand when %q is: try something like \"in 2h\", \"in 3 days\", "+
+			"\"tomorrow 9am\", \"friday at 5pm\", \"%s 13:30\" or \"noon\"",
+		e.input, dateLayout)
+}
+
+type errPastWhen struct {
+	when time.Time
+}
+
+func (e errPastWhen) Error() string {
+	return fmt.Sprintf("%s is in the past", e.when.Format("Mon Jan 2 2006 15:04 MST"))
+}
+
+// ParseWhen parses a future time from the natural language time expression at
+// the start of words, returning the time and the words that follow the
+// expression. Days and times of day are in now's location. Supported
+// expressions are:
+//
+//	in <duration>            e.g. "in 2h", "in 90m", "in 3d", "in 2 weeks"
+//	<day> [at] [<time>]      e.g. "tomorrow", "today 5pm", "friday at 9:30am",
+//	                         "2021-03-01 17:00"
+//	[at] <time>              e.g. "at 5pm", "noon", "13:30"
+//
+// A day without a time of day is at 9am. A time of day without a day is the
+// next occurrence of that time.
+func ParseWhen(words []string, now time.Time) (time.Time, []string, error) {
+	if len(words) == 0 {
+		return time.Time{}, nil, errBadWhen{""}
+	}
+
+	if strings.EqualFold(words[0], "in") {
+		return parseIn(words, now)
+	}
+
+	day, rest, foundDay := parseDay(words, now)
+
+	if len(rest) > 0 && strings.EqualFold(rest[0], "at") {
+		rest = rest[1:]
+	}
+
+	hour, minute, foundClock := 0, 0, false
+	if len(rest) > 0 {
+		hour, minute, foundClock = parseClock(rest[0])
+	}
+
+	var when time.Time
+
+	switch {
+	case foundDay && foundClock:
+		when = atClock(day, hour, minute)
+		rest = rest[1:]
+	case foundDay:
+		when = atClock(day, defaultHour, 0)
+	case foundClock:
+		if when = atClock(now, hour, minute); !when.After(now) {
+			when = atClock(now.AddDate(0, 0, 1), hour, minute)
+		}
+
+		rest = rest[1:]
+	default:
+		return time.Time{}, nil, errBadWhen{strings.Join(words, " ")}
+	}
+
+	if !when.After(now) {
+		return time.Time{}, nil, errPastWhen{when}
+	}
+
+	return when, rest, nil
+}
+
+// parseIn parses a relative time like "in 2h" or "in 2 hours".
+func parseIn(words []string, now time.Time) (time.Time, []string, error) {
+	if len(words) < 2 {
+		return time.Time{}, nil, errBadWhen{strings.Join(words, " ")}
+	}
+
+	input, rest := words[1], words[2:]
+
+	// A number followed by a unit word.
+	if _, err := strconv.Atoi(input); err == nil && len(rest) > 0 {
+		if suffix, ok := unitWords[strings.ToLower(rest[0])]; ok {
+			input, rest = input+suffix, rest[1:]
+		}
+	}
+
+	d, err := parseAge(strings.ToLower(input))
+	if err != nil || d <= 0 {
+		return time.Time{}, nil, errBadWhen{strings.Join(words[:len(words)-len(rest)], " ")}
+	}
+
+	return now.Add(d), rest, nil
+}
+
+// parseDay parses a day like "today", "tomorrow", "friday" or "2021-03-01" from
+// the first word, returning the day and the remaining words.
+func parseDay(words []string, now time.Time) (time.Time, []string, bool) {
+	word := strings.ToLower(words[0])
+
+	switch word {
+	case "today":
+		return now, words[1:], true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), words[1:], true
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		days := (int(weekday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+
+		return now.AddDate(0, 0, days), words[1:], true
+	}
+
+	if day, err := time.ParseInLocation(dateLayout, word, now.Location()); err == nil {
+		return day, words[1:], true
+	}
+
+	return time.Time{}, words, false
+}
+
+// parseClock parses a time of day like "9am", "9:30pm", "17:00", "noon" or
+// "midnight".
+func parseClock(word string) (int, int, bool) {
+	word = strings.ToLower(word)
+
+	switch word {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	match := clockPattern.FindStringSubmatch(word)
+	// A bare number (e.g. "9") isn't a time of day, it's likely part of the
+	// reminder text.
+	if match == nil || (match[2] == "" && match[3] == "") {
+		return 0, 0, false
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, false
+		}
+	}
+
+	if minute > 59 {
+		return 0, 0, false
+	}
+
+	return hour, minute, true
+}
+
+// atClock returns the given hour and minute on the day of t, in t's location.
+func atClock(t time.Time, hour, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, t.Location())
+}
@@ -0,0 +1,150 @@
+package botcmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//nolint:funlen
+func TestParseWhen(t *testing.T) {
+	// Monday March 15th 2021, 12:00 in a fixed UTC-5 zone.
+	loc := time.FixedZone("EST", -5*60*60)
+	now := time.Date(2021, time.March, 15, 12, 0, 0, 0, loc)
+
+	testCases := []struct {
+		input        string
+		expected     time.Time
+		expectedRest string
+		expectBad    bool
+		expectPast   bool
+	}{
+		{
+			input:        "in 2h to feed the frog",
+			expected:     now.Add(2 * time.Hour),
+			expectedRest: "to feed the frog",
+		},
+		{
+			input:        "in 90m stretch",
+			expected:     now.Add(90 * time.Minute),
+			expectedRest: "stretch",
+		},
+		{
+			input:        "in 3 days check the pond",
+			expected:     now.AddDate(0, 0, 3),
+			expectedRest: "check the pond",
+		},
+		{
+			input:        "in 1 Week",
+			expected:     now.AddDate(0, 0, 7),
+			expectedRest: "",
+		},
+		{
+			input:     "in 2 frogs",
+			expectBad: true,
+		},
+		{
+			input:     "in",
+			expectBad: true,
+		},
+		{
+			input:        "tomorrow 9am standup",
+			expected:     time.Date(2021, time.March, 16, 9, 0, 0, 0, loc),
+			expectedRest: "standup",
+		},
+		{
+			input:        "tomorrow standup",
+			expected:     time.Date(2021, time.March, 16, 9, 0, 0, 0, loc),
+			expectedRest: "standup",
+		},
+		{
+			input:        "today at 5:30pm leave",
+			expected:     time.Date(2021, time.March, 15, 17, 30, 0, 0, loc),
+			expectedRest: "leave",
+		},
+		{
+			input:      "today 9am",
+			expectPast: true,
+		},
+		{
+			input:        "friday at noon lunch",
+			expected:     time.Date(2021, time.March, 19, 12, 0, 0, 0, loc),
+			expectedRest: "lunch",
+		},
+		{
+			input:        "Monday 12am",
+			expected:     time.Date(2021, time.March, 22, 0, 0, 0, 0, loc),
+			expectedRest: "",
+		},
+		{
+			input:        "2021-04-01 13:30 pranks",
+			expected:     time.Date(2021, time.April, 1, 13, 30, 0, 0, loc),
+			expectedRest: "pranks",
+		},
+		{
+			input:      "2021-03-01",
+			expectPast: true,
+		},
+		{
+			input:        "at 11am coffee",
+			expected:     time.Date(2021, time.March, 16, 11, 0, 0, 0, loc),
+			expectedRest: "coffee",
+		},
+		{
+			input:        "13:00 lunch",
+			expected:     time.Date(2021, time.March, 15, 13, 0, 0, 0, loc),
+			expectedRest: "lunch",
+		},
+		{
+			input:        "midnight",
+			expected:     time.Date(2021, time.March, 16, 0, 0, 0, 0, loc),
+			expectedRest: "",
+		},
+		{
+			input:     "13pm",
+			expectBad: true,
+		},
+		{
+			input:     "9 frogs",
+			expectBad: true,
+		},
+		{
+			input:     "soon",
+			expectBad: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			when, rest, err := ParseWhen(strings.Fields(tc.input), now)
+
+			var badErr errBadWhen
+
+			var pastErr errPastWhen
+
+			switch {
+			case tc.expectBad:
+				if !errors.As(err, &badErr) {
+					t.Errorf("expected errBadWhen, got %v", err)
+				}
+			case tc.expectPast:
+				if !errors.As(err, &pastErr) {
+					t.Errorf("expected errPastWhen, got %v", err)
+				}
+			case err != nil:
+				t.Errorf("unexpected err: %v", err)
+			default:
+				if !when.Equal(tc.expected) {
+					t.Errorf("expected %s, got %s", tc.expected, when)
+				}
+
+				expected := strings.Fields(tc.expectedRest)
+				if len(rest)+len(expected) > 0 && !reflect.DeepEqual(rest, expected) {
+					t.Errorf("expected rest %q, got %q", expected, rest)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Package chart renders simple bar and line charts as PNG images. Charts are
+// drawn in pure Go with the standard library image packages and a fixed size
+// bitmap font so that they can be rendered without any external charting
+// service.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// width is the width of every chart image.
+	width = 640
+	// margin is the space around the edges of the chart.
+	margin = 16
+	// charWidth and lineHeight are the dimensions of the basicfont face.
+	charWidth  = 7
+	lineHeight = 13
+	// titleHeight is the space reserved above the plot for the title.
+	titleHeight = 2 * lineHeight
+
+	// barHeight is the height of each bar in a bar chart and barGap the space
+	// between bars.
+	barHeight = 18
+	barGap    = 6
+	// maxLabelChars is the maximum length of a bar chart label. Longer labels
+	// are truncated.
+	maxLabelChars = 24
+
+	// lineChartHeight is the height of line chart images.
+	lineChartHeight = 360
+	// maxXLabels is the maximum number of x-axis labels drawn on a line chart.
+	maxXLabels = 6
+	// yAxisWidth is the space reserved left of a line chart's plot for the y
+	// axis labels.
+	yAxisWidth = 7 * charWidth
+)
+
+const (
+	// MaxBars is the maximum number of bars in a bar chart.
+	MaxBars = 25
+	// MaxPoints is the maximum number of points in a line chart.
+	MaxPoints = 366
+)
+
+var (
+	background = color.White
+	foreground = color.Black
+	gridColor  = color.Gray{Y: 0xdd}
+	dataColor  = color.RGBA{R: 0x2e, G: 0x8b, B: 0x57, A: 0xff}
+)
+
+var (
+	// ErrNoData is returned when rendering a chart without any data.
+	ErrNoData = errors.New("chart has no data")
+	// ErrTooMuchData is returned when rendering a bar chart with more than
+	// MaxBars bars or a line chart with more than MaxPoints points.
+	ErrTooMuchData = errors.New("chart has too much data")
+)
+
+// Datum is a labelled value in a chart. For a bar chart each Datum is a bar,
+// for a line chart each Datum is a point along the x axis.
+type Datum struct {
+	Label string
+	Value int
+}
+
+// canvas is an image being drawn for a chart.
+type canvas struct {
+	*image.RGBA
+}
+
+func newCanvas(w, h int) canvas {
+	c := canvas{image.NewRGBA(image.Rect(0, 0, w, h))}
+	draw.Draw(c, c.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	return c
+}
+
+// text draws s with its top left corner at x, y.
+func (c canvas) text(x, y int, s string) {
+	d := font.Drawer{
+		Dst:  c,
+		Src:  image.NewUniform(foreground),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y+basicfont.Face7x13.Ascent),
+	}
+	d.DrawString(s)
+}
+
+// rect fills the rectangle from x0, y0 to x1, y1 (exclusive) with col.
+func (c canvas) rect(x0, y0, x1, y1 int, col color.Color) {
+	draw.Draw(c, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// line draws a two pixel wide line from x0, y0 to x1, y1 with col using
+// Bresenham's algorithm.
+func (c canvas) line(x0, y0, x1, y1 int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+
+	for {
+		c.rect(x0, y0, x0+2, y0+2, col)
+
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// encode returns the canvas as a PNG.
+func (c canvas) encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, c); err != nil {
+		return nil, fmt.Errorf("chart png encode err: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// truncate shortens s to at most n characters, replacing the end with "..."
+// if it was too long.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-3]) + "..."
+}
+
+// maxValue returns the largest value in data, or 1 if no value is positive so
+// that it can be used to scale values.
+func maxValue(data []Datum) int {
+	largest := 1
+	for _, d := range data {
+		if d.Value > largest {
+			largest = d.Value
+		}
+	}
+
+	return largest
+}
+
+// Bar returns a PNG of a horizontal bar chart with the given title and a bar
+// for each Datum, in order from top to bottom.
+func Bar(title string, data []Datum) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	} else if len(data) > MaxBars {
+		return nil, ErrTooMuchData
+	}
+
+	labelChars := 0
+	for _, d := range data {
+		if n := len([]rune(truncate(d.Label, maxLabelChars))); n > labelChars {
+			labelChars = n
+		}
+	}
+
+	height := 2*margin + titleHeight + len(data)*(barHeight+barGap)
+	c := newCanvas(width, height)
+	c.text(margin, margin, title)
+
+	scale := maxValue(data)
+	barX := margin + (labelChars+1)*charWidth
+	// Leave room after the longest bar for its value.
+	maxBarWidth := width - margin - barX - len(fmt.Sprint(scale))*charWidth - charWidth
+
+	for i, d := range data {
+		y := margin + titleHeight + i*(barHeight+barGap)
+		textY := y + (barHeight-lineHeight)/2
+
+		c.text(margin, textY, truncate(d.Label, maxLabelChars))
+
+		barWidth := 0
+		if d.Value > 0 {
+			barWidth = d.Value * maxBarWidth / scale
+		}
+
+		c.rect(barX, y, barX+barWidth, y+barHeight, dataColor)
+		c.text(barX+barWidth+charWidth/2, textY, fmt.Sprint(d.Value))
+	}
+
+	return c.encode()
+}
+
+// Line returns a PNG of a line chart with the given title and a point for each
+// Datum, in order from left to right. The y axis starts at zero. Labels are
+// drawn for a subset of the points if there are too many to fit.
+func Line(title string, data []Datum) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	} else if len(data) > MaxPoints {
+		return nil, ErrTooMuchData
+	}
+
+	c := newCanvas(width, lineChartHeight)
+	c.text(margin, margin, title)
+
+	plotLeft := margin + yAxisWidth
+	plotRight := width - margin
+	plotTop := margin + titleHeight
+	plotBottom := lineChartHeight - margin - 2*lineHeight
+	scale := maxValue(data)
+
+	// Horizontal grid lines and y axis labels at zero, half and the max value.
+	for _, v := range []int{0, scale / 2, scale} {
+		y := plotBottom - v*(plotBottom-plotTop)/scale
+		label := fmt.Sprint(v)
+
+		c.rect(plotLeft, y, plotRight, y+1, gridColor)
+		c.text(plotLeft-(len(label)+1)*charWidth, y-lineHeight/2, label)
+	}
+
+	// Axes.
+	c.rect(plotLeft, plotTop, plotLeft+1, plotBottom+1, foreground)
+	c.rect(plotLeft, plotBottom, plotRight, plotBottom+1, foreground)
+
+	point := func(i int) (int, int) {
+		x := (plotLeft + plotRight) / 2
+		if len(data) > 1 {
+			x = plotLeft + i*(plotRight-plotLeft)/(len(data)-1)
+		}
+
+		v := data[i].Value
+		if v < 0 {
+			v = 0
+		}
+
+		return x, plotBottom - v*(plotBottom-plotTop)/scale
+	}
+
+	step := (len(data) + maxXLabels - 1) / maxXLabels
+
+	for i := range data {
+		x, y := point(i)
+
+		if i > 0 {
+			prevX, prevY := point(i - 1)
+			c.line(prevX, prevY, x, y, dataColor)
+		}
+
+		c.rect(x-2, y-2, x+3, y+3, dataColor)
+
+		if i%step == 0 {
+			label := data[i].Label
+			labelX := x - len(label)*charWidth/2
+
+			// Keep the label inside the image.
+			if labelX+len(label)*charWidth > width {
+				labelX = width - len(label)*charWidth
+			}
+
+			c.rect(x, plotBottom, x+1, plotBottom+4, foreground)
+			c.text(labelX, plotBottom+lineHeight/2, label)
+		}
+	}
+
+	return c.encode()
+}
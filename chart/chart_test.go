@@ -0,0 +1,96 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected err decoding png: %v", err)
+	}
+
+	return img
+}
+
+func TestNoData(t *testing.T) {
+	if _, err := Bar("empty", nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("expected ErrNoData from Bar, got %v", err)
+	}
+
+	if _, err := Line("empty", nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("expected ErrNoData from Line, got %v", err)
+	}
+}
+
+func TestTooMuchData(t *testing.T) {
+	if _, err := Bar("big", make([]Datum, MaxBars+1)); !errors.Is(err, ErrTooMuchData) {
+		t.Errorf("expected ErrTooMuchData from Bar, got %v", err)
+	}
+
+	if _, err := Line("big", make([]Datum, MaxPoints+1)); !errors.Is(err, ErrTooMuchData) {
+		t.Errorf("expected ErrTooMuchData from Line, got %v", err)
+	}
+}
+
+func TestBar(t *testing.T) {
+	data := []Datum{
+		{Label: ":frog:", Value: 10},
+		{Label: ":a_very_long_emoji_name_that_gets_truncated:", Value: 5},
+		{Label: ":zero:", Value: 0},
+	}
+
+	out, err := Bar("Top emoji", data)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	img := decode(t, out)
+	expectedHeight := 2*margin + titleHeight + len(data)*(barHeight+barGap)
+
+	if bounds := img.Bounds(); bounds.Dx() != width || bounds.Dy() != expectedHeight {
+		t.Errorf("expected %dx%d image, got %dx%d", width, expectedHeight, bounds.Dx(), bounds.Dy())
+	}
+
+	// The first bar starts after the longest (truncated) label.
+	barX := margin + (maxLabelChars+1)*charWidth
+	barY := margin + titleHeight + barHeight/2
+
+	if c := img.At(barX+1, barY); c != dataColor {
+		t.Errorf("expected bar color at %d,%d, got %v", barX+1, barY, c)
+	}
+}
+
+func TestLine(t *testing.T) {
+	for _, n := range []int{1, 2, 30} {
+		data := make([]Datum, n)
+		for i := range data {
+			data[i] = Datum{Label: "09-13", Value: i}
+		}
+
+		out, err := Line("Usage", data)
+		if err != nil {
+			t.Fatalf("unexpected err with %d points: %v", n, err)
+		}
+
+		if bounds := decode(t, out).Bounds(); bounds.Dx() != width || bounds.Dy() != lineChartHeight {
+			t.Errorf("expected %dx%d image, got %dx%d", width, lineChartHeight, bounds.Dx(), bounds.Dy())
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if s := truncate("short", 10); s != "short" {
+		t.Errorf("expected short string unchanged, got %q", s)
+	}
+
+	if s := truncate("much too long", 10); s != "much to..." {
+		t.Errorf("expected truncated string %q, got %q", "much to...", s)
+	}
+}
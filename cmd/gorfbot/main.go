@@ -15,6 +15,9 @@ var (
 
 	logLevel = flag.String(
 		"loglevel", "WARN", "Log msgs only at levels >= the provided logLevel")
+
+	logFormat = flag.String(
+		"logformat", "", `Log format, "text" or "json". Overrides the LogConf Format from the config file`)
 )
 
 func onErrQuit(log *logrus.Logger, e error) {
@@ -42,6 +45,19 @@ func stringToLevel(levelStr string) logrus.Level {
 	return logrus.WarnLevel
 }
 
+func stringToFormatter(formatStr string) logrus.Formatter {
+	switch strings.ToLower(formatStr) {
+	case "", "text":
+		return &logrus.TextFormatter{}
+	case "json":
+		return &logrus.JSONFormatter{}
+	}
+
+	logrus.Warnf(`Unknown log format: %q Using "text"`, formatStr)
+
+	return &logrus.TextFormatter{}
+}
+
 func main() {
 	flag.Parse()
 
@@ -56,6 +72,14 @@ func main() {
 	onErrQuit(log, err)
 	log.Infof("Read config from %q", "config.yml")
 
+	// Set the log format. The command line flag overrides the config file.
+	format := c.LogConf.Format
+	if *logFormat != "" {
+		format = *logFormat
+	}
+
+	log.SetFormatter(stringToFormatter(format))
+
 	// Create a Bot instance from the config.
 	garf, err := bot.New(log, c)
 	onErrQuit(log, err)
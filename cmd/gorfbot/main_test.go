@@ -50,3 +50,41 @@ func TestStringToLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestStringToFormatter(t *testing.T) {
+	testCases := []struct {
+		formatStr string
+		json      bool
+	}{
+		{
+			formatStr: "", // Test the default when nothing is configured
+			json:      false,
+		},
+		{
+			formatStr: "text",
+			json:      false,
+		},
+		{
+			formatStr: "JSON", // Test case insensitivity
+			json:      true,
+		},
+		{
+			formatStr: "json",
+			json:      true,
+		},
+		{
+			formatStr: "xml", // Test default for unknown formats
+			json:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.formatStr, func(t *testing.T) {
+			_, isJSON := stringToFormatter(tc.formatStr).(*logrus.JSONFormatter)
+			if isJSON != tc.json {
+				t.Errorf("expected format str %q to give JSON formatter %v, was %v",
+					tc.formatStr, tc.json, isJSON)
+			}
+		})
+	}
+}
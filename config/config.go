@@ -19,13 +19,25 @@ func ContextForTimeout(timeout time.Duration) (context.Context, context.CancelFu
 
 // Config is a structure describing the overall gorfbot configuration.
 type Config struct {
-	MongoConf       MongoConfig       `yaml:"MongoConf"`
-	SlackConf       SlackConfig       `yaml:"SlackConf"`
-	ReactjiKeysConf ReactjiKeysConfig `yaml:"ReactjiKeysConf"`
-	FrogtipConf     FrogtipConfig     `yaml:"FrogtipConf"`
-	GISConf         GISConfig         `yaml:"GISConf"`
-	URLsConf        URLsConfig        `yaml:"URLsConf"`
-	MkthemeConf     MkthemeConfig     `yaml:"MkthemeConf"`
+	MongoConf         MongoConfig         `yaml:"MongoConf"`
+	SlackConf         SlackConfig         `yaml:"SlackConf"`
+	ReactjiKeysConf   ReactjiKeysConfig   `yaml:"ReactjiKeysConf"`
+	FrogtipConf       FrogtipConfig       `yaml:"FrogtipConf"`
+	GISConf           GISConfig           `yaml:"GISConf"`
+	URLsConf          URLsConfig          `yaml:"URLsConf"`
+	MkthemeConf       MkthemeConfig       `yaml:"MkthemeConf"`
+	HTTPConf          HTTPConfig          `yaml:"HTTPConf"`
+	LogConf           LogConfig           `yaml:"LogConf"`
+	StarboardConf     StarboardConfig     `yaml:"StarboardConf"`
+	EmojiAnnounceConf EmojiAnnounceConfig `yaml:"EmojiAnnounceConf"`
+	ScheduleConf      ScheduleConfig      `yaml:"ScheduleConf"`
+	QuoteConf         QuoteConfig         `yaml:"QuoteConf"`
+	GorfsayConf       GorfsayConfig       `yaml:"GorfsayConf"`
+	RollConf          RollConfig          `yaml:"RollConf"`
+	RepostConf        RepostConfig        `yaml:"RepostConf"`
+	// Admins is a list of Slack user names allowed to use admin commands (e.g.
+	// "!schedule list").
+	Admins []string `yaml:"Admins"`
 }
 
 var ErrNilConfig = errors.New("config was nil")
@@ -130,6 +142,9 @@ type SlackConfig struct {
 	Debug bool `yaml:"Debug"`
 	// How long to wait before refreshing slack state (channels, users, etc).
 	StateMaxAge *time.Duration `yaml:"StateMaxAge"`
+	// APIURL overrides the Slack Web API URL - optional. Only useful for pointing
+	// the bot at a fake Slack server in tests.
+	APIURL string `yaml:"APIURL"`
 }
 
 var errMissingSlackAPIToken = errors.New("provided Slack Config missing APIToken")
@@ -145,10 +160,39 @@ func (c SlackConfig) Check() error {
 }
 
 // ReactjiKeysConfig describes a mapping of keywords to lists of reactions to apply
-// when the keyword is seen.
+// when the keyword is seen, and richer rules for reacting to messages. More
+// rules can be added at runtime with the reactji command.
 type ReactjiKeysConfig struct {
 	// Keywords is a map of keyword to reaction list.
 	Keywords map[string][]string `yaml:"Keywords"`
+	// Rules is a list of ReactjiRules.
+	Rules []ReactjiRule `yaml:"Rules"`
+	// RandomSeed for deciding if rules with a Chance fire.
+	RandomSeed int64 `yaml:"RandomSeed"`
+}
+
+// ReactjiRule describes reactions to add to (and optionally text to reply to)
+// messages matching a trigger.
+type ReactjiRule struct {
+	// Trigger is a word, a phrase or (if Regex is true) a regular expression
+	// that messages must match. Words and phrases only match whole words.
+	Trigger string `yaml:"Trigger"`
+	// Regex is true if the Trigger is a regular expression.
+	Regex bool `yaml:"Regex"`
+	// CaseSensitive is true if the Trigger must match the case of messages.
+	CaseSensitive bool `yaml:"CaseSensitive"`
+	// Reactions is a list of reactions (no ":" delimiters) to add.
+	Reactions []string `yaml:"Reactions"`
+	// Reply is text to post in reply, if not empty.
+	Reply string `yaml:"Reply"`
+	// Chance is the probability (between 0 and 1) of the rule firing for a
+	// matching message. Defaults to always firing.
+	Chance float64 `yaml:"Chance"`
+	// Channels is a list of channel names (no "#" prefix) the rule applies to.
+	// It applies to every channel if empty.
+	Channels []string `yaml:"Channels"`
+	// Cooldown is the minimum time between the rule firing - optional.
+	Cooldown *time.Duration `yaml:"Cooldown"`
 }
 
 // FrogtipConfig describes configuration used by the Frogtips botcmd.
@@ -198,3 +242,137 @@ type MkthemeConfig struct {
 	// RandomSeed for seeding the colour generator.
 	RandomSeed int64 `yaml:"RandomSeed"`
 }
+
+// HTTPConfig describes configuration for the bot's optional HTTP listener.
+type HTTPConfig struct {
+	// ListenAddr is the address (e.g. ":9090") to serve Prometheus metrics
+	// (/metrics) and health checks (/healthz, /readyz) on - optional. The HTTP
+	// listener is disabled if empty.
+	ListenAddr string `yaml:"ListenAddr"`
+}
+
+// LogConfig describes configuration for the bot's logging.
+type LogConfig struct {
+	// Format of log lines, either "text" or "json" - optional. Defaults to
+	// "text". The -logformat command line flag takes precedence.
+	Format string `yaml:"Format"`
+}
+
+// StarboardConfig describes configuration used by the starboard reaction
+// handler, which reposts messages that collect enough reactions of an emoji to
+// a hall of fame channel.
+type StarboardConfig struct {
+	// Channel is the name (no "#" prefix) of the channel to repost messages to
+	// (e.g. "hall-of-fame"). The starboard is disabled if empty.
+	Channel string `yaml:"Channel"`
+	// Rules is a list of StarboardRules. Messages in channels without a
+	// matching rule are never reposted.
+	Rules []StarboardRule `yaml:"Rules"`
+}
+
+// StarboardRule describes how many reactions of an emoji a message in a channel
+// needs to collect to be reposted.
+type StarboardRule struct {
+	// Channel is the name (no "#" prefix) of the channel the rule applies to. A
+	// rule without a Channel applies to every channel without its own rule.
+	Channel string `yaml:"Channel"`
+	// Emoji is the reaction (no ":" delimiters) to count. Defaults to "star".
+	Emoji string `yaml:"Emoji"`
+	// Threshold is the number of reactions needed. Defaults to 5.
+	Threshold int `yaml:"Threshold"`
+}
+
+// EmojiAnnounceConfig describes configuration used by the new emoji handler,
+// which announces custom emoji being added to or removed from the workspace.
+type EmojiAnnounceConfig struct {
+	// Channel is the name (no "#" prefix) of the channel to announce emoji
+	// changes in (e.g. "emoji"). Announcements are disabled if empty.
+	Channel string `yaml:"Channel"`
+}
+
+// QuoteConfig describes configuration used by the quote botcmd and reaction
+// handler, which save memorable messages to the quote database.
+type QuoteConfig struct {
+	// Emoji is the reaction (no ":" delimiters) that saves the reacted to
+	// message as a quote. Defaults to "speech_balloon".
+	Emoji string `yaml:"Emoji"`
+	// RandomSeed for choosing random quotes.
+	RandomSeed int64 `yaml:"RandomSeed"`
+}
+
+// GorfsayConfig describes configuration used by the gorfsay botcmd and the
+// pattern handler that learns how people talk from their messages for it.
+type GorfsayConfig struct {
+	// Channels is a list of channel names (no "#" prefix) whose messages are
+	// learned from. Nothing is learned if empty.
+	Channels []string `yaml:"Channels"`
+	// Order is the number of previous words used to choose each next word.
+	// Defaults to 2. Changing it discards everything learned so far.
+	Order int `yaml:"Order"`
+	// RandomSeed for generating text.
+	RandomSeed int64 `yaml:"RandomSeed"`
+}
+
+// RollConfig describes configuration used by the roll botcmd.
+type RollConfig struct {
+	// RandomSeed for rolling dice.
+	RandomSeed int64 `yaml:"RandomSeed"`
+}
+
+// RepostConfig describes configuration used by the repost pattern handler,
+// which remembers every link shared and calls out links that were already
+// shared recently.
+type RepostConfig struct {
+	// Enabled turns on remembering links and calling out reposts. Off by
+	// default.
+	Enabled bool `yaml:"Enabled"`
+	// Lookback is how recently a link must have been shared for sharing it again
+	// to be called out - optional. Defaults to 30 days ("720h").
+	Lookback *time.Duration `yaml:"Lookback"`
+	// IgnoreHosts is a list of hosts (e.g. "giphy.com") whose links, and the
+	// links of their subdomains, are never remembered or called out.
+	IgnoreHosts []string `yaml:"IgnoreHosts"`
+	// IgnoreChannels is a list of channel names (no "#" prefix) where links are
+	// never remembered or called out.
+	IgnoreChannels []string `yaml:"IgnoreChannels"`
+	// StripParams is a list of query parameters to remove from links before
+	// comparing them, in addition to tracking parameters like "utm_source" and
+	// "fbclid" that are always removed.
+	StripParams []string `yaml:"StripParams"`
+}
+
+// ScheduleConfig describes the scheduled commands to run and when to run them.
+type ScheduleConfig struct {
+	// Schedules is a list of ScheduleEntry. Scheduled commands without an entry
+	// are never run.
+	Schedules []ScheduleEntry `yaml:"Schedules"`
+}
+
+// ScheduleEntry describes when to run a scheduled command and where to post
+// its result.
+type ScheduleEntry struct {
+	// Command is the name of the registered scheduled command to run (e.g.
+	// "on this day").
+	Command string `yaml:"Command"`
+	// Cron is a standard five field cron expression (e.g. "0 14 * * *") or
+	// descriptor (e.g. "@daily") for when to run the command. Times are UTC.
+	Cron string `yaml:"Cron"`
+	// Channel is the name (no "#" prefix) of the channel to post the command's
+	// result in (e.g. "general").
+	Channel string `yaml:"Channel"`
+	// SkipMissed disables running the command once at startup when a run was
+	// missed while the bot was not running.
+	SkipMissed bool `yaml:"SkipMissed"`
+}
+
+// IsAdmin returns true if the given Slack user name is one of the configured
+// Admins.
+func (c Config) IsAdmin(userName string) bool {
+	for _, admin := range c.Admins {
+		if admin == userName {
+			return true
+		}
+	}
+
+	return false
+}
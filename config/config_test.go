@@ -45,6 +45,16 @@ ReactjiKeysConf:
     baz: 
       - bop
       - pog
+  Rules:
+  - Trigger: "^ri+bbit$"
+    Regex: true
+    Reactions:
+    - "frog"
+    Reply: "ribbit"
+    Chance: 0.5
+    Channels:
+    - "general"
+    Cooldown: "1h"
 GISConf:
   CSEID: "xxx"
   APIKey: "yyy"
@@ -57,6 +67,15 @@ URLsConf:
     FirstMsg: ":sparkles: new example.com link shared :sparkles:"
     Reactji:
     - "link"
+RepostConf:
+  Enabled: true
+  Lookback: "1h"
+  IgnoreHosts:
+  - "giphy.com"
+  IgnoreChannels:
+  - "memes"
+  StripParams:
+  - "t"
 `),
 			expectedConfig: &config.Config{
 				MongoConf: config.MongoConfig{
@@ -82,6 +101,17 @@ URLsConf:
 						"foo": {"bar"},
 						"baz": {"bop", "pog"},
 					},
+					Rules: []config.ReactjiRule{
+						{
+							Trigger:   "^ri+bbit$",
+							Regex:     true,
+							Reactions: []string{"frog"},
+							Reply:     "ribbit",
+							Chance:    0.5,
+							Channels:  []string{"general"},
+							Cooldown:  &oneHour,
+						},
+					},
 				},
 				GISConf: config.GISConfig{
 					CSEID:   "xxx",
@@ -99,6 +129,13 @@ URLsConf:
 						},
 					},
 				},
+				RepostConf: config.RepostConfig{
+					Enabled:        true,
+					Lookback:       &oneHour,
+					IgnoreHosts:    []string{"giphy.com"},
+					IgnoreChannels: []string{"memes"},
+					StripParams:    []string{"t"},
+				},
 			},
 		},
 		{
@@ -5,10 +5,15 @@ go 1.14
 require (
 	github.com/dustinkirkland/golang-petname v0.0.0-20191129215211-8e5a1ed0cff0
 	github.com/golang/mock v1.4.4
+	github.com/gorilla/websocket v1.4.2
+	github.com/kyokomi/emoji/v2 v2.2.13
 	github.com/lucasb-eyer/go-colorful v1.0.3
-	github.com/sirupsen/logrus v1.4.2
+	github.com/prometheus/client_golang v1.10.0
+	github.com/robfig/cron/v3 v3.0.1
+	github.com/sirupsen/logrus v1.6.0
 	github.com/slack-go/slack v0.7.2
 	go.mongodb.org/mongo-driver v1.5.1
+	golang.org/x/image v0.0.0-20201208152932-35266b937fa6
 	google.golang.org/api v0.36.0
-	gopkg.in/yaml.v2 v2.2.8
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -0,0 +1,168 @@
+// Package metrics defines the Prometheus metrics Gorfbot exports and small
+// helpers for updating them. The metrics are registered with the default
+// Prometheus registry and are served by the bot's optional HTTP listener.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "gorfbot"
+
+// Handler kinds used as the "kind" label of handler metrics.
+const (
+	KindCommand     = "command"
+	KindPattern     = "pattern"
+	KindReaction    = "reaction"
+	KindEmojiChange = "emoji_change"
+	KindScheduled   = "scheduled"
+)
+
+// Event types used as the "type" label of EventsReceived.
+const (
+	EventMessage         = "message"
+	EventReactionAdded   = "reaction_added"
+	EventReactionRemoved = "reaction_removed"
+	EventEmojiChanged    = "emoji_changed"
+)
+
+var (
+	// EventsReceived counts Slack events received by the bot by type.
+	EventsReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "Slack events received by the bot, by event type.",
+	}, []string{"type"})
+
+	// HandlerInvocations counts runs of commands, patterns and reaction handlers.
+	HandlerInvocations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "handler_invocations_total",
+		Help:      "Runs of commands, patterns and reaction handlers, by kind and name.",
+	}, []string{"kind", "name"})
+
+	// HandlerErrors counts runs of commands, patterns and reaction handlers that
+	// returned an error.
+	HandlerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "handler_errors_total",
+		Help:      "Runs of commands, patterns and reaction handlers that returned an error, by kind and name.",
+	}, []string{"kind", "name"})
+
+	// HandlerDuration observes how long runs of commands, patterns and reaction
+	// handlers take.
+	HandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Duration of command, pattern and reaction handler runs, by kind and name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind", "name"})
+
+	// StorageDuration observes how long storage operations take.
+	StorageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Duration of storage operations, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// StorageErrors counts storage operations that returned an error.
+	StorageErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_errors_total",
+		Help:      "Storage operations that returned an error, by operation.",
+	}, []string{"operation"})
+
+	// SlackSendFailures counts messages that Slack failed to send.
+	SlackSendFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_send_failures_total",
+		Help:      "Messages the Slack client failed to send.",
+	})
+
+	// SlackReactionFailures counts reactions that Slack failed to add.
+	SlackReactionFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_reaction_failures_total",
+		Help:      "Reactions the Slack client failed to add.",
+	})
+
+	// SlackUploadFailures counts files that Slack failed to upload.
+	SlackUploadFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_upload_failures_total",
+		Help:      "Files the Slack client failed to upload.",
+	})
+
+	// SlackStateSize is the number of users, conversations and custom emoji in
+	// the Slack state cache.
+	SlackStateSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slack_state_size",
+		Help:      "Number of items in the Slack state cache, by kind (users, conversations or emoji).",
+	}, []string{"kind"})
+
+	// SlackStateAge is the time since the Slack state cache was last refreshed.
+	// It is computed when scraped.
+	SlackStateAge = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slack_state_age_seconds",
+		Help:      "Seconds since the Slack state cache was last refreshed (0 if it never has been).",
+	}, slackStateAge)
+)
+
+var (
+	slackStateMu      sync.Mutex
+	slackStateUpdated time.Time
+)
+
+// slackStateAge returns the number of seconds since SlackStateRefreshed was
+// last called, or 0 if it has never been called.
+func slackStateAge() float64 {
+	slackStateMu.Lock()
+	defer slackStateMu.Unlock()
+
+	if slackStateUpdated.IsZero() {
+		return 0
+	}
+
+	return time.Since(slackStateUpdated).Seconds()
+}
+
+// SlackStateRefreshed records that the Slack state cache was refreshed at the
+// given time and now holds the given number of users, conversations and custom
+// emoji.
+func SlackStateRefreshed(updated time.Time, users, conversations, emoji int) {
+	slackStateMu.Lock()
+	slackStateUpdated = updated
+	slackStateMu.Unlock()
+
+	SlackStateSize.WithLabelValues("users").Set(float64(users))
+	SlackStateSize.WithLabelValues("conversations").Set(float64(conversations))
+	SlackStateSize.WithLabelValues("emoji").Set(float64(emoji))
+}
+
+// ObserveHandler records a run of the handler with the given kind and name that
+// started at the given time and returned the given error.
+func ObserveHandler(kind, name string, start time.Time, err error) {
+	HandlerInvocations.WithLabelValues(kind, name).Inc()
+	HandlerDuration.WithLabelValues(kind, name).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		HandlerErrors.WithLabelValues(kind, name).Inc()
+	}
+}
+
+// ObserveStorage records a storage operation with the given name that started
+// at the given time and returned the given error.
+func ObserveStorage(operation string, start time.Time, err error) {
+	StorageDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		StorageErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -0,0 +1,62 @@
+//nolint:goerr113
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveHandler(t *testing.T) {
+	invocations := HandlerInvocations.WithLabelValues(KindCommand, "test-observe")
+	errs := HandlerErrors.WithLabelValues(KindCommand, "test-observe")
+
+	ObserveHandler(KindCommand, "test-observe", time.Now(), nil)
+	ObserveHandler(KindCommand, "test-observe", time.Now(), errors.New("oops"))
+
+	if count := testutil.ToFloat64(invocations); count != 2 {
+		t.Errorf("expected 2 invocations, got %v", count)
+	}
+
+	if count := testutil.ToFloat64(errs); count != 1 {
+		t.Errorf("expected 1 error, got %v", count)
+	}
+}
+
+func TestObserveStorage(t *testing.T) {
+	errs := StorageErrors.WithLabelValues("TestOperation")
+
+	ObserveStorage("TestOperation", time.Now(), nil)
+
+	if count := testutil.ToFloat64(errs); count != 0 {
+		t.Errorf("expected 0 errors, got %v", count)
+	}
+
+	ObserveStorage("TestOperation", time.Now(), errors.New("oops"))
+
+	if count := testutil.ToFloat64(errs); count != 1 {
+		t.Errorf("expected 1 error, got %v", count)
+	}
+}
+
+func TestSlackStateRefreshed(t *testing.T) {
+	SlackStateRefreshed(time.Now().Add(-time.Minute), 10, 3, 7)
+
+	if size := testutil.ToFloat64(SlackStateSize.WithLabelValues("users")); size != 10 {
+		t.Errorf("expected users size 10, got %v", size)
+	}
+
+	if size := testutil.ToFloat64(SlackStateSize.WithLabelValues("conversations")); size != 3 {
+		t.Errorf("expected conversations size 3, got %v", size)
+	}
+
+	if size := testutil.ToFloat64(SlackStateSize.WithLabelValues("emoji")); size != 7 {
+		t.Errorf("expected emoji size 7, got %v", size)
+	}
+
+	if age := testutil.ToFloat64(SlackStateAge); age < 60 {
+		t.Errorf("expected state age of at least 60s, got %v", age)
+	}
+}
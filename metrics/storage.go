@@ -0,0 +1,373 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+// instrumentedStorage is a storage.Storage that wraps another Storage
+// implementation and records the latency and errors of each operation.
+type instrumentedStorage struct {
+	storage storage.Storage
+}
+
+// InstrumentStorage returns a storage.Storage that records metrics for each
+// operation before passing it to the provided Storage.
+func InstrumentStorage(s storage.Storage) storage.Storage {
+	return instrumentedStorage{storage: s}
+}
+
+func (s instrumentedStorage) GetTopics(opts storage.GetTopicOptions) ([]models.Topic, error) {
+	start := time.Now()
+	topics, err := s.storage.GetTopics(opts)
+	ObserveStorage("GetTopics", start, err)
+
+	return topics, err
+}
+
+func (s instrumentedStorage) AddTopic(topic models.Topic) error {
+	start := time.Now()
+	err := s.storage.AddTopic(topic)
+	ObserveStorage("AddTopic", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetEmoji(opts storage.GetEmojiOptions) ([]models.Emoji, error) {
+	start := time.Now()
+	emoji, err := s.storage.GetEmoji(opts)
+	ObserveStorage("GetEmoji", start, err)
+
+	return emoji, err
+}
+
+func (s instrumentedStorage) UpsertEmojiCount(emoji models.Emoji, decrement bool) (models.Emoji, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertEmojiCount(emoji, decrement)
+	ObserveStorage("UpsertEmojiCount", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) UpsertEmojiUsage(usage models.EmojiUsage, decrement bool) (models.EmojiUsage, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertEmojiUsage(usage, decrement)
+	ObserveStorage("UpsertEmojiUsage", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) GetEmojiHistory(opts storage.GetEmojiHistoryOptions) ([]models.EmojiUsage, error) {
+	start := time.Now()
+	history, err := s.storage.GetEmojiHistory(opts)
+	ObserveStorage("GetEmojiHistory", start, err)
+
+	return history, err
+}
+
+func (s instrumentedStorage) GetReactedMessages(opts storage.GetReactedMessageOptions) ([]models.ReactedMessage, error) {
+	start := time.Now()
+	msgs, err := s.storage.GetReactedMessages(opts)
+	ObserveStorage("GetReactedMessages", start, err)
+
+	return msgs, err
+}
+
+func (s instrumentedStorage) UpsertReactedMessage(msg models.ReactedMessage, decrement bool) (models.ReactedMessage, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertReactedMessage(msg, decrement)
+	ObserveStorage("UpsertReactedMessage", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) UpsertStarboardCount(msg models.StarboardMessage, decrement bool) (models.StarboardMessage, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertStarboardCount(msg, decrement)
+	ObserveStorage("UpsertStarboardCount", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) MarkStarboardPosted(msg models.StarboardMessage) (bool, error) {
+	start := time.Now()
+	marked, err := s.storage.MarkStarboardPosted(msg)
+	ObserveStorage("MarkStarboardPosted", start, err)
+
+	return marked, err
+}
+
+func (s instrumentedStorage) GetLeaderboard(opts storage.GetLeaderboardOptions) ([]models.LeaderboardEntry, error) {
+	start := time.Now()
+	entries, err := s.storage.GetLeaderboard(opts)
+	ObserveStorage("GetLeaderboard", start, err)
+
+	return entries, err
+}
+
+func (s instrumentedStorage) UpsertURLCount(collection string, urlCount models.URLCount) (models.URLCount, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertURLCount(collection, urlCount)
+	ObserveStorage("UpsertURLCount", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) GetURLCounts(collection string, opts storage.GetURLCountOptions) ([]models.URLCount, error) {
+	start := time.Now()
+	counts, err := s.storage.GetURLCounts(collection, opts)
+	ObserveStorage("GetURLCounts", start, err)
+
+	return counts, err
+}
+
+func (s instrumentedStorage) AddLinkShare(share models.LinkShare) error {
+	start := time.Now()
+	err := s.storage.AddLinkShare(share)
+	ObserveStorage("AddLinkShare", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetLinkShares(opts storage.GetLinkShareOptions) ([]models.LinkShare, error) {
+	start := time.Now()
+	shares, err := s.storage.GetLinkShares(opts)
+	ObserveStorage("GetLinkShares", start, err)
+
+	return shares, err
+}
+
+func (s instrumentedStorage) GetThemes(opts storage.GetThemeOptions) ([]models.Theme, error) {
+	start := time.Now()
+	themes, err := s.storage.GetThemes(opts)
+	ObserveStorage("GetThemes", start, err)
+
+	return themes, err
+}
+
+func (s instrumentedStorage) AddTheme(theme models.Theme) error {
+	start := time.Now()
+	err := s.storage.AddTheme(theme)
+	ObserveStorage("AddTheme", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetScheduledRuns() ([]models.ScheduledRun, error) {
+	start := time.Now()
+	runs, err := s.storage.GetScheduledRuns()
+	ObserveStorage("GetScheduledRuns", start, err)
+
+	return runs, err
+}
+
+func (s instrumentedStorage) UpsertScheduledRun(run models.ScheduledRun) error {
+	start := time.Now()
+	err := s.storage.UpsertScheduledRun(run)
+	ObserveStorage("UpsertScheduledRun", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetKarma(opts storage.GetKarmaOptions) ([]models.Karma, error) {
+	start := time.Now()
+	karma, err := s.storage.GetKarma(opts)
+	ObserveStorage("GetKarma", start, err)
+
+	return karma, err
+}
+
+func (s instrumentedStorage) UpsertKarma(karma models.Karma, decrement bool) (models.Karma, error) {
+	start := time.Now()
+	updated, err := s.storage.UpsertKarma(karma, decrement)
+	ObserveStorage("UpsertKarma", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) GetQuotes(opts storage.GetQuoteOptions) ([]models.Quote, error) {
+	start := time.Now()
+	quotes, err := s.storage.GetQuotes(opts)
+	ObserveStorage("GetQuotes", start, err)
+
+	return quotes, err
+}
+
+func (s instrumentedStorage) AddQuote(quote models.Quote) (bool, error) {
+	start := time.Now()
+	added, err := s.storage.AddQuote(quote)
+	ObserveStorage("AddQuote", start, err)
+
+	return added, err
+}
+
+func (s instrumentedStorage) GetReminders(opts storage.GetReminderOptions) ([]models.Reminder, error) {
+	start := time.Now()
+	reminders, err := s.storage.GetReminders(opts)
+	ObserveStorage("GetReminders", start, err)
+
+	return reminders, err
+}
+
+func (s instrumentedStorage) GetDueReminders(now time.Time) ([]models.Reminder, error) {
+	start := time.Now()
+	reminders, err := s.storage.GetDueReminders(now)
+	ObserveStorage("GetDueReminders", start, err)
+
+	return reminders, err
+}
+
+func (s instrumentedStorage) AddReminder(reminder models.Reminder) error {
+	start := time.Now()
+	err := s.storage.AddReminder(reminder)
+	ObserveStorage("AddReminder", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) CancelReminder(id string) (bool, error) {
+	start := time.Now()
+	cancelled, err := s.storage.CancelReminder(id)
+	ObserveStorage("CancelReminder", start, err)
+
+	return cancelled, err
+}
+
+func (s instrumentedStorage) GetPolls(opts storage.GetPollOptions) ([]models.Poll, error) {
+	start := time.Now()
+	polls, err := s.storage.GetPolls(opts)
+	ObserveStorage("GetPolls", start, err)
+
+	return polls, err
+}
+
+func (s instrumentedStorage) GetDuePolls(now time.Time) ([]models.Poll, error) {
+	start := time.Now()
+	polls, err := s.storage.GetDuePolls(now)
+	ObserveStorage("GetDuePolls", start, err)
+
+	return polls, err
+}
+
+func (s instrumentedStorage) AddPoll(poll models.Poll) error {
+	start := time.Now()
+	err := s.storage.AddPoll(poll)
+	ObserveStorage("AddPoll", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) UpdatePollVote(
+	channel, timestamp string, vote models.PollVote, removed bool) (bool, error) {
+	start := time.Now()
+	updated, err := s.storage.UpdatePollVote(channel, timestamp, vote, removed)
+	ObserveStorage("UpdatePollVote", start, err)
+
+	return updated, err
+}
+
+func (s instrumentedStorage) ClosePoll(channel, timestamp string) (bool, error) {
+	start := time.Now()
+	closed, err := s.storage.ClosePoll(channel, timestamp)
+	ObserveStorage("ClosePoll", start, err)
+
+	return closed, err
+}
+
+func (s instrumentedStorage) AddMarkovTransitions(transitions []models.MarkovTransition) error {
+	start := time.Now()
+	err := s.storage.AddMarkovTransitions(transitions)
+	ObserveStorage("AddMarkovTransitions", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetMarkovTransitions(model string, prefix []string) ([]models.MarkovTransition, error) {
+	start := time.Now()
+	transitions, err := s.storage.GetMarkovTransitions(model, prefix)
+	ObserveStorage("GetMarkovTransitions", start, err)
+
+	return transitions, err
+}
+
+func (s instrumentedStorage) DeleteMarkovModel(model string) error {
+	start := time.Now()
+	err := s.storage.DeleteMarkovModel(model)
+	ObserveStorage("DeleteMarkovModel", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetMarkovOptOut(user string) (bool, error) {
+	start := time.Now()
+	optOut, err := s.storage.GetMarkovOptOut(user)
+	ObserveStorage("GetMarkovOptOut", start, err)
+
+	return optOut, err
+}
+
+func (s instrumentedStorage) SetMarkovOptOut(user string, optOut bool) error {
+	start := time.Now()
+	err := s.storage.SetMarkovOptOut(user, optOut)
+	ObserveStorage("SetMarkovOptOut", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) GetReactjiRules() ([]models.ReactjiRule, error) {
+	start := time.Now()
+	rules, err := s.storage.GetReactjiRules()
+	ObserveStorage("GetReactjiRules", start, err)
+
+	return rules, err
+}
+
+func (s instrumentedStorage) AddReactjiRule(rule models.ReactjiRule) error {
+	start := time.Now()
+	err := s.storage.AddReactjiRule(rule)
+	ObserveStorage("AddReactjiRule", start, err)
+
+	return err
+}
+
+func (s instrumentedStorage) RemoveReactjiRule(id string) (bool, error) {
+	start := time.Now()
+	removed, err := s.storage.RemoveReactjiRule(id)
+	ObserveStorage("RemoveReactjiRule", start, err)
+
+	return removed, err
+}
+
+func (s instrumentedStorage) GetURLRules() ([]models.URLRule, error) {
+	start := time.Now()
+	rules, err := s.storage.GetURLRules()
+	ObserveStorage("GetURLRules", start, err)
+
+	return rules, err
+}
+
+func (s instrumentedStorage) AddURLRule(rule models.URLRule) (bool, error) {
+	start := time.Now()
+	added, err := s.storage.AddURLRule(rule)
+	ObserveStorage("AddURLRule", start, err)
+
+	return added, err
+}
+
+func (s instrumentedStorage) RemoveURLRule(collection string) (bool, error) {
+	start := time.Now()
+	removed, err := s.storage.RemoveURLRule(collection)
+	ObserveStorage("RemoveURLRule", start, err)
+
+	return removed, err
+}
+
+func (s instrumentedStorage) Ping() error {
+	start := time.Now()
+	err := s.storage.Ping()
+	ObserveStorage("Ping", start, err)
+
+	return err
+}
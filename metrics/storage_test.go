@@ -0,0 +1,49 @@
+//nolint:goerr113
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/mocks"
+	"github.com/cpu/gorfbot/storage/models"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	s := InstrumentStorage(mockStorage)
+
+	emoji := models.Emoji{User: "U001", Emoji: ":wave:"}
+	opts := storage.GetEmojiOptions{User: "U001"}
+
+	mockStorage.EXPECT().GetEmoji(opts).Return([]models.Emoji{emoji}, nil)
+	mockStorage.EXPECT().UpsertEmojiCount(emoji, true).Return(models.Emoji{}, errors.New("oops"))
+
+	getErrs := StorageErrors.WithLabelValues("GetEmoji")
+	upsertErrs := StorageErrors.WithLabelValues("UpsertEmojiCount")
+
+	// Results and errors are passed through from the wrapped storage.
+	if results, err := s.GetEmoji(opts); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	} else if len(results) != 1 || results[0] != emoji {
+		t.Errorf("expected results [%v] got %v", emoji, results)
+	}
+
+	if _, err := s.UpsertEmojiCount(emoji, true); err == nil {
+		t.Errorf("expected err from UpsertEmojiCount, got nil")
+	}
+
+	if count := testutil.ToFloat64(getErrs); count != 0 {
+		t.Errorf("expected 0 GetEmoji errors, got %v", count)
+	}
+
+	if count := testutil.ToFloat64(upsertErrs); count != 1 {
+		t.Errorf("expected 1 UpsertEmojiCount error, got %v", count)
+	}
+}
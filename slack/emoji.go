@@ -0,0 +1,74 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kyokomi/emoji/v2"
+)
+
+const (
+	// aliasPrefix prefixes the value of custom emoji that are aliases of other
+	// emoji in the emoji.list response.
+	aliasPrefix = "alias:"
+	// maxAliasDepth limits how many custom emoji aliases are followed so that
+	// alias loops can't hang the client.
+	maxAliasDepth = 10
+)
+
+var (
+	// skinToneSuffixRegexp matches a skin tone modifier suffix. Slack appends
+	// these to reaction names (e.g. "thumbsup::skin-tone-2") and to emoji in
+	// message text (e.g. ":thumbsup::skin-tone-2:").
+	skinToneSuffixRegexp = regexp.MustCompile(`::skin-tone-[2-6]:?$`)
+	// skinToneRegexp matches a skin tone modifier on its own.
+	skinToneRegexp = regexp.MustCompile(`^skin-tone-[2-6]$`)
+)
+
+// standardEmoji returns the canonical name of the standard (unicode) emoji with
+// the given name (no ":" delimiters). Where several names are aliases for the
+// same emoji (e.g. "thumbsup" and "+1") the same canonical name is returned for
+// each.
+func standardEmoji(name string) (string, bool) {
+	shortCode := ":" + name + ":"
+	if _, found := emoji.CodeMap()[shortCode]; !found {
+		return "", false
+	}
+
+	return strings.Trim(emoji.NormalizeShortCode(shortCode), ":"), true
+}
+
+func (c *clientImpl) CanonicalEmoji(name string) (string, bool) {
+	name = strings.Trim(skinToneSuffixRegexp.ReplaceAllString(name, ""), ":")
+
+	// A skin tone modifier on its own is part of the previous emoji in a
+	// message, not an emoji itself.
+	if skinToneRegexp.MatchString(name) {
+		return name, false
+	}
+
+	for i := 0; i < maxAliasDepth; i++ {
+		if canonical, found := standardEmoji(name); found {
+			return canonical, true
+		}
+
+		value, found := c.state.CustomEmoji(name)
+		if !found {
+			return name, false
+		}
+
+		if !strings.HasPrefix(value, aliasPrefix) {
+			return name, true
+		}
+
+		name = strings.TrimPrefix(value, aliasPrefix)
+	}
+
+	c.log.Warnf("Custom emoji alias chain for %q is too long", name)
+
+	return name, false
+}
+
+func (c *clientImpl) CustomEmoji() map[string]string {
+	return c.state.CustomEmojiList()
+}
@@ -0,0 +1,50 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/cpu/gorfbot/config"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestCanonicalEmoji(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	state := newSlackStateImpl(log, config.SlackConfig{})
+	state.customEmoji = map[string]string{
+		"gorf":        "https://emoji.example.com/gorf.png",
+		"gorfing":     "alias:gorf",
+		"gorfgorfing": "alias:gorfing",
+		"thumbs":      "alias:thumbsup",
+		"gorfloop":    "alias:gorfloop",
+	}
+
+	client := &clientImpl{log: log, state: state}
+
+	testCases := []struct {
+		name          string
+		expected      string
+		expectedKnown bool
+	}{
+		{name: "frog", expected: "frog", expectedKnown: true},
+		{name: ":frog:", expected: "frog", expectedKnown: true},
+		{name: ":thumbsup:", expected: "+1", expectedKnown: true},
+		{name: "+1", expected: "+1", expectedKnown: true},
+		{name: "thumbsup::skin-tone-2", expected: "+1", expectedKnown: true},
+		{name: ":wave::skin-tone-6:", expected: "wave", expectedKnown: true},
+		{name: ":skin-tone-3:", expected: "skin-tone-3", expectedKnown: false},
+		{name: ":gorf:", expected: "gorf", expectedKnown: true},
+		{name: "gorfing", expected: "gorf", expectedKnown: true},
+		{name: "gorfgorfing", expected: "gorf", expectedKnown: true},
+		{name: "thumbs", expected: "+1", expectedKnown: true},
+		{name: ":notanemoji:", expected: "notanemoji", expectedKnown: false},
+		{name: "gorfloop", expected: "gorfloop", expectedKnown: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if canonical, known := client.CanonicalEmoji(tc.name); canonical != tc.expected || known != tc.expectedKnown {
+				t.Errorf("expected %q, %v got %q, %v", tc.expected, tc.expectedKnown, canonical, known)
+			}
+		})
+	}
+}
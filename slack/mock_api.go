@@ -49,6 +49,21 @@ func (mr *MockSlackAPIMockRecorder) GetConversations(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversations", reflect.TypeOf((*MockSlackAPI)(nil).GetConversations), arg0)
 }
 
+// GetEmoji mocks base method
+func (m *MockSlackAPI) GetEmoji() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmoji")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmoji indicates an expected call of GetEmoji
+func (mr *MockSlackAPIMockRecorder) GetEmoji() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmoji", reflect.TypeOf((*MockSlackAPI)(nil).GetEmoji))
+}
+
 // GetUsers mocks base method
 func (m *MockSlackAPI) GetUsers() ([]slack.User, error) {
 	m.ctrl.T.Helper()
@@ -76,6 +76,47 @@ func (mr *MockClientMockRecorder) BotName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BotName", reflect.TypeOf((*MockClient)(nil).BotName))
 }
 
+// CanonicalEmoji mocks base method
+func (m *MockClient) CanonicalEmoji(arg0 string) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanonicalEmoji", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// CanonicalEmoji indicates an expected call of CanonicalEmoji
+func (mr *MockClientMockRecorder) CanonicalEmoji(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanonicalEmoji", reflect.TypeOf((*MockClient)(nil).CanonicalEmoji), arg0)
+}
+
+// Close mocks base method
+func (m *MockClient) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close
+func (mr *MockClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
+}
+
+// Connected mocks base method
+func (m *MockClient) Connected() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connected")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Connected indicates an expected call of Connected
+func (mr *MockClientMockRecorder) Connected() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connected", reflect.TypeOf((*MockClient)(nil).Connected))
+}
+
 // ConversationID mocks base method
 func (m *MockClient) ConversationID(arg0 string) string {
 	m.ctrl.T.Helper()
@@ -104,16 +145,74 @@ func (mr *MockClientMockRecorder) ConversationName(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConversationName", reflect.TypeOf((*MockClient)(nil).ConversationName), arg0)
 }
 
+// ConversationPublic mocks base method
+func (m *MockClient) ConversationPublic(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConversationPublic", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ConversationPublic indicates an expected call of ConversationPublic
+func (mr *MockClientMockRecorder) ConversationPublic(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConversationPublic", reflect.TypeOf((*MockClient)(nil).ConversationPublic), arg0)
+}
+
+// CustomEmoji mocks base method
+func (m *MockClient) CustomEmoji() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CustomEmoji")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// CustomEmoji indicates an expected call of CustomEmoji
+func (mr *MockClientMockRecorder) CustomEmoji() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomEmoji", reflect.TypeOf((*MockClient)(nil).CustomEmoji))
+}
+
+// GetMessage mocks base method
+func (m *MockClient) GetMessage(arg0, arg1 string) (*slack.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessage", arg0, arg1)
+	ret0, _ := ret[0].(*slack.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessage indicates an expected call of GetMessage
+func (mr *MockClientMockRecorder) GetMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockClient)(nil).GetMessage), arg0, arg1)
+}
+
+// GetPermalink mocks base method
+func (m *MockClient) GetPermalink(arg0, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermalink", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermalink indicates an expected call of GetPermalink
+func (mr *MockClientMockRecorder) GetPermalink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermalink", reflect.TypeOf((*MockClient)(nil).GetPermalink), arg0, arg1)
+}
+
 // Listen mocks base method
-func (m *MockClient) Listen(arg0 chan<- *slack.Message, arg1 chan<- *slack.Reaction) {
+func (m *MockClient) Listen(arg0 chan<- *slack.Message, arg1 chan<- *slack.Reaction, arg2 chan<- *slack.EmojiChange) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Listen", arg0, arg1)
+	m.ctrl.Call(m, "Listen", arg0, arg1, arg2)
 }
 
 // Listen indicates an expected call of Listen
-func (mr *MockClientMockRecorder) Listen(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) Listen(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockClient)(nil).Listen), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockClient)(nil).Listen), arg0, arg1, arg2)
 }
 
 // ParseTimestamp mocks base method
@@ -131,6 +230,21 @@ func (mr *MockClientMockRecorder) ParseTimestamp(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseTimestamp", reflect.TypeOf((*MockClient)(nil).ParseTimestamp), arg0)
 }
 
+// PostMessage mocks base method
+func (m *MockClient) PostMessage(arg0, arg1 string) (*slack.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostMessage", arg0, arg1)
+	ret0, _ := ret[0].(*slack.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostMessage indicates an expected call of PostMessage
+func (mr *MockClientMockRecorder) PostMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockClient)(nil).PostMessage), arg0, arg1)
+}
+
 // SendMessage mocks base method
 func (m *MockClient) SendMessage(arg0, arg1 string) {
 	m.ctrl.T.Helper()
@@ -143,6 +257,20 @@ func (mr *MockClientMockRecorder) SendMessage(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockClient)(nil).SendMessage), arg0, arg1)
 }
 
+// StatePopulated mocks base method
+func (m *MockClient) StatePopulated() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatePopulated")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// StatePopulated indicates an expected call of StatePopulated
+func (mr *MockClientMockRecorder) StatePopulated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatePopulated", reflect.TypeOf((*MockClient)(nil).StatePopulated))
+}
+
 // TeamID mocks base method
 func (m *MockClient) TeamID() string {
 	m.ctrl.T.Helper()
@@ -171,6 +299,20 @@ func (mr *MockClientMockRecorder) TeamName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TeamName", reflect.TypeOf((*MockClient)(nil).TeamName))
 }
 
+// UploadFile mocks base method
+func (m *MockClient) UploadFile(arg0 slack.File, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadFile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadFile indicates an expected call of UploadFile
+func (mr *MockClientMockRecorder) UploadFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockClient)(nil).UploadFile), arg0, arg1)
+}
+
 // UserID mocks base method
 func (m *MockClient) UserID(arg0 string) string {
 	m.ctrl.T.Helper()
@@ -185,6 +327,20 @@ func (mr *MockClientMockRecorder) UserID(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserID", reflect.TypeOf((*MockClient)(nil).UserID), arg0)
 }
 
+// UserLocation mocks base method
+func (m *MockClient) UserLocation(arg0 string) *time.Location {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserLocation", arg0)
+	ret0, _ := ret[0].(*time.Location)
+	return ret0
+}
+
+// UserLocation indicates an expected call of UserLocation
+func (mr *MockClientMockRecorder) UserLocation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserLocation", reflect.TypeOf((*MockClient)(nil).UserLocation), arg0)
+}
+
 // UserName mocks base method
 func (m *MockClient) UserName(arg0 string) string {
 	m.ctrl.T.Helper()
@@ -1,11 +1,15 @@
 package slack
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/metrics"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
@@ -15,15 +19,30 @@ import (
 // Slack.
 //go:generate mockgen -destination=mocks/mock_client.go -package=mocks . Client
 type Client interface {
-	// Listen starts the client running forever and is intended to be called from
-	// a dedicated goroutine - it will not return. Real time events for messages
-	// and reactions are dispatched to the provided channels.
-	Listen(msgChan chan<- *Message, reactionChan chan<- *Reaction)
+	// Listen starts the client running until it is closed and is intended to be
+	// called from a dedicated goroutine. Real time events for messages,
+	// reactions and custom emoji changes are dispatched to the provided channels.
+	Listen(msgChan chan<- *Message, reactionChan chan<- *Reaction, emojiChan chan<- *EmojiChange)
+	// Close disconnects from Slack and stops Listen and the state updates. The
+	// client can't be used after it is closed and must only be closed once.
+	Close()
 	// SendMessage sends the provided text to the provided slack channel ID.
 	SendMessage(text, channelID string)
+	// PostMessage sends the provided text to the provided slack channel ID like
+	// SendMessage, but waits for it to be sent and returns the sent message
+	// (e.g. so that it can be reacted to).
+	PostMessage(text, channelID string) (*Message, error)
 	// AddReaction adds the provided reaction (no ":" delimiters) to the given
 	// message.
 	AddReaction(reaction string, message *Message) error
+	// UploadFile uploads the provided file to the provided slack channel ID.
+	UploadFile(file File, channelID string) error
+	// GetMessage fetches the message with the given timestamp from the given
+	// channel ID.
+	GetMessage(channelID, timestamp string) (*Message, error)
+	// GetPermalink returns a permanent URL for the message with the given
+	// timestamp in the given channel ID.
+	GetPermalink(channelID, timestamp string) (string, error)
 	// ParseTimestamp parses a Slack-style timestamp and returns a time.Time
 	// instance.
 	ParseTimestamp(ts string) (time.Time, error)
@@ -40,11 +59,33 @@ type Client interface {
 	// ConversationID is the reverse of ConversationName and returns the ID for
 	// a friendly channel/conversation name.
 	ConversationID(name string) string
+	// ConversationPublic returns true if the conversation with the given ID is a
+	// public channel. Private channels, group DMs, DMs and unknown
+	// conversations aren't public.
+	ConversationPublic(id string) bool
+	// CanonicalEmoji returns the canonical name (no ":" delimiters) of the
+	// standard or workspace custom emoji with the given name (with or without
+	// ":" delimiters). Skin tone modifiers are removed and aliases (e.g.
+	// "thumbsup" for "+1") are resolved. It returns false if the name isn't a
+	// known emoji, along with the name stripped of delimiters and modifiers.
+	CanonicalEmoji(name string) (string, bool)
+	// CustomEmoji returns the workspace's custom emoji, mapping each name (no
+	// ":" delimiters) to its image URL or "alias:<name>" for aliases.
+	CustomEmoji() map[string]string
 	// UserName returns the friendly user name for the given slack user ID.
 	UserName(id string) string
 	// UserID is the reverse of Username and returns the ID for a friendly user
 	// name.
 	UserID(username string) string
+	// UserLocation returns the time zone from the profile of the user with the
+	// given slack user ID, or UTC if the user or their time zone is unknown.
+	UserLocation(id string) *time.Location
+	// Connected returns true if the client has received a ConnectedEvent and
+	// hasn't since been disconnected.
+	Connected() bool
+	// StatePopulated returns true if the client's cache of users and
+	// conversations has been populated at least once.
+	StatePopulated() bool
 }
 
 // SlackAPI is an interface that abstracts away the slack API from this package's
@@ -57,6 +98,7 @@ type Client interface {
 type SlackAPI interface { //nolint:golint
 	GetConversations(opts *slack.GetConversationsParameters) ([]slack.Channel, string, error)
 	GetUsers() ([]slack.User, error)
+	GetEmoji() (map[string]string, error)
 }
 
 // Message is a structure describing a slack message from a user in a channel.
@@ -77,6 +119,21 @@ func (m Message) String() string {
 		m.Timestamp, m.ChannelID, m.UserID, m.Text)
 }
 
+// File is a structure describing a file to upload to slack.
+type File struct {
+	// Name is the file name (e.g. "chart.png").
+	Name string
+	// Title is the title slack displays for the file. Optional.
+	Title string
+	// Content is the raw file content.
+	Content []byte
+}
+
+// String is a simple debugging representation of a file.
+func (f File) String() string {
+	return fmt.Sprintf("file %q titled %q (%d bytes)", f.Name, f.Title, len(f.Content))
+}
+
 // Conversation is a structure describing a channel/conversation. It has both an ID
 // and a friendly name. Note: typically a Conversation is a channel but it may also
 // be a DM exchange!
@@ -85,6 +142,9 @@ type Conversation struct {
 	ID string
 	// Conversation name (no "#" prefix for channel names).
 	Name string
+	// Private is true for conversations that aren't visible to everyone in the
+	// workspace: private channels, group DMs and DMs.
+	Private bool
 }
 
 // User is a structure describing a slack user. It has both an ID and a friendly
@@ -94,6 +154,28 @@ type User struct {
 	ID string
 	// User's friendly name (no leading "@" prefix).
 	Name string
+	// TZ is the IANA name of the user's time zone from their profile (e.g.
+	// "America/Toronto"). It may be empty.
+	TZ string
+	// TZOffset is the user's offset from UTC in seconds.
+	TZOffset int
+}
+
+// Location returns the user's time zone. If the TZ can't be loaded (e.g. there
+// is no time zone database) a fixed zone with the user's TZOffset is returned
+// instead.
+func (u User) Location() *time.Location {
+	if u.TZ != "" {
+		if loc, err := time.LoadLocation(u.TZ); err == nil {
+			return loc
+		}
+	}
+
+	if u.TZOffset != 0 {
+		return time.FixedZone(u.TZ, u.TZOffset)
+	}
+
+	return time.UTC
 }
 
 // Reaction is a structure describing a reaction event.
@@ -106,16 +188,56 @@ type Reaction struct {
 	Timestamp string
 	// Whether the reactji was removed or added.
 	Removed bool
+	// The user ID of the author of the item that was reacted to. May be empty if
+	// the item has no author (e.g. some bot messages).
+	ItemUser string
+	// The ID of the channel containing the item that was reacted to.
+	ItemChannel string
+	// The slack timestamp of the item that was reacted to.
+	ItemTimestamp string
+}
+
+// EmojiChange is a structure describing workspace custom emoji being added or
+// removed.
+type EmojiChange struct {
+	// Names of the custom emoji (no ":" delimiters). An added emoji has one
+	// name, removing an emoji also removes its aliases.
+	Names []string
+	// Value is the image URL of an added emoji, or "alias:<name>" for an added
+	// alias. Empty for removed emoji.
+	Value string
+	// Whether the emoji were removed or added.
+	Removed bool
+	// The slack timestamp that the emoji change event occurred.
+	Timestamp string
+}
+
+// Alias returns the name of the emoji an added alias refers to, or "" if the
+// change isn't an added alias.
+func (e EmojiChange) Alias() string {
+	if e.Removed || !strings.HasPrefix(e.Value, aliasPrefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(e.Value, aliasPrefix)
 }
 
 // clientImpl is the implementation of the Client interface.
 type clientImpl struct {
-	log            *logrus.Logger
-	config         config.SlackConfig
-	rtm            *slack.RTM
+	log    *logrus.Logger
+	config config.SlackConfig
+	rtm    *slack.RTM
+	state  slackState
+
+	// mu protects the connection state and bot user and team details, which are
+	// updated by Listen on each ConnectedEvent.
+	mu             sync.RWMutex
+	connected      bool
 	botUserDetails *slack.UserDetails
 	botTeamDetails *slack.Team
-	state          slackState
+
+	// done is closed by Close to stop Listen and updateState.
+	done chan struct{}
 }
 
 // clientLogger is a simple adapter for the slack logger interface that dumps
@@ -150,10 +272,15 @@ func New(log *logrus.Logger, c *config.Config) (Client, error) {
 
 	// Create a slack client instance with the Slack client library. Use a
 	// clientLogger to adapt the slack logs to logrus.
-	client := slack.New(
-		c.SlackConf.APIToken,
+	opts := []slack.Option{
 		slack.OptionDebug(c.SlackConf.Debug),
-		slack.OptionLog(clientLogger{log: log}))
+		slack.OptionLog(clientLogger{log: log}),
+	}
+	if c.SlackConf.APIURL != "" {
+		opts = append(opts, slack.OptionAPIURL(c.SlackConf.APIURL))
+	}
+
+	client := slack.New(c.SlackConf.APIToken, opts...)
 
 	// Start processing real time message events. Let the Slack client library manage
 	// the connection in its own goroutine.
@@ -165,6 +292,7 @@ func New(log *logrus.Logger, c *config.Config) (Client, error) {
 		config: c.SlackConf,
 		rtm:    rtm,
 		state:  newSlackStateImpl(log, c.SlackConf),
+		done:   make(chan struct{}),
 	}
 	// Keep the slack state cache updated in a dedicated goroutine.
 	go clientImpl.updateState()
@@ -172,24 +300,43 @@ func New(log *logrus.Logger, c *config.Config) (Client, error) {
 	return clientImpl, nil
 }
 
-// updateState will update the slack state when required, sleeping for the
-// configured max age minus one second.
-func (c clientImpl) updateState() {
+// updateState will update the slack state, sleeping for the configured max age
+// minus one second in between, until the client is closed. The refresh is
+// forced because the state isn't stale yet when the goroutine wakes up a second
+// early.
+func (c *clientImpl) updateState() {
 	for {
 		c.log.Info("updateState goroutine waking up to try state refresh")
 
-		if err := c.state.Refresh(c.rtm, false); err != nil {
+		if err := c.state.Refresh(c.rtm, true); err != nil {
 			c.log.Errorf("error updating slack client state: %v", err)
 		}
 
 		c.log.Infof("updateState goroutine sleeping for %s", c.state.MaxAge())
-		time.Sleep(c.state.MaxAge() - time.Second)
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.state.MaxAge() - time.Second):
+		}
 	}
 }
 
+// Close disconnects the RTM instance and stops Listen and updateState.
+func (c *clientImpl) Close() {
+	close(c.done)
+
+	// The only error is that the RTM instance was already disconnected.
+	_ = c.rtm.Disconnect()
+}
+
 // String is a simple debugging representation of the client's connection state.
-func (c clientImpl) String() string {
-	if c.botUserDetails == nil || c.botTeamDetails == nil {
+func (c *clientImpl) String() string {
+	c.mu.RLock()
+	connected := c.botUserDetails != nil && c.botTeamDetails != nil
+	c.mu.RUnlock()
+
+	if !connected {
 		return "Client waiting for ConnectedEvent"
 	}
 
@@ -198,9 +345,18 @@ func (c clientImpl) String() string {
 }
 
 // Listen begins processing Slack RTM incoming events and dispatches them as types
-// from this package.
-func (c *clientImpl) Listen(msgChan chan<- *Message, reactionChan chan<- *Reaction) {
-	for msg := range c.rtm.IncomingEvents {
+// from this package until the client is closed.
+//nolint:funlen
+func (c *clientImpl) Listen(msgChan chan<- *Message, reactionChan chan<- *Reaction, emojiChan chan<- *EmojiChange) {
+	for {
+		var msg slack.RTMEvent
+
+		select {
+		case <-c.done:
+			return
+		case msg = <-c.rtm.IncomingEvents:
+		}
+
 		switch ev := msg.Data.(type) {
 		case *slack.ConnectedEvent:
 			c.log.Infof("Connected to Slack (%v)", ev.ConnectionCount)
@@ -211,10 +367,20 @@ func (c *clientImpl) Listen(msgChan chan<- *Message, reactionChan chan<- *Reacti
 				continue
 			}
 
+			c.mu.Lock()
+			c.connected = true
 			c.botUserDetails = ev.Info.User
 			c.botTeamDetails = ev.Info.Team
+			c.mu.Unlock()
 			c.log.Info(c)
 
+		case *slack.DisconnectedEvent:
+			c.log.Warnf("Disconnected from Slack (intentional: %v): %v", ev.Intentional, ev.Cause)
+
+			c.mu.Lock()
+			c.connected = false
+			c.mu.Unlock()
+
 		case *slack.MessageEvent:
 			msgChan <- &Message{
 				Timestamp: ev.Msg.Timestamp,
@@ -225,18 +391,48 @@ func (c *clientImpl) Listen(msgChan chan<- *Message, reactionChan chan<- *Reacti
 
 		case *slack.ReactionAddedEvent:
 			reactionChan <- &Reaction{
-				Timestamp: ev.EventTimestamp,
-				User:      ev.User,
-				Reaction:  ev.Reaction,
+				Timestamp:     ev.EventTimestamp,
+				User:          ev.User,
+				Reaction:      ev.Reaction,
+				ItemUser:      ev.ItemUser,
+				ItemChannel:   ev.Item.Channel,
+				ItemTimestamp: ev.Item.Timestamp,
 			}
 
 		case *slack.ReactionRemovedEvent:
 			reactionChan <- &Reaction{
+				Timestamp:     ev.EventTimestamp,
+				User:          ev.User,
+				Reaction:      ev.Reaction,
+				Removed:       true,
+				ItemUser:      ev.ItemUser,
+				ItemChannel:   ev.Item.Channel,
+				ItemTimestamp: ev.Item.Timestamp,
+			}
+
+		case *slack.EmojiChangedEvent:
+			change := &EmojiChange{
+				Names:     ev.Names,
+				Value:     ev.Value,
+				Removed:   ev.SubType == "remove",
 				Timestamp: ev.EventTimestamp,
-				User:      ev.User,
-				Reaction:  ev.Reaction,
-				Removed:   true,
 			}
+			if !change.Removed {
+				change.Names = []string{ev.Name}
+			}
+
+			// Keep the cached custom emoji up to date so the change is known
+			// before the next state refresh.
+			c.state.UpdateCustomEmoji(*change)
+			emojiChan <- change
+
+		case *slack.OutgoingErrorEvent:
+			metrics.SlackSendFailures.Inc()
+			c.log.Errorf("Slack failed to send message: %v", ev)
+
+		case *slack.AckErrorEvent:
+			metrics.SlackSendFailures.Inc()
+			c.log.Errorf("Slack failed to acknowledge message: %v", ev)
 
 		case *slack.LatencyReport:
 			c.log.Tracef("Current latency: %v\n", ev.Value)
@@ -251,13 +447,29 @@ func (c *clientImpl) Listen(msgChan chan<- *Message, reactionChan chan<- *Reacti
 	}
 }
 
-func (c clientImpl) SendMessage(text, channelID string) {
+func (c *clientImpl) SendMessage(text, channelID string) {
 	c.rtm.SendMessage(c.rtm.NewOutgoingMessage(text, channelID))
 }
 
+func (c *clientImpl) PostMessage(text, channelID string) (*Message, error) {
+	_, ts, err := c.rtm.PostMessage(channelID, slack.MsgOptionText(text, false))
+	if err != nil {
+		metrics.SlackSendFailures.Inc()
+
+		return nil, fmt.Errorf("post message err: %w", err)
+	}
+
+	return &Message{
+		ChannelID: channelID,
+		UserID:    c.BotID(),
+		Text:      text,
+		Timestamp: ts,
+	}, nil
+}
+
 var errNilMessage = errors.New("add reaction failed: message is nil")
 
-func (c clientImpl) AddReaction(reaction string, message *Message) error {
+func (c *clientImpl) AddReaction(reaction string, message *Message) error {
 	if message == nil {
 		return errNilMessage
 	}
@@ -267,10 +479,83 @@ func (c clientImpl) AddReaction(reaction string, message *Message) error {
 		Timestamp: message.Timestamp,
 	}
 
-	return c.rtm.AddReaction(reaction, item)
+	if err := c.rtm.AddReaction(reaction, item); err != nil {
+		metrics.SlackReactionFailures.Inc()
+
+		return err
+	}
+
+	return nil
 }
 
-func (c clientImpl) BotName() string {
+func (c *clientImpl) UploadFile(file File, channelID string) error {
+	_, err := c.rtm.UploadFile(slack.FileUploadParameters{
+		Reader:   bytes.NewReader(file.Content),
+		Filename: file.Name,
+		Title:    file.Title,
+		Channels: []string{channelID},
+	})
+	if err != nil {
+		metrics.SlackUploadFailures.Inc()
+
+		return fmt.Errorf("upload file err: %w", err)
+	}
+
+	return nil
+}
+
+type errMessageNotFound struct {
+	channelID string
+	timestamp string
+}
+
+func (e errMessageNotFound) Error() string {
+	return fmt.Sprintf("message %q not found in channel %q", e.timestamp, e.channelID)
+}
+
+func (c *clientImpl) GetMessage(channelID, timestamp string) (*Message, error) {
+	// The message is the newest one in the channel history up to and including
+	// its timestamp.
+	resp, err := c.rtm.GetConversationHistory(&slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    timestamp,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get message err: %w", err)
+	}
+
+	if len(resp.Messages) == 0 || resp.Messages[0].Timestamp != timestamp {
+		return nil, errMessageNotFound{channelID: channelID, timestamp: timestamp}
+	}
+
+	msg := resp.Messages[0]
+
+	return &Message{
+		ChannelID: channelID,
+		UserID:    msg.User,
+		Text:      msg.Text,
+		Timestamp: msg.Timestamp,
+	}, nil
+}
+
+func (c *clientImpl) GetPermalink(channelID, timestamp string) (string, error) {
+	permalink, err := c.rtm.GetPermalink(&slack.PermalinkParameters{
+		Channel: channelID,
+		Ts:      timestamp,
+	})
+	if err != nil {
+		return "", fmt.Errorf("get permalink err: %w", err)
+	}
+
+	return permalink, nil
+}
+
+func (c *clientImpl) BotName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.botUserDetails != nil {
 		return c.botUserDetails.Name
 	}
@@ -278,7 +563,10 @@ func (c clientImpl) BotName() string {
 	return ""
 }
 
-func (c clientImpl) BotID() string {
+func (c *clientImpl) BotID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.botUserDetails != nil {
 		return c.botUserDetails.ID
 	}
@@ -286,7 +574,10 @@ func (c clientImpl) BotID() string {
 	return ""
 }
 
-func (c clientImpl) TeamName() string {
+func (c *clientImpl) TeamName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.botTeamDetails != nil {
 		return c.botTeamDetails.Name
 	}
@@ -294,7 +585,10 @@ func (c clientImpl) TeamName() string {
 	return ""
 }
 
-func (c clientImpl) TeamID() string {
+func (c *clientImpl) TeamID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.botTeamDetails != nil {
 		return c.botTeamDetails.ID
 	}
@@ -302,7 +596,7 @@ func (c clientImpl) TeamID() string {
 	return ""
 }
 
-func (c clientImpl) ConversationName(id string) string {
+func (c *clientImpl) ConversationName(id string) string {
 	if conversation, found := c.state.Conversation(id); found {
 		return conversation.Name
 	}
@@ -310,7 +604,7 @@ func (c clientImpl) ConversationName(id string) string {
 	return ""
 }
 
-func (c clientImpl) ConversationID(name string) string {
+func (c *clientImpl) ConversationID(name string) string {
 	if conversation, found := c.state.ConversationID(name); found {
 		return conversation.ID
 	}
@@ -318,7 +612,13 @@ func (c clientImpl) ConversationID(name string) string {
 	return ""
 }
 
-func (c clientImpl) UserName(id string) string {
+func (c *clientImpl) ConversationPublic(id string) bool {
+	conversation, found := c.state.Conversation(id)
+
+	return found && !conversation.Private
+}
+
+func (c *clientImpl) UserName(id string) string {
 	if user, found := c.state.User(id); found {
 		return user.Name
 	}
@@ -326,10 +626,30 @@ func (c clientImpl) UserName(id string) string {
 	return ""
 }
 
-func (c clientImpl) UserID(username string) string {
+func (c *clientImpl) UserID(username string) string {
 	if user, found := c.state.UserID(username); found {
 		return user.ID
 	}
 
 	return ""
 }
+
+func (c *clientImpl) UserLocation(id string) *time.Location {
+	user, found := c.state.User(id)
+	if !found {
+		return time.UTC
+	}
+
+	return user.Location()
+}
+
+func (c *clientImpl) Connected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.connected && c.botUserDetails != nil
+}
+
+func (c *clientImpl) StatePopulated() bool {
+	return c.state.Populated()
+}
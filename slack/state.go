@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/metrics"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
@@ -18,17 +19,21 @@ var (
 // facilitate unit testing with mock state.
 type slackState interface {
 	Stale() bool
+	Populated() bool
 	MaxAge() time.Duration
 	Refresh(client SlackAPI, force bool) error
 	Conversation(id string) (Conversation, bool)
 	ConversationID(name string) (Conversation, bool)
 	User(id string) (User, bool)
 	UserID(username string) (User, bool)
+	CustomEmoji(name string) (string, bool)
+	CustomEmojiList() map[string]string
+	UpdateCustomEmoji(change EmojiChange)
 }
 
 // slackStateImpl is a simple concurrency safe cache of slack API state. It
-// periodically fetches the entire conversation and user list from the connected
-// slack team. Using this information it's possible to quickly map
+// periodically fetches the entire conversation, user and custom emoji list from
+// the connected slack team. Using this information it's possible to quickly map
 // conversation/user IDs to friendly names (and vice-versa). Ideally
 // cache-misses would be checked against the API directly to help with situations
 // where new users/channels are created but yet known by the bot. In practice for
@@ -43,6 +48,9 @@ type slackStateImpl struct {
 	conversationsByName map[string]Conversation
 	usersByID           map[string]User
 	usersByName         map[string]User
+	// customEmoji maps custom emoji names to their image URL, or "alias:<name>"
+	// for aliases of other emoji.
+	customEmoji map[string]string
 }
 
 func newSlackStateImpl(log *logrus.Logger, config config.SlackConfig) *slackStateImpl {
@@ -62,6 +70,7 @@ func newSlackStateImpl(log *logrus.Logger, config config.SlackConfig) *slackStat
 		conversationsByName: make(map[string]Conversation),
 		usersByID:           make(map[string]User),
 		usersByName:         make(map[string]User),
+		customEmoji:         make(map[string]string),
 	}
 }
 
@@ -82,6 +91,15 @@ func (s *slackStateImpl) Stale() bool {
 	return stale
 }
 
+// Populated returns true if the state has been refreshed successfully at least
+// once.
+func (s *slackStateImpl) Populated() bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return !s.lastUpdated.IsZero()
+}
+
 func (s *slackStateImpl) Conversation(id string) (Conversation, bool) {
 	s.RLock()
 	defer s.RUnlock()
@@ -118,6 +136,49 @@ func (s *slackStateImpl) UserID(username string) (User, bool) {
 	return user, found
 }
 
+// CustomEmoji returns the value of the workspace custom emoji with the given
+// name (no ":" delimiters): either an image URL or "alias:<name>".
+func (s *slackStateImpl) CustomEmoji(name string) (string, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	value, found := s.customEmoji[name]
+
+	return value, found
+}
+
+// CustomEmojiList returns a copy of all of the workspace custom emoji, mapping
+// each name to its image URL or "alias:<name>".
+func (s *slackStateImpl) CustomEmojiList() map[string]string {
+	s.RLock()
+	defer s.RUnlock()
+
+	list := make(map[string]string, len(s.customEmoji))
+	for name, value := range s.customEmoji {
+		list[name] = value
+	}
+
+	return list
+}
+
+// UpdateCustomEmoji applies an added or removed custom emoji to the cached
+// custom emoji so that it's known before the next refresh.
+func (s *slackStateImpl) UpdateCustomEmoji(change EmojiChange) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, name := range change.Names {
+		if change.Removed {
+			delete(s.customEmoji, name)
+		} else {
+			s.customEmoji[name] = change.Value
+		}
+	}
+}
+
+// Refresh fetches the users, conversations and custom emoji from Slack if the
+// state is stale or force is true. A successful refresh records when it
+// happened so the state isn't stale again until MaxAge has passed.
 func (s *slackStateImpl) Refresh(client SlackAPI, force bool) error {
 	// Only refresh if stale or forced.
 	if !s.Stale() && !force {
@@ -152,6 +213,14 @@ func (s *slackStateImpl) Refresh(client SlackAPI, force bool) error {
 		newUsersByName[user.Name] = user
 	}
 
+	// Fetch the custom emoji list. Unlike users and conversations the bot can
+	// get by without it (e.g. if the token lacks the emoji:read scope) so on
+	// error the previous list is kept.
+	newCustomEmoji, err := s.emoji(client)
+	if err != nil {
+		s.log.Warnf("Keeping previous custom emoji list: %v", err)
+	}
+
 	// Lock for update, replace maps
 	s.Lock()
 	defer s.Unlock()
@@ -160,6 +229,14 @@ func (s *slackStateImpl) Refresh(client SlackAPI, force bool) error {
 	s.usersByID = newUsersByID
 	s.usersByName = newUsersByName
 
+	if newCustomEmoji != nil {
+		s.customEmoji = newCustomEmoji
+	}
+
+	s.lastUpdated = time.Now()
+
+	metrics.SlackStateRefreshed(s.lastUpdated, len(newUsersByID), len(newConversationsByID), len(s.customEmoji))
+
 	return nil
 }
 
@@ -176,14 +253,33 @@ func (s *slackStateImpl) users(client SlackAPI) ([]User, error) {
 	results := make([]User, len(users))
 	for i, user := range users {
 		results[i] = User{
-			ID:   user.ID,
-			Name: user.Name,
+			ID:       user.ID,
+			Name:     user.Name,
+			TZ:       user.TZ,
+			TZOffset: user.TZOffset,
 		}
 	}
 
 	return results, nil
 }
 
+func (s *slackStateImpl) emoji(client SlackAPI) (map[string]string, error) {
+	s.log.Infof("Fetching custom emoji")
+
+	emoji, err := client.GetEmoji()
+	if err != nil {
+		return nil, fmt.Errorf("slack client failed to get custom emoji: %w", err)
+	}
+
+	s.log.Infof("Found %d custom emoji", len(emoji))
+
+	if emoji == nil {
+		emoji = make(map[string]string)
+	}
+
+	return emoji, nil
+}
+
 func (s *slackStateImpl) conversations(client SlackAPI) ([]Conversation, error) {
 	var apiResults []slack.Channel
 
@@ -221,8 +317,9 @@ func (s *slackStateImpl) conversations(client SlackAPI) ([]Conversation, error)
 	results := make([]Conversation, len(apiResults))
 	for i, channel := range apiResults {
 		results[i] = Conversation{
-			Name: channel.Name,
-			ID:   channel.ID,
+			Name:    channel.Name,
+			ID:      channel.ID,
+			Private: channel.IsPrivate || channel.IsIM || channel.IsMpIM,
 		}
 	}
 
@@ -4,11 +4,13 @@ package slack
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/cpu/gorfbot/config"
 	"github.com/cpu/gorfbot/test"
+	"github.com/cpu/gorfbot/test/fakeslack"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 	logtest "github.com/sirupsen/logrus/hooks/test"
@@ -175,6 +177,31 @@ func TestUserID(t *testing.T) {
 	}
 }
 
+func TestUpdateCustomEmoji(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	state := newSlackStateImpl(log, config.SlackConfig{})
+
+	state.customEmoji["gorf"] = "https://example.com/gorf.png"
+	state.customEmoji["gorfing"] = "alias:gorf"
+
+	state.UpdateCustomEmoji(EmojiChange{Names: []string{"frog"}, Value: "https://example.com/frog.png"})
+	state.UpdateCustomEmoji(EmojiChange{Names: []string{"gorf", "gorfing"}, Removed: true})
+
+	expected := map[string]string{"frog": "https://example.com/frog.png"}
+	list := state.CustomEmojiList()
+
+	if !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected custom emoji %v got %v", expected, list)
+	}
+
+	// The list is a copy.
+	list["gorf"] = "alias:frog"
+
+	if _, found := state.CustomEmoji("gorf"); found {
+		t.Errorf("expected changes to the custom emoji list to not change the state")
+	}
+}
+
 var (
 	mockChanList = []real_slack.Channel{
 		{
@@ -206,6 +233,11 @@ var (
 	}
 )
 
+var mockEmojiList = map[string]string{
+	"gorf":    "https://emoji.example.com/gorf.png",
+	"gorfing": "alias:gorf",
+}
+
 func expectUsers(t *testing.T, state slackState) {
 	for _, u := range mockUsersList {
 		uID := u.ID
@@ -244,6 +276,11 @@ func setupRefresh(
 
 	if expectUsersRefresh {
 		mockAPI.EXPECT().GetUsers().Return(mockUsersList, userErr)
+
+		// Custom emoji are fetched after users.
+		if userErr == nil {
+			mockAPI.EXPECT().GetEmoji().Return(mockEmojiList, nil)
+		}
 	}
 
 	if expectConversationsRefresh {
@@ -311,6 +348,16 @@ func TestRefreshStale(t *testing.T) {
 	// Users and conversations should be present
 	expectUsers(t, state)
 	expectConversations(t, state)
+
+	// And the state should no longer be stale
+	if state.Stale() {
+		t.Errorf("expected state to not be stale after Refresh")
+	}
+
+	// So refreshing again without force shouldn't make more mock calls
+	if err := state.Refresh(mockAPI, false); err != nil {
+		t.Errorf("unexpected err from Refresh")
+	}
 }
 
 func TestRefreshUsersErr(t *testing.T) {
@@ -333,6 +380,37 @@ func TestRefreshUsersErr(t *testing.T) {
 	}
 }
 
+func TestRefreshEmojiErr(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPI := NewMockSlackAPI(ctrl)
+	getOps := &real_slack.GetConversationsParameters{ExcludeArchived: "true"}
+
+	mockAPI.EXPECT().GetConversations(getOps).Return(mockChanList, "", nil).Times(2)
+	mockAPI.EXPECT().GetUsers().Return(mockUsersList, nil).Times(2)
+	mockAPI.EXPECT().GetEmoji().Return(mockEmojiList, nil)
+	mockAPI.EXPECT().GetEmoji().Return(nil, errors.New("missing scope"))
+
+	state := newSlackStateImpl(log, config.SlackConfig{})
+
+	// Failing to get the custom emoji doesn't fail the refresh and the previous
+	// custom emoji are kept.
+	for i := 0; i < 2; i++ {
+		if err := state.Refresh(mockAPI, true); err != nil {
+			t.Fatalf("unexpected err from Refresh %d: %v", i, err)
+		}
+
+		if value, found := state.CustomEmoji("gorfing"); !found || value != "alias:gorf" {
+			t.Errorf("expected to find custom emoji alias after refresh %d, got %q", i, value)
+		}
+	}
+
+	expectUsers(t, state)
+	expectConversations(t, state)
+}
+
 func TestRefreshConversationsErr(t *testing.T) {
 	log, testConfig, ctrl, mockAPI := setupRefresh(
 		// NB: Using a non-nil conversations err with setupRefresh
@@ -352,3 +430,75 @@ func TestRefreshConversationsErr(t *testing.T) {
 		t.Errorf("expected err %q got %q", expectedErrMsg, actual)
 	}
 }
+
+func TestRefreshFakeSlackPagination(t *testing.T) {
+	log, _ := logtest.NewNullLogger()
+
+	fakeConfig := fakeslack.DefaultConfig()
+	// Add enough users and conversations that with a small page size the
+	// client has to follow several cursors.
+	for i := 3; i <= 7; i++ {
+		fakeConfig.Users = append(fakeConfig.Users, fakeslack.User{
+			ID:   fmt.Sprintf("U00%d", i),
+			Name: fmt.Sprintf("user%d", i),
+		})
+		fakeConfig.Conversations = append(fakeConfig.Conversations, fakeslack.Conversation{
+			ID:      fmt.Sprintf("C00%d", i),
+			Name:    fmt.Sprintf("channel%d", i),
+			Private: i%2 == 0,
+		})
+	}
+
+	fakeConfig.PageSize = 2
+
+	server := fakeslack.New(fakeConfig)
+	defer server.Close()
+
+	client := real_slack.New(fakeslack.Token, real_slack.OptionAPIURL(server.APIURL()))
+
+	state := newSlackStateImpl(log, config.SlackConfig{})
+	if state.Populated() {
+		t.Errorf("expected new state to not be populated")
+	}
+
+	if err := state.Refresh(client, false); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+
+	if !state.Populated() {
+		t.Errorf("expected state to be populated after refresh")
+	}
+
+	// Each page holds two items so fetching all of them takes ceil(n/2) calls.
+	expectedConversationCalls := (len(fakeConfig.Conversations) + 1) / 2
+	if calls := server.APICalls("conversations.list"); calls != expectedConversationCalls {
+		t.Errorf("expected %d conversations.list calls, got %d", expectedConversationCalls, calls)
+	}
+
+	// The bot user is listed with the other users.
+	expectedUserCalls := (len(fakeConfig.Users) + 2) / 2
+	if calls := server.APICalls("users.list"); calls != expectedUserCalls {
+		t.Errorf("expected %d users.list calls, got %d", expectedUserCalls, calls)
+	}
+
+	for _, c := range fakeConfig.Conversations {
+		if conv, found := state.Conversation(c.ID); !found {
+			t.Errorf("expected to find conversation %q but didn't", c.ID)
+		} else if conv.Name != c.Name {
+			t.Errorf("expected conversation %q to have name %q but had %q",
+				c.ID, c.Name, conv.Name)
+		} else if conv.Private != c.Private {
+			t.Errorf("expected conversation %q to have private %v but had %v",
+				c.ID, c.Private, conv.Private)
+		}
+	}
+
+	for _, u := range append(fakeConfig.Users, fakeConfig.Bot) {
+		if user, found := state.User(u.ID); !found {
+			t.Errorf("expected to find user %q but didn't", u.ID)
+		} else if user.Name != u.Name {
+			t.Errorf("expected user %q to have name %q but had %q",
+				u.ID, u.Name, user.Name)
+		}
+	}
+}
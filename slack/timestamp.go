@@ -19,7 +19,7 @@ func (e errBadTimestamp) Error() string {
 
 // Parses a Slack-style timestamp by removing the UUID component if it is
 // present. Returns a time.Time instance or an err.
-func (c clientImpl) ParseTimestamp(timestamp string) (time.Time, error) {
+func (c *clientImpl) ParseTimestamp(timestamp string) (time.Time, error) {
 	components := strings.Split(timestamp, ".")
 	if len(components) != expectedSlackTimestampComponents {
 		c.log.Warnf("found weird timestamp %q, components %v", timestamp, components)
@@ -0,0 +1,1191 @@
+// Package memory provides an in-memory implementation of the storage.Storage
+// interface. Nothing is persisted: it is intended for tests that want real
+// storage behaviour without a MongoDB instance.
+package memory
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+// memoryStorage is the implementation of the Storage interface that keeps
+// everything in memory.
+type memoryStorage struct {
+	mu sync.Mutex
+
+	topics    []models.Topic
+	emoji     []models.Emoji
+	reactji   []models.Emoji
+	received  []models.Emoji
+	messages  []models.ReactedMessage
+	starboard []models.StarboardMessage
+	usage     []models.EmojiUsage
+	urlCounts map[string][]models.URLCount
+	themes    []models.Theme
+	runs      []models.ScheduledRun
+	reminders []models.Reminder
+	karma     []models.Karma
+	quotes    []models.Quote
+	polls     []models.Poll
+	markov    []models.MarkovTransition
+	optOuts   map[string]bool
+	rules     []models.ReactjiRule
+	urlRules  []models.URLRule
+	shares    []models.LinkShare
+}
+
+// NewMemoryStorage returns an empty Storage implementation backed by memory.
+func NewMemoryStorage() storage.Storage {
+	return &memoryStorage{
+		urlCounts: make(map[string][]models.URLCount),
+	}
+}
+
+// errEmptySortField is returned by finds with an empty FindOptions SortField.
+// Mongo rejects sorting by an empty field path so the memory storage does too,
+// to catch the mistake in tests.
+var errEmptySortField = errors.New("memory storage: find options have an empty SortField")
+
+// checkSortField returns errEmptySortField if the find options have no
+// SortField.
+func checkSortField(opts storage.FindOptions) error {
+	if opts.SortField == "" {
+		return errEmptySortField
+	}
+
+	return nil
+}
+
+// sortAndLimit sorts the provided slice in place by the struct field named by
+// the find options SortField and returns it truncated to the find options
+// Limit. Like Mongo (where models are stored with lowercased field names) the
+// sort field is matched case insensitively. Sorting is stable so that results
+// with equal sort keys are returned in insertion order.
+func sortAndLimit(results interface{}, opts storage.FindOptions) interface{} {
+	v := reflect.ValueOf(results)
+
+	if opts.SortField != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			a := fieldByName(v.Index(i), opts.SortField)
+			b := fieldByName(v.Index(j), opts.SortField)
+
+			if opts.Asc {
+				return less(a, b)
+			}
+
+			return less(b, a)
+		})
+	}
+
+	if opts.Limit > 0 && int64(v.Len()) > opts.Limit {
+		return v.Slice(0, int(opts.Limit)).Interface()
+	}
+
+	return results
+}
+
+// fieldByName returns the field of the struct value v with a name matching
+// name case insensitively, or an invalid value if there is no such field.
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	return v.FieldByNameFunc(func(field string) bool {
+		return strings.EqualFold(field, name)
+	})
+}
+
+// less compares two values of a sortable kind or time.Time values. Values of
+// other kinds (or invalid values for unknown fields) are never less than each
+// other.
+func less(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return false
+	}
+
+	if at, ok := a.Interface().(time.Time); ok {
+		bt, _ := b.Interface().(time.Time)
+
+		return at.Before(bt)
+	}
+
+	switch a.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	default:
+		return false
+	}
+}
+
+// GetTopics returns Topic models matching the options.
+func (m *memoryStorage) GetTopics(opts storage.GetTopicOptions) ([]models.Topic, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Topic
+
+	for _, topic := range m.topics {
+		if opts.Channel != "" && topic.Channel != opts.Channel {
+			continue
+		}
+
+		if opts.Creator != "" && topic.Creator != opts.Creator {
+			continue
+		}
+
+		if !opts.Since.IsZero() && topic.Date < models.TopicDate(opts.Since) {
+			continue
+		}
+
+		if !opts.Until.IsZero() && topic.Date >= models.TopicDate(opts.Until) {
+			continue
+		}
+
+		if opts.Search != "" && !searchMatch(topic.Topic, opts.Search) {
+			continue
+		}
+
+		results = append(results, topic)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Topic)
+
+	return results, nil
+}
+
+// searchMatch approximates a Mongo text search, returning true if the text
+// contains any of the words of the search, ignoring case.
+func searchMatch(text, search string) bool {
+	text = strings.ToLower(text)
+
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddTopic adds a topic model.
+func (m *memoryStorage) AddTopic(topic models.Topic) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.topics = append(m.topics, topic)
+
+	return nil
+}
+
+// emojiCollection returns a pointer to the emoji, reactji or received reactji
+// slice.
+func (m *memoryStorage) emojiCollection(reaction, received bool) *[]models.Emoji {
+	switch {
+	case received:
+		return &m.received
+	case reaction:
+		return &m.reactji
+	default:
+		return &m.emoji
+	}
+}
+
+// GetEmoji returns Emoji models matching the options. If the options are
+// windowed the results are summed from the matching EmojiUsage buckets.
+func (m *memoryStorage) GetEmoji(opts storage.GetEmojiOptions) ([]models.Emoji, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if opts.Windowed() && opts.Received {
+		return nil, storage.ErrWindowedReceived
+	} else if opts.Windowed() {
+		return m.windowedEmoji(opts), nil
+	} else if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Emoji
+
+	for _, emoji := range *m.emojiCollection(opts.Reaction, opts.Received) {
+		if opts.User != "" && emoji.User != opts.User {
+			continue
+		}
+
+		if opts.Emoji != "" && emoji.Emoji != opts.Emoji {
+			continue
+		}
+
+		results = append(results, emoji)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Emoji)
+
+	return results, nil
+}
+
+// UpsertEmojiCount increases or decreases the count of the emoji model with the
+// same user and emoji, adding it if it doesn't exist. Like the Mongo storage
+// the model is returned as it was **before** the update, with a zero count if
+// it was added.
+func (m *memoryStorage) UpsertEmojiCount(emoji models.Emoji, decrement bool) (models.Emoji, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	collection := m.emojiCollection(emoji.Reaction, emoji.Received)
+
+	for i, existing := range *collection {
+		if existing.User == emoji.User && existing.Emoji == emoji.Emoji {
+			(*collection)[i].Count += updateCount
+
+			return existing, nil
+		}
+	}
+
+	added := emoji
+	added.Count = updateCount
+	*collection = append(*collection, added)
+
+	emoji.Count = 0
+
+	return emoji, nil
+}
+
+// windowedEmoji sums the counts of EmojiUsage buckets matching the options into
+// Emoji models by user and emoji. Like the Mongo storage, models without a
+// positive total count are omitted. The caller must hold the lock.
+func (m *memoryStorage) windowedEmoji(opts storage.GetEmojiOptions) []models.Emoji {
+	var since time.Time
+	if !opts.Since.IsZero() {
+		since = models.UsageDay(opts.Since)
+	}
+
+	type key struct {
+		user, emoji string
+	}
+
+	var results []models.Emoji
+
+	indexes := make(map[key]int)
+
+	for _, usage := range m.usage {
+		if usage.Reaction != opts.Reaction ||
+			(opts.User != "" && usage.User != opts.User) ||
+			(opts.Emoji != "" && usage.Emoji != opts.Emoji) ||
+			(opts.Channel != "" && usage.Channel != opts.Channel) ||
+			(!since.IsZero() && usage.Day.Before(since)) ||
+			(!opts.Until.IsZero() && !usage.Day.Before(opts.Until)) {
+			continue
+		}
+
+		k := key{usage.User, usage.Emoji}
+		if i, found := indexes[k]; found {
+			results[i].Count += usage.Count
+			continue
+		}
+
+		indexes[k] = len(results)
+		results = append(results, models.Emoji{
+			User:     usage.User,
+			Emoji:    usage.Emoji,
+			Count:    usage.Count,
+			Reaction: opts.Reaction,
+		})
+	}
+
+	positive := results[:0]
+
+	for _, emoji := range results {
+		if emoji.Count > 0 {
+			positive = append(positive, emoji)
+		}
+	}
+
+	positive, _ = sortAndLimit(positive, opts.FindOptions).([]models.Emoji)
+
+	return positive
+}
+
+// GetReactedMessages returns ReactedMessage models matching the options.
+func (m *memoryStorage) GetReactedMessages(opts storage.GetReactedMessageOptions) ([]models.ReactedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.ReactedMessage
+
+	for _, msg := range m.messages {
+		if opts.Channel != "" && msg.Channel != opts.Channel {
+			continue
+		}
+
+		if opts.User != "" && msg.User != opts.User {
+			continue
+		}
+
+		results = append(results, msg)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.ReactedMessage)
+
+	return results, nil
+}
+
+// UpsertReactedMessage increases or decreases the count of the reacted message
+// model with the same channel and timestamp, adding it if it doesn't exist.
+// Like UpsertEmojiCount the model is returned as it was before the update.
+func (m *memoryStorage) UpsertReactedMessage(msg models.ReactedMessage, decrement bool) (models.ReactedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	for i, existing := range m.messages {
+		if existing.Channel == msg.Channel && existing.Timestamp == msg.Timestamp {
+			m.messages[i].Count += updateCount
+			m.messages[i].User = msg.User
+
+			return existing, nil
+		}
+	}
+
+	added := msg
+	added.Count = updateCount
+	m.messages = append(m.messages, added)
+
+	msg.Count = 0
+
+	return msg, nil
+}
+
+// UpsertStarboardCount increases or decreases the count of the starboard
+// message model with the same channel, timestamp and emoji, adding it if it
+// doesn't exist. Like UpsertEmojiCount the model is returned as it was before
+// the update.
+func (m *memoryStorage) UpsertStarboardCount(msg models.StarboardMessage, decrement bool) (models.StarboardMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	if i := m.starboardIndex(msg); i >= 0 {
+		existing := m.starboard[i]
+		m.starboard[i].Count += updateCount
+
+		return existing, nil
+	}
+
+	added := msg
+	added.Count = updateCount
+	added.Posted = false
+	m.starboard = append(m.starboard, added)
+
+	msg.Count = 0
+	msg.Posted = false
+
+	return msg, nil
+}
+
+// MarkStarboardPosted marks the starboard message model with the same channel,
+// timestamp and emoji as posted, returning true if it exists and wasn't already
+// posted.
+func (m *memoryStorage) MarkStarboardPosted(msg models.StarboardMessage) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.starboardIndex(msg)
+	if i < 0 || m.starboard[i].Posted {
+		return false, nil
+	}
+
+	m.starboard[i].Posted = true
+
+	return true, nil
+}
+
+// starboardIndex returns the index of the starboard message model with the same
+// channel, timestamp and emoji, or -1. The caller must hold the lock.
+func (m *memoryStorage) starboardIndex(msg models.StarboardMessage) int {
+	for i, existing := range m.starboard {
+		if existing.Channel == msg.Channel && existing.Timestamp == msg.Timestamp &&
+			existing.Emoji == msg.Emoji {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// GetEmojiHistory sums the daily usage buckets matching the options by day.
+// Like the Mongo storage days without a positive total count are omitted.
+func (m *memoryStorage) GetEmojiHistory(opts storage.GetEmojiHistoryOptions) ([]models.EmojiUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var since time.Time
+	if !opts.Since.IsZero() {
+		since = models.UsageDay(opts.Since)
+	}
+
+	totals := make(map[time.Time]int)
+
+	for _, usage := range m.usage {
+		if usage.Reaction != opts.Reaction ||
+			(opts.User != "" && usage.User != opts.User) ||
+			(opts.Emoji != "" && usage.Emoji != opts.Emoji) ||
+			(opts.Channel != "" && usage.Channel != opts.Channel) ||
+			(!since.IsZero() && usage.Day.Before(since)) ||
+			(!opts.Until.IsZero() && !usage.Day.Before(opts.Until)) {
+			continue
+		}
+
+		totals[usage.Day] += usage.Count
+	}
+
+	var results []models.EmojiUsage
+
+	for day, count := range totals {
+		if count > 0 {
+			results = append(results, models.EmojiUsage{Day: day, Count: count, Reaction: opts.Reaction})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Day.Before(results[j].Day)
+	})
+
+	return results, nil
+}
+
+// GetLeaderboard returns leaderboard entries summed from the emoji or reactji
+// counts, or for windowed options the matching EmojiUsage buckets. Like the
+// Mongo storage entries without a positive total count are omitted and
+// entries with equal counts are ordered by key.
+func (m *memoryStorage) GetLeaderboard(opts storage.GetLeaderboardOptions) ([]models.LeaderboardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find the matching usage as lifetime Emoji models.
+	var usage []models.Emoji
+
+	if opts.Windowed() {
+		usage = m.windowedEmoji(storage.GetEmojiOptions{
+			Emoji:    opts.Emoji,
+			Reaction: opts.Reaction,
+			Since:    opts.Since,
+			Until:    opts.Until,
+			Channel:  opts.Channel,
+		})
+	} else {
+		for _, emoji := range *m.emojiCollection(opts.Reaction, false) {
+			if opts.Emoji == "" || emoji.Emoji == opts.Emoji {
+				usage = append(usage, emoji)
+			}
+		}
+	}
+
+	totals := make(map[string]int)
+
+	for _, emoji := range usage {
+		key := emoji.Emoji
+		if opts.GroupBy == storage.GroupByUser {
+			key = emoji.User
+		}
+
+		totals[key] += emoji.Count
+	}
+
+	var results []models.LeaderboardEntry
+
+	for key, count := range totals {
+		if count > 0 {
+			results = append(results, models.LeaderboardEntry{Key: key, Count: count})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			if opts.Asc {
+				return results[i].Count < results[j].Count
+			}
+
+			return results[i].Count > results[j].Count
+		}
+
+		return results[i].Key < results[j].Key
+	})
+
+	if opts.Limit > 0 && int64(len(results)) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results, nil
+}
+
+// UpsertEmojiUsage increases or decreases the count of the emoji usage model
+// with the same user, emoji, channel and day, adding it if it doesn't exist.
+// Like UpsertEmojiCount the model is returned as it was before the update.
+func (m *memoryStorage) UpsertEmojiUsage(usage models.EmojiUsage, decrement bool) (models.EmojiUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	for i, existing := range m.usage {
+		if existing.Reaction == usage.Reaction && existing.User == usage.User &&
+			existing.Emoji == usage.Emoji && existing.Channel == usage.Channel &&
+			existing.Day.Equal(usage.Day) {
+			m.usage[i].Count += updateCount
+
+			return existing, nil
+		}
+	}
+
+	added := usage
+	added.Count = updateCount
+	m.usage = append(m.usage, added)
+
+	usage.Count = 0
+
+	return usage, nil
+}
+
+// UpsertURLCount increases the occurrences of the URL model with the same URL
+// in the named collection, adding it if it doesn't exist. Like
+// UpsertEmojiCount the model is returned as it was before the update.
+func (m *memoryStorage) UpsertURLCount(collection string, urlCount models.URLCount) (models.URLCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := m.urlCounts[collection]
+
+	for i, existing := range counts {
+		if existing.URL == urlCount.URL {
+			counts[i].Occurrences++
+			counts[i].LastSeen = urlCount.LastSeen
+
+			return existing, nil
+		}
+	}
+
+	added := urlCount
+	added.Occurrences = 1
+	m.urlCounts[collection] = append(counts, added)
+
+	urlCount.Occurrences = 0
+
+	return urlCount, nil
+}
+
+// GetURLCounts returns URLCount models in the named collection matching the
+// options.
+func (m *memoryStorage) GetURLCounts(collection string, opts storage.GetURLCountOptions) ([]models.URLCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.URLCount
+
+	for _, u := range m.urlCounts[collection] {
+		if opts.URL != "" && u.URL != opts.URL {
+			continue
+		}
+
+		results = append(results, u)
+	}
+
+	return sortAndLimit(results, opts.FindOptions).([]models.URLCount), nil
+}
+
+// AddLinkShare adds a LinkShare model.
+func (m *memoryStorage) AddLinkShare(share models.LinkShare) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shares = append(m.shares, share)
+
+	return nil
+}
+
+// GetLinkShares returns LinkShare models matching the options.
+func (m *memoryStorage) GetLinkShares(opts storage.GetLinkShareOptions) ([]models.LinkShare, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.LinkShare
+
+	for _, share := range m.shares {
+		if opts.URL != "" && share.URL != opts.URL {
+			continue
+		}
+
+		if !opts.Since.IsZero() && share.Time.Before(opts.Since) {
+			continue
+		}
+
+		results = append(results, share)
+	}
+
+	return sortAndLimit(results, opts.FindOptions).([]models.LinkShare), nil
+}
+
+// GetThemes returns Theme models matching the options.
+func (m *memoryStorage) GetThemes(opts storage.GetThemeOptions) ([]models.Theme, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Theme
+
+	for _, theme := range m.themes {
+		if opts.User != "" && theme.Creator != opts.User {
+			continue
+		}
+
+		if opts.Name != "" && theme.Name != opts.Name {
+			continue
+		}
+
+		results = append(results, theme)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Theme)
+
+	return results, nil
+}
+
+// AddTheme adds a theme model.
+func (m *memoryStorage) AddTheme(theme models.Theme) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.themes = append(m.themes, theme)
+
+	return nil
+}
+
+// GetScheduledRuns returns every ScheduledRun model.
+func (m *memoryStorage) GetScheduledRuns() ([]models.ScheduledRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.ScheduledRun(nil), m.runs...), nil
+}
+
+// UpsertScheduledRun sets the LastRun of the scheduled run model with the same
+// command and channel, adding it if it doesn't exist.
+func (m *memoryStorage) UpsertScheduledRun(run models.ScheduledRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.runs {
+		if existing.Command == run.Command && existing.Channel == run.Channel {
+			m.runs[i].LastRun = run.LastRun
+
+			return nil
+		}
+	}
+
+	m.runs = append(m.runs, run)
+
+	return nil
+}
+
+// GetKarma returns Karma models matching the options.
+func (m *memoryStorage) GetKarma(opts storage.GetKarmaOptions) ([]models.Karma, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Karma
+
+	for _, karma := range m.karma {
+		if opts.Thing != "" && (karma.Thing != opts.Thing || karma.User != opts.User) {
+			continue
+		}
+
+		results = append(results, karma)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Karma)
+
+	return results, nil
+}
+
+// UpsertKarma increases or decreases the count of the karma model with the
+// same thing and user, adding it if it doesn't exist. Like the Mongo storage it
+// returns the model as it was before the update.
+func (m *memoryStorage) UpsertKarma(karma models.Karma, decrement bool) (models.Karma, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	for i, existing := range m.karma {
+		if existing.Thing == karma.Thing && existing.User == karma.User {
+			m.karma[i].Count += updateCount
+
+			return existing, nil
+		}
+	}
+
+	added := karma
+	added.Count = updateCount
+	m.karma = append(m.karma, added)
+
+	karma.Count = 0
+
+	return karma, nil
+}
+
+// GetQuotes returns Quote models matching the options.
+func (m *memoryStorage) GetQuotes(opts storage.GetQuoteOptions) ([]models.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Quote
+
+	for _, quote := range m.quotes {
+		if opts.Author != "" && quote.Author != opts.Author {
+			continue
+		}
+
+		if opts.Search != "" && !searchMatch(quote.Text, opts.Search) {
+			continue
+		}
+
+		results = append(results, quote)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Quote)
+
+	return results, nil
+}
+
+// AddQuote adds the Quote unless a quote with the same channel and timestamp
+// was already added. It returns true if the quote was added.
+func (m *memoryStorage) AddQuote(quote models.Quote) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.quotes {
+		if existing.Channel == quote.Channel && existing.Timestamp == quote.Timestamp {
+			return false, nil
+		}
+	}
+
+	m.quotes = append(m.quotes, quote)
+
+	return true, nil
+}
+
+// GetReminders returns Reminder models matching the options.
+func (m *memoryStorage) GetReminders(opts storage.GetReminderOptions) ([]models.Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Reminder
+
+	for _, reminder := range m.reminders {
+		if opts.Creator != "" && reminder.Creator != opts.Creator {
+			continue
+		}
+
+		results = append(results, reminder)
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Reminder)
+
+	return results, nil
+}
+
+// GetDueReminders returns the Reminder models due at or before now, oldest
+// first.
+func (m *memoryStorage) GetDueReminders(now time.Time) ([]models.Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var results []models.Reminder
+
+	for _, reminder := range m.reminders {
+		if !reminder.Due.After(now) {
+			results = append(results, reminder)
+		}
+	}
+
+	results, _ = sortAndLimit(results, storage.FindOptions{SortField: "due", Asc: true}).([]models.Reminder)
+
+	return results, nil
+}
+
+// AddReminder adds a reminder model.
+func (m *memoryStorage) AddReminder(reminder models.Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reminders = append(m.reminders, reminder)
+
+	return nil
+}
+
+// CancelReminder removes the reminder model with the given ID.
+func (m *memoryStorage) CancelReminder(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, reminder := range m.reminders {
+		if reminder.ID == id {
+			m.reminders = append(m.reminders[:i], m.reminders[i+1:]...)
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetPolls returns Poll models matching the options.
+func (m *memoryStorage) GetPolls(opts storage.GetPollOptions) ([]models.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkSortField(opts.FindOptions); err != nil {
+		return nil, err
+	}
+
+	var results []models.Poll
+
+	for _, poll := range m.polls {
+		if (opts.Channel != "" && poll.Channel != opts.Channel) ||
+			(opts.Timestamp != "" && poll.Timestamp != opts.Timestamp) ||
+			(opts.Creator != "" && poll.Creator != opts.Creator) ||
+			(opts.Open && poll.Closed) {
+			continue
+		}
+
+		results = append(results, copyPoll(poll))
+	}
+
+	results, _ = sortAndLimit(results, opts.FindOptions).([]models.Poll)
+
+	return results, nil
+}
+
+// GetDuePolls returns the open Poll models with a deadline at or before now,
+// oldest deadline first.
+func (m *memoryStorage) GetDuePolls(now time.Time) ([]models.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var results []models.Poll
+
+	for _, poll := range m.polls {
+		if !poll.Closed && !poll.Deadline.IsZero() && !poll.Deadline.After(now) {
+			results = append(results, copyPoll(poll))
+		}
+	}
+
+	results, _ = sortAndLimit(results, storage.FindOptions{SortField: "deadline", Asc: true}).([]models.Poll)
+
+	return results, nil
+}
+
+// copyPoll returns a copy of the poll that doesn't share its votes, so that
+// returned models aren't changed by later votes.
+func copyPoll(poll models.Poll) models.Poll {
+	poll.Votes = append([]models.PollVote(nil), poll.Votes...)
+
+	return poll
+}
+
+// AddPoll adds a poll model.
+func (m *memoryStorage) AddPoll(poll models.Poll) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.polls = append(m.polls, copyPoll(poll))
+
+	return nil
+}
+
+// openPoll returns the open poll with the given channel and timestamp, or nil.
+// The caller must hold the lock.
+func (m *memoryStorage) openPoll(channel, timestamp string) *models.Poll {
+	for i, poll := range m.polls {
+		if poll.Channel == channel && poll.Timestamp == timestamp && !poll.Closed {
+			return &m.polls[i]
+		}
+	}
+
+	return nil
+}
+
+// UpdatePollVote adds the vote to the open poll with the given channel and
+// timestamp if the poll doesn't already have it, or removes it if removed is
+// true.
+func (m *memoryStorage) UpdatePollVote(
+	channel, timestamp string, vote models.PollVote, removed bool) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll := m.openPoll(channel, timestamp)
+	if poll == nil {
+		return false, nil
+	}
+
+	for i, existing := range poll.Votes {
+		if existing != vote {
+			continue
+		}
+
+		if removed {
+			poll.Votes = append(poll.Votes[:i], poll.Votes[i+1:]...)
+		}
+
+		return true, nil
+	}
+
+	if !removed {
+		poll.Votes = append(poll.Votes, vote)
+	}
+
+	return true, nil
+}
+
+// ClosePoll closes the open poll with the given channel and timestamp.
+func (m *memoryStorage) ClosePoll(channel, timestamp string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll := m.openPoll(channel, timestamp)
+	if poll == nil {
+		return false, nil
+	}
+
+	poll.Closed = true
+
+	return true, nil
+}
+
+// AddMarkovTransitions increases the count of each existing transition with
+// the same model, prefix and next word, adding those that don't exist.
+func (m *memoryStorage) AddMarkovTransitions(transitions []models.MarkovTransition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range transitions {
+		found := false
+
+		for i, existing := range m.markov {
+			if existing.Model == t.Model && existing.Next == t.Next && reflect.DeepEqual(existing.Prefix, t.Prefix) {
+				m.markov[i].Count += t.Count
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			t.Prefix = append([]string(nil), t.Prefix...)
+			m.markov = append(m.markov, t)
+		}
+	}
+
+	return nil
+}
+
+// GetMarkovTransitions returns the transitions of the named model with the
+// given prefix.
+func (m *memoryStorage) GetMarkovTransitions(model string, prefix []string) ([]models.MarkovTransition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var results []models.MarkovTransition
+
+	for _, t := range m.markov {
+		if t.Model == model && reflect.DeepEqual(t.Prefix, prefix) {
+			results = append(results, t)
+		}
+	}
+
+	return results, nil
+}
+
+// DeleteMarkovModel removes the transitions of the named model.
+func (m *memoryStorage) DeleteMarkovModel(model string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.markov[:0]
+
+	for _, t := range m.markov {
+		if t.Model != model {
+			kept = append(kept, t)
+		}
+	}
+
+	m.markov = kept
+
+	return nil
+}
+
+// GetMarkovOptOut returns true if the user opted out of Markov models.
+func (m *memoryStorage) GetMarkovOptOut(user string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.optOuts[user], nil
+}
+
+// SetMarkovOptOut opts the user out of (or back in to) Markov models.
+func (m *memoryStorage) SetMarkovOptOut(user string, optOut bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.optOuts == nil {
+		m.optOuts = make(map[string]bool)
+	}
+
+	if optOut {
+		m.optOuts[user] = true
+	} else {
+		delete(m.optOuts, user)
+	}
+
+	return nil
+}
+
+// GetReactjiRules returns all of the ReactjiRule models, oldest first.
+func (m *memoryStorage) GetReactjiRules() ([]models.ReactjiRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.ReactjiRule(nil), m.rules...), nil
+}
+
+// AddReactjiRule adds a ReactjiRule model.
+func (m *memoryStorage) AddReactjiRule(rule models.ReactjiRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rules = append(m.rules, rule)
+
+	return nil
+}
+
+// RemoveReactjiRule removes the ReactjiRule model with the given ID.
+func (m *memoryStorage) RemoveReactjiRule(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetURLRules returns all of the URLRule models, oldest first.
+func (m *memoryStorage) GetURLRules() ([]models.URLRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.URLRule(nil), m.urlRules...), nil
+}
+
+// AddURLRule adds a URLRule model unless one for the same collection exists.
+func (m *memoryStorage) AddURLRule(rule models.URLRule) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.urlRules {
+		if existing.Collection == rule.Collection {
+			return false, nil
+		}
+	}
+
+	m.urlRules = append(m.urlRules, rule)
+
+	return true, nil
+}
+
+// RemoveURLRule removes the URLRule model for the given collection.
+func (m *memoryStorage) RemoveURLRule(collection string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.urlRules {
+		if rule.Collection == collection {
+			m.urlRules = append(m.urlRules[:i], m.urlRules[i+1:]...)
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Ping always succeeds.
+func (m *memoryStorage) Ping() error {
+	return nil
+}
@@ -0,0 +1,1211 @@
+package memory
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cpu/gorfbot/storage"
+	"github.com/cpu/gorfbot/storage/models"
+)
+
+func TestUpsertEmojiCount(t *testing.T) {
+	s := NewMemoryStorage()
+
+	wave := models.Emoji{User: "U001", Emoji: ":wave:", Count: 1}
+
+	// The first upsert adds the model and returns a zero count.
+	if prev, err := s.UpsertEmojiCount(wave, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if prev.Count != 0 {
+		t.Errorf("expected first upsert to return count 0, got %d", prev.Count)
+	}
+
+	// Subsequent upserts return the count before the update.
+	for i := 1; i <= 2; i++ {
+		if prev, err := s.UpsertEmojiCount(wave, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if prev.Count != i {
+			t.Errorf("expected upsert to return count %d, got %d", i, prev.Count)
+		}
+	}
+
+	if _, err := s.UpsertEmojiCount(wave, true); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Reactions are kept separately from emoji.
+	joy := models.Emoji{User: "U001", Emoji: "joy", Count: 1, Reaction: true}
+	if _, err := s.UpsertEmojiCount(joy, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	emoji, err := s.GetEmoji(storage.GetEmojiOptions{FindOptions: insertionOrder, User: "U001"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := []models.Emoji{{User: "U001", Emoji: ":wave:", Count: 2}}
+	if !reflect.DeepEqual(emoji, expected) {
+		t.Errorf("expected emoji %v got %v", expected, emoji)
+	}
+
+	reactji, err := s.GetEmoji(storage.GetEmojiOptions{FindOptions: insertionOrder, User: "U001", Reaction: true})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected = []models.Emoji{joy}
+	if !reflect.DeepEqual(reactji, expected) {
+		t.Errorf("expected reactji %v got %v", expected, reactji)
+	}
+}
+
+// insertionOrder are find options that keep results in the order they were
+// added. The memory storage leaves results as they are when sorting by a field
+// the models don't have, and Mongo's "_id" increases as documents are added.
+var insertionOrder = storage.FindOptions{SortField: "_id"}
+
+func TestGetEmojiSortAndLimit(t *testing.T) {
+	s := NewMemoryStorage()
+
+	counts := map[string]int{":a:": 3, ":b:": 1, ":c:": 2, ":d:": 2}
+	for _, name := range []string{":a:", ":b:", ":c:", ":d:"} {
+		for i := 0; i < counts[name]; i++ {
+			if _, err := s.UpsertEmojiCount(models.Emoji{User: "U001", Emoji: name}, false); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.FindOptions
+		expected []string
+	}{
+		{
+			name:     "sort desc",
+			opts:     storage.FindOptions{SortField: "count"},
+			expected: []string{":a:", ":c:", ":d:", ":b:"},
+		},
+		{
+			name:     "sort asc, limit",
+			opts:     storage.FindOptions{SortField: "count", Asc: true, Limit: 2},
+			expected: []string{":b:", ":c:"},
+		},
+		{
+			name:     "unknown sort field",
+			opts:     storage.FindOptions{SortField: "whatever"},
+			expected: []string{":a:", ":b:", ":c:", ":d:"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			emoji, err := s.GetEmoji(storage.GetEmojiOptions{FindOptions: tc.opts})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			var names []string
+			for _, e := range emoji {
+				names = append(names, e.Emoji)
+			}
+
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected emoji %v got %v", tc.expected, names)
+			}
+		})
+	}
+
+	// Mongo rejects sorting by an empty field, so the memory storage does too.
+	if _, err := s.GetEmoji(storage.GetEmojiOptions{}); !errors.Is(err, errEmptySortField) {
+		t.Errorf("expected errEmptySortField with no sort field, got %v", err)
+	}
+}
+
+func TestWindowedEmoji(t *testing.T) {
+	s := NewMemoryStorage()
+
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	march2 := march1.AddDate(0, 0, 1)
+	march3 := march1.AddDate(0, 0, 2)
+
+	upserts := []struct {
+		usage     models.EmojiUsage
+		decrement bool
+	}{
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march1}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march2}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C002", Day: march2}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C002", Day: march3}},
+		{usage: models.EmojiUsage{User: "U002", Emoji: ":wave:", Channel: "C001", Day: march3}},
+		{usage: models.EmojiUsage{User: "U002", Emoji: ":tada:", Channel: "C001", Day: march3}},
+		// A reaction added and then removed nets to zero and isn't returned.
+		{usage: models.EmojiUsage{User: "U001", Emoji: "joy", Channel: "C001", Day: march2, Reaction: true}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: "joy", Channel: "C001", Day: march2, Reaction: true}, decrement: true},
+		{usage: models.EmojiUsage{User: "U002", Emoji: "joy", Channel: "C001", Day: march2, Reaction: true}},
+	}
+
+	for _, u := range upserts {
+		if _, err := s.UpsertEmojiUsage(u.usage, u.decrement); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	// Upserting an existing bucket returns its count before the update.
+	if prev, err := s.UpsertEmojiUsage(upserts[0].usage, true); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if prev.Count != 1 {
+		t.Errorf("expected upsert to return count 1, got %d", prev.Count)
+	}
+
+	if _, err := s.UpsertEmojiUsage(upserts[0].usage, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetEmojiOptions
+		expected []models.Emoji
+	}{
+		{
+			name: "since",
+			// The time of day is ignored, the whole of March 2nd is included.
+			opts: storage.GetEmojiOptions{
+				Since:       march2.Add(12 * time.Hour),
+				FindOptions: storage.FindOptions{SortField: "count"},
+			},
+			expected: []models.Emoji{
+				{User: "U001", Emoji: ":wave:", Count: 3},
+				{User: "U002", Emoji: ":wave:", Count: 1},
+				{User: "U002", Emoji: ":tada:", Count: 1},
+			},
+		},
+		{
+			name: "until",
+			opts: storage.GetEmojiOptions{Until: march3},
+			expected: []models.Emoji{
+				{User: "U001", Emoji: ":wave:", Count: 3},
+			},
+		},
+		{
+			name: "channel and user",
+			opts: storage.GetEmojiOptions{Channel: "C001", User: "U002"},
+			expected: []models.Emoji{
+				{User: "U002", Emoji: ":wave:", Count: 1},
+				{User: "U002", Emoji: ":tada:", Count: 1},
+			},
+		},
+		{
+			name: "emoji and limit",
+			opts: storage.GetEmojiOptions{
+				Channel:     "C001",
+				Emoji:       ":wave:",
+				FindOptions: storage.FindOptions{SortField: "count", Limit: 1},
+			},
+			expected: []models.Emoji{
+				{User: "U001", Emoji: ":wave:", Count: 2},
+			},
+		},
+		{
+			name: "reactions",
+			opts: storage.GetEmojiOptions{Channel: "C001", Reaction: true},
+			expected: []models.Emoji{
+				{User: "U002", Emoji: "joy", Count: 1, Reaction: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			emoji, err := s.GetEmoji(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(emoji, tc.expected) {
+				t.Errorf("expected emoji %v got %v", tc.expected, emoji)
+			}
+		})
+	}
+}
+
+func TestGetEmojiHistory(t *testing.T) {
+	s := NewMemoryStorage()
+
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	march2 := march1.AddDate(0, 0, 1)
+	march3 := march1.AddDate(0, 0, 2)
+
+	upserts := []struct {
+		usage     models.EmojiUsage
+		decrement bool
+	}{
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march3}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march1}},
+		{usage: models.EmojiUsage{User: "U002", Emoji: ":wave:", Channel: "C002", Day: march1}},
+		{usage: models.EmojiUsage{User: "U002", Emoji: ":tada:", Channel: "C001", Day: march2}},
+		// A reaction added and then removed nets to zero and isn't returned.
+		{usage: models.EmojiUsage{User: "U001", Emoji: "joy", Channel: "C001", Day: march2, Reaction: true}},
+		{usage: models.EmojiUsage{User: "U001", Emoji: "joy", Channel: "C001", Day: march2, Reaction: true}, decrement: true},
+		{usage: models.EmojiUsage{User: "U001", Emoji: "joy", Channel: "C001", Day: march3, Reaction: true}},
+	}
+
+	for _, u := range upserts {
+		if _, err := s.UpsertEmojiUsage(u.usage, u.decrement); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetEmojiHistoryOptions
+		expected []models.EmojiUsage
+	}{
+		{
+			name: "all",
+			opts: storage.GetEmojiHistoryOptions{},
+			expected: []models.EmojiUsage{
+				{Day: march1, Count: 2},
+				{Day: march2, Count: 1},
+				{Day: march3, Count: 1},
+			},
+		},
+		{
+			name: "user and emoji",
+			opts: storage.GetEmojiHistoryOptions{User: "U001", Emoji: ":wave:", Since: march2},
+			expected: []models.EmojiUsage{
+				{Day: march3, Count: 1},
+			},
+		},
+		{
+			name: "reactions",
+			opts: storage.GetEmojiHistoryOptions{Reaction: true},
+			expected: []models.EmojiUsage{
+				{Day: march3, Count: 1, Reaction: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			history, err := s.GetEmojiHistory(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(history, tc.expected) {
+				t.Errorf("expected history %v got %v", tc.expected, history)
+			}
+		})
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	s := NewMemoryStorage()
+
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	march2 := march1.AddDate(0, 0, 1)
+
+	counts := []models.Emoji{
+		{User: "U001", Emoji: ":wave:"},
+		{User: "U001", Emoji: ":wave:"},
+		{User: "U001", Emoji: ":tada:"},
+		{User: "U002", Emoji: ":wave:"},
+		{User: "U002", Emoji: ":frog:"},
+		{User: "U002", Emoji: ":frog:"},
+		{User: "U002", Emoji: "joy", Reaction: true},
+	}
+	for _, e := range counts {
+		if _, err := s.UpsertEmojiCount(e, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	usages := []models.EmojiUsage{
+		{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march1},
+		{User: "U001", Emoji: ":wave:", Channel: "C001", Day: march2},
+		{User: "U001", Emoji: ":tada:", Channel: "C002", Day: march2},
+		{User: "U002", Emoji: ":wave:", Channel: "C001", Day: march2},
+	}
+	for _, u := range usages {
+		if _, err := s.UpsertEmojiUsage(u, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetLeaderboardOptions
+		expected []models.LeaderboardEntry
+	}{
+		{
+			name: "top emoji",
+			opts: storage.GetLeaderboardOptions{},
+			expected: []models.LeaderboardEntry{
+				{Key: ":wave:", Count: 3},
+				{Key: ":frog:", Count: 2},
+				{Key: ":tada:", Count: 1},
+			},
+		},
+		{
+			name: "rarest emoji with limit",
+			opts: storage.GetLeaderboardOptions{
+				FindOptions: storage.FindOptions{Asc: true, Limit: 2},
+			},
+			expected: []models.LeaderboardEntry{
+				{Key: ":tada:", Count: 1},
+				{Key: ":frog:", Count: 2},
+			},
+		},
+		{
+			name: "top users of an emoji",
+			opts: storage.GetLeaderboardOptions{GroupBy: storage.GroupByUser, Emoji: ":wave:"},
+			expected: []models.LeaderboardEntry{
+				{Key: "U001", Count: 2},
+				{Key: "U002", Count: 1},
+			},
+		},
+		{
+			name: "top reactors",
+			opts: storage.GetLeaderboardOptions{GroupBy: storage.GroupByUser, Reaction: true},
+			expected: []models.LeaderboardEntry{
+				{Key: "U002", Count: 1},
+			},
+		},
+		{
+			name: "windowed users",
+			opts: storage.GetLeaderboardOptions{
+				GroupBy: storage.GroupByUser,
+				Since:   march2,
+				Channel: "C001",
+			},
+			// Equal counts are ordered by key.
+			expected: []models.LeaderboardEntry{
+				{Key: "U001", Count: 1},
+				{Key: "U002", Count: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := s.GetLeaderboard(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(entries, tc.expected) {
+				t.Errorf("expected entries %v got %v", tc.expected, entries)
+			}
+		})
+	}
+}
+
+func TestReceivedAndReactedMessages(t *testing.T) {
+	s := NewMemoryStorage()
+
+	// Received reactji are kept separately from reactji given.
+	given := models.Emoji{User: "U001", Emoji: "joy", Reaction: true}
+	received := models.Emoji{User: "U001", Emoji: "joy", Reaction: true, Received: true}
+
+	for _, e := range []models.Emoji{given, received, received} {
+		if _, err := s.UpsertEmojiCount(e, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	emoji, err := s.GetEmoji(storage.GetEmojiOptions{FindOptions: insertionOrder, User: "U001", Reaction: true, Received: true})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	received.Count = 2
+	if expected := []models.Emoji{received}; !reflect.DeepEqual(emoji, expected) {
+		t.Errorf("expected received %v got %v", expected, emoji)
+	}
+
+	if _, err := s.GetEmoji(storage.GetEmojiOptions{Received: true, Channel: "C001"}); err != storage.ErrWindowedReceived {
+		t.Errorf("expected err %v for windowed received, got %v", storage.ErrWindowedReceived, err)
+	}
+
+	msgA := models.ReactedMessage{Channel: "C001", Timestamp: "1.1", User: "U001"}
+	msgB := models.ReactedMessage{Channel: "C002", Timestamp: "2.2", User: "U002"}
+
+	for _, msg := range []models.ReactedMessage{msgA, msgB, msgB} {
+		if _, err := s.UpsertReactedMessage(msg, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	if prev, err := s.UpsertReactedMessage(msgB, true); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if prev.Count != 2 {
+		t.Errorf("expected upsert to return count 2, got %d", prev.Count)
+	}
+
+	msgs, err := s.GetReactedMessages(storage.GetReactedMessageOptions{
+		FindOptions: storage.FindOptions{SortField: "count"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	msgA.Count = 1
+	msgB.Count = 1
+	if expected := []models.ReactedMessage{msgA, msgB}; !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected reacted messages %v got %v", expected, msgs)
+	}
+
+	msgs, err = s.GetReactedMessages(storage.GetReactedMessageOptions{FindOptions: insertionOrder, User: "U002"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := []models.ReactedMessage{msgB}; !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected reacted messages %v got %v", expected, msgs)
+	}
+}
+
+func TestStarboard(t *testing.T) {
+	s := NewMemoryStorage()
+
+	msg := models.StarboardMessage{Channel: "C001", Timestamp: "1.1", Emoji: "star"}
+
+	if marked, err := s.MarkStarboardPosted(msg); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if marked {
+		t.Errorf("expected unknown message not to be marked posted")
+	}
+
+	for i := 0; i < 2; i++ {
+		if prev, err := s.UpsertStarboardCount(msg, false); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if prev.Count != i {
+			t.Errorf("expected upsert to return count %d, got %d", i, prev.Count)
+		}
+	}
+
+	for i, expected := range []bool{true, false} {
+		if marked, err := s.MarkStarboardPosted(msg); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if marked != expected {
+			t.Errorf("expected mark %d to return %v, got %v", i, expected, marked)
+		}
+	}
+
+	prev, err := s.UpsertStarboardCount(msg, true)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := msg
+	expected.Count = 2
+	expected.Posted = true
+
+	if prev != expected {
+		t.Errorf("expected upsert to return %v, got %v", expected, prev)
+	}
+}
+
+func TestScheduledRuns(t *testing.T) {
+	s := NewMemoryStorage()
+
+	first := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+
+	for _, run := range []models.ScheduledRun{
+		{Command: "on this day", Channel: "general", LastRun: first},
+		{Command: "on this day", Channel: "random", LastRun: first},
+		{Command: "on this day", Channel: "general", LastRun: second},
+	} {
+		if err := s.UpsertScheduledRun(run); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	runs, err := s.GetScheduledRuns()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := []models.ScheduledRun{
+		{Command: "on this day", Channel: "general", LastRun: second},
+		{Command: "on this day", Channel: "random", LastRun: first},
+	}
+
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected runs %v, got %v", expected, runs)
+	}
+}
+
+func TestKarma(t *testing.T) {
+	s := NewMemoryStorage()
+
+	frogs := models.Karma{Thing: "frogs"}
+	// A user with an ID that is the same as a thing is counted separately.
+	user := models.Karma{Thing: "frogs", User: true}
+
+	for i, decrement := range []bool{false, false, true, false} {
+		prev, err := s.UpsertKarma(frogs, decrement)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if expected := []int{0, 1, 2, 1}[i]; prev.Count != expected {
+			t.Errorf("expected upsert %d to return count %d, got %d", i, expected, prev.Count)
+		}
+	}
+
+	if _, err := s.UpsertKarma(user, true); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	top, err := s.GetKarma(storage.GetKarmaOptions{
+		FindOptions: storage.FindOptions{SortField: "count"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := []models.Karma{
+		{Thing: "frogs", Count: 2},
+		{Thing: "frogs", User: true, Count: -1},
+	}
+	if !reflect.DeepEqual(top, expected) {
+		t.Errorf("expected karma %v, got %v", expected, top)
+	}
+
+	one, err := s.GetKarma(storage.GetKarmaOptions{FindOptions: insertionOrder, Thing: "frogs", User: true})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if !reflect.DeepEqual(one, expected[1:]) {
+		t.Errorf("expected karma %v, got %v", expected[1:], one)
+	}
+}
+
+func TestQuotes(t *testing.T) {
+	s := NewMemoryStorage()
+
+	quotes := []models.Quote{
+		{Channel: "C001", Timestamp: "1.0", Author: "U001", Text: "Gorf is a frog"},
+		{Channel: "C001", Timestamp: "2.0", Author: "U002", Text: "frogs are green"},
+		{Channel: "C002", Timestamp: "1.0", Author: "U001", Text: "toads are not"},
+	}
+
+	for _, quote := range quotes {
+		if added, err := s.AddQuote(quote); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if !added {
+			t.Errorf("expected quote %v to be added", quote)
+		}
+	}
+
+	// The same message can't be added twice.
+	dupe := quotes[0]
+	dupe.AddedBy = "U003"
+
+	if added, err := s.AddQuote(dupe); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if added {
+		t.Errorf("expected duplicate quote %v not to be added", dupe)
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetQuoteOptions
+		expected []models.Quote
+	}{
+		{
+			name:     "all",
+			opts:     storage.GetQuoteOptions{FindOptions: insertionOrder},
+			expected: quotes,
+		},
+		{
+			name:     "author",
+			opts:     storage.GetQuoteOptions{FindOptions: insertionOrder, Author: "U001"},
+			expected: []models.Quote{quotes[0], quotes[2]},
+		},
+		{
+			name:     "search",
+			opts:     storage.GetQuoteOptions{FindOptions: insertionOrder, Search: "GORF toad"},
+			expected: []models.Quote{quotes[0], quotes[2]},
+		},
+		{
+			name:     "author and search",
+			opts:     storage.GetQuoteOptions{FindOptions: insertionOrder, Author: "U002", Search: "green"},
+			expected: []models.Quote{quotes[1]},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := s.GetQuotes(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(results, tc.expected) {
+				t.Errorf("expected quotes %v, got %v", tc.expected, results)
+			}
+		})
+	}
+}
+
+func TestReminders(t *testing.T) {
+	s := NewMemoryStorage()
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	reminders := []models.Reminder{
+		{ID: "a", Creator: "U001", Text: "later", Due: now.Add(time.Hour)},
+		{ID: "b", Creator: "U002", Text: "now", Due: now},
+		{ID: "c", Creator: "U001", Text: "earlier", Due: now.Add(-time.Hour)},
+	}
+
+	for _, reminder := range reminders {
+		if err := s.AddReminder(reminder); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	due, err := s.GetDueReminders(now)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := []models.Reminder{reminders[2], reminders[1]}; !reflect.DeepEqual(due, expected) {
+		t.Errorf("expected due reminders %v, got %v", expected, due)
+	}
+
+	for i, expected := range []bool{true, false} {
+		if cancelled, err := s.CancelReminder("c"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if cancelled != expected {
+			t.Errorf("expected cancel %d to return %v, got %v", i, expected, cancelled)
+		}
+	}
+
+	mine, err := s.GetReminders(storage.GetReminderOptions{FindOptions: insertionOrder, Creator: "U001"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if expected := []models.Reminder{reminders[0]}; !reflect.DeepEqual(mine, expected) {
+		t.Errorf("expected reminders %v, got %v", expected, mine)
+	}
+}
+
+func TestSearchTopics(t *testing.T) {
+	s := NewMemoryStorage()
+
+	march1 := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	topics := []models.Topic{
+		{Channel: "C001", Creator: "U001", Topic: "Gorf is great", Date: models.TopicDate(march1)},
+		{Channel: "C002", Creator: "U002", Topic: "frogs are great", Date: models.TopicDate(march1.AddDate(0, 0, 1))},
+		{Channel: "C001", Creator: "U002", Topic: "nothing to see", Date: models.TopicDate(march1.AddDate(0, 0, 2))},
+	}
+	for _, topic := range topics {
+		if err := s.AddTopic(topic); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetTopicOptions
+		expected []models.Topic
+	}{
+		{
+			name:     "all channels",
+			expected: []models.Topic{topics[2], topics[1], topics[0]},
+		},
+		{
+			name:     "search any word ignoring case",
+			opts:     storage.GetTopicOptions{Search: "gorf FROGS"},
+			expected: []models.Topic{topics[1], topics[0]},
+		},
+		{
+			name:     "creator",
+			opts:     storage.GetTopicOptions{Creator: "U002", Channel: "C001"},
+			expected: []models.Topic{topics[2]},
+		},
+		{
+			name:     "window",
+			opts:     storage.GetTopicOptions{Since: march1.AddDate(0, 0, 1), Until: march1.AddDate(0, 0, 2)},
+			expected: []models.Topic{topics[1]},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.FindOptions = storage.FindOptions{SortField: "date"}
+
+			results, err := s.GetTopics(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(results, tc.expected) {
+				t.Errorf("expected topics %v got %v", tc.expected, results)
+			}
+		})
+	}
+}
+
+func TestTopicsAndThemes(t *testing.T) {
+	s := NewMemoryStorage()
+
+	topics := []models.Topic{
+		{Channel: "C001", Topic: "first", Date: "1"},
+		{Channel: "C002", Topic: "other", Date: "2"},
+		{Channel: "C001", Topic: "second", Date: "3"},
+	}
+	for _, topic := range topics {
+		if err := s.AddTopic(topic); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	results, err := s.GetTopics(storage.GetTopicOptions{
+		FindOptions: storage.FindOptions{SortField: "date"},
+		Channel:     "C001",
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expectedTopics := []models.Topic{topics[2], topics[0]}
+	if !reflect.DeepEqual(results, expectedTopics) {
+		t.Errorf("expected topics %v got %v", expectedTopics, results)
+	}
+
+	theme := models.Theme{Name: "gorf", Theme: "#000000", Creator: "U001"}
+	if err := s.AddTheme(theme); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if themes, err := s.GetThemes(storage.GetThemeOptions{FindOptions: insertionOrder, Name: "gorf"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(themes, []models.Theme{theme}) {
+		t.Errorf("expected themes [%v] got %v", theme, themes)
+	}
+
+	if themes, err := s.GetThemes(storage.GetThemeOptions{FindOptions: insertionOrder, Name: "nope"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if len(themes) != 0 {
+		t.Errorf("expected no themes got %v", themes)
+	}
+}
+
+func TestUpsertURLCount(t *testing.T) {
+	s := NewMemoryStorage()
+
+	first := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	u := models.URLCount{
+		URL:            "https://example.com",
+		FirstUser:      "U001",
+		FirstChannel:   "C001",
+		FirstTimestamp: "1609596000.000001",
+		FirstSeen:      first,
+		LastSeen:       first,
+	}
+
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			// Later upserts are by other users at later times.
+			u.FirstUser = "U002"
+			u.FirstSeen = first.Add(time.Duration(i) * time.Hour)
+			u.LastSeen = u.FirstSeen
+		}
+
+		if prev, err := s.UpsertURLCount("urls", u); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if prev.Occurrences != i {
+			t.Errorf("expected upsert %d to return %d occurrences got %d", i, i, prev.Occurrences)
+		}
+	}
+
+	expected := []models.URLCount{{
+		URL:            "https://example.com",
+		Occurrences:    3,
+		FirstUser:      "U001",
+		FirstChannel:   "C001",
+		FirstTimestamp: "1609596000.000001",
+		FirstSeen:      first,
+		LastSeen:       first.Add(2 * time.Hour),
+	}}
+
+	if counts, err := s.GetURLCounts("urls", storage.GetURLCountOptions{FindOptions: insertionOrder}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected counts %v got %v", expected, counts)
+	}
+
+	// Collections are independent.
+	if prev, err := s.UpsertURLCount("other", u); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if prev.Occurrences != 0 {
+		t.Errorf("expected new collection upsert to return 0 occurrences got %d", prev.Occurrences)
+	}
+}
+
+func TestGetURLCounts(t *testing.T) {
+	s := NewMemoryStorage()
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	for i, url := range []string{"https://a.com", "https://b.com", "https://b.com", "https://c.com"} {
+		u := models.URLCount{URL: url, LastSeen: now.Add(time.Duration(i) * time.Minute)}
+		if _, err := s.UpsertURLCount("urls", u); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetURLCountOptions
+		expected []string
+	}{
+		{
+			name:     "top",
+			opts:     storage.GetURLCountOptions{FindOptions: storage.FindOptions{SortField: "occurrences", Limit: 2}},
+			expected: []string{"https://b.com", "https://a.com"},
+		},
+		{
+			name:     "recent",
+			opts:     storage.GetURLCountOptions{FindOptions: storage.FindOptions{SortField: "lastseen"}},
+			expected: []string{"https://c.com", "https://b.com", "https://a.com"},
+		},
+		{
+			name:     "by URL",
+			opts:     storage.GetURLCountOptions{FindOptions: insertionOrder, URL: "https://c.com"},
+			expected: []string{"https://c.com"},
+		},
+		{
+			name: "missing URL",
+			opts: storage.GetURLCountOptions{FindOptions: insertionOrder, URL: "https://d.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			counts, err := s.GetURLCounts("urls", tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			var urls []string
+			for _, u := range counts {
+				urls = append(urls, u.URL)
+			}
+
+			if !reflect.DeepEqual(urls, tc.expected) {
+				t.Errorf("expected URLs %v got %v", tc.expected, urls)
+			}
+		})
+	}
+
+	if counts, err := s.GetURLCounts("other", storage.GetURLCountOptions{FindOptions: insertionOrder}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if len(counts) != 0 {
+		t.Errorf("expected no counts for an empty collection, got %v", counts)
+	}
+}
+
+func TestPolls(t *testing.T) {
+	s := NewMemoryStorage()
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	polls := []models.Poll{
+		{Channel: "C001", Timestamp: "1.0", Creator: "U001", Question: "Lunch?", Options: []string{"tacos", "pho"}},
+		{Channel: "C001", Timestamp: "2.0", Creator: "U002", Question: "Dinner?", Options: []string{"pizza", "soup"},
+			Deadline: now.Add(time.Hour)},
+		{Channel: "C002", Timestamp: "1.0", Creator: "U001", Question: "Snack?", Options: []string{"chips", "fruit"},
+			Deadline: now.Add(-time.Minute)},
+	}
+
+	for _, poll := range polls {
+		if err := s.AddPoll(poll); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	votes := []struct {
+		vote    models.PollVote
+		removed bool
+	}{
+		{vote: models.PollVote{User: "U001", Option: 0}},
+		{vote: models.PollVote{User: "U002", Option: 1}},
+		// Voting twice for the same option only counts once.
+		{vote: models.PollVote{User: "U002", Option: 1}},
+		{vote: models.PollVote{User: "U002", Option: 0}},
+		{vote: models.PollVote{User: "U001", Option: 0}, removed: true},
+	}
+
+	for _, v := range votes {
+		if updated, err := s.UpdatePollVote("C001", "1.0", v.vote, v.removed); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if !updated {
+			t.Errorf("expected vote %v to update the poll", v.vote)
+		}
+	}
+
+	if updated, err := s.UpdatePollVote("C003", "1.0", models.PollVote{User: "U001"}, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if updated {
+		t.Errorf("expected vote for an unknown poll not to update a poll")
+	}
+
+	results, err := s.GetPolls(storage.GetPollOptions{FindOptions: insertionOrder, Channel: "C001", Timestamp: "1.0"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expectedVotes := []models.PollVote{{User: "U002", Option: 1}, {User: "U002", Option: 0}}
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Votes, expectedVotes) {
+		t.Errorf("expected one poll with votes %v, got %v", expectedVotes, results)
+	}
+
+	due, err := s.GetDuePolls(now)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if len(due) != 1 || due[0].Question != "Snack?" {
+		t.Errorf("expected the snack poll to be due, got %v", due)
+	}
+
+	// Polls can only be closed once, and closed polls don't take votes.
+	for i, expected := range []bool{true, false} {
+		if closed, err := s.ClosePoll("C002", "1.0"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if closed != expected {
+			t.Errorf("expected close %d to return %v, got %v", i, expected, closed)
+		}
+	}
+
+	if updated, err := s.UpdatePollVote("C002", "1.0", models.PollVote{User: "U001"}, false); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if updated {
+		t.Errorf("expected vote for a closed poll not to update it")
+	}
+
+	open, err := s.GetPolls(storage.GetPollOptions{
+		FindOptions: storage.FindOptions{SortField: "timestamp"},
+		Creator:     "U001",
+		Open:        true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if len(open) != 1 || open[0].Question != "Lunch?" {
+		t.Errorf("expected only the lunch poll to be open, got %v", open)
+	}
+}
+
+func TestMarkov(t *testing.T) {
+	s := NewMemoryStorage()
+
+	transitions := models.MarkovTransitions("user:U001", []string{"gorf", "is", "gorf", "is", "gorf"}, 1)
+	other := models.MarkovTransitions("user:U002", []string{"gorf", "says"}, 1)
+
+	for _, add := range [][]models.MarkovTransition{transitions, transitions, other} {
+		if err := s.AddMarkovTransitions(add); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	results, err := s.GetMarkovTransitions("user:U001", []string{"gorf"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := []models.MarkovTransition{
+		{Model: "user:U001", Prefix: []string{"gorf"}, Next: "is", Count: 4},
+		{Model: "user:U001", Prefix: []string{"gorf"}, Next: "", Count: 2},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected transitions %v, got %v", expected, results)
+	}
+
+	if err := s.DeleteMarkovModel("user:U001"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if results, err := s.GetMarkovTransitions("user:U001", []string{"gorf"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if len(results) != 0 {
+		t.Errorf("expected no transitions for deleted model, got %v", results)
+	}
+
+	if results, err := s.GetMarkovTransitions("user:U002", []string{"gorf"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if len(results) != 1 {
+		t.Errorf("expected one transition for other model, got %v", results)
+	}
+
+	for _, optOut := range []bool{true, false} {
+		if err := s.SetMarkovOptOut("U001", optOut); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if got, err := s.GetMarkovOptOut("U001"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if got != optOut {
+			t.Errorf("expected opt out %v, got %v", optOut, got)
+		}
+	}
+}
+
+func TestReactjiRules(t *testing.T) {
+	s := NewMemoryStorage()
+
+	rules := []models.ReactjiRule{
+		{ID: "a", Trigger: "turkey", Reactions: []string{"turkey"}},
+		{ID: "b", Trigger: "gorf+", Regex: true, Reply: "ribbit"},
+	}
+
+	for _, rule := range rules {
+		if err := s.AddReactjiRule(rule); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	if results, err := s.GetReactjiRules(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(results, rules) {
+		t.Errorf("expected rules %v, got %v", rules, results)
+	}
+
+	for _, tc := range []struct {
+		id       string
+		expected bool
+	}{{"a", true}, {"a", false}, {"c", false}} {
+		if removed, err := s.RemoveReactjiRule(tc.id); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if removed != tc.expected {
+			t.Errorf("expected removing %q to return %v, got %v", tc.id, tc.expected, removed)
+		}
+	}
+
+	if results, err := s.GetReactjiRules(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(results, rules[1:]) {
+		t.Errorf("expected rules %v, got %v", rules[1:], results)
+	}
+}
+
+func TestURLRules(t *testing.T) {
+	s := NewMemoryStorage()
+
+	rules := []models.URLRule{
+		{HostPattern: `github\.com`, PathPattern: "/cpu/.*", Collection: "cpu_links", Reactji: []string{"link"}},
+		{HostPattern: `frog\.tips`, Collection: "frog_links", FirstMsg: "new tip!"},
+	}
+
+	for _, rule := range rules {
+		if added, err := s.AddURLRule(rule); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if !added {
+			t.Errorf("expected rule %v to be added", rule)
+		}
+	}
+
+	// A second rule for the same collection isn't added.
+	if added, err := s.AddURLRule(models.URLRule{HostPattern: ".*", Collection: "cpu_links"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if added {
+		t.Errorf("expected rule for existing collection not to be added")
+	}
+
+	if results, err := s.GetURLRules(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(results, rules) {
+		t.Errorf("expected rules %v, got %v", rules, results)
+	}
+
+	for _, tc := range []struct {
+		collection string
+		expected   bool
+	}{{"cpu_links", true}, {"cpu_links", false}, {"nope_links", false}} {
+		if removed, err := s.RemoveURLRule(tc.collection); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		} else if removed != tc.expected {
+			t.Errorf("expected removing %q to return %v, got %v", tc.collection, tc.expected, removed)
+		}
+	}
+
+	if results, err := s.GetURLRules(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	} else if !reflect.DeepEqual(results, rules[1:]) {
+		t.Errorf("expected rules %v, got %v", rules[1:], results)
+	}
+}
+
+func TestLinkShares(t *testing.T) {
+	s := NewMemoryStorage()
+
+	now := time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC)
+	shares := []models.LinkShare{
+		{URL: "https://frog.tips", User: "U001", Channel: "C001", Timestamp: "1.0", Time: now.Add(-48 * time.Hour)},
+		{URL: "https://frog.tips", User: "U002", Channel: "C002", Timestamp: "2.0", Time: now.Add(-time.Hour)},
+		{URL: "https://example.com", User: "U001", Channel: "C001", Timestamp: "3.0", Time: now},
+	}
+
+	for _, share := range shares {
+		if err := s.AddLinkShare(share); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		opts     storage.GetLinkShareOptions
+		expected []models.LinkShare
+	}{
+		{
+			name:     "all",
+			opts:     storage.GetLinkShareOptions{FindOptions: insertionOrder},
+			expected: shares,
+		},
+		{
+			name:     "by URL, oldest first",
+			opts:     storage.GetLinkShareOptions{FindOptions: storage.FindOptions{SortField: "time", Asc: true}, URL: "https://frog.tips"},
+			expected: shares[:2],
+		},
+		{
+			name:     "since",
+			opts:     storage.GetLinkShareOptions{FindOptions: insertionOrder, URL: "https://frog.tips", Since: now.Add(-24 * time.Hour)},
+			expected: shares[1:2],
+		},
+		{
+			name:     "newest",
+			opts:     storage.GetLinkShareOptions{FindOptions: storage.FindOptions{SortField: "time", Limit: 1}},
+			expected: shares[2:],
+		},
+		{
+			name: "unshared",
+			opts: storage.GetLinkShareOptions{FindOptions: insertionOrder, URL: "https://gorf.example.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := s.GetLinkShares(tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if !reflect.DeepEqual(results, tc.expected) {
+				t.Errorf("expected shares %v got %v", tc.expected, results)
+			}
+		})
+	}
+}
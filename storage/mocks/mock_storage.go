@@ -9,6 +9,7 @@ import (
 	models "github.com/cpu/gorfbot/storage/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStorage is a mock of Storage interface
@@ -34,6 +35,91 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// AddLinkShare mocks base method
+func (m *MockStorage) AddLinkShare(arg0 models.LinkShare) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLinkShare", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLinkShare indicates an expected call of AddLinkShare
+func (mr *MockStorageMockRecorder) AddLinkShare(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLinkShare", reflect.TypeOf((*MockStorage)(nil).AddLinkShare), arg0)
+}
+
+// AddMarkovTransitions mocks base method
+func (m *MockStorage) AddMarkovTransitions(arg0 []models.MarkovTransition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMarkovTransitions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMarkovTransitions indicates an expected call of AddMarkovTransitions
+func (mr *MockStorageMockRecorder) AddMarkovTransitions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMarkovTransitions", reflect.TypeOf((*MockStorage)(nil).AddMarkovTransitions), arg0)
+}
+
+// AddPoll mocks base method
+func (m *MockStorage) AddPoll(arg0 models.Poll) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPoll", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPoll indicates an expected call of AddPoll
+func (mr *MockStorageMockRecorder) AddPoll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPoll", reflect.TypeOf((*MockStorage)(nil).AddPoll), arg0)
+}
+
+// AddQuote mocks base method
+func (m *MockStorage) AddQuote(arg0 models.Quote) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddQuote", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddQuote indicates an expected call of AddQuote
+func (mr *MockStorageMockRecorder) AddQuote(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddQuote", reflect.TypeOf((*MockStorage)(nil).AddQuote), arg0)
+}
+
+// AddReactjiRule mocks base method
+func (m *MockStorage) AddReactjiRule(arg0 models.ReactjiRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReactjiRule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReactjiRule indicates an expected call of AddReactjiRule
+func (mr *MockStorageMockRecorder) AddReactjiRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReactjiRule", reflect.TypeOf((*MockStorage)(nil).AddReactjiRule), arg0)
+}
+
+// AddReminder mocks base method
+func (m *MockStorage) AddReminder(arg0 models.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReminder", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReminder indicates an expected call of AddReminder
+func (mr *MockStorageMockRecorder) AddReminder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReminder", reflect.TypeOf((*MockStorage)(nil).AddReminder), arg0)
+}
+
 // AddTheme mocks base method
 func (m *MockStorage) AddTheme(arg0 models.Theme) error {
 	m.ctrl.T.Helper()
@@ -62,6 +148,95 @@ func (mr *MockStorageMockRecorder) AddTopic(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTopic", reflect.TypeOf((*MockStorage)(nil).AddTopic), arg0)
 }
 
+// AddURLRule mocks base method
+func (m *MockStorage) AddURLRule(arg0 models.URLRule) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddURLRule", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddURLRule indicates an expected call of AddURLRule
+func (mr *MockStorageMockRecorder) AddURLRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddURLRule", reflect.TypeOf((*MockStorage)(nil).AddURLRule), arg0)
+}
+
+// CancelReminder mocks base method
+func (m *MockStorage) CancelReminder(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReminder", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReminder indicates an expected call of CancelReminder
+func (mr *MockStorageMockRecorder) CancelReminder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReminder", reflect.TypeOf((*MockStorage)(nil).CancelReminder), arg0)
+}
+
+// ClosePoll mocks base method
+func (m *MockStorage) ClosePoll(arg0, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClosePoll", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClosePoll indicates an expected call of ClosePoll
+func (mr *MockStorageMockRecorder) ClosePoll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClosePoll", reflect.TypeOf((*MockStorage)(nil).ClosePoll), arg0, arg1)
+}
+
+// DeleteMarkovModel mocks base method
+func (m *MockStorage) DeleteMarkovModel(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMarkovModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMarkovModel indicates an expected call of DeleteMarkovModel
+func (mr *MockStorageMockRecorder) DeleteMarkovModel(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMarkovModel", reflect.TypeOf((*MockStorage)(nil).DeleteMarkovModel), arg0)
+}
+
+// GetDuePolls mocks base method
+func (m *MockStorage) GetDuePolls(arg0 time.Time) ([]models.Poll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDuePolls", arg0)
+	ret0, _ := ret[0].([]models.Poll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDuePolls indicates an expected call of GetDuePolls
+func (mr *MockStorageMockRecorder) GetDuePolls(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDuePolls", reflect.TypeOf((*MockStorage)(nil).GetDuePolls), arg0)
+}
+
+// GetDueReminders mocks base method
+func (m *MockStorage) GetDueReminders(arg0 time.Time) ([]models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueReminders", arg0)
+	ret0, _ := ret[0].([]models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueReminders indicates an expected call of GetDueReminders
+func (mr *MockStorageMockRecorder) GetDueReminders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueReminders", reflect.TypeOf((*MockStorage)(nil).GetDueReminders), arg0)
+}
+
 // GetEmoji mocks base method
 func (m *MockStorage) GetEmoji(arg0 storage.GetEmojiOptions) ([]models.Emoji, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +252,186 @@ func (mr *MockStorageMockRecorder) GetEmoji(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmoji", reflect.TypeOf((*MockStorage)(nil).GetEmoji), arg0)
 }
 
+// GetEmojiHistory mocks base method
+func (m *MockStorage) GetEmojiHistory(arg0 storage.GetEmojiHistoryOptions) ([]models.EmojiUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmojiHistory", arg0)
+	ret0, _ := ret[0].([]models.EmojiUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmojiHistory indicates an expected call of GetEmojiHistory
+func (mr *MockStorageMockRecorder) GetEmojiHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmojiHistory", reflect.TypeOf((*MockStorage)(nil).GetEmojiHistory), arg0)
+}
+
+// GetKarma mocks base method
+func (m *MockStorage) GetKarma(arg0 storage.GetKarmaOptions) ([]models.Karma, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKarma", arg0)
+	ret0, _ := ret[0].([]models.Karma)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKarma indicates an expected call of GetKarma
+func (mr *MockStorageMockRecorder) GetKarma(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKarma", reflect.TypeOf((*MockStorage)(nil).GetKarma), arg0)
+}
+
+// GetLeaderboard mocks base method
+func (m *MockStorage) GetLeaderboard(arg0 storage.GetLeaderboardOptions) ([]models.LeaderboardEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeaderboard", arg0)
+	ret0, _ := ret[0].([]models.LeaderboardEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeaderboard indicates an expected call of GetLeaderboard
+func (mr *MockStorageMockRecorder) GetLeaderboard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeaderboard", reflect.TypeOf((*MockStorage)(nil).GetLeaderboard), arg0)
+}
+
+// GetLinkShares mocks base method
+func (m *MockStorage) GetLinkShares(arg0 storage.GetLinkShareOptions) ([]models.LinkShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkShares", arg0)
+	ret0, _ := ret[0].([]models.LinkShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkShares indicates an expected call of GetLinkShares
+func (mr *MockStorageMockRecorder) GetLinkShares(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkShares", reflect.TypeOf((*MockStorage)(nil).GetLinkShares), arg0)
+}
+
+// GetMarkovOptOut mocks base method
+func (m *MockStorage) GetMarkovOptOut(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMarkovOptOut", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMarkovOptOut indicates an expected call of GetMarkovOptOut
+func (mr *MockStorageMockRecorder) GetMarkovOptOut(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMarkovOptOut", reflect.TypeOf((*MockStorage)(nil).GetMarkovOptOut), arg0)
+}
+
+// GetMarkovTransitions mocks base method
+func (m *MockStorage) GetMarkovTransitions(arg0 string, arg1 []string) ([]models.MarkovTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMarkovTransitions", arg0, arg1)
+	ret0, _ := ret[0].([]models.MarkovTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMarkovTransitions indicates an expected call of GetMarkovTransitions
+func (mr *MockStorageMockRecorder) GetMarkovTransitions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMarkovTransitions", reflect.TypeOf((*MockStorage)(nil).GetMarkovTransitions), arg0, arg1)
+}
+
+// GetPolls mocks base method
+func (m *MockStorage) GetPolls(arg0 storage.GetPollOptions) ([]models.Poll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolls", arg0)
+	ret0, _ := ret[0].([]models.Poll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolls indicates an expected call of GetPolls
+func (mr *MockStorageMockRecorder) GetPolls(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolls", reflect.TypeOf((*MockStorage)(nil).GetPolls), arg0)
+}
+
+// GetQuotes mocks base method
+func (m *MockStorage) GetQuotes(arg0 storage.GetQuoteOptions) ([]models.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotes", arg0)
+	ret0, _ := ret[0].([]models.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotes indicates an expected call of GetQuotes
+func (mr *MockStorageMockRecorder) GetQuotes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotes", reflect.TypeOf((*MockStorage)(nil).GetQuotes), arg0)
+}
+
+// GetReactedMessages mocks base method
+func (m *MockStorage) GetReactedMessages(arg0 storage.GetReactedMessageOptions) ([]models.ReactedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactedMessages", arg0)
+	ret0, _ := ret[0].([]models.ReactedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactedMessages indicates an expected call of GetReactedMessages
+func (mr *MockStorageMockRecorder) GetReactedMessages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactedMessages", reflect.TypeOf((*MockStorage)(nil).GetReactedMessages), arg0)
+}
+
+// GetReactjiRules mocks base method
+func (m *MockStorage) GetReactjiRules() ([]models.ReactjiRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactjiRules")
+	ret0, _ := ret[0].([]models.ReactjiRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactjiRules indicates an expected call of GetReactjiRules
+func (mr *MockStorageMockRecorder) GetReactjiRules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactjiRules", reflect.TypeOf((*MockStorage)(nil).GetReactjiRules))
+}
+
+// GetReminders mocks base method
+func (m *MockStorage) GetReminders(arg0 storage.GetReminderOptions) ([]models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminders", arg0)
+	ret0, _ := ret[0].([]models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminders indicates an expected call of GetReminders
+func (mr *MockStorageMockRecorder) GetReminders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminders", reflect.TypeOf((*MockStorage)(nil).GetReminders), arg0)
+}
+
+// GetScheduledRuns mocks base method
+func (m *MockStorage) GetScheduledRuns() ([]models.ScheduledRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledRuns")
+	ret0, _ := ret[0].([]models.ScheduledRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledRuns indicates an expected call of GetScheduledRuns
+func (mr *MockStorageMockRecorder) GetScheduledRuns() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledRuns", reflect.TypeOf((*MockStorage)(nil).GetScheduledRuns))
+}
+
 // GetThemes mocks base method
 func (m *MockStorage) GetThemes(arg0 storage.GetThemeOptions) ([]models.Theme, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +462,124 @@ func (mr *MockStorageMockRecorder) GetTopics(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopics", reflect.TypeOf((*MockStorage)(nil).GetTopics), arg0)
 }
 
+// GetURLCounts mocks base method
+func (m *MockStorage) GetURLCounts(arg0 string, arg1 storage.GetURLCountOptions) ([]models.URLCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLCounts", arg0, arg1)
+	ret0, _ := ret[0].([]models.URLCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLCounts indicates an expected call of GetURLCounts
+func (mr *MockStorageMockRecorder) GetURLCounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLCounts", reflect.TypeOf((*MockStorage)(nil).GetURLCounts), arg0, arg1)
+}
+
+// GetURLRules mocks base method
+func (m *MockStorage) GetURLRules() ([]models.URLRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLRules")
+	ret0, _ := ret[0].([]models.URLRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLRules indicates an expected call of GetURLRules
+func (mr *MockStorageMockRecorder) GetURLRules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLRules", reflect.TypeOf((*MockStorage)(nil).GetURLRules))
+}
+
+// MarkStarboardPosted mocks base method
+func (m *MockStorage) MarkStarboardPosted(arg0 models.StarboardMessage) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkStarboardPosted", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkStarboardPosted indicates an expected call of MarkStarboardPosted
+func (mr *MockStorageMockRecorder) MarkStarboardPosted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkStarboardPosted", reflect.TypeOf((*MockStorage)(nil).MarkStarboardPosted), arg0)
+}
+
+// Ping mocks base method
+func (m *MockStorage) Ping() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockStorageMockRecorder) Ping() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorage)(nil).Ping))
+}
+
+// RemoveReactjiRule mocks base method
+func (m *MockStorage) RemoveReactjiRule(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReactjiRule", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveReactjiRule indicates an expected call of RemoveReactjiRule
+func (mr *MockStorageMockRecorder) RemoveReactjiRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReactjiRule", reflect.TypeOf((*MockStorage)(nil).RemoveReactjiRule), arg0)
+}
+
+// RemoveURLRule mocks base method
+func (m *MockStorage) RemoveURLRule(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveURLRule", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveURLRule indicates an expected call of RemoveURLRule
+func (mr *MockStorageMockRecorder) RemoveURLRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveURLRule", reflect.TypeOf((*MockStorage)(nil).RemoveURLRule), arg0)
+}
+
+// SetMarkovOptOut mocks base method
+func (m *MockStorage) SetMarkovOptOut(arg0 string, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMarkovOptOut", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMarkovOptOut indicates an expected call of SetMarkovOptOut
+func (mr *MockStorageMockRecorder) SetMarkovOptOut(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMarkovOptOut", reflect.TypeOf((*MockStorage)(nil).SetMarkovOptOut), arg0, arg1)
+}
+
+// UpdatePollVote mocks base method
+func (m *MockStorage) UpdatePollVote(arg0, arg1 string, arg2 models.PollVote, arg3 bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePollVote", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePollVote indicates an expected call of UpdatePollVote
+func (mr *MockStorageMockRecorder) UpdatePollVote(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePollVote", reflect.TypeOf((*MockStorage)(nil).UpdatePollVote), arg0, arg1, arg2, arg3)
+}
+
 // UpsertEmojiCount mocks base method
 func (m *MockStorage) UpsertEmojiCount(arg0 models.Emoji, arg1 bool) (models.Emoji, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +595,80 @@ func (mr *MockStorageMockRecorder) UpsertEmojiCount(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmojiCount", reflect.TypeOf((*MockStorage)(nil).UpsertEmojiCount), arg0, arg1)
 }
 
+// UpsertEmojiUsage mocks base method
+func (m *MockStorage) UpsertEmojiUsage(arg0 models.EmojiUsage, arg1 bool) (models.EmojiUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEmojiUsage", arg0, arg1)
+	ret0, _ := ret[0].(models.EmojiUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEmojiUsage indicates an expected call of UpsertEmojiUsage
+func (mr *MockStorageMockRecorder) UpsertEmojiUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmojiUsage", reflect.TypeOf((*MockStorage)(nil).UpsertEmojiUsage), arg0, arg1)
+}
+
+// UpsertKarma mocks base method
+func (m *MockStorage) UpsertKarma(arg0 models.Karma, arg1 bool) (models.Karma, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertKarma", arg0, arg1)
+	ret0, _ := ret[0].(models.Karma)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertKarma indicates an expected call of UpsertKarma
+func (mr *MockStorageMockRecorder) UpsertKarma(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertKarma", reflect.TypeOf((*MockStorage)(nil).UpsertKarma), arg0, arg1)
+}
+
+// UpsertReactedMessage mocks base method
+func (m *MockStorage) UpsertReactedMessage(arg0 models.ReactedMessage, arg1 bool) (models.ReactedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertReactedMessage", arg0, arg1)
+	ret0, _ := ret[0].(models.ReactedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertReactedMessage indicates an expected call of UpsertReactedMessage
+func (mr *MockStorageMockRecorder) UpsertReactedMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertReactedMessage", reflect.TypeOf((*MockStorage)(nil).UpsertReactedMessage), arg0, arg1)
+}
+
+// UpsertScheduledRun mocks base method
+func (m *MockStorage) UpsertScheduledRun(arg0 models.ScheduledRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScheduledRun", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertScheduledRun indicates an expected call of UpsertScheduledRun
+func (mr *MockStorageMockRecorder) UpsertScheduledRun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScheduledRun", reflect.TypeOf((*MockStorage)(nil).UpsertScheduledRun), arg0)
+}
+
+// UpsertStarboardCount mocks base method
+func (m *MockStorage) UpsertStarboardCount(arg0 models.StarboardMessage, arg1 bool) (models.StarboardMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertStarboardCount", arg0, arg1)
+	ret0, _ := ret[0].(models.StarboardMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertStarboardCount indicates an expected call of UpsertStarboardCount
+func (mr *MockStorageMockRecorder) UpsertStarboardCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertStarboardCount", reflect.TypeOf((*MockStorage)(nil).UpsertStarboardCount), arg0, arg1)
+}
+
 // UpsertURLCount mocks base method
 func (m *MockStorage) UpsertURLCount(arg0 string, arg1 models.URLCount) (models.URLCount, error) {
 	m.ctrl.T.Helper()
@@ -15,11 +15,20 @@ type Emoji struct {
 	// usage and not usage in messages. When Reaction is false the model
 	// represents a count of emoji usage in regular messages and not reactions.
 	Reaction bool
+	// Received is true if the model represents a count of reactions **received**
+	// by the user (i.e. reactions other users added to the user's messages)
+	// instead of reactions the user added. Received implies Reaction.
+	Received bool
 }
 
 // String returns a simple representation of the model mostly useful for
 // debugging.
 func (e Emoji) String() string {
+	if e.Received {
+		return fmt.Sprintf("User %q has received reactji %q %d times",
+			e.User, e.Emoji, e.Count)
+	}
+
 	if e.Reaction {
 		return fmt.Sprintf("User %q has reacted with emoji %q %d times",
 			e.User, e.Emoji, e.Count)
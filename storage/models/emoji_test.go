@@ -27,6 +27,17 @@ func TestEmojiString(t *testing.T) {
 			},
 			expected: `User "U000" has reacted with emoji ":wave:" 10 times`,
 		},
+		{
+			name: "emoji, received reaction",
+			e: Emoji{
+				User:     "U000",
+				Emoji:    ":wave:",
+				Count:    10,
+				Reaction: true,
+				Received: true,
+			},
+			expected: `User "U000" has received reactji ":wave:" 10 times`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
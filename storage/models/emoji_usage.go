@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmojiUsage is a model for storing a count of emoji/reaction usage by a user
+// ID in a channel on a single day. Unlike Emoji, which holds a lifetime count,
+// EmojiUsage buckets can be filtered by date and channel.
+type EmojiUsage struct {
+	// User ID of the user that used the emoji/reaction.
+	User string
+	// Emoji is the emoji that was used **with** delimiters (e.g. ":wave:" not
+	// "wave")
+	Emoji string
+	// Channel is the ID of the channel the emoji/reaction was used in (note: not
+	// the friendly channel name).
+	Channel string
+	// Day is the start (midnight UTC) of the day the emoji/reaction was used on.
+	// See UsageDay.
+	Day time.Time
+	// Count is the number of times the emoji/reaction was used by the user in the
+	// channel on the day.
+	Count int
+	// Reaction is true if the model represents **reaction** emoji usage and not
+	// usage in messages.
+	Reaction bool
+}
+
+// UsageDay returns the start (midnight UTC) of the day that t falls on, for use
+// as an EmojiUsage Day.
+func UsageDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (e EmojiUsage) String() string {
+	verb := "used emoji"
+	if e.Reaction {
+		verb = "reacted with emoji"
+	}
+
+	return fmt.Sprintf("User %q %s %q in channel %q %d times on %s",
+		e.User, verb, e.Emoji, e.Channel, e.Count, e.Day.Format("2006-01-02"))
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageDay(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	// 11pm on the 1st in EST is 4am on the 2nd in UTC.
+	input := time.Date(2021, time.March, 1, 23, 0, 0, 0, est)
+	expected := time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	if actual := UsageDay(input); !actual.Equal(expected) || actual.Location() != time.UTC {
+		t.Errorf("expected usage day of %v to be %v, was %v", input, expected, actual)
+	}
+}
+
+func TestEmojiUsageString(t *testing.T) {
+	day := time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		e        EmojiUsage
+		expected string
+	}{
+		{
+			name: "emoji, not reaction",
+			e: EmojiUsage{
+				User:    "U000",
+				Emoji:   ":wave:",
+				Channel: "C000",
+				Day:     day,
+				Count:   3,
+			},
+			expected: `User "U000" used emoji ":wave:" in channel "C000" 3 times on 2021-03-02`,
+		},
+		{
+			name: "emoji, reaction",
+			e: EmojiUsage{
+				User:     "U000",
+				Emoji:    ":wave:",
+				Channel:  "C000",
+				Day:      day,
+				Count:    3,
+				Reaction: true,
+			},
+			expected: `User "U000" reacted with emoji ":wave:" in channel "C000" 3 times on 2021-03-02`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.e.String(); actual != tc.expected {
+				t.Errorf("expected emoji usage %v to have string form %q, was %q",
+					tc.e, tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "fmt"
+
+// Karma is a model for the karma (`thing++` minus `thing--`) given to a thing
+// or a user.
+type Karma struct {
+	// Thing is the lowercased word or phrase given karma (e.g. "frogs" or "the
+	// swamp"), or the ID of the user given karma if User is true (note: not the
+	// friendly user name).
+	Thing string
+	// User is true if Thing is a user ID.
+	User bool
+	// Count is the total karma given to Thing.
+	Count int
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (k Karma) String() string {
+	if k.User {
+		return fmt.Sprintf("User %q has %d karma", k.Thing, k.Count)
+	}
+
+	return fmt.Sprintf("%q has %d karma", k.Thing, k.Count)
+}
@@ -0,0 +1,20 @@
+package models
+
+import "fmt"
+
+// LeaderboardEntry is a model for one row of an emoji or reactji leaderboard.
+// Depending on how the leaderboard is grouped the entry counts the usage of an
+// emoji by all users, or the usage of emoji by a single user.
+type LeaderboardEntry struct {
+	// Key is the emoji (with delimiters for emoji used in messages, without for
+	// reactji) or the user ID (note: not the friendly username) being counted.
+	Key string
+	// Count is the total usage counted for the key.
+	Count int
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (e LeaderboardEntry) String() string {
+	return fmt.Sprintf("%q used %d times", e.Key, e.Count)
+}
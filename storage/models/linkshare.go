@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// LinkShare is a model for a link shared in a message.
+type LinkShare struct {
+	// URL is the canonical form of the shared link.
+	URL string
+	// User is the ID of the user that shared the link (note: not the friendly
+	// user name).
+	User string
+	// Channel is the ID of the channel the link was shared in.
+	Channel string
+	// Public is true if the channel the link was shared in is a public channel.
+	Public bool
+	// Timestamp is the raw slack timestamp of the message that shared the link.
+	Timestamp string
+	// Time is when the link was shared.
+	Time time.Time
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (l LinkShare) String() string {
+	return fmt.Sprintf("%s - channel %s user %s shared %q", l.Timestamp, l.Channel, l.User, l.URL)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMarkovOrder is the default number of previous words used to choose
+// each next word of generated text.
+const DefaultMarkovOrder = 2
+
+// MarkovTransition is a model for the number of times a word followed a
+// prefix of words in the messages learned by a Markov model.
+type MarkovTransition struct {
+	// Model is the name of the Markov model the transition belongs to (e.g.
+	// MarkovUserModel("U1234")).
+	Model string
+	// Prefix is the words that came before Next. Empty words are before the
+	// start of a message.
+	Prefix []string
+	// Next is the word that followed the prefix, or "" if the message ended.
+	Next string
+	// Count is the number of times Next followed the prefix.
+	Count int
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (t MarkovTransition) String() string {
+	return fmt.Sprintf("%s: %q -> %q (%d)", t.Model, strings.Join(t.Prefix, " "), t.Next, t.Count)
+}
+
+// MarkovUserModel returns the name of the Markov model for the messages of the
+// user with the given ID.
+func MarkovUserModel(id string) string {
+	return "user:" + id
+}
+
+// MarkovChannelModel returns the name of the Markov model for the messages in
+// the channel with the given ID.
+func MarkovChannelModel(id string) string {
+	return "channel:" + id
+}
+
+// MarkovTransitions returns the transitions for the named model learned from
+// the words of a message, with the given number of words in each prefix.
+// Repeated transitions are counted together.
+func MarkovTransitions(model string, words []string, order int) []MarkovTransition {
+	var transitions []MarkovTransition
+
+	prefix := make([]string, order)
+
+	for i := 0; i <= len(words); i++ {
+		var next string
+		if i < len(words) {
+			next = words[i]
+		}
+
+		found := false
+
+		for j := range transitions {
+			if transitions[j].Next == next && equalWords(transitions[j].Prefix, prefix) {
+				transitions[j].Count++
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			transitions = append(transitions, MarkovTransition{
+				Model:  model,
+				Prefix: prefix,
+				Next:   next,
+				Count:  1,
+			})
+		}
+
+		prefix = append(append([]string(nil), prefix[1:]...), next)
+	}
+
+	return transitions
+}
+
+// equalWords returns true if a and b have the same words in the same order.
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkovTransitions(t *testing.T) {
+	words := []string{"gorf", "is", "gorf", "is", "gorf"}
+
+	expected := []MarkovTransition{
+		{Model: "m", Prefix: []string{"", ""}, Next: "gorf", Count: 1},
+		{Model: "m", Prefix: []string{"", "gorf"}, Next: "is", Count: 1},
+		{Model: "m", Prefix: []string{"gorf", "is"}, Next: "gorf", Count: 2},
+		{Model: "m", Prefix: []string{"is", "gorf"}, Next: "is", Count: 1},
+		{Model: "m", Prefix: []string{"is", "gorf"}, Next: "", Count: 1},
+	}
+
+	if transitions := MarkovTransitions("m", words, 2); !reflect.DeepEqual(transitions, expected) {
+		t.Errorf("expected transitions %v, got %v", expected, transitions)
+	}
+
+	expected = []MarkovTransition{
+		{Model: "m", Prefix: []string{""}, Next: "", Count: 1},
+	}
+
+	if transitions := MarkovTransitions("m", nil, 1); !reflect.DeepEqual(transitions, expected) {
+		t.Errorf("expected transitions %v, got %v", expected, transitions)
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PollVote is a vote by a user for one of the options of a poll.
+type PollVote struct {
+	// User is the ID of the user that voted.
+	User string
+	// Option is the index of the option voted for.
+	Option int
+}
+
+// Poll is a model for a poll posted by the bot that users vote in by reacting
+// to it.
+type Poll struct {
+	// Channel is the ID of the channel the poll was posted in.
+	Channel string
+	// Timestamp is the raw slack timestamp of the poll message. Together with
+	// the Channel it uniquely identifies the poll.
+	Timestamp string
+	// Creator is the user ID of the user that created the poll.
+	Creator string
+	// Question is the question being polled.
+	Question string
+	// Options are the answers that can be voted for.
+	Options []string
+	// Votes are the votes for the options. A user may vote for more than one
+	// option.
+	Votes []PollVote
+	// Deadline is when the poll closes automatically. Zero if the poll only
+	// closes when its creator closes it.
+	Deadline time.Time
+	// Closed is true once the poll is closed and no longer accepting votes.
+	Closed bool
+}
+
+// Tally returns the number of votes for each of the poll's options, in order.
+// Votes for options the poll doesn't have are ignored.
+func (p Poll) Tally() []int {
+	tally := make([]int, len(p.Options))
+
+	for _, vote := range p.Votes {
+		if vote.Option >= 0 && vote.Option < len(tally) {
+			tally[vote.Option]++
+		}
+	}
+
+	return tally
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (p Poll) String() string {
+	return fmt.Sprintf("%s - channel %s poll by %s %q %q (%d votes, closed: %v)",
+		p.Timestamp, p.Channel, p.Creator, p.Question, p.Options, len(p.Votes), p.Closed)
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPollTally(t *testing.T) {
+	poll := Poll{
+		Options: []string{"tacos", "pho", "pizza"},
+		Votes: []PollVote{
+			{User: "U001", Option: 1},
+			{User: "U002", Option: 1},
+			{User: "U002", Option: 0},
+			// Votes for options that don't exist aren't counted.
+			{User: "U003", Option: 3},
+			{User: "U003", Option: -1},
+		},
+	}
+
+	if tally, expected := poll.Tally(), []int{1, 2, 0}; !reflect.DeepEqual(tally, expected) {
+		t.Errorf("expected tally %v, got %v", expected, tally)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// Quote is a model for a memorable message saved to the quote database.
+type Quote struct {
+	// Channel is the ID of the channel the quoted message was said in.
+	Channel string
+	// Timestamp is the raw slack timestamp of the quoted message. Together with
+	// the Channel it uniquely identifies the quoted message.
+	Timestamp string
+	// Author is the user ID of the user that said the quoted message.
+	Author string
+	// Text is the text of the quoted message.
+	Text string
+	// AddedBy is the user ID of the user that saved the quote.
+	AddedBy string
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (q Quote) String() string {
+	return fmt.Sprintf("%s - channel %s user %s said %q (added by %s)",
+		q.Timestamp, q.Channel, q.Author, q.Text, q.AddedBy)
+}
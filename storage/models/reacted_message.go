@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// ReactedMessage is a model for storing the number of reactions a message has
+// received, for ranking the most reacted messages.
+type ReactedMessage struct {
+	// Channel is the ID of the channel the message was posted in (note: not the
+	// friendly channel name).
+	Channel string
+	// Timestamp is the slack timestamp of the message.
+	Timestamp string
+	// User is the ID of the author of the message (note: not the friendly
+	// username).
+	User string
+	// Count is the number of reactions the message has received.
+	Count int
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (m ReactedMessage) String() string {
+	return fmt.Sprintf("message %s in channel %q by user %q has %d reactions",
+		m.Timestamp, m.Channel, m.User, m.Count)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReactjiRule is a model for a rule that reacts to (and optionally replies to)
+// messages matching a trigger.
+type ReactjiRule struct {
+	// ID is a short identifier for the rule, used to remove it.
+	ID string
+	// Trigger is a word, a phrase or (if Regex is true) a regular expression
+	// that messages must match.
+	Trigger string
+	// Regex is true if the Trigger is a regular expression.
+	Regex bool
+	// CaseSensitive is true if the Trigger must match the case of messages.
+	CaseSensitive bool
+	// Reactions are the reactions (no ":" delimiters) added to matching
+	// messages.
+	Reactions []string
+	// Reply is text to post in reply to matching messages, if not empty.
+	Reply string
+	// Chance is the probability (between 0 and 1) of the rule firing for a
+	// matching message. The rule always fires if it is 0.
+	Chance float64
+	// Channels are the names (no "#" prefix) of the channels the rule applies
+	// to. It applies to every channel if empty.
+	Channels []string
+	// Cooldown is the minimum time between the rule firing.
+	Cooldown time.Duration
+	// Creator is the ID of the user that created the rule (note: not the
+	// friendly user name).
+	Creator string
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (r ReactjiRule) String() string {
+	return fmt.Sprintf("reactji rule %s from %q for %q: %v %q",
+		r.ID, r.Creator, r.Trigger, r.Reactions, r.Reply)
+}
+
+// Matcher returns a regular expression for the rule's Trigger. Words and
+// phrases only match whole words, with any whitespace between them.
+func (r ReactjiRule) Matcher() (*regexp.Regexp, error) {
+	expr := r.Trigger
+
+	if !r.Regex {
+		words := strings.Fields(r.Trigger)
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+
+		expr = `(?:^|\W)` + strings.Join(words, `\s+`) + `(?:\W|$)`
+	}
+
+	if !r.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	matcher, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("bad reactji rule trigger %q: %w", r.Trigger, err)
+	}
+
+	return matcher, nil
+}
+
+// AppliesTo returns true if the rule applies to the named channel.
+func (r ReactjiRule) AppliesTo(channel string) bool {
+	if len(r.Channels) == 0 {
+		return true
+	}
+
+	for _, c := range r.Channels {
+		if strings.EqualFold(strings.TrimPrefix(c, "#"), channel) {
+			return true
+		}
+	}
+
+	return false
+}
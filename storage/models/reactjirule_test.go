@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestReactjiRuleMatcher(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     ReactjiRule
+		text     string
+		expected bool
+	}{
+		{name: "word", rule: ReactjiRule{Trigger: "turkey"}, text: "Turkey time!", expected: true},
+		{name: "part of a word", rule: ReactjiRule{Trigger: "turkey"}, text: "turkeys", expected: false},
+		{name: "phrase", rule: ReactjiRule{Trigger: "good  morning"}, text: "well good\nmorning", expected: true},
+		{name: "phrase words", rule: ReactjiRule{Trigger: "good morning"}, text: "good day morning", expected: false},
+		{name: "punctuation", rule: ReactjiRule{Trigger: "c++"}, text: "I like c++.", expected: true},
+		{name: "case", rule: ReactjiRule{Trigger: "Gorf", CaseSensitive: true}, text: "gorf", expected: false},
+		{name: "regex", rule: ReactjiRule{Trigger: `^ri+bbit$`, Regex: true}, text: "RIIIBBIT", expected: true},
+		{
+			name:     "case regex",
+			rule:     ReactjiRule{Trigger: `^ri+bbit$`, Regex: true, CaseSensitive: true},
+			text:     "RIIIBBIT",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := tc.rule.Matcher()
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if matched := matcher.MatchString(tc.text); matched != tc.expected {
+				t.Errorf("expected %q matching %q to be %v, got %v", matcher, tc.text, tc.expected, matched)
+			}
+		})
+	}
+
+	if _, err := (ReactjiRule{Trigger: "(", Regex: true}).Matcher(); err == nil {
+		t.Errorf("expected err from bad regex, got nil")
+	}
+}
+
+func TestReactjiRuleAppliesTo(t *testing.T) {
+	if !(ReactjiRule{}).AppliesTo("general") {
+		t.Errorf("expected rule without channels to apply to every channel")
+	}
+
+	rule := ReactjiRule{Channels: []string{"#random", "Frogs"}}
+
+	for channel, expected := range map[string]bool{"random": true, "frogs": true, "general": false} {
+		if applies := rule.AppliesTo(channel); applies != expected {
+			t.Errorf("expected rule applying to %q to be %v, got %v", channel, expected, applies)
+		}
+	}
+}
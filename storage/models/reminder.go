@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reminder is a model for a message to post in a channel at a later time.
+type Reminder struct {
+	// ID is a short random identifier for the reminder, used to cancel it.
+	ID string
+	// Creator is the ID of the user that created the reminder (note: not the
+	// friendly user name).
+	Creator string
+	// Channel is the ID of the channel to post the reminder in (note: not the
+	// friendly channel name).
+	Channel string
+	// Self is true if the reminder is for the Creator, rather than the channel.
+	Self bool
+	// Text is what to remind about.
+	Text string
+	// Due is when to post the reminder.
+	Due time.Time
+	// Created is when the reminder was created.
+	Created time.Time
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (m Reminder) String() string {
+	return fmt.Sprintf("reminder %s from %q for channel %q at %s: %q",
+		m.ID, m.Creator, m.Channel, m.Due, m.Text)
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduledRun is a model for when a scheduled command last ran for a
+// schedule. It is used to notice runs that were missed while the bot wasn't
+// running.
+type ScheduledRun struct {
+	// Command is the name of the scheduled command.
+	Command string
+	// Channel is the name (not ID) of the channel the schedule posts in.
+	Channel string
+	// LastRun is the time the last run was scheduled for.
+	LastRun time.Time
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (m ScheduledRun) String() string {
+	return fmt.Sprintf("scheduled command %q for channel %q last ran at %s",
+		m.Command, m.Channel, m.LastRun)
+}
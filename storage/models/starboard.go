@@ -0,0 +1,26 @@
+package models
+
+import "fmt"
+
+// StarboardMessage is a model for tallying the reactions of a single emoji a
+// message has received, and whether it has been reposted to the starboard.
+type StarboardMessage struct {
+	// Channel is the ID of the channel the message was posted in (note: not the
+	// friendly channel name).
+	Channel string
+	// Timestamp is the slack timestamp of the message.
+	Timestamp string
+	// Emoji is the reaction being tallied (no ":" delimiters).
+	Emoji string
+	// Count is the number of Emoji reactions the message has received.
+	Count int
+	// Posted is true once the message has been reposted to the starboard.
+	Posted bool
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (m StarboardMessage) String() string {
+	return fmt.Sprintf("message %s in channel %q has %d %q reactions (posted: %v)",
+		m.Timestamp, m.Channel, m.Count, m.Emoji, m.Posted)
+}
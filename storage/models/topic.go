@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Topic is a model for holding topic history information for a channel.
 type Topic struct {
@@ -21,3 +24,9 @@ func (t Topic) String() string {
 	return fmt.Sprintf("on %s channel %s was updated by %s to have topic %q",
 		t.Date, t.Channel, t.Creator, t.Topic)
 }
+
+// TopicDate returns the slack timestamp of the given time in the form of a
+// Topic Date so that it can be compared with the Date of stored topics.
+func TopicDate(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
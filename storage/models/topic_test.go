@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/storage/models"
 )
@@ -22,3 +23,12 @@ func TestTopic(t *testing.T) {
 			topic, expectedStrForm, topic.String())
 	}
 }
+
+func TestTopicDate(t *testing.T) {
+	date := time.Date(2021, time.March, 1, 0, 0, 0, 1500, time.UTC)
+	expected := "1614556800.000001"
+
+	if actual := models.TopicDate(date); actual != expected {
+		t.Errorf("expected TopicDate(%v) to be %q, got %q", date, expected, actual)
+	}
+}
@@ -1,10 +1,27 @@
 package models
 
+import "time"
+
 // URLCount is a simple model for representing how many times a given URL has
-// been seen.
+// been seen, and when and where it was seen first.
 type URLCount struct {
 	// URL is the string representation of the URL.
 	URL string
 	// Occurrences is a count of how many times the URL has been seen.
 	Occurrences int
+	// FirstUser is the ID of the user that first shared the URL (note: not the
+	// friendly user name). It is empty for URLs counted before it was recorded.
+	FirstUser string
+	// FirstChannel is the ID of the channel the URL was first shared in.
+	FirstChannel string
+	// FirstPublic is true if the channel the URL was first shared in is a
+	// public channel.
+	FirstPublic bool
+	// FirstTimestamp is the Slack timestamp of the message that first shared
+	// the URL.
+	FirstTimestamp string
+	// FirstSeen is when the URL was first shared.
+	FirstSeen time.Time
+	// LastSeen is when the URL was most recently shared.
+	LastSeen time.Time
 }
@@ -0,0 +1,31 @@
+package models
+
+import "fmt"
+
+// URLRule is a model for a host (and optional path) pattern for matching URLs
+// whose occurrences are tracked in a collection.
+type URLRule struct {
+	// HostPattern is a regex that must match on the URL's host component.
+	HostPattern string
+	// PathPattern is an optional regex that must match on the URL's path
+	// component.
+	PathPattern string
+	// Collection is the name of the storage collection the occurrences of
+	// matching URLs are tracked in. It identifies the rule.
+	Collection string
+	// FirstMsg is a message to post in response to matching URLs that have
+	// never been seen before.
+	FirstMsg string
+	// Reactji is a list of reactions (no ":" delimiters) to add to messages with
+	// matching URLs.
+	Reactji []string
+	// Creator is the ID of the user that added the rule (note: not the friendly
+	// user name). It is empty for rules from the config.
+	Creator string
+}
+
+// String returns a simple representation of the model mostly useful for
+// debugging.
+func (r URLRule) String() string {
+	return fmt.Sprintf("URL rule %q from %q for host %q path %q", r.Collection, r.Creator, r.HostPattern, r.PathPattern)
+}
@@ -60,11 +60,57 @@ func NewMongoStorage(log *logrus.Logger, c *config.Config) (storage.Storage, err
 		return nil, fmt.Errorf("mono client ping err: %w", err)
 	}
 
-	return mongoStorage{
+	m := mongoStorage{
 		log:    log,
 		config: c.MongoConf,
 		client: client,
-	}, nil
+	}
+
+	if err := m.createIndexes(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// createIndexes creates the indexes required by queries, if they don't already
+// exist.
+func (m mongoStorage) createIndexes() error {
+	// GetTopics searches topic text with a $text query, which requires a text
+	// index.
+	_, err := m.topicsCollection().Indexes().CreateOne(m.writeCtx(), mongo.IndexModel{
+		Keys: bson.D{{Key: "topic", Value: "text"}},
+	})
+	if err != nil {
+		return fmt.Errorf("mongo client topics text index err: %w", err)
+	}
+
+	// GetQuotes searches quote text the same way.
+	_, err = m.quotesCollection().Indexes().CreateOne(m.writeCtx(), mongo.IndexModel{
+		Keys: bson.D{{Key: "text", Value: "text"}},
+	})
+	if err != nil {
+		return fmt.Errorf("mongo client quotes text index err: %w", err)
+	}
+
+	// GetMarkovTransitions looks up transitions by model and prefix for every
+	// word of generated text.
+	_, err = m.markovCollection().Indexes().CreateOne(m.writeCtx(), mongo.IndexModel{
+		Keys: bson.D{{Key: "model", Value: 1}, {Key: "prefix", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("mongo client markov index err: %w", err)
+	}
+
+	// GetLinkShares looks up the recent shares of every link shared.
+	_, err = m.linkShareCollection().Indexes().CreateOne(m.writeCtx(), mongo.IndexModel{
+		Keys: bson.D{{Key: "url", Value: 1}, {Key: "time", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("mongo client link share index err: %w", err)
+	}
+
+	return nil
 }
 
 // findOptions creates Mongo FindOptions from the gorfbot specific
@@ -108,21 +154,46 @@ func (m mongoStorage) topicsCollection() *mongo.Collection {
 	return m.collection("topics")
 }
 
+// topicsFilter returns a filter for topic documents matching the options. The
+// search uses the topics collection text index.
+func topicsFilter(opts storage.GetTopicOptions) bson.D {
+	filter := bson.D{}
+	if opts.Channel != "" {
+		filter = append(filter, bson.E{Key: "channel", Value: opts.Channel})
+	}
+
+	if opts.Creator != "" {
+		filter = append(filter, bson.E{Key: "creator", Value: opts.Creator})
+	}
+
+	date := bson.D{}
+	if !opts.Since.IsZero() {
+		date = append(date, bson.E{Key: "$gte", Value: models.TopicDate(opts.Since)})
+	}
+
+	if !opts.Until.IsZero() {
+		date = append(date, bson.E{Key: "$lt", Value: models.TopicDate(opts.Until)})
+	}
+
+	if len(date) > 0 {
+		filter = append(filter, bson.E{Key: "date", Value: date})
+	}
+
+	if opts.Search != "" {
+		filter = append(filter, bson.E{Key: "$text", Value: bson.D{{Key: "$search", Value: opts.Search}}})
+	}
+
+	return filter
+}
+
 // GetTopics reads Topic models from the mongo topics collection.
 func (m mongoStorage) GetTopics(opts storage.GetTopicOptions) ([]models.Topic, error) {
 	ctx := m.readCtx()
 	collection := m.topicsCollection()
 
-	var filter interface{}
-	if opts.Channel != "" {
-		filter = bson.M{"channel": opts.Channel}
-	} else {
-		filter = bson.D{}
-	}
-
 	findOpts := findOptions(opts.FindOptions)
 
-	cursor, err := collection.Find(ctx, filter, findOpts)
+	cursor, err := collection.Find(ctx, topicsFilter(opts), findOpts)
 	if err != nil {
 		return nil, fmt.Errorf("mongo client topics collection find err: %w", err)
 	}
@@ -149,15 +220,24 @@ func (m mongoStorage) GetTopics(opts storage.GetTopicOptions) ([]models.Topic, e
 }
 
 // GetEmoji reads Emoji models from the mongo emoji collection, or reactji
-// collection, as appropriate.
+// collection, as appropriate. If the options are windowed the models are
+// aggregated from the matching daily emoji usage buckets instead.
 func (m mongoStorage) GetEmoji(opts storage.GetEmojiOptions) ([]models.Emoji, error) {
+	if opts.Windowed() && opts.Received {
+		return nil, storage.ErrWindowedReceived
+	} else if opts.Windowed() {
+		return m.getWindowedEmoji(opts)
+	}
+
 	ctx := m.readCtx()
 
 	// Default to finding data from the emoji collection for emoji in messages.
 	collection := m.emojiCollection()
 	// But if the reactji is requested in the opts, use the reaction collection
 	// instead (legacy reasons)...
-	if opts.Reaction {
+	if opts.Received {
+		collection = m.receivedReactionCollection()
+	} else if opts.Reaction {
 		collection = m.reactionCollection()
 	}
 
@@ -188,7 +268,8 @@ func (m mongoStorage) GetEmoji(opts storage.GetEmojiOptions) ([]models.Emoji, er
 
 		// Because legacy data doesn't store the reaction field set it to match the
 		// lookup opts.
-		emoji.Reaction = opts.Reaction
+		emoji.Reaction = opts.Reaction || opts.Received
+		emoji.Received = opts.Received
 		results = append(results, emoji)
 	}
 
@@ -242,6 +323,12 @@ func (m mongoStorage) reactionCollection() *mongo.Collection {
 	return m.collection("panoptireactjis")
 }
 
+// receivedReactionCollection returns the collection for counts of reaction
+// emoji received.
+func (m mongoStorage) receivedReactionCollection() *mongo.Collection {
+	return m.collection("panoptireactjis_received")
+}
+
 // UpsertEmojiCount updates an emoji or reaction model's count to increase or
 // decrease it depending on the decrement argument. By default the usage count
 // is incremented.
@@ -252,7 +339,9 @@ func (m mongoStorage) UpsertEmojiCount(emoji models.Emoji, decrement bool) (mode
 	collection := m.emojiCollection()
 	// But if the emoji is a reaction, use the reaction collection instead (legacy
 	// reasons)...
-	if emoji.Reaction {
+	if emoji.Received {
+		collection = m.receivedReactionCollection()
+	} else if emoji.Reaction {
 		collection = m.reactionCollection()
 	}
 
@@ -289,106 +378,1358 @@ func (m mongoStorage) UpsertEmojiCount(emoji models.Emoji, decrement bool) (mode
 
 	// Because legacy data doesn't store the reaction field set it to match the lookup.
 	updatedEmoji.Reaction = emoji.Reaction
+	updatedEmoji.Received = emoji.Received
 
 	return updatedEmoji, nil
 }
 
-type errNoSuchCollection struct {
-	name string
+// emojiUsageCollection returns the collection for daily emoji usage buckets.
+func (m mongoStorage) emojiUsageCollection() *mongo.Collection {
+	return m.collection("panoptimoji_usage")
 }
 
-func (e errNoSuchCollection) Error() string {
-	return fmt.Sprintf("no such collection: %q", e.name)
+// reactionUsageCollection returns the collection for daily reaction emoji
+// usage buckets.
+func (m mongoStorage) reactionUsageCollection() *mongo.Collection {
+	return m.collection("panoptireactji_usage")
 }
 
-// UpsertURLCount updates a URLCount model's occurrence count.
-func (m mongoStorage) UpsertURLCount(collectionName string, urlCount models.URLCount) (models.URLCount, error) {
+// UpsertEmojiUsage updates a daily emoji or reaction usage model's count to
+// increase or decrease it depending on the decrement argument. By default the
+// usage count is incremented.
+func (m mongoStorage) UpsertEmojiUsage(usage models.EmojiUsage, decrement bool) (models.EmojiUsage, error) {
 	ctx := m.writeCtx()
 
-	collection := m.collection(collectionName)
-	if collection == nil {
-		return models.URLCount{}, errNoSuchCollection{collectionName}
+	collection := m.emojiUsageCollection()
+	if usage.Reaction {
+		collection = m.reactionUsageCollection()
 	}
 
-	// Filter by URL
+	// Filter by user/emoji/channel/day
 	filter := bson.D{
-		bson.E{Key: "url", Value: urlCount.URL},
+		bson.E{Key: "user", Value: usage.User},
+		bson.E{Key: "emoji", Value: usage.Emoji},
+		bson.E{Key: "channel", Value: usage.Channel},
+		bson.E{Key: "day", Value: usage.Day},
 	}
-	// Increment occurrences if found
-	update := bson.D{bson.E{
-		Key:   "$inc",
-		Value: bson.M{"occurrences": 1},
-	}}
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	update := bson.D{
+		bson.E{Key: "$inc", Value: bson.M{"count": updateCount}},
+		bson.E{Key: "$set", Value: bson.M{"reaction": usage.Reaction}},
+	}
+
 	// Upsert to add if not exists
 	opts := options.FindOneAndUpdate().SetUpsert(true)
 
-	var updatedCount models.URLCount
+	var updatedUsage models.EmojiUsage
 
-	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedCount)
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedUsage)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		return models.URLCount{}, fmt.Errorf("mongo upsert URL count failure: %w", err)
+		return models.EmojiUsage{}, fmt.Errorf("mongo upsert emoji usage failure: %w", err)
 	} else if errors.Is(err, mongo.ErrNoDocuments) {
-		urlCount.Occurrences = 0
-		return urlCount, nil
+		usage.Count = 0
+		return usage, nil
 	}
 
-	return updatedCount, nil
+	return updatedUsage, nil
 }
 
-// themeCollection returns the mongo collection for themes.
-func (m mongoStorage) themeCollection() *mongo.Collection {
-	return m.collection("themes")
+// reactedMessageCollection returns the collection for reacted message counts.
+func (m mongoStorage) reactedMessageCollection() *mongo.Collection {
+	return m.collection("reacted_messages")
 }
 
-// GetThemes returns theme models from the theme collection.
-func (m mongoStorage) GetThemes(opts storage.GetThemeOptions) ([]models.Theme, error) {
+// GetReactedMessages reads ReactedMessage models from the reacted messages
+// collection.
+func (m mongoStorage) GetReactedMessages(opts storage.GetReactedMessageOptions) ([]models.ReactedMessage, error) {
 	ctx := m.readCtx()
-
-	collection := m.themeCollection()
+	collection := m.reactedMessageCollection()
 
 	filter := bson.D{}
-	if opts.User != "" {
-		filter = append(filter, bson.E{Key: "user", Value: opts.User})
+	if opts.Channel != "" {
+		filter = append(filter, bson.E{Key: "channel", Value: opts.Channel})
 	}
 
-	if opts.Name != "" {
-		filter = append(filter, bson.E{Key: "name", Value: opts.Name})
+	if opts.User != "" {
+		filter = append(filter, bson.E{Key: "user", Value: opts.User})
 	}
 
 	findOpts := findOptions(opts.FindOptions)
 
 	cursor, err := collection.Find(ctx, filter, findOpts)
 	if err != nil {
-		return nil, fmt.Errorf("mongo client theme collection find err: %w", err)
+		return nil, fmt.Errorf("mongo client reacted messages collection find err: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	var results []models.Theme
+	var results []models.ReactedMessage
 
 	for cursor.Next(ctx) {
-		var theme models.Theme
-		if err := cursor.Decode(&theme); err != nil {
-			return nil, fmt.Errorf("mongo client theme decode err: %w", err)
+		var msg models.ReactedMessage
+		if err := cursor.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("mongo client reacted message decode err: %w", err)
 		}
 
-		results = append(results, theme)
+		results = append(results, msg)
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, fmt.Errorf("mongo client theme cursor err: %w", err)
+		return nil, fmt.Errorf("mongo client reacted message cursor err: %w", err)
 	}
 
 	return results, nil
 }
 
-// AddTheme adds a theme to the theme collection.
-func (m mongoStorage) AddTheme(theme models.Theme) error {
+// UpsertReactedMessage updates a reacted message model's count to increase or
+// decrease it depending on the decrement argument. By default the count is
+// incremented.
+func (m mongoStorage) UpsertReactedMessage(msg models.ReactedMessage, decrement bool) (models.ReactedMessage, error) {
 	ctx := m.writeCtx()
-	collection := m.themeCollection()
+	collection := m.reactedMessageCollection()
 
-	_, err := collection.InsertOne(ctx, theme)
+	// Filter by channel/timestamp
+	filter := bson.D{
+		bson.E{Key: "channel", Value: msg.Channel},
+		bson.E{Key: "timestamp", Value: msg.Timestamp},
+	}
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	update := bson.D{
+		bson.E{Key: "$inc", Value: bson.M{"count": updateCount}},
+		bson.E{Key: "$set", Value: bson.M{"user": msg.User}},
+	}
+
+	// Upsert to add if not exists
+	opts := options.FindOneAndUpdate().SetUpsert(true)
+
+	var updatedMsg models.ReactedMessage
+
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedMsg)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.ReactedMessage{}, fmt.Errorf("mongo upsert reacted message failure: %w", err)
+	} else if errors.Is(err, mongo.ErrNoDocuments) {
+		msg.Count = 0
+		return msg, nil
+	}
+
+	return updatedMsg, nil
+}
+
+// starboardCollection returns the collection for starboard message tallies.
+func (m mongoStorage) starboardCollection() *mongo.Collection {
+	return m.collection("starboard")
+}
+
+// starboardFilter returns a filter matching the starboard message model's
+// channel, timestamp and emoji.
+func starboardFilter(msg models.StarboardMessage) bson.D {
+	return bson.D{
+		bson.E{Key: "channel", Value: msg.Channel},
+		bson.E{Key: "timestamp", Value: msg.Timestamp},
+		bson.E{Key: "emoji", Value: msg.Emoji},
+	}
+}
+
+// UpsertStarboardCount updates a starboard message model's count to increase
+// or decrease it depending on the decrement argument. By default the count is
+// incremented.
+func (m mongoStorage) UpsertStarboardCount(msg models.StarboardMessage, decrement bool) (models.StarboardMessage, error) {
+	ctx := m.writeCtx()
+	collection := m.starboardCollection()
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	update := bson.D{bson.E{
+		Key:   "$inc",
+		Value: bson.M{"count": updateCount},
+	}}
+
+	// Upsert to add if not exists
+	opts := options.FindOneAndUpdate().SetUpsert(true)
+
+	var updatedMsg models.StarboardMessage
+
+	err := collection.FindOneAndUpdate(ctx, starboardFilter(msg), update, opts).Decode(&updatedMsg)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.StarboardMessage{}, fmt.Errorf("mongo upsert starboard count failure: %w", err)
+	} else if errors.Is(err, mongo.ErrNoDocuments) {
+		msg.Count = 0
+		msg.Posted = false
+
+		return msg, nil
+	}
+
+	return updatedMsg, nil
+}
+
+// MarkStarboardPosted sets the posted field of a starboard message model,
+// returning true only if it wasn't already set. The check and update are a
+// single atomic operation.
+func (m mongoStorage) MarkStarboardPosted(msg models.StarboardMessage) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.starboardCollection()
+
+	filter := append(starboardFilter(msg),
+		bson.E{Key: "posted", Value: bson.M{"$ne": true}})
+	update := bson.D{bson.E{
+		Key:   "$set",
+		Value: bson.M{"posted": true},
+	}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("mongo client theme add err: %w", err)
+		return false, fmt.Errorf("mongo mark starboard posted failure: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// usageMatch returns a filter for emoji documents matching the non-empty
+// user, emoji and channel arguments and, for daily usage buckets, the
+// non-zero since and until times.
+func usageMatch(user, emoji, channel string, since, until time.Time) bson.D {
+	match := bson.D{}
+	if user != "" {
+		match = append(match, bson.E{Key: "user", Value: user})
+	}
+
+	if emoji != "" {
+		match = append(match, bson.E{Key: "emoji", Value: emoji})
+	}
+
+	if channel != "" {
+		match = append(match, bson.E{Key: "channel", Value: channel})
+	}
+
+	day := bson.D{}
+	if !since.IsZero() {
+		day = append(day, bson.E{Key: "$gte", Value: models.UsageDay(since)})
+	}
+
+	if !until.IsZero() {
+		day = append(day, bson.E{Key: "$lt", Value: until})
+	}
+
+	if len(day) > 0 {
+		match = append(match, bson.E{Key: "day", Value: day})
+	}
+
+	return match
+}
+
+// windowedEmojiPipeline returns an aggregation pipeline that sums the counts of
+// the daily emoji usage buckets matching the options by user and emoji. Totals
+// that aren't positive (e.g. reactions that were added and removed) are
+// omitted.
+func windowedEmojiPipeline(opts storage.GetEmojiOptions) mongo.Pipeline {
+	match := usageMatch(opts.User, opts.Emoji, opts.Channel, opts.Since, opts.Until)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "user", Value: "$user"},
+				{Key: "emoji", Value: "$emoji"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "user", Value: "$_id.user"},
+			{Key: "emoji", Value: "$_id.emoji"},
+			{Key: "count", Value: 1},
+		}}},
+	}
+
+	if opts.SortField != "" {
+		sortValue := -1
+		if opts.Asc {
+			sortValue = 1
+		}
+
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{
+			{Key: opts.SortField, Value: sortValue},
+		}}})
+	}
+
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: opts.Limit}})
+	}
+
+	return pipeline
+}
+
+// getWindowedEmoji aggregates Emoji models from the daily emoji usage, or
+// reaction usage, collection as appropriate.
+func (m mongoStorage) getWindowedEmoji(opts storage.GetEmojiOptions) ([]models.Emoji, error) {
+	ctx := m.readCtx()
+
+	collection := m.emojiUsageCollection()
+	if opts.Reaction {
+		collection = m.reactionUsageCollection()
+	}
+
+	cursor, err := collection.Aggregate(ctx, windowedEmojiPipeline(opts))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client emoji usage aggregate err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Emoji
+
+	for cursor.Next(ctx) {
+		var emoji models.Emoji
+		if err := cursor.Decode(&emoji); err != nil {
+			return nil, fmt.Errorf("mongo client emoji usage decode err: %w", err)
+		}
+
+		emoji.Reaction = opts.Reaction
+		results = append(results, emoji)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client emoji usage cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// emojiHistoryPipeline returns an aggregation pipeline that sums the counts of
+// the daily emoji usage buckets matching the options by day, oldest first.
+// Like windowedEmojiPipeline totals that aren't positive are omitted.
+func emojiHistoryPipeline(opts storage.GetEmojiHistoryOptions) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: usageMatch(opts.User, opts.Emoji, opts.Channel, opts.Since, opts.Until)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$day"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "day", Value: "$_id"},
+			{Key: "count", Value: 1},
+		}}},
+	}
+}
+
+// GetEmojiHistory aggregates daily totals from the daily emoji usage, or
+// reaction usage, collection as appropriate.
+func (m mongoStorage) GetEmojiHistory(opts storage.GetEmojiHistoryOptions) ([]models.EmojiUsage, error) {
+	ctx := m.readCtx()
+
+	collection := m.emojiUsageCollection()
+	if opts.Reaction {
+		collection = m.reactionUsageCollection()
+	}
+
+	cursor, err := collection.Aggregate(ctx, emojiHistoryPipeline(opts))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client emoji history aggregate err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.EmojiUsage
+
+	for cursor.Next(ctx) {
+		var usage models.EmojiUsage
+		if err := cursor.Decode(&usage); err != nil {
+			return nil, fmt.Errorf("mongo client emoji history decode err: %w", err)
+		}
+
+		usage.Reaction = opts.Reaction
+		results = append(results, usage)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client emoji history cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// leaderboardPipeline returns an aggregation pipeline that sums the counts of
+// the emoji documents matching the options by emoji or user, as requested.
+// Like windowedEmojiPipeline totals that aren't positive are omitted. Entries
+// with equal counts are ordered by key.
+func leaderboardPipeline(opts storage.GetLeaderboardOptions) mongo.Pipeline {
+	groupKey := "$emoji"
+	if opts.GroupBy == storage.GroupByUser {
+		groupKey = "$user"
+	}
+
+	sortValue := -1
+	if opts.Asc {
+		sortValue = 1
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: usageMatch("", opts.Emoji, opts.Channel, opts.Since, opts.Until)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: groupKey},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "count", Value: sortValue},
+			{Key: "_id", Value: 1},
+		}}},
+	}
+
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: opts.Limit}})
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.D{
+		{Key: "_id", Value: 0},
+		{Key: "key", Value: "$_id"},
+		{Key: "count", Value: 1},
+	}}})
+
+	return pipeline
+}
+
+// GetLeaderboard aggregates leaderboard entries from the emoji or reactji
+// collection, or for windowed options the daily emoji or reactji usage
+// collection, as appropriate.
+func (m mongoStorage) GetLeaderboard(opts storage.GetLeaderboardOptions) ([]models.LeaderboardEntry, error) {
+	ctx := m.readCtx()
+
+	var collection *mongo.Collection
+
+	switch {
+	case opts.Windowed() && opts.Reaction:
+		collection = m.reactionUsageCollection()
+	case opts.Windowed():
+		collection = m.emojiUsageCollection()
+	case opts.Reaction:
+		collection = m.reactionCollection()
+	default:
+		collection = m.emojiCollection()
+	}
+
+	cursor, err := collection.Aggregate(ctx, leaderboardPipeline(opts))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client leaderboard aggregate err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.LeaderboardEntry
+
+	for cursor.Next(ctx) {
+		var entry models.LeaderboardEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("mongo client leaderboard decode err: %w", err)
+		}
+
+		results = append(results, entry)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client leaderboard cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+type errNoSuchCollection struct {
+	name string
+}
+
+func (e errNoSuchCollection) Error() string {
+	return fmt.Sprintf("no such collection: %q", e.name)
+}
+
+// UpsertURLCount updates a URLCount model's occurrence count.
+func (m mongoStorage) UpsertURLCount(collectionName string, urlCount models.URLCount) (models.URLCount, error) {
+	ctx := m.writeCtx()
+
+	collection := m.collection(collectionName)
+	if collection == nil {
+		return models.URLCount{}, errNoSuchCollection{collectionName}
+	}
+
+	// Filter by URL
+	filter := bson.D{
+		bson.E{Key: "url", Value: urlCount.URL},
+	}
+	// Increment occurrences and update the last seen time if found, setting the
+	// first seen fields if not.
+	update := bson.D{
+		bson.E{Key: "$inc", Value: bson.M{"occurrences": 1}},
+		bson.E{Key: "$set", Value: bson.M{"lastseen": urlCount.LastSeen}},
+		bson.E{Key: "$setOnInsert", Value: bson.M{
+			"firstuser":      urlCount.FirstUser,
+			"firstchannel":   urlCount.FirstChannel,
+			"firstpublic":    urlCount.FirstPublic,
+			"firsttimestamp": urlCount.FirstTimestamp,
+			"firstseen":      urlCount.FirstSeen,
+		}},
+	}
+	// Upsert to add if not exists
+	opts := options.FindOneAndUpdate().SetUpsert(true)
+
+	var updatedCount models.URLCount
+
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedCount)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.URLCount{}, fmt.Errorf("mongo upsert URL count failure: %w", err)
+	} else if errors.Is(err, mongo.ErrNoDocuments) {
+		urlCount.Occurrences = 0
+		return urlCount, nil
+	}
+
+	return updatedCount, nil
+}
+
+// GetURLCounts returns URLCount models from the named collection.
+func (m mongoStorage) GetURLCounts(collectionName string, opts storage.GetURLCountOptions) ([]models.URLCount, error) {
+	ctx := m.readCtx()
+
+	collection := m.collection(collectionName)
+	if collection == nil {
+		return nil, errNoSuchCollection{collectionName}
+	}
+
+	filter := bson.D{}
+	if opts.URL != "" {
+		filter = append(filter, bson.E{Key: "url", Value: opts.URL})
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions(opts.FindOptions))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client URL count collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.URLCount
+
+	for cursor.Next(ctx) {
+		var urlCount models.URLCount
+		if err := cursor.Decode(&urlCount); err != nil {
+			return nil, fmt.Errorf("mongo client URL count decode err: %w", err)
+		}
+
+		results = append(results, urlCount)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client URL count cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// linkShareCollection returns the mongo collection for link shares.
+func (m mongoStorage) linkShareCollection() *mongo.Collection {
+	return m.collection("link_shares")
+}
+
+// AddLinkShare inserts the provided link share model in the link share
+// collection.
+func (m mongoStorage) AddLinkShare(share models.LinkShare) error {
+	ctx := m.writeCtx()
+	collection := m.linkShareCollection()
+
+	if _, err := collection.InsertOne(ctx, share); err != nil {
+		return fmt.Errorf("mongo client add link share err: %w", err)
+	}
+
+	return nil
+}
+
+// GetLinkShares returns link share models from the link share collection.
+func (m mongoStorage) GetLinkShares(opts storage.GetLinkShareOptions) ([]models.LinkShare, error) {
+	ctx := m.readCtx()
+	collection := m.linkShareCollection()
+
+	filter := bson.D{}
+	if opts.URL != "" {
+		filter = append(filter, bson.E{Key: "url", Value: opts.URL})
+	}
+
+	if !opts.Since.IsZero() {
+		filter = append(filter, bson.E{Key: "time", Value: bson.D{bson.E{Key: "$gte", Value: opts.Since}}})
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions(opts.FindOptions))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client link share collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.LinkShare
+
+	for cursor.Next(ctx) {
+		var share models.LinkShare
+		if err := cursor.Decode(&share); err != nil {
+			return nil, fmt.Errorf("mongo client link share decode err: %w", err)
+		}
+
+		results = append(results, share)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client link share cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// themeCollection returns the mongo collection for themes.
+func (m mongoStorage) themeCollection() *mongo.Collection {
+	return m.collection("themes")
+}
+
+// GetThemes returns theme models from the theme collection.
+func (m mongoStorage) GetThemes(opts storage.GetThemeOptions) ([]models.Theme, error) {
+	ctx := m.readCtx()
+
+	collection := m.themeCollection()
+
+	filter := bson.D{}
+	if opts.User != "" {
+		filter = append(filter, bson.E{Key: "user", Value: opts.User})
+	}
+
+	if opts.Name != "" {
+		filter = append(filter, bson.E{Key: "name", Value: opts.Name})
+	}
+
+	findOpts := findOptions(opts.FindOptions)
+
+	cursor, err := collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("mongo client theme collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Theme
+
+	for cursor.Next(ctx) {
+		var theme models.Theme
+		if err := cursor.Decode(&theme); err != nil {
+			return nil, fmt.Errorf("mongo client theme decode err: %w", err)
+		}
+
+		results = append(results, theme)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client theme cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddTheme adds a theme to the theme collection.
+func (m mongoStorage) AddTheme(theme models.Theme) error {
+	ctx := m.writeCtx()
+	collection := m.themeCollection()
+
+	_, err := collection.InsertOne(ctx, theme)
+	if err != nil {
+		return fmt.Errorf("mongo client theme add err: %w", err)
+	}
+
+	return nil
+}
+
+// scheduledRunCollection returns the collection for scheduled runs.
+func (m mongoStorage) scheduledRunCollection() *mongo.Collection {
+	return m.collection("scheduled_runs")
+}
+
+// GetScheduledRuns reads every ScheduledRun model from the scheduled runs
+// collection.
+func (m mongoStorage) GetScheduledRuns() ([]models.ScheduledRun, error) {
+	ctx := m.readCtx()
+	collection := m.scheduledRunCollection()
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("mongo client scheduled run collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.ScheduledRun
+
+	for cursor.Next(ctx) {
+		var run models.ScheduledRun
+		if err := cursor.Decode(&run); err != nil {
+			return nil, fmt.Errorf("mongo client scheduled run decode err: %w", err)
+		}
+
+		results = append(results, run)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client scheduled run cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// UpsertScheduledRun sets the LastRun of the scheduled run with the same
+// command and channel, adding it if it doesn't exist.
+func (m mongoStorage) UpsertScheduledRun(run models.ScheduledRun) error {
+	ctx := m.writeCtx()
+	collection := m.scheduledRunCollection()
+
+	filter := bson.D{
+		bson.E{Key: "command", Value: run.Command},
+		bson.E{Key: "channel", Value: run.Channel},
+	}
+	update := bson.D{bson.E{
+		Key:   "$set",
+		Value: bson.M{"lastrun": run.LastRun},
+	}}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongo upsert scheduled run failure: %w", err)
+	}
+
+	return nil
+}
+
+// karmaCollection returns the collection for karma.
+func (m mongoStorage) karmaCollection() *mongo.Collection {
+	return m.collection("karma")
+}
+
+// GetKarma reads Karma models from the karma collection.
+func (m mongoStorage) GetKarma(opts storage.GetKarmaOptions) ([]models.Karma, error) {
+	ctx := m.readCtx()
+	collection := m.karmaCollection()
+
+	filter := bson.D{}
+	if opts.Thing != "" {
+		filter = append(filter,
+			bson.E{Key: "thing", Value: opts.Thing},
+			bson.E{Key: "user", Value: opts.User})
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions(opts.FindOptions))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client karma collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Karma
+
+	for cursor.Next(ctx) {
+		var karma models.Karma
+		if err := cursor.Decode(&karma); err != nil {
+			return nil, fmt.Errorf("mongo client karma decode err: %w", err)
+		}
+
+		results = append(results, karma)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client karma cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// UpsertKarma increments or decrements the count of the karma model with the
+// same thing and user, adding it if it doesn't exist. It returns the model as
+// it was before the update.
+func (m mongoStorage) UpsertKarma(karma models.Karma, decrement bool) (models.Karma, error) {
+	ctx := m.writeCtx()
+	collection := m.karmaCollection()
+
+	filter := bson.D{
+		bson.E{Key: "thing", Value: karma.Thing},
+		bson.E{Key: "user", Value: karma.User},
+	}
+
+	updateCount := 1
+	if decrement {
+		updateCount = -1
+	}
+
+	update := bson.D{bson.E{
+		Key:   "$inc",
+		Value: bson.M{"count": updateCount},
+	}}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true)
+
+	var updated models.Karma
+
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Karma{}, fmt.Errorf("mongo upsert karma failure: %w", err)
+	} else if errors.Is(err, mongo.ErrNoDocuments) {
+		karma.Count = 0
+		return karma, nil
+	}
+
+	return updated, nil
+}
+
+// quotesCollection returns the collection for quotes.
+func (m mongoStorage) quotesCollection() *mongo.Collection {
+	return m.collection("quotes")
+}
+
+// quotesFilter returns a filter for quote documents matching the options. The
+// search uses the quotes collection text index.
+func quotesFilter(opts storage.GetQuoteOptions) bson.D {
+	filter := bson.D{}
+	if opts.Author != "" {
+		filter = append(filter, bson.E{Key: "author", Value: opts.Author})
+	}
+
+	if opts.Search != "" {
+		filter = append(filter, bson.E{Key: "$text", Value: bson.D{{Key: "$search", Value: opts.Search}}})
+	}
+
+	return filter
+}
+
+// GetQuotes reads Quote models from the mongo quotes collection.
+func (m mongoStorage) GetQuotes(opts storage.GetQuoteOptions) ([]models.Quote, error) {
+	ctx := m.readCtx()
+	collection := m.quotesCollection()
+
+	cursor, err := collection.Find(ctx, quotesFilter(opts), findOptions(opts.FindOptions))
+	if err != nil {
+		return nil, fmt.Errorf("mongo client quotes collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Quote
+
+	for cursor.Next(ctx) {
+		var quote models.Quote
+		if err := cursor.Decode(&quote); err != nil {
+			return nil, fmt.Errorf("mongo client quote decode err: %w", err)
+		}
+
+		results = append(results, quote)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client quotes cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddQuote inserts the quote into the quotes collection unless a quote with
+// the same channel and timestamp exists. It returns true if the quote was
+// inserted.
+func (m mongoStorage) AddQuote(quote models.Quote) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.quotesCollection()
+
+	filter := bson.D{
+		bson.E{Key: "channel", Value: quote.Channel},
+		bson.E{Key: "timestamp", Value: quote.Timestamp},
+	}
+	update := bson.D{bson.E{Key: "$setOnInsert", Value: quote}}
+
+	res, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("mongo client add quote err: %w", err)
+	}
+
+	return res.UpsertedCount > 0, nil
+}
+
+// reminderCollection returns the collection for reminders.
+func (m mongoStorage) reminderCollection() *mongo.Collection {
+	return m.collection("reminders")
+}
+
+// GetReminders reads Reminder models from the reminders collection.
+func (m mongoStorage) GetReminders(opts storage.GetReminderOptions) ([]models.Reminder, error) {
+	filter := bson.D{}
+	if opts.Creator != "" {
+		filter = append(filter, bson.E{Key: "creator", Value: opts.Creator})
+	}
+
+	return m.findReminders(filter, findOptions(opts.FindOptions))
+}
+
+// GetDueReminders reads the Reminder models due at or before now from the
+// reminders collection, oldest first.
+func (m mongoStorage) GetDueReminders(now time.Time) ([]models.Reminder, error) {
+	filter := bson.D{bson.E{Key: "due", Value: bson.M{"$lte": now}}}
+
+	return m.findReminders(filter, findOptions(storage.FindOptions{SortField: "due", Asc: true}))
+}
+
+// findReminders reads the Reminder models matching the filter from the
+// reminders collection.
+func (m mongoStorage) findReminders(filter bson.D, findOpts *options.FindOptions) ([]models.Reminder, error) {
+	ctx := m.readCtx()
+	collection := m.reminderCollection()
+
+	cursor, err := collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("mongo client reminder collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Reminder
+
+	for cursor.Next(ctx) {
+		var reminder models.Reminder
+		if err := cursor.Decode(&reminder); err != nil {
+			return nil, fmt.Errorf("mongo client reminder decode err: %w", err)
+		}
+
+		results = append(results, reminder)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client reminder cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddReminder adds a reminder to the reminders collection.
+func (m mongoStorage) AddReminder(reminder models.Reminder) error {
+	ctx := m.writeCtx()
+	collection := m.reminderCollection()
+
+	_, err := collection.InsertOne(ctx, reminder)
+	if err != nil {
+		return fmt.Errorf("mongo client reminder add err: %w", err)
+	}
+
+	return nil
+}
+
+// CancelReminder deletes the reminder with the given ID from the reminders
+// collection.
+func (m mongoStorage) CancelReminder(id string) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.reminderCollection()
+
+	result, err := collection.DeleteOne(ctx, bson.D{bson.E{Key: "id", Value: id}})
+	if err != nil {
+		return false, fmt.Errorf("mongo client reminder cancel err: %w", err)
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// pollsCollection returns the collection for polls.
+func (m mongoStorage) pollsCollection() *mongo.Collection {
+	return m.collection("polls")
+}
+
+// pollFilter returns a filter for the poll with the given channel ID and
+// timestamp, if it's open.
+func pollFilter(channel, timestamp string) bson.D {
+	return bson.D{
+		bson.E{Key: "channel", Value: channel},
+		bson.E{Key: "timestamp", Value: timestamp},
+		bson.E{Key: "closed", Value: false},
+	}
+}
+
+// pollsFilter returns a filter for poll documents matching the options.
+func pollsFilter(opts storage.GetPollOptions) bson.D {
+	filter := bson.D{}
+	if opts.Channel != "" {
+		filter = append(filter, bson.E{Key: "channel", Value: opts.Channel})
+	}
+
+	if opts.Timestamp != "" {
+		filter = append(filter, bson.E{Key: "timestamp", Value: opts.Timestamp})
+	}
+
+	if opts.Creator != "" {
+		filter = append(filter, bson.E{Key: "creator", Value: opts.Creator})
+	}
+
+	if opts.Open {
+		filter = append(filter, bson.E{Key: "closed", Value: false})
+	}
+
+	return filter
+}
+
+// GetPolls reads Poll models from the polls collection.
+func (m mongoStorage) GetPolls(opts storage.GetPollOptions) ([]models.Poll, error) {
+	return m.findPolls(pollsFilter(opts), findOptions(opts.FindOptions))
+}
+
+// GetDuePolls reads the open Poll models with a deadline at or before now from
+// the polls collection, oldest deadline first.
+func (m mongoStorage) GetDuePolls(now time.Time) ([]models.Poll, error) {
+	filter := bson.D{
+		bson.E{Key: "closed", Value: false},
+		bson.E{Key: "deadline", Value: bson.M{"$gt": time.Time{}, "$lte": now}},
+	}
+
+	return m.findPolls(filter, findOptions(storage.FindOptions{SortField: "deadline", Asc: true}))
+}
+
+// findPolls reads the Poll models matching the filter from the polls
+// collection.
+func (m mongoStorage) findPolls(filter bson.D, findOpts *options.FindOptions) ([]models.Poll, error) {
+	ctx := m.readCtx()
+	collection := m.pollsCollection()
+
+	cursor, err := collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("mongo client polls collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Poll
+
+	for cursor.Next(ctx) {
+		var poll models.Poll
+		if err := cursor.Decode(&poll); err != nil {
+			return nil, fmt.Errorf("mongo client poll decode err: %w", err)
+		}
+
+		results = append(results, poll)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client polls cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddPoll adds a poll to the polls collection.
+func (m mongoStorage) AddPoll(poll models.Poll) error {
+	ctx := m.writeCtx()
+	collection := m.pollsCollection()
+
+	// Store an empty array rather than null so votes can be added to it.
+	if poll.Votes == nil {
+		poll.Votes = []models.PollVote{}
+	}
+
+	if _, err := collection.InsertOne(ctx, poll); err != nil {
+		return fmt.Errorf("mongo client add poll err: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePollVote adds the vote to the votes of the open poll with the given
+// channel and timestamp, or pulls it from them if removed is true.
+func (m mongoStorage) UpdatePollVote(
+	channel, timestamp string, vote models.PollVote, removed bool) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.pollsCollection()
+
+	op := "$addToSet"
+	if removed {
+		op = "$pull"
+	}
+
+	update := bson.D{bson.E{Key: op, Value: bson.M{"votes": vote}}}
+
+	result, err := collection.UpdateOne(ctx, pollFilter(channel, timestamp), update)
+	if err != nil {
+		return false, fmt.Errorf("mongo client poll vote update err: %w", err)
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ClosePoll sets the closed field of the open poll with the given channel and
+// timestamp.
+func (m mongoStorage) ClosePoll(channel, timestamp string) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.pollsCollection()
+
+	update := bson.D{bson.E{Key: "$set", Value: bson.M{"closed": true}}}
+
+	result, err := collection.UpdateOne(ctx, pollFilter(channel, timestamp), update)
+	if err != nil {
+		return false, fmt.Errorf("mongo client poll close err: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// markovCollection returns the collection for Markov transitions.
+func (m mongoStorage) markovCollection() *mongo.Collection {
+	return m.collection("markov")
+}
+
+// markovOptOutsCollection returns the collection for users that opted out of
+// Markov models.
+func (m mongoStorage) markovOptOutsCollection() *mongo.Collection {
+	return m.collection("markov_optouts")
+}
+
+// AddMarkovTransitions upserts each transition, incrementing the count of the
+// existing transition with the same model, prefix and next word.
+func (m mongoStorage) AddMarkovTransitions(transitions []models.MarkovTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	ctx := m.writeCtx()
+	collection := m.markovCollection()
+
+	writes := make([]mongo.WriteModel, 0, len(transitions))
+
+	for _, t := range transitions {
+		filter := bson.D{
+			bson.E{Key: "model", Value: t.Model},
+			bson.E{Key: "prefix", Value: t.Prefix},
+			bson.E{Key: "next", Value: t.Next},
+		}
+		update := bson.D{bson.E{Key: "$inc", Value: bson.M{"count": t.Count}}}
+
+		writes = append(writes,
+			mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("mongo client add markov transitions err: %w", err)
+	}
+
+	return nil
+}
+
+// GetMarkovTransitions reads the MarkovTransition models of the named model
+// with the given prefix from the markov collection.
+func (m mongoStorage) GetMarkovTransitions(model string, prefix []string) ([]models.MarkovTransition, error) {
+	ctx := m.readCtx()
+	collection := m.markovCollection()
+
+	filter := bson.D{
+		bson.E{Key: "model", Value: model},
+		bson.E{Key: "prefix", Value: prefix},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("mongo client markov collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.MarkovTransition
+
+	for cursor.Next(ctx) {
+		var transition models.MarkovTransition
+		if err := cursor.Decode(&transition); err != nil {
+			return nil, fmt.Errorf("mongo client markov decode err: %w", err)
+		}
+
+		results = append(results, transition)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client markov cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// DeleteMarkovModel deletes the transitions of the named model from the
+// markov collection.
+func (m mongoStorage) DeleteMarkovModel(model string) error {
+	ctx := m.writeCtx()
+	collection := m.markovCollection()
+
+	if _, err := collection.DeleteMany(ctx, bson.D{bson.E{Key: "model", Value: model}}); err != nil {
+		return fmt.Errorf("mongo client markov delete err: %w", err)
+	}
+
+	return nil
+}
+
+// GetMarkovOptOut returns true if the user is in the markov_optouts
+// collection.
+func (m mongoStorage) GetMarkovOptOut(user string) (bool, error) {
+	ctx := m.readCtx()
+	collection := m.markovOptOutsCollection()
+
+	count, err := collection.CountDocuments(ctx, bson.D{bson.E{Key: "user", Value: user}})
+	if err != nil {
+		return false, fmt.Errorf("mongo client markov opt out count err: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// SetMarkovOptOut adds the user to the markov_optouts collection, or removes
+// them from it if optOut is false.
+func (m mongoStorage) SetMarkovOptOut(user string, optOut bool) error {
+	ctx := m.writeCtx()
+	collection := m.markovOptOutsCollection()
+
+	filter := bson.D{bson.E{Key: "user", Value: user}}
+
+	var err error
+	if optOut {
+		update := bson.D{bson.E{Key: "$set", Value: bson.M{"user": user}}}
+		_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	} else {
+		_, err = collection.DeleteOne(ctx, filter)
+	}
+
+	if err != nil {
+		return fmt.Errorf("mongo client markov opt out update err: %w", err)
+	}
+
+	return nil
+}
+
+// reactjiRulesCollection returns the collection for reactji rules.
+func (m mongoStorage) reactjiRulesCollection() *mongo.Collection {
+	return m.collection("reactji_rules")
+}
+
+// GetReactjiRules reads all of the ReactjiRule models from the reactji rules
+// collection.
+func (m mongoStorage) GetReactjiRules() ([]models.ReactjiRule, error) {
+	ctx := m.readCtx()
+	collection := m.reactjiRulesCollection()
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("mongo client reactji rules collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.ReactjiRule
+
+	for cursor.Next(ctx) {
+		var rule models.ReactjiRule
+		if err := cursor.Decode(&rule); err != nil {
+			return nil, fmt.Errorf("mongo client reactji rule decode err: %w", err)
+		}
+
+		results = append(results, rule)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client reactji rule cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddReactjiRule adds a rule to the reactji rules collection.
+func (m mongoStorage) AddReactjiRule(rule models.ReactjiRule) error {
+	ctx := m.writeCtx()
+	collection := m.reactjiRulesCollection()
+
+	if _, err := collection.InsertOne(ctx, rule); err != nil {
+		return fmt.Errorf("mongo client reactji rule add err: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveReactjiRule deletes the rule with the given ID from the reactji rules
+// collection.
+func (m mongoStorage) RemoveReactjiRule(id string) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.reactjiRulesCollection()
+
+	result, err := collection.DeleteOne(ctx, bson.D{bson.E{Key: "id", Value: id}})
+	if err != nil {
+		return false, fmt.Errorf("mongo client reactji rule remove err: %w", err)
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// urlRulesCollection returns the collection for URL rules.
+func (m mongoStorage) urlRulesCollection() *mongo.Collection {
+	return m.collection("url_rules")
+}
+
+// GetURLRules reads all of the URLRule models from the URL rules collection.
+func (m mongoStorage) GetURLRules() ([]models.URLRule, error) {
+	ctx := m.readCtx()
+	collection := m.urlRulesCollection()
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("mongo client URL rules collection find err: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.URLRule
+
+	for cursor.Next(ctx) {
+		var rule models.URLRule
+		if err := cursor.Decode(&rule); err != nil {
+			return nil, fmt.Errorf("mongo client URL rule decode err: %w", err)
+		}
+
+		results = append(results, rule)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo client URL rule cursor err: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddURLRule inserts the rule into the URL rules collection unless a rule for
+// the same collection exists. It returns true if the rule was inserted.
+func (m mongoStorage) AddURLRule(rule models.URLRule) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.urlRulesCollection()
+
+	filter := bson.D{bson.E{Key: "collection", Value: rule.Collection}}
+	update := bson.D{bson.E{Key: "$setOnInsert", Value: rule}}
+
+	res, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("mongo client add URL rule err: %w", err)
+	}
+
+	return res.UpsertedCount > 0, nil
+}
+
+// RemoveURLRule deletes the rule for the given collection from the URL rules
+// collection.
+func (m mongoStorage) RemoveURLRule(collectionName string) (bool, error) {
+	ctx := m.writeCtx()
+	collection := m.urlRulesCollection()
+
+	result, err := collection.DeleteOne(ctx, bson.D{bson.E{Key: "collection", Value: collectionName}})
+	if err != nil {
+		return false, fmt.Errorf("mongo client URL rule remove err: %w", err)
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// Ping pings the MongoDB server using the read timeout.
+func (m mongoStorage) Ping() error {
+	if err := m.client.Ping(m.readCtx(), nil); err != nil {
+		return fmt.Errorf("mongo client ping err: %w", err)
 	}
 
 	return nil
@@ -1,9 +1,13 @@
 package mongo
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cpu/gorfbot/config"
+	"github.com/cpu/gorfbot/storage"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestNewMongoStorageNilConf(t *testing.T) {
@@ -17,3 +21,165 @@ func TestNewMongoStorageInvalidConf(t *testing.T) {
 		t.Error("expected err from NewMongoStorage w/ invalid config got nil")
 	}
 }
+
+func TestWindowedEmojiPipeline(t *testing.T) {
+	since := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	pipeline := windowedEmojiPipeline(storage.GetEmojiOptions{
+		FindOptions: storage.FindOptions{SortField: "count", Limit: 5},
+		User:        "U001",
+		Channel:     "C001",
+		Since:       since,
+		Until:       until,
+	})
+
+	if len(pipeline) != 6 {
+		t.Fatalf("expected 6 pipeline stages, got %d: %v", len(pipeline), pipeline)
+	}
+
+	// The since time is rounded down to the start of its day.
+	expectedMatch := bson.D{{Key: "$match", Value: bson.D{
+		{Key: "user", Value: "U001"},
+		{Key: "channel", Value: "C001"},
+		{Key: "day", Value: bson.D{
+			{Key: "$gte", Value: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)},
+			{Key: "$lt", Value: until},
+		}},
+	}}}
+	if !reflect.DeepEqual(pipeline[0], expectedMatch) {
+		t.Errorf("expected first stage %v, got %v", expectedMatch, pipeline[0])
+	}
+
+	expectedSort := bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}}
+	if !reflect.DeepEqual(pipeline[4], expectedSort) {
+		t.Errorf("expected sort stage %v, got %v", expectedSort, pipeline[4])
+	}
+
+	expectedLimit := bson.D{{Key: "$limit", Value: int64(5)}}
+	if !reflect.DeepEqual(pipeline[5], expectedLimit) {
+		t.Errorf("expected limit stage %v, got %v", expectedLimit, pipeline[5])
+	}
+
+	// Without sort or limit options there are no sort or limit stages.
+	if pipeline := windowedEmojiPipeline(storage.GetEmojiOptions{Channel: "C001"}); len(pipeline) != 4 {
+		t.Errorf("expected 4 pipeline stages, got %d: %v", len(pipeline), pipeline)
+	}
+}
+
+func TestLeaderboardPipeline(t *testing.T) {
+	pipeline := leaderboardPipeline(storage.GetLeaderboardOptions{
+		FindOptions: storage.FindOptions{Limit: 3, Asc: true},
+		GroupBy:     storage.GroupByUser,
+		Emoji:       ":wave:",
+	})
+
+	if len(pipeline) != 6 {
+		t.Fatalf("expected 6 pipeline stages, got %d: %v", len(pipeline), pipeline)
+	}
+
+	expectedMatch := bson.D{{Key: "$match", Value: bson.D{{Key: "emoji", Value: ":wave:"}}}}
+	if !reflect.DeepEqual(pipeline[0], expectedMatch) {
+		t.Errorf("expected match stage %v, got %v", expectedMatch, pipeline[0])
+	}
+
+	expectedGroup := bson.D{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: "$user"},
+		{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+	}}}
+	if !reflect.DeepEqual(pipeline[1], expectedGroup) {
+		t.Errorf("expected group stage %v, got %v", expectedGroup, pipeline[1])
+	}
+
+	expectedSort := bson.D{{Key: "$sort", Value: bson.D{
+		{Key: "count", Value: 1},
+		{Key: "_id", Value: 1},
+	}}}
+	if !reflect.DeepEqual(pipeline[3], expectedSort) {
+		t.Errorf("expected sort stage %v, got %v", expectedSort, pipeline[3])
+	}
+
+	// Without a limit there's no limit stage.
+	if pipeline := leaderboardPipeline(storage.GetLeaderboardOptions{}); len(pipeline) != 5 {
+		t.Errorf("expected 5 pipeline stages, got %d: %v", len(pipeline), pipeline)
+	}
+}
+
+func TestEmojiHistoryPipeline(t *testing.T) {
+	pipeline := emojiHistoryPipeline(storage.GetEmojiHistoryOptions{
+		User:  "U001",
+		Emoji: ":wave:",
+	})
+
+	if len(pipeline) != 5 {
+		t.Fatalf("expected 5 pipeline stages, got %d: %v", len(pipeline), pipeline)
+	}
+
+	expectedMatch := bson.D{{Key: "$match", Value: bson.D{
+		{Key: "user", Value: "U001"},
+		{Key: "emoji", Value: ":wave:"},
+	}}}
+	if !reflect.DeepEqual(pipeline[0], expectedMatch) {
+		t.Errorf("expected match stage %v, got %v", expectedMatch, pipeline[0])
+	}
+
+	expectedGroup := bson.D{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: "$day"},
+		{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+	}}}
+	if !reflect.DeepEqual(pipeline[1], expectedGroup) {
+		t.Errorf("expected group stage %v, got %v", expectedGroup, pipeline[1])
+	}
+
+	expectedSort := bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}}
+	if !reflect.DeepEqual(pipeline[3], expectedSort) {
+		t.Errorf("expected sort stage %v, got %v", expectedSort, pipeline[3])
+	}
+}
+
+func TestTopicsFilter(t *testing.T) {
+	if filter := topicsFilter(storage.GetTopicOptions{}); len(filter) != 0 {
+		t.Errorf("expected empty filter without options, got %v", filter)
+	}
+
+	since := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	filter := topicsFilter(storage.GetTopicOptions{
+		Channel: "C001",
+		Creator: "U001",
+		Search:  "gorf frog",
+		Since:   since,
+		Until:   since.AddDate(0, 1, 0),
+	})
+
+	expected := bson.D{
+		{Key: "channel", Value: "C001"},
+		{Key: "creator", Value: "U001"},
+		{Key: "date", Value: bson.D{
+			{Key: "$gte", Value: "1614556800.000000"},
+			{Key: "$lt", Value: "1617235200.000000"},
+		}},
+		{Key: "$text", Value: bson.D{{Key: "$search", Value: "gorf frog"}}},
+	}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected filter %v, got %v", expected, filter)
+	}
+}
+
+func TestQuotesFilter(t *testing.T) {
+	if filter := quotesFilter(storage.GetQuoteOptions{}); len(filter) != 0 {
+		t.Errorf("expected empty filter without options, got %v", filter)
+	}
+
+	filter := quotesFilter(storage.GetQuoteOptions{
+		Author: "U001",
+		Search: "gorf frog",
+	})
+
+	expected := bson.D{
+		{Key: "author", Value: "U001"},
+		{Key: "$text", Value: bson.D{{Key: "$search", Value: "gorf frog"}}},
+	}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected filter %v, got %v", expected, filter)
+	}
+}
@@ -1,6 +1,11 @@
 package storage
 
-import "github.com/cpu/gorfbot/storage/models"
+import (
+	"errors"
+	"time"
+
+	"github.com/cpu/gorfbot/storage/models"
+)
 
 // FindOptions is a struct for options common to most find operations: limiting
 // result counts, sorting by a field name, and indicating if the sort is
@@ -18,10 +23,25 @@ type FindOptions struct {
 type GetTopicOptions struct {
 	FindOptions
 	// Channel ID of the channel to retrieve topics for (note: an ID like
-	// 'C123456' not a friendly name like '#general').
+	// 'C123456' not a friendly name like '#general'). Optional, topics from all
+	// channels are returned if empty.
 	Channel string
+	// Search is text to search topics for. Topics containing any of the words
+	// match. Optional.
+	Search string
+	// Creator is the ID of the user that set the topics (note: an ID like
+	// 'U1234' not a friendly name like '@daniel'). Optional.
+	Creator string
+	// Since limits the topics to those set at or after the given time. Optional.
+	Since time.Time
+	// Until limits the topics to those set before the given time. Optional.
+	Until time.Time
 }
 
+// ErrWindowedReceived is returned from GetEmoji when windowed options are used
+// to get received reactions.
+var ErrWindowedReceived = errors.New("windowed options are not supported for received reactions")
+
 // GetEmojiOptions is a struct for customizing GetEmoji.
 type GetEmojiOptions struct {
 	FindOptions
@@ -33,6 +53,110 @@ type GetEmojiOptions struct {
 	// Reaction indicates if the returned emoji info should be for reactions, or
 	// normal emoji usage in messages (default).
 	Reaction bool
+	// Received indicates if the returned emoji info should be for reactions
+	// received by the user instead of reactions added by the user. Received
+	// implies Reaction. Windowed options are not supported for received
+	// reactions.
+	Received bool
+	// Since limits results to emoji usage on or after the day of the given time.
+	// Optional.
+	Since time.Time
+	// Until limits results to emoji usage before the given time. Optional.
+	Until time.Time
+	// Channel ID of the channel to retrieve emoji history for (note: an ID like
+	// 'C123456' not a friendly name like '#general'). Optional.
+	Channel string
+}
+
+// Windowed returns true if the options filter by date or channel. Windowed
+// results are computed by summing daily EmojiUsage buckets instead of from the
+// lifetime Emoji counts.
+func (o GetEmojiOptions) Windowed() bool {
+	return !o.Since.IsZero() || !o.Until.IsZero() || o.Channel != ""
+}
+
+// LeaderboardGroup describes how a leaderboard aggregates usage.
+type LeaderboardGroup int
+
+const (
+	// GroupByEmoji ranks emoji by their usage across all users.
+	GroupByEmoji LeaderboardGroup = iota
+	// GroupByUser ranks users by their usage of emoji.
+	GroupByUser
+)
+
+// GetLeaderboardOptions is a struct for customizing GetLeaderboard. Entries are
+// always sorted by count, the FindOptions SortField is ignored.
+type GetLeaderboardOptions struct {
+	FindOptions
+	// GroupBy determines if emoji or users are ranked.
+	GroupBy LeaderboardGroup
+	// Emoji name to limit the leaderboard to. Optional. Combined with
+	// GroupByUser this ranks the users of a single emoji.
+	Emoji string
+	// Reaction indicates if the leaderboard should be for reactions, or normal
+	// emoji usage in messages (default).
+	Reaction bool
+	// Since limits the leaderboard to usage on or after the day of the given
+	// time. Optional.
+	Since time.Time
+	// Until limits the leaderboard to usage before the given time. Optional.
+	Until time.Time
+	// Channel ID of the channel to limit the leaderboard to (note: an ID like
+	// 'C123456' not a friendly name like '#general'). Optional.
+	Channel string
+}
+
+// Windowed returns true if the options filter by date or channel. Like
+// GetEmojiOptions, windowed leaderboards are computed from the daily
+// EmojiUsage buckets instead of the lifetime Emoji counts.
+func (o GetLeaderboardOptions) Windowed() bool {
+	return !o.Since.IsZero() || !o.Until.IsZero() || o.Channel != ""
+}
+
+// GetEmojiHistoryOptions is a struct for customizing GetEmojiHistory.
+type GetEmojiHistoryOptions struct {
+	// User ID of the user to retrieve emoji history for. Optional.
+	User string
+	// Emoji name to retrieve emoji history for. Optional.
+	Emoji string
+	// Reaction indicates if the history should be for reactions, or normal
+	// emoji usage in messages (default).
+	Reaction bool
+	// Since limits the history to usage on or after the day of the given time.
+	// Optional.
+	Since time.Time
+	// Until limits the history to usage before the given time. Optional.
+	Until time.Time
+	// Channel ID of the channel to retrieve emoji history for. Optional.
+	Channel string
+}
+
+// GetReactedMessageOptions is a struct for customizing GetReactedMessages.
+type GetReactedMessageOptions struct {
+	FindOptions
+	// Channel ID of the channel to retrieve reacted messages for (note: an ID
+	// like 'C123456' not a friendly name like '#general'). Optional.
+	Channel string
+	// User ID of the message author to retrieve reacted messages for (note: an
+	// ID like 'U1234' not a friendly name like '@daniel'). Optional.
+	User string
+}
+
+// GetURLCountOptions is a struct for customizing GetURLCounts.
+type GetURLCountOptions struct {
+	FindOptions
+	// URL limits the results to the model for the given URL. Optional.
+	URL string
+}
+
+// GetLinkShareOptions is a struct for customizing GetLinkShares.
+type GetLinkShareOptions struct {
+	FindOptions
+	// URL limits the results to shares of the given canonical URL. Optional.
+	URL string
+	// Since limits the results to shares at or after the given time. Optional.
+	Since time.Time
 }
 
 // GetThemeOptions is a struct for customizing GetThemes.
@@ -46,6 +170,48 @@ type GetThemeOptions struct {
 	Name string
 }
 
+// GetReminderOptions is a struct for customizing GetReminders.
+type GetReminderOptions struct {
+	FindOptions
+	// Creator is a User ID that if provided will be used to limit results to
+	// just reminders created by that user ID.
+	Creator string
+}
+
+// GetKarmaOptions is a struct for customizing GetKarma.
+type GetKarmaOptions struct {
+	FindOptions
+	// Thing limits results to the karma for the thing (or user ID, if User is
+	// true) with this name. Optional.
+	Thing string
+	// User indicates if Thing is a user ID.
+	User bool
+}
+
+// GetQuoteOptions is a struct for customizing GetQuotes.
+type GetQuoteOptions struct {
+	FindOptions
+	// Author is the ID of the user that said the quoted messages (note: an ID
+	// like 'U1234' not a friendly name like '@daniel'). Optional.
+	Author string
+	// Search is text to search quotes for. Quotes containing any of the words
+	// match. Optional.
+	Search string
+}
+
+// GetPollOptions is a struct for customizing GetPolls.
+type GetPollOptions struct {
+	FindOptions
+	// Channel ID of the channel to retrieve polls for. Optional.
+	Channel string
+	// Timestamp of the poll message to retrieve. Optional.
+	Timestamp string
+	// Creator is the ID of the user that created the polls. Optional.
+	Creator string
+	// Open limits the polls to those that haven't been closed.
+	Open bool
+}
+
 //go:generate mockgen -destination=mocks/mock_storage.go -package=mocks . Storage
 // Storage is an interface describing all of the operations a Gorfbot storage
 // backend must provide.
@@ -63,12 +229,147 @@ type Storage interface {
 	// It returns the updated model.
 	UpsertEmojiCount(emoji models.Emoji, decrement bool) (models.Emoji, error)
 
+	// UpsertEmojiUsage upserts the provided daily emoji usage model (matching on
+	// user, emoji, channel and day) either increasing or decreasing the count
+	// based on the decrement parameter (default: increment). It returns the
+	// model as it was before the update.
+	UpsertEmojiUsage(usage models.EmojiUsage, decrement bool) (models.EmojiUsage, error)
+
+	// GetEmojiHistory returns the total emoji usage matching the options on each
+	// day, oldest first. Only the Day, Count and Reaction fields of the returned
+	// models are set and days without a positive total count are omitted.
+	GetEmojiHistory(opts GetEmojiHistoryOptions) ([]models.EmojiUsage, error)
+
+	// GetReactedMessages returns reacted message models matching the options
+	// criteria.
+	GetReactedMessages(opts GetReactedMessageOptions) ([]models.ReactedMessage, error)
+
+	// UpsertReactedMessage upserts the provided reacted message model (matching
+	// on channel and timestamp), either increasing or decreasing the count based
+	// on the decrement parameter (default: increment). It returns the model as it
+	// was before the update.
+	UpsertReactedMessage(msg models.ReactedMessage, decrement bool) (models.ReactedMessage, error)
+
+	// UpsertStarboardCount upserts the provided starboard message model
+	// (matching on channel, timestamp and emoji), either increasing or
+	// decreasing the count based on the decrement parameter (default:
+	// increment). It returns the model as it was before the update.
+	UpsertStarboardCount(msg models.StarboardMessage, decrement bool) (models.StarboardMessage, error)
+	// MarkStarboardPosted marks the existing starboard message model matching
+	// the provided model's channel, timestamp and emoji as posted. It returns
+	// true only if the model exists and wasn't already marked posted, so that
+	// concurrent callers can ensure a message is reposted once.
+	MarkStarboardPosted(msg models.StarboardMessage) (bool, error)
+
+	// GetLeaderboard returns leaderboard entries aggregating emoji usage across
+	// users, as described by the options.
+	GetLeaderboard(opts GetLeaderboardOptions) ([]models.LeaderboardEntry, error)
+
 	// UsertURLCount upserts the provided url model in the provided collection
-	// name. It returns the updated model.
+	// name. It returns the model as it was before the update, with zero
+	// Occurrences if the URL is new. The first seen fields of the provided model
+	// are only stored for new URLs, and its LastSeen always replaces the stored
+	// one.
 	UpsertURLCount(collection string, urlCount models.URLCount) (models.URLCount, error)
+	// GetURLCounts returns url models from the provided collection name matching
+	// the options criteria.
+	GetURLCounts(collection string, opts GetURLCountOptions) ([]models.URLCount, error)
+
+	// AddLinkShare adds the provided link share model.
+	AddLinkShare(share models.LinkShare) error
+	// GetLinkShares returns link share models matching the options criteria.
+	GetLinkShares(opts GetLinkShareOptions) ([]models.LinkShare, error)
 
 	// GetThemes returns theme models matching the options criteria.
 	GetThemes(opts GetThemeOptions) ([]models.Theme, error)
 	// AddTheme adds a theme model to the storage.
 	AddTheme(theme models.Theme) error
+
+	// GetScheduledRuns returns every scheduled run model.
+	GetScheduledRuns() ([]models.ScheduledRun, error)
+	// UpsertScheduledRun upserts the provided scheduled run model (matching on
+	// command and channel), setting its LastRun.
+	UpsertScheduledRun(run models.ScheduledRun) error
+
+	// GetKarma returns karma models matching the options criteria.
+	GetKarma(opts GetKarmaOptions) ([]models.Karma, error)
+	// UpsertKarma upserts the provided karma model (matching on thing and user),
+	// either increasing or decreasing the count based on the decrement
+	// parameter (default: increment). It returns the model as it was before the
+	// update.
+	UpsertKarma(karma models.Karma, decrement bool) (models.Karma, error)
+
+	// GetQuotes returns quote models matching the options criteria.
+	GetQuotes(opts GetQuoteOptions) ([]models.Quote, error)
+	// AddQuote adds the provided quote model unless a quote of the same message
+	// (matching on channel and timestamp) was already added. It returns true if
+	// the quote was added.
+	AddQuote(quote models.Quote) (bool, error)
+
+	// GetReminders returns reminder models matching the options criteria.
+	GetReminders(opts GetReminderOptions) ([]models.Reminder, error)
+	// GetDueReminders returns the reminder models due at or before the given
+	// time, oldest first.
+	GetDueReminders(now time.Time) ([]models.Reminder, error)
+	// AddReminder adds a reminder model to the storage.
+	AddReminder(reminder models.Reminder) error
+	// CancelReminder removes the reminder model with the given ID. It returns
+	// true only if the reminder existed, so that concurrent callers can ensure
+	// a reminder is delivered or cancelled once.
+	CancelReminder(id string) (bool, error)
+
+	// GetPolls returns poll models matching the options criteria.
+	GetPolls(opts GetPollOptions) ([]models.Poll, error)
+	// GetDuePolls returns the open poll models with a deadline at or before the
+	// given time, oldest deadline first.
+	GetDuePolls(now time.Time) ([]models.Poll, error)
+	// AddPoll adds a poll model to the storage.
+	AddPoll(poll models.Poll) error
+	// UpdatePollVote adds the vote to (or removes it from, if removed is true)
+	// the open poll with the given channel ID and timestamp. It returns true if
+	// there is such an open poll.
+	UpdatePollVote(channel, timestamp string, vote models.PollVote, removed bool) (bool, error)
+	// ClosePoll closes the open poll with the given channel ID and timestamp. It
+	// returns true only if the poll was open, so that concurrent callers can
+	// ensure a poll's results are announced once.
+	ClosePoll(channel, timestamp string) (bool, error)
+
+	// AddMarkovTransitions increases the counts of the provided Markov
+	// transition models (matching on model, prefix and next) by their counts,
+	// adding them if they don't exist.
+	AddMarkovTransitions(transitions []models.MarkovTransition) error
+	// GetMarkovTransitions returns the Markov transition models of the named
+	// model with the given prefix.
+	GetMarkovTransitions(model string, prefix []string) ([]models.MarkovTransition, error)
+	// DeleteMarkovModel removes all of the Markov transition models of the
+	// named model.
+	DeleteMarkovModel(model string) error
+	// GetMarkovOptOut returns true if the user with the given ID has opted out
+	// of having their messages learned by Markov models.
+	GetMarkovOptOut(user string) (bool, error)
+	// SetMarkovOptOut opts the user with the given ID out of (or back in to)
+	// having their messages learned by Markov models.
+	SetMarkovOptOut(user string, optOut bool) error
+
+	// GetReactjiRules returns all of the reactji rule models.
+	GetReactjiRules() ([]models.ReactjiRule, error)
+	// AddReactjiRule adds a reactji rule model to the storage.
+	AddReactjiRule(rule models.ReactjiRule) error
+	// RemoveReactjiRule removes the reactji rule model with the given ID. It
+	// returns true only if the rule existed.
+	RemoveReactjiRule(id string) (bool, error)
+
+	// GetURLRules returns all of the URL rule models.
+	GetURLRules() ([]models.URLRule, error)
+	// AddURLRule adds the provided URL rule model unless a rule for the same
+	// collection exists. It returns true if the rule was added.
+	AddURLRule(rule models.URLRule) (bool, error)
+	// RemoveURLRule removes the URL rule model for the given collection. It
+	// returns true only if the rule existed. URLs already tracked in the
+	// collection are kept.
+	RemoveURLRule(collection string) (bool, error)
+
+	// Ping checks that the storage backend is reachable, returning an error if
+	// it isn't.
+	Ping() error
 }